@@ -0,0 +1,111 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/search-aggregations-bucket-composite-aggregation.html
+type CompositeAggregation struct {
+	// Sources are the value sources of the composite key,
+	// e.g. {"tenant": {"terms": {"field": "tenant_id"}}}.
+	Sources []map[string]interface{}
+	// Size is the number of buckets fetched per page. Zero uses the server default (10).
+	Size int
+	// Aggregations are sub-aggregations computed for every bucket.
+	Aggregations map[string]interface{}
+}
+
+type CompositeBucket struct {
+	Key          map[string]interface{}
+	DocCount     int
+	Aggregations map[string]json.RawMessage
+}
+
+func (b *CompositeBucket) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(fields["key"], &b.Key); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(fields["doc_count"], &b.DocCount); err != nil {
+		return err
+	}
+	delete(fields, "key")
+	delete(fields, "doc_count")
+
+	// Sub-aggregation results are siblings of key and doc_count.
+	b.Aggregations = fields
+	return nil
+}
+
+const compositeAggregationName = "composite"
+
+// ScanCompositeAggregation runs agg over the documents matching query and calls fn for
+// every bucket, following after_key until all buckets are consumed.
+// query is a search body such as `{"query": {...}}` and may be empty to match all documents.
+// Scanning stops at the first error returned by fn, which is returned as is.
+func (es *_elasticsearch) ScanCompositeAggregation(index, query string, agg *CompositeAggregation, fn func(bucket *CompositeBucket) error) (StatusCode, error) {
+	if agg == nil || len(agg.Sources) == 0 {
+		return StatusInternalError, errors.New("Required composite sources")
+	}
+
+	body := map[string]interface{}{}
+	if query != "" {
+		if err := json.Unmarshal([]byte(query), &body); err != nil {
+			return StatusInternalError, err
+		}
+	}
+	body["size"] = 0
+
+	composite := map[string]interface{}{
+		"sources": agg.Sources,
+	}
+	if agg.Size > 0 {
+		composite["size"] = agg.Size
+	}
+
+	for {
+		aggregation := map[string]interface{}{
+			"composite": composite,
+		}
+		if len(agg.Aggregations) > 0 {
+			aggregation["aggs"] = agg.Aggregations
+		}
+		body["aggs"] = map[string]interface{}{
+			compositeAggregationName: aggregation,
+		}
+
+		b, err := json.Marshal(body)
+		if err != nil {
+			return StatusInternalError, err
+		}
+
+		status, result, err := es.SearchWithResult(index, string(b), nil)
+		if err != nil || status != StatusSuccess {
+			return status, err
+		}
+
+		var page struct {
+			AfterKey map[string]interface{} `json:"after_key"`
+			Buckets  []*CompositeBucket     `json:"buckets"`
+		}
+		if err := json.Unmarshal(result.Aggregations[compositeAggregationName], &page); err != nil {
+			return StatusParseError, err
+		}
+
+		for _, bucket := range page.Buckets {
+			if err := fn(bucket); err != nil {
+				return StatusSuccess, err
+			}
+		}
+
+		if len(page.Buckets) == 0 || page.AfterKey == nil {
+			return StatusSuccess, nil
+		}
+		composite["after"] = page.AfterKey
+	}
+}
@@ -0,0 +1,99 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/bxcodec/faker/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompositeBucketUnmarshalJSON(t *testing.T) {
+	var bucket CompositeBucket
+	err := json.Unmarshal([]byte(`{
+		"key": {"tenant": "a"},
+		"doc_count": 3,
+		"total": {"value": 42}
+	}`), &bucket)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "a", bucket.Key["tenant"])
+	assert.Equal(t, 3, bucket.DocCount)
+	assert.JSONEq(t, `{"value": 42}`, string(bucket.Aggregations["total"]))
+}
+
+func TestScanCompositeAggregation(t *testing.T) {
+	es := newElasticsearch()
+
+	group := faker.UUIDDigit()
+	keys := []string{faker.UUIDDigit(), faker.UUIDDigit(), faker.UUIDDigit()}
+	for i, key := range keys {
+		for j := 0; j <= i; j++ {
+			var data DocBody
+			faker.FakeData(&data)
+			data.Id = group
+			data.S = key
+			data.I = 1
+			es.CreateDocument(&Document{
+				Index: indexName,
+				ID:    faker.UUIDDigit(),
+				Body:  data,
+			})
+		}
+	}
+	es.Refresh(indexName)
+
+	query := fmt.Sprintf(`{
+		"query": {
+			"term": {
+				"id": "%s"
+			}
+		}
+	}`, group)
+	agg := &CompositeAggregation{
+		Sources: []map[string]interface{}{
+			{"s": map[string]interface{}{"terms": map[string]interface{}{"field": "s.keyword"}}},
+		},
+		Size: 2,
+		Aggregations: map[string]interface{}{
+			"total": map[string]interface{}{"sum": map[string]interface{}{"field": "i"}},
+		},
+	}
+
+	t.Run("All buckets", func(t *testing.T) {
+		counts := map[string]int{}
+		status, err := es.ScanCompositeAggregation(indexName, query, agg, func(bucket *CompositeBucket) error {
+			counts[bucket.Key["s"].(string)] = bucket.DocCount
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+		for i, key := range keys {
+			assert.Equal(t, i+1, counts[key])
+		}
+	})
+
+	t.Run("Stop by callback", func(t *testing.T) {
+		stop := errors.New("stop")
+		n := 0
+		_, err := es.ScanCompositeAggregation(indexName, query, agg, func(bucket *CompositeBucket) error {
+			n++
+			return stop
+		})
+
+		assert.ErrorIs(t, err, stop)
+		assert.Equal(t, 1, n)
+	})
+
+	t.Run("No sources", func(t *testing.T) {
+		status, err := es.ScanCompositeAggregation(indexName, query, &CompositeAggregation{}, func(bucket *CompositeBucket) error {
+			return nil
+		})
+
+		assert.Error(t, err)
+		assert.Equal(t, StatusInternalError, status)
+	})
+}
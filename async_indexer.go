@@ -0,0 +1,185 @@
+package elasticsearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrIndexerClosed is returned by AsyncIndexer.Add after Close.
+var ErrIndexerClosed = errors.New("elasticsearch: async indexer is closed")
+
+type AsyncIndexerConfig struct {
+	// BatchSize is the maximum number of items of a bulk request. Default: 500.
+	BatchSize int
+	// FlushInterval is the longest time an item waits for its batch to fill. Default: 1s.
+	FlushInterval time.Duration
+	// QueueSize is the number of items queued before Add blocks. Default: 2 * BatchSize.
+	QueueSize int
+	// Workers is the number of bulk requests in flight. Default: 1, which keeps the order of the items.
+	Workers int
+	// Refresh is the refresh policy of the bulk requests.
+	Refresh RefreshPolicy
+}
+
+// AsyncResult is the result of an item of AsyncIndexer.
+type AsyncResult struct {
+	Item *BulkItem
+	// Result is nil when the bulk request failed as a whole.
+	Result *BulkItemResult
+	// Err is the error of the item: an *ESError when it failed, or the error of the bulk request.
+	Err error
+}
+
+// AsyncIndexer indexes the items added to it with bulk requests in the background, e.g.
+// while consuming from a message queue:
+//
+//	indexer := NewAsyncIndexer(es, &AsyncIndexerConfig{})
+//	go func() {
+//		for r := range indexer.Results() {
+//			if r.Err != nil { ... } else { ... ack the message ... }
+//		}
+//	}()
+//	for msg := range messages {
+//		indexer.Add(ctx, &BulkItem{Index: "logs", Body: msg})
+//	}
+//	indexer.Close()
+//
+// Add blocks while the queue is full, so a slow cluster slows the producer down.
+// Results must be consumed, otherwise the indexer blocks too.
+type AsyncIndexer struct {
+	es      Elasticsearch
+	config  AsyncIndexerConfig
+	queue   chan *BulkItem
+	results chan *AsyncResult
+	wg      sync.WaitGroup
+
+	mu     sync.RWMutex
+	closed bool
+	// deregister removes Close from the closers of the client.
+	deregister func()
+}
+
+func NewAsyncIndexer(es Elasticsearch, config *AsyncIndexerConfig) *AsyncIndexer {
+	c := *config
+	if c.BatchSize <= 0 {
+		c.BatchSize = 500
+	}
+	if c.FlushInterval <= 0 {
+		c.FlushInterval = time.Second
+	}
+	if c.QueueSize <= 0 {
+		c.QueueSize = 2 * c.BatchSize
+	}
+	if c.Workers <= 0 {
+		c.Workers = 1
+	}
+
+	ai := &AsyncIndexer{
+		es:      es,
+		config:  c,
+		queue:   make(chan *BulkItem, c.QueueSize),
+		results: make(chan *AsyncResult, c.QueueSize),
+	}
+	for i := 0; i < c.Workers; i++ {
+		ai.wg.Add(1)
+		go ai.work()
+	}
+	ai.deregister = onClose(es, ai.Close)
+	return ai
+}
+
+// Add queues item, waiting while the queue is full until ctx is done.
+func (ai *AsyncIndexer) Add(ctx context.Context, item *BulkItem) error {
+	ai.mu.RLock()
+	defer ai.mu.RUnlock()
+	if ai.closed {
+		return ErrIndexerClosed
+	}
+
+	select {
+	case ai.queue <- item:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Results returns the results of the items, closed after Close.
+func (ai *AsyncIndexer) Results() <-chan *AsyncResult {
+	return ai.results
+}
+
+// Close indexes the queued items and waits for their results to be sent.
+func (ai *AsyncIndexer) Close() {
+	ai.mu.Lock()
+	if ai.closed {
+		ai.mu.Unlock()
+		return
+	}
+	ai.closed = true
+	close(ai.queue)
+	ai.mu.Unlock()
+	ai.deregister()
+
+	ai.wg.Wait()
+	close(ai.results)
+}
+
+func (ai *AsyncIndexer) work() {
+	defer ai.wg.Done()
+
+	ticker := time.NewTicker(ai.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*BulkItem, 0, ai.config.BatchSize)
+	for {
+		select {
+		case item, ok := <-ai.queue:
+			if !ok {
+				ai.flush(batch)
+				return
+			}
+			batch = append(batch, item)
+			if len(batch) < ai.config.BatchSize {
+				continue
+			}
+		case <-ticker.C:
+			if len(batch) == 0 {
+				continue
+			}
+		}
+		ai.flush(batch)
+		batch = make([]*BulkItem, 0, ai.config.BatchSize)
+		ticker.Reset(ai.config.FlushInterval)
+	}
+}
+
+func (ai *AsyncIndexer) flush(batch []*BulkItem) {
+	if len(batch) == 0 {
+		return
+	}
+
+	_, results, err := ai.es.Bulk(batch, ai.config.Refresh)
+
+	var bulkErr *BulkError
+	if err != nil && !errors.As(err, &bulkErr) || len(results) != len(batch) {
+		if err == nil {
+			err = fmt.Errorf("bulk returned %d results for %d items", len(results), len(batch))
+		}
+		for _, item := range batch {
+			ai.results <- &AsyncResult{Item: item, Err: err}
+		}
+		return
+	}
+
+	for i, item := range batch {
+		r := &AsyncResult{Item: item, Result: results[i]}
+		if err := results[i].esError(); err != nil {
+			r.Err = err
+		}
+		ai.results <- r
+	}
+}
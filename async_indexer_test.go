@@ -0,0 +1,63 @@
+package elasticsearch
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAsyncIndexer(t *testing.T) {
+	es := NewFake()
+	indexer := NewAsyncIndexer(es, &AsyncIndexerConfig{BatchSize: 2, FlushInterval: 10 * time.Millisecond})
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, indexer.Add(ctx, &BulkItem{Index: "a", ID: fmt.Sprint(i), Body: DocBody{Id: fmt.Sprint(i)}}))
+	}
+	assert.NoError(t, indexer.Add(ctx, &BulkItem{Action: BulkCreate, Index: "a", ID: "0", Body: DocBody{Id: "0"}}))
+
+	t.Run("Results", func(t *testing.T) {
+		for i := 0; i < 5; i++ {
+			r := <-indexer.Results()
+			assert.NoError(t, r.Err)
+			assert.Equal(t, fmt.Sprint(i), r.Result.ID)
+		}
+
+		r := <-indexer.Results()
+		assert.ErrorIs(t, r.Err, ErrConflict)
+		assert.Equal(t, BulkCreate, r.Item.Action)
+	})
+
+	t.Run("Close", func(t *testing.T) {
+		indexer.Close()
+		_, ok := <-indexer.Results()
+		assert.False(t, ok)
+		assert.ErrorIs(t, indexer.Add(ctx, &BulkItem{Index: "a", Body: DocBody{}}), ErrIndexerClosed)
+
+		_, total, _ := es.Count("a", "")
+		assert.Equal(t, 5, total)
+	})
+}
+
+func TestAsyncIndexerBackpressure(t *testing.T) {
+	indexer := NewAsyncIndexer(NewFake(), &AsyncIndexerConfig{BatchSize: 1, QueueSize: 1})
+	defer func() {
+		go func() {
+			for range indexer.Results() {
+			}
+		}()
+		indexer.Close()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	var err error
+	for i := 0; i < 10 && err == nil; i++ {
+		err = indexer.Add(ctx, &BulkItem{Index: "a", Body: DocBody{}})
+	}
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
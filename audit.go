@@ -0,0 +1,302 @@
+package elasticsearch
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+type AuditConfig struct {
+	// Index receives the AuditEntry of the writes. Default: "audit".
+	Index string
+	// Indexer configures the AsyncIndexer writing the entries.
+	Indexer *AsyncIndexerConfig
+	// Logger receives the entries that could not be written. Default: all levels to the standard log package.
+	Logger Logger
+}
+
+// AuditEntry is the document of a write in the audit index.
+type AuditEntry struct {
+	// Operation is the BulkAction of a document write, "delete_by_query" or "update_by_query" for the writes by query,
+	// or "load_fixtures", "bulk_ndjson" or "bulk_csv" for the bulk loads.
+	Operation string `json:"operation"`
+	Index     string `json:"index"`
+	// ID is the ID of the document, empty for the writes by query, the bulk loads and the
+	// documents created by CreateDocument without ID.
+	ID string `json:"id,omitempty"`
+	// Actor is the Actor of the context of the write.
+	Actor     string    `json:"actor,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+	// DiffHash is the SHA-256 of the written document or fields, or of the query of a write by query,
+	// to check a document against the entries of its writes without storing its content.
+	DiffHash string `json:"diff_hash,omitempty"`
+}
+
+type actorKey struct{}
+
+// WithActor returns a context whose writes through an Audit are recorded as made by actor.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// Actor returns the actor set by WithActor, or else the username set by WithRunAs.
+func Actor(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok {
+		return actor
+	}
+	return RunAs(ctx)
+}
+
+// Audit records an AuditEntry for each successful write of DocumentWriter into the audit index,
+// in the background with an AsyncIndexer:
+//
+//	audit := elasticsearch.NewAudit(es, &elasticsearch.AuditConfig{Index: "audit"})
+//	defer audit.Close()
+//	audit.WithContext(elasticsearch.WithActor(ctx, user.ID)).UpdateDocument(doc)
+//
+// A write waits for its entry to be queued, until the Audit is closed, even if its context is done before.
+// The entries that could not be written are logged. The other methods are those of the wrapped client.
+type Audit struct {
+	Elasticsearch
+
+	shared *auditShared
+	ctx    context.Context
+}
+
+// auditShared is shared by an Audit and those returned by its With methods.
+type auditShared struct {
+	index   string
+	indexer *AsyncIndexer
+	logger  Logger
+	now     func() time.Time
+	// ctx bounds the queuing of the entries, cancelled by Close.
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+	once   sync.Once
+}
+
+func NewAudit(es Elasticsearch, config *AuditConfig) *Audit {
+	if config == nil {
+		config = &AuditConfig{}
+	}
+	indexerConfig := config.Indexer
+	if indexerConfig == nil {
+		indexerConfig = &AsyncIndexerConfig{}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &auditShared{
+		index:   config.Index,
+		indexer: NewAsyncIndexer(es, indexerConfig),
+		logger:  config.Logger,
+		now:     time.Now,
+		ctx:     ctx,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+	if s.index == "" {
+		s.index = "audit"
+	}
+	if s.logger == nil {
+		s.logger = NewStdLogger(nil, LevelDebug)
+	}
+	go s.logFailures()
+
+	return &Audit{Elasticsearch: es, shared: s, ctx: context.Background()}
+}
+
+func (s *auditShared) logFailures() {
+	defer close(s.done)
+	for r := range s.indexer.Results() {
+		if r.Err != nil {
+			s.logger.Errorf("Failed to write audit entry: %s; entry=%s", r.Err, r.Item.Body)
+		}
+	}
+}
+
+// Close writes the queued entries, waits for them, then closes the wrapped client.
+// The writes after Close are not recorded.
+func (a *Audit) Close() error {
+	a.shared.once.Do(func() {
+		a.shared.cancel()
+		a.shared.indexer.Close()
+	})
+	<-a.shared.done
+	return a.Elasticsearch.Close()
+}
+
+func (a *Audit) unwrap() Elasticsearch {
+	return a.Elasticsearch
+}
+
+// WithContext returns an Audit of the client bound to ctx, recording the writes as made by Actor(ctx).
+func (a *Audit) WithContext(ctx context.Context) Elasticsearch {
+	return &Audit{Elasticsearch: a.Elasticsearch.WithContext(ctx), shared: a.shared, ctx: ctx}
+}
+
+func (a *Audit) WithIndexPrefix(prefix string) Elasticsearch {
+	return &Audit{Elasticsearch: a.Elasticsearch.WithIndexPrefix(prefix), shared: a.shared, ctx: a.ctx}
+}
+
+func (a *Audit) WithPreference(preference string) Elasticsearch {
+	return &Audit{Elasticsearch: a.Elasticsearch.WithPreference(preference), shared: a.shared, ctx: a.ctx}
+}
+
+// record queues the entry of a write of content, which may be nil.
+func (a *Audit) record(operation, index, id string, content []byte) {
+	entry := &AuditEntry{
+		Operation: operation,
+		Index:     index,
+		ID:        id,
+		Actor:     Actor(a.ctx),
+		Timestamp: a.shared.now().UTC(),
+	}
+	if content != nil {
+		sum := sha256.Sum256(content)
+		entry.DiffHash = hex.EncodeToString(sum[:])
+	}
+
+	body, _ := json.Marshal(entry)
+	if err := a.shared.indexer.Add(a.shared.ctx, &BulkItem{Action: BulkCreate, Index: a.shared.index, Body: json.RawMessage(body)}); err != nil {
+		a.shared.logger.Errorf("Failed to queue audit entry: %s; entry=%s", err, body)
+	}
+}
+
+// auditContent returns the serialized body, and body itself or its content when it is an io.Reader
+// that could only be read once.
+func auditContent(body interface{}) (interface{}, []byte, error) {
+	if body == nil {
+		return nil, nil, nil
+	}
+	b, err := marshalBody(jsonCodec{}, body)
+	if err != nil {
+		return body, nil, err
+	}
+	if _, ok := body.(io.Reader); ok {
+		return json.RawMessage(b), b, nil
+	}
+	return body, b, nil
+}
+
+func (a *Audit) CreateDocument(doc *Document) (StatusCode, error) {
+	d := *doc
+	body, content, err := auditContent(doc.Body)
+	if err != nil {
+		return StatusInternalError, err
+	}
+	d.Body = body
+
+	status, err := a.Elasticsearch.CreateDocument(&d)
+	if err == nil {
+		a.record(string(BulkIndex), doc.Index, doc.ID, content)
+	}
+	return status, err
+}
+
+func (a *Audit) UpdateDocument(doc *Document) (StatusCode, error) {
+	d := *doc
+	body, content, err := auditContent(doc.Body)
+	if err != nil {
+		return StatusInternalError, err
+	}
+	d.Body = body
+
+	status, err := a.Elasticsearch.UpdateDocument(&d)
+	if err == nil {
+		a.record(string(BulkUpdate), doc.Index, doc.ID, content)
+	}
+	return status, err
+}
+
+func (a *Audit) RemoveDocument(doc *Document) (StatusCode, error) {
+	status, err := a.Elasticsearch.RemoveDocument(doc)
+	if err == nil {
+		a.record(string(BulkDelete), doc.Index, doc.ID, nil)
+	}
+	return status, err
+}
+
+// Bulk records the items which succeeded.
+func (a *Audit) Bulk(items []*BulkItem, refresh RefreshPolicy) (StatusCode, []*BulkItemResult, error) {
+	audited := make([]*BulkItem, len(items))
+	contents := make([][]byte, len(items))
+	for i, item := range items {
+		audited[i] = item
+		if item.Action == BulkDelete {
+			continue
+		}
+		body, content, err := auditContent(item.Body)
+		if err != nil {
+			return StatusInternalError, []*BulkItemResult{}, err
+		}
+		if body != item.Body {
+			it := *item
+			it.Body = body
+			audited[i] = &it
+		}
+		contents[i] = content
+	}
+
+	status, results, err := a.Elasticsearch.Bulk(audited, refresh)
+	var bulkErr *BulkError
+	if err != nil && !errors.As(err, &bulkErr) || len(results) != len(items) {
+		return status, results, err
+	}
+	for i, item := range items {
+		if results[i].Error != nil {
+			continue
+		}
+		action := item.Action
+		if action == "" {
+			action = BulkIndex
+		}
+		a.record(string(action), item.Index, results[i].ID, contents[i])
+	}
+	return status, results, err
+}
+
+func (a *Audit) LoadFixtures(index string, r io.Reader) (StatusCode, error) {
+	status, err := a.Elasticsearch.LoadFixtures(index, r)
+	if err == nil {
+		a.record("load_fixtures", index, "", nil)
+	}
+	return status, err
+}
+
+func (a *Audit) BulkFromNDJSON(ctx context.Context, index string, r io.Reader, opts ...BulkStreamOption) (StatusCode, int, error) {
+	status, n, err := a.Elasticsearch.BulkFromNDJSON(ctx, index, r, opts...)
+	if n > 0 {
+		a.record("bulk_ndjson", index, "", nil)
+	}
+	return status, n, err
+}
+
+func (a *Audit) BulkFromCSV(ctx context.Context, index string, r io.Reader, mapping *CSVMapping, opts ...BulkStreamOption) (StatusCode, int, error) {
+	status, n, err := a.Elasticsearch.BulkFromCSV(ctx, index, r, mapping, opts...)
+	if n > 0 {
+		a.record("bulk_csv", index, "", nil)
+	}
+	return status, n, err
+}
+
+func (a *Audit) DeleteByQuery(index, query string, opts ...ByQueryOption) (StatusCode, int, error) {
+	status, n, err := a.Elasticsearch.DeleteByQuery(index, query, opts...)
+	if n > 0 {
+		a.record("delete_by_query", index, "", []byte(query))
+	}
+	return status, n, err
+}
+
+func (a *Audit) UpdateByQuery(index, query string, opts ...ByQueryOption) (StatusCode, int, error) {
+	status, n, err := a.Elasticsearch.UpdateByQuery(index, query, opts...)
+	if n > 0 {
+		a.record("update_by_query", index, "", []byte(query))
+	}
+	return status, n, err
+}
@@ -0,0 +1,96 @@
+package elasticsearch
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAudit(t *testing.T) {
+	type doc struct {
+		ID string `json:"id"`
+	}
+	now := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	hash := func(s string) string {
+		sum := sha256.Sum256([]byte(s))
+		return hex.EncodeToString(sum[:])
+	}
+	setup := func() (*Fake, *Audit) {
+		fake := NewFake()
+		audit := NewAudit(fake, &AuditConfig{Indexer: &AsyncIndexerConfig{FlushInterval: time.Millisecond}, Logger: NopLogger()})
+		audit.shared.now = func() time.Time { return now }
+		return fake, audit
+	}
+	entries := func(fake *Fake) []*AuditEntry {
+		var entries []*AuditEntry
+		fake.Search("audit", SearchBody(MatchAllQuery()), &entries)
+		return entries
+	}
+
+	t.Run("Document writes", func(t *testing.T) {
+		fake, audit := setup()
+		es := audit.WithContext(WithActor(context.Background(), "alice"))
+
+		_, err := es.CreateDocument(&Document{Index: "a", ID: "1", Body: strings.NewReader(`{"id":"1"}`)})
+		assert.NoError(t, err)
+		_, err = es.UpdateDocument(&Document{Index: "a", ID: "1", Body: doc{ID: "2"}})
+		assert.NoError(t, err)
+		_, err = es.RemoveDocument(&Document{Index: "a", ID: "1"})
+		assert.NoError(t, err)
+		_, err = es.RemoveDocument(&Document{Index: "a", ID: "missing"})
+		assert.Error(t, err)
+		audit.Close()
+
+		assert.Equal(t, []*AuditEntry{
+			{Operation: "index", Index: "a", ID: "1", Actor: "alice", Timestamp: now, DiffHash: hash(`{"id":"1"}`)},
+			{Operation: "update", Index: "a", ID: "1", Actor: "alice", Timestamp: now, DiffHash: hash(`{"id":"2"}`)},
+			{Operation: "delete", Index: "a", ID: "1", Actor: "alice", Timestamp: now},
+		}, entries(fake))
+	})
+
+	t.Run("Bulk", func(t *testing.T) {
+		fake, audit := setup()
+		es := audit.WithContext(WithRunAs(context.Background(), "bob"))
+
+		_, _, err := es.Bulk([]*BulkItem{
+			{Index: "a", Body: doc{ID: "1"}},
+			{Action: BulkUpdate, Index: "a", ID: "missing", Body: doc{ID: "2"}},
+		}, RefreshFalse)
+		assert.Error(t, err)
+		audit.Close()
+
+		assert.Equal(t, []*AuditEntry{
+			{Operation: "index", Index: "a", ID: "fake-1", Actor: "bob", Timestamp: now, DiffHash: hash(`{"id":"1"}`)},
+		}, entries(fake))
+	})
+
+	t.Run("Context done", func(t *testing.T) {
+		fake, audit := setup()
+		ctx, cancel := context.WithCancel(WithActor(context.Background(), "carol"))
+		es := audit.WithContext(ctx)
+		cancel()
+
+		_, err := es.CreateDocument(&Document{Index: "a", ID: "1", Body: doc{ID: "1"}})
+		assert.NoError(t, err)
+		audit.Close()
+
+		assert.Equal(t, []*AuditEntry{
+			{Operation: "index", Index: "a", ID: "1", Actor: "carol", Timestamp: now, DiffHash: hash(`{"id":"1"}`)},
+		}, entries(fake))
+	})
+
+	t.Run("Closed", func(t *testing.T) {
+		fake, audit := setup()
+		audit.Close()
+		audit.Close()
+
+		_, err := audit.CreateDocument(&Document{Index: "a", ID: "1", Body: doc{ID: "1"}})
+		assert.NoError(t, err)
+		assert.Empty(t, entries(fake))
+	})
+}
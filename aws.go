@@ -0,0 +1,225 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AWSAuth signs requests with AWS Signature Version 4 for Amazon OpenSearch Service domains
+// (formerly Amazon Elasticsearch Service).
+// https://docs.aws.amazon.com/general/latest/gr/signature-version-4.html
+//
+// The domains run OpenSearch or an OSS build of Elasticsearch, which the product check of go-elasticsearch
+// rejects, so the check is skipped. Version reports their distribution.
+type AWSAuth struct {
+	Region string
+	// Service defaults to "es".
+	Service     string
+	Credentials AWSCredentialsProvider
+}
+
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// AWSCredentialsProvider returns the credentials signing a request. It is called for every request,
+// so it must cache the credentials itself, e.g. an aws-sdk-go-v2 aws.CredentialsCache adapted with
+// AWSCredentialsProviderFunc.
+type AWSCredentialsProvider interface {
+	Retrieve(ctx context.Context) (AWSCredentials, error)
+}
+
+type AWSCredentialsProviderFunc func(ctx context.Context) (AWSCredentials, error)
+
+func (f AWSCredentialsProviderFunc) Retrieve(ctx context.Context) (AWSCredentials, error) {
+	return f(ctx)
+}
+
+// StaticAWSCredentials returns fixed credentials. sessionToken may be empty.
+func StaticAWSCredentials(accessKeyID, secretAccessKey, sessionToken string) AWSCredentialsProvider {
+	return AWSCredentialsProviderFunc(func(ctx context.Context) (AWSCredentials, error) {
+		return AWSCredentials{
+			AccessKeyID:     accessKeyID,
+			SecretAccessKey: secretAccessKey,
+			SessionToken:    sessionToken,
+		}, nil
+	})
+}
+
+// EnvAWSCredentials reads the credentials from AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN.
+func EnvAWSCredentials() AWSCredentialsProvider {
+	return AWSCredentialsProviderFunc(func(ctx context.Context) (AWSCredentials, error) {
+		creds := AWSCredentials{
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		}
+		if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+			return AWSCredentials{}, errors.New("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are not set")
+		}
+		return creds, nil
+	})
+}
+
+func (auth *AWSAuth) validate() error {
+	if auth.Region == "" {
+		return errors.New("AWS.Region is required")
+	}
+	if auth.Credentials == nil {
+		return errors.New("AWS.Credentials is required")
+	}
+	return nil
+}
+
+func (auth *AWSAuth) service() string {
+	if auth.Service == "" {
+		return "es"
+	}
+	return auth.Service
+}
+
+// awsSigner signs the requests sent by next.
+type awsSigner struct {
+	auth *AWSAuth
+	next http.RoundTripper
+	now  func() time.Time
+}
+
+func (s *awsSigner) RoundTrip(req *http.Request) (*http.Response, error) {
+	creds, err := s.auth.Credentials.Retrieve(req.Context())
+	if err != nil {
+		return nil, fmt.Errorf("retrieving AWS credentials: %w", err)
+	}
+
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	signed := req.Clone(req.Context())
+	if body != nil {
+		signed.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	signAWSV4(signed, body, creds, s.auth.Region, s.auth.service(), s.now())
+
+	next := s.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return next.RoundTrip(signed)
+}
+
+func signAWSV4(req *http.Request, body []byte, creds AWSCredentials, region, service string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	req.Header.Set("X-Amz-Date", amzDate)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	headers := map[string]string{
+		"host":       host,
+		"x-amz-date": amzDate,
+	}
+	if creds.SessionToken != "" {
+		headers["x-amz-security-token"] = creds.SessionToken
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	payloadHash := sha256.Sum256(body)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		awsEscape(req.URL.EscapedPath(), false),
+		awsCanonicalQuery(req),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	scope := strings.Join([]string{date, region, service, "aws4_request"}, "/")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hex.EncodeToString(requestHash[:]),
+	}, "\n")
+
+	key := []byte("AWS4" + creds.SecretAccessKey)
+	for _, part := range []string{date, region, service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.AccessKeyID, scope, signedHeaders, signature,
+	))
+}
+
+func awsCanonicalQuery(req *http.Request) string {
+	query := req.URL.Query()
+	params := []string{}
+	for key, values := range query {
+		for _, value := range values {
+			params = append(params, awsEscape(key, true)+"="+awsEscape(value, true))
+		}
+	}
+	sort.Strings(params)
+	return strings.Join(params, "&")
+}
+
+// awsEscape percent-encodes every byte but the unreserved characters of RFC 3986,
+// and "/" unless encodeSlash.
+func awsEscape(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' || c == '/' && !encodeSlash {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -0,0 +1,138 @@
+package elasticsearch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignAWSV4(t *testing.T) {
+	// Test vectors of the AWS Signature Version 4 test suite.
+	creds := AWSCredentials{
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+	}
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+
+	cases := []struct {
+		name      string
+		method    string
+		url       string
+		signature string
+	}{
+		{"get-vanilla", http.MethodGet, "https://example.amazonaws.com/", "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"},
+		{"get-vanilla-query-order-key-case", http.MethodGet, "https://example.amazonaws.com/?Param2=value2&Param1=value1", "b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500"},
+		{"post-vanilla", http.MethodPost, "https://example.amazonaws.com/", "5da7c1a2acd57cee7505fc6676e4e544621c30862966e37dddb68e92efbe5d6b"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req, _ := http.NewRequest(c.method, c.url, nil)
+			signAWSV4(req, nil, creds, "us-east-1", "service", now)
+
+			assert.Equal(t, "20150830T123600Z", req.Header.Get("X-Amz-Date"))
+			assert.Equal(t,
+				"AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature="+c.signature,
+				req.Header.Get("Authorization"),
+			)
+		})
+	}
+}
+
+func TestAWSAuth(t *testing.T) {
+	server, requests := newTestServer(t, nil)
+
+	es, err := New(&Config{
+		Address: []string{server.URL},
+		AWS: &AWSAuth{
+			Region:      "ap-northeast-1",
+			Credentials: StaticAWSCredentials("AKID", "SECRET", "TOKEN"),
+		},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, es.Ping())
+
+	reqs := requests()
+	req := reqs[len(reqs)-1]
+	assert.True(t, strings.HasPrefix(req.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/"))
+	assert.Contains(t, req.Header.Get("Authorization"), "/ap-northeast-1/es/aws4_request, SignedHeaders=host;x-amz-date;x-amz-security-token,")
+	assert.Equal(t, "TOKEN", req.Header.Get("X-Amz-Security-Token"))
+
+	t.Run("Credentials error", func(t *testing.T) {
+		es, err := New(&Config{
+			Address: []string{server.URL},
+			AWS: &AWSAuth{
+				Region: "ap-northeast-1",
+				Credentials: AWSCredentialsProviderFunc(func(ctx context.Context) (AWSCredentials, error) {
+					return AWSCredentials{}, context.DeadlineExceeded
+				}),
+			},
+		})
+		assert.NoError(t, err)
+		assert.Error(t, es.Ping())
+	})
+
+	t.Run("Invalid config", func(t *testing.T) {
+		_, err := New(&Config{AWS: &AWSAuth{Credentials: EnvAWSCredentials()}})
+		assert.Error(t, err)
+
+		_, err = New(&Config{APIKey: "key", AWS: &AWSAuth{Region: "ap-northeast-1", Credentials: EnvAWSCredentials()}})
+		assert.Error(t, err)
+	})
+}
+
+func TestAWSAuthOpenSearch(t *testing.T) {
+	// Amazon OpenSearch Service answers without the X-Elastic-Product header.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/":
+			w.Write([]byte(`{
+				"name": "node-1", "cluster_name": "123456789012:domain", "cluster_uuid": "uuid",
+				"version": {"distribution": "opensearch", "number": "2.11.0", "build_type": "tar", "lucene_version": "9.7.0"},
+				"tagline": "The OpenSearch Project: https://opensearch.org/"
+			}`))
+		case "/a/_search":
+			if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 ") {
+				w.WriteHeader(http.StatusForbidden)
+				w.Write([]byte(`{"message": "missing signature"}`))
+				return
+			}
+			w.Write([]byte(`{"hits": {"total": {"value": 1, "relation": "eq"}, "hits": [{"_index": "a", "_id": "1", "_source": {"id": "1"}}]}}`))
+		default:
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+
+	es, err := New(&Config{
+		Address: []string{server.URL},
+		AWS:     &AWSAuth{Region: "ap-northeast-1", Credentials: StaticAWSCredentials("AKID", "SECRET", "")},
+		Logger:  NopLogger(),
+	})
+	assert.NoError(t, err)
+
+	status, _, total, err := es.Search("a", SearchBody(MatchAllQuery()), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSuccess, status)
+	assert.Equal(t, 1, total)
+
+	_, v, err := es.Version()
+	assert.NoError(t, err)
+	assert.Equal(t, "opensearch", v.Distribution)
+	assert.Equal(t, 2, v.Major)
+	_, _, err = es.OpenPIT("a", time.Minute)
+	assert.NotErrorIs(t, err, ErrUnsupportedByServer)
+
+	t.Run("Without AWS", func(t *testing.T) {
+		es, err := New(&Config{Address: []string{server.URL}, Logger: NopLogger()})
+		assert.NoError(t, err)
+		_, _, err = es.Info()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "unknown product")
+	})
+}
@@ -0,0 +1,192 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"time"
+)
+
+// BackfillIterator yields the items of a backfill in a stable order, e.g. the rows of a table by primary key.
+type BackfillIterator interface {
+	// Next returns the next item and the checkpoint to resume after it, such as its primary key,
+	// or io.EOF after the last item.
+	Next(ctx context.Context) (item *BulkItem, checkpoint string, err error)
+}
+
+type BackfillConfig struct {
+	// Open returns the iterator of the items after checkpoint, or of all items for an empty checkpoint.
+	Open func(ctx context.Context, checkpoint string) (BackfillIterator, error)
+	// CheckpointPath is the file the checkpoint is saved to after every batch, so that Backfill
+	// resumes after the last batch indexed when run again after a crash. It is removed when the
+	// backfill completes. When empty, the backfill always starts from the beginning.
+	CheckpointPath string
+	// Total is the number of items of the backfill, to estimate the time left. 0 when unknown.
+	Total int
+	// DocsPerSecond caps the throughput to spare the cluster. 0 means unlimited.
+	DocsPerSecond float64
+	// BatchSize is the number of items of each bulk request. Default: 500.
+	BatchSize int
+	// Progress is called after every batch.
+	Progress func(p *BackfillProgress)
+}
+
+// BackfillProgress reports the progress of a backfill, including the batches of the previous runs.
+type BackfillProgress struct {
+	Checkpoint string `json:"checkpoint"`
+	Indexed    int    `json:"indexed"`
+	// Failed is the number of items rejected by Elasticsearch, which are not retried.
+	Failed int `json:"failed"`
+
+	// Elapsed is the duration of this run.
+	Elapsed time.Duration `json:"-"`
+	// DocsPerSecond is the throughput of this run.
+	DocsPerSecond float64 `json:"-"`
+	// ETA is the estimated time left, or 0 when Total is unknown.
+	ETA time.Duration `json:"-"`
+}
+
+// Backfill indexes the items of config.Open with bulk requests at the rate of config.DocsPerSecond,
+// checkpointing its progress, e.g. to fill a new index from a database:
+//
+//	progress, err := Backfill(ctx, es, &BackfillConfig{
+//		Open: func(ctx context.Context, checkpoint string) (BackfillIterator, error) {
+//			return newRowIterator(db, "SELECT * FROM articles WHERE id > $1 ORDER BY id", checkpoint)
+//		},
+//		CheckpointPath: "articles.checkpoint",
+//		DocsPerSecond:  2000,
+//	})
+//
+// It stops at the first failed request or iterator error, or when ctx is done, and returns the
+// progress so far. The items of the batch in flight are indexed again by the next run.
+func Backfill(ctx context.Context, es Elasticsearch, config *BackfillConfig) (*BackfillProgress, error) {
+	if config.Open == nil {
+		return nil, errors.New("BackfillConfig.Open must not be nil")
+	}
+	batchSize := config.BatchSize
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+
+	progress := &BackfillProgress{}
+	if config.CheckpointPath != "" {
+		b, err := os.ReadFile(config.CheckpointPath)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		if len(b) > 0 {
+			if err := json.Unmarshal(b, progress); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	it, err := config.Open(ctx, progress.Checkpoint)
+	if err != nil {
+		return progress, err
+	}
+
+	es = es.WithContext(ctx)
+	start := time.Now()
+	done := 0
+	for {
+		batch := make([]*BulkItem, 0, batchSize)
+		checkpoint := progress.Checkpoint
+		var nextErr error
+		for len(batch) < batchSize {
+			item, cp, err := it.Next(ctx)
+			if err != nil {
+				nextErr = err
+				break
+			}
+			batch = append(batch, item)
+			checkpoint = cp
+		}
+		if nextErr != nil && nextErr != io.EOF {
+			return progress, nextErr
+		}
+
+		if len(batch) > 0 {
+			if err := backfillPace(ctx, start, done, config.DocsPerSecond); err != nil {
+				return progress, err
+			}
+
+			_, _, err := es.Bulk(batch, "")
+			var bulkErr *BulkError
+			if err != nil && !errors.As(err, &bulkErr) {
+				return progress, err
+			}
+			failed := 0
+			if bulkErr != nil {
+				failed = len(bulkErr.Failed)
+			}
+
+			done += len(batch)
+			progress.Indexed += len(batch) - failed
+			progress.Failed += failed
+			progress.Checkpoint = checkpoint
+			progress.Elapsed = time.Since(start)
+			progress.DocsPerSecond = float64(done) / progress.Elapsed.Seconds()
+			if left := config.Total - progress.Indexed - progress.Failed; config.Total > 0 && left > 0 && done > 0 {
+				progress.ETA = time.Duration(float64(left) / progress.DocsPerSecond * float64(time.Second))
+			} else {
+				progress.ETA = 0
+			}
+
+			if config.CheckpointPath != "" {
+				if err := saveCheckpoint(config.CheckpointPath, progress); err != nil {
+					return progress, err
+				}
+			}
+			if config.Progress != nil {
+				config.Progress(progress)
+			}
+		}
+
+		if nextErr == io.EOF {
+			break
+		}
+	}
+
+	if config.CheckpointPath != "" {
+		if err := os.Remove(config.CheckpointPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return progress, err
+		}
+	}
+	return progress, nil
+}
+
+// backfillPace waits until n documents sent since start do not exceed rate.
+func backfillPace(ctx context.Context, start time.Time, n int, rate float64) error {
+	if rate <= 0 {
+		return nil
+	}
+	wait := time.Until(start.Add(time.Duration(float64(n) / rate * float64(time.Second))))
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// saveCheckpoint replaces the checkpoint file at path with progress.
+func saveCheckpoint(path string, progress *BackfillProgress) error {
+	b, err := json.Marshal(progress)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
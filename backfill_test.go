@@ -0,0 +1,97 @@
+package elasticsearch
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type sliceIterator struct {
+	ids    []string
+	next   int
+	failAt string
+}
+
+func (it *sliceIterator) Next(ctx context.Context) (*BulkItem, string, error) {
+	if it.next >= len(it.ids) {
+		return nil, "", io.EOF
+	}
+	id := it.ids[it.next]
+	if id == it.failAt {
+		return nil, "", errors.New("connection lost")
+	}
+	it.next++
+	return &BulkItem{Index: "a", ID: id, Body: DocBody{Id: id}}, id, nil
+}
+
+func TestBackfill(t *testing.T) {
+	es := NewFake()
+	ids := []string{"1", "2", "3", "4", "5"}
+	path := filepath.Join(t.TempDir(), "backfill.checkpoint")
+
+	var opened []string
+	failAt := "4"
+	config := &BackfillConfig{
+		Open: func(ctx context.Context, checkpoint string) (BackfillIterator, error) {
+			opened = append(opened, checkpoint)
+			next := 0
+			if checkpoint != "" {
+				n, _ := strconv.Atoi(checkpoint)
+				next = n
+			}
+			return &sliceIterator{ids: ids, next: next, failAt: failAt}, nil
+		},
+		CheckpointPath: path,
+		Total:          len(ids),
+		BatchSize:      2,
+	}
+
+	t.Run("Crash", func(t *testing.T) {
+		progress, err := Backfill(context.Background(), es, config)
+		assert.Error(t, err)
+		assert.Equal(t, "2", progress.Checkpoint)
+		assert.Equal(t, 2, progress.Indexed)
+		assert.FileExists(t, path)
+	})
+
+	t.Run("Resume", func(t *testing.T) {
+		failAt = ""
+		var reports []BackfillProgress
+		config.Progress = func(p *BackfillProgress) {
+			reports = append(reports, *p)
+		}
+
+		progress, err := Backfill(context.Background(), es, config)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"", "2"}, opened)
+		assert.Equal(t, 5, progress.Indexed)
+		assert.Equal(t, "5", progress.Checkpoint)
+		assert.Len(t, reports, 2)
+		assert.Greater(t, reports[0].ETA, time.Duration(0))
+		assert.Equal(t, time.Duration(0), reports[1].ETA)
+
+		_, err = os.Stat(path)
+		assert.True(t, os.IsNotExist(err))
+
+		_, total, _ := es.Count("a", "")
+		assert.Equal(t, 5, total)
+	})
+}
+
+func TestBackfillPace(t *testing.T) {
+	start := time.Now()
+	assert.NoError(t, backfillPace(context.Background(), start, 10, 0))
+	assert.NoError(t, backfillPace(context.Background(), start, 1, 100))
+	assert.GreaterOrEqual(t, time.Since(start), 10*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, backfillPace(ctx, time.Now(), 100, 1), context.Canceled)
+}
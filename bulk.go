@@ -0,0 +1,265 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+)
+
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/docs-bulk.html
+type BulkAction string
+
+const (
+	BulkIndex  BulkAction = "index"
+	BulkCreate BulkAction = "create"
+	BulkUpdate BulkAction = "update"
+	BulkDelete BulkAction = "delete"
+)
+
+// BulkItem is one operation of Bulk. Action defaults to BulkIndex, and an empty ID
+// lets Elasticsearch generate one for BulkIndex and BulkCreate.
+// Body is the document, or its fields to update for BulkUpdate, and is ignored for BulkDelete.
+type BulkItem struct {
+	Action  BulkAction
+	Index   string
+	ID      string
+	Routing string
+	// Pipeline is the ingest pipeline of an indexed or created document, overriding the pipeline of the request,
+	// or NoPipeline.
+	Pipeline string
+	// RetryOnConflict is the number of retries of an update when the document is changed concurrently.
+	RetryOnConflict int
+	// Version is the external version of an indexed or deleted document, sent with version_type=external:
+	// the write fails with a conflict unless it is greater than the version of the document. Zero is not sent.
+	Version int64
+	Body    interface{}
+}
+
+type BulkItemResult struct {
+	Action BulkAction
+	Index  string      `json:"_index"`
+	ID     string      `json:"_id"`
+	Status int         `json:"status"`
+	Result string      `json:"result"`
+	Error  *ErrorCause `json:"error,omitempty"`
+}
+
+// esError returns the error of a failed item, or nil.
+func (r *BulkItemResult) esError() *ESError {
+	if r.Error == nil {
+		return nil
+	}
+	return &ESError{StatusCode: r.Status, Type: r.Error.Type, Reason: r.Error.Reason, Index: r.Error.Index, CausedBy: r.Error.CausedBy}
+}
+
+// BulkError is returned by Bulk when some items failed. The other items succeeded.
+type BulkError struct {
+	Failed []*BulkItemResult
+}
+
+func (e *BulkError) Error() string {
+	first := e.Failed[0]
+	reason := ""
+	if first.Error != nil {
+		reason = fmt.Sprintf(": [%s] %s", first.Error.Type, first.Error.Reason)
+	}
+	return fmt.Sprintf("elasticsearch: %d bulk items failed, first %s ID=%s%s", len(e.Failed), first.Action, first.ID, reason)
+}
+
+func bulkBody(codec Codec, items []*BulkItem) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+
+	for i, item := range items {
+		action := item.Action
+		if action == "" {
+			action = BulkIndex
+		}
+
+		meta := map[string]interface{}{"_index": item.Index}
+		if item.ID != "" {
+			meta["_id"] = item.ID
+		}
+		if item.Routing != "" {
+			meta["routing"] = item.Routing
+		}
+		if item.Pipeline != "" && (action == BulkIndex || action == BulkCreate) {
+			meta["pipeline"] = item.Pipeline
+		}
+		if item.RetryOnConflict > 0 && action == BulkUpdate {
+			meta["retry_on_conflict"] = item.RetryOnConflict
+		}
+		if item.Version > 0 && (action == BulkIndex || action == BulkDelete) {
+			meta["version"] = item.Version
+			meta["version_type"] = "external"
+		}
+		if err := enc.Encode(map[string]interface{}{string(action): meta}); err != nil {
+			return nil, err
+		}
+
+		switch action {
+		case BulkDelete:
+			continue
+		case BulkUpdate:
+			body, err := marshalUpdateBody(codec, item.Body)
+			if err != nil {
+				return nil, fmt.Errorf("bulk item %d: %w", i, err)
+			}
+			if err := writeBulkLine(&buf, body); err != nil {
+				return nil, fmt.Errorf("bulk item %d: %w", i, err)
+			}
+		default:
+			if item.Body == nil {
+				return nil, fmt.Errorf("bulk item %d: Required body", i)
+			}
+			body, err := marshalBody(codec, item.Body)
+			if err != nil {
+				return nil, fmt.Errorf("bulk item %d: %w", i, err)
+			}
+			if err := writeBulkLine(&buf, body); err != nil {
+				return nil, fmt.Errorf("bulk item %d: %w", i, err)
+			}
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// writeBulkLine writes the JSON body on a single line, compacting it if it spans several.
+func writeBulkLine(buf *bytes.Buffer, body []byte) error {
+	if bytes.IndexByte(body, '\n') >= 0 {
+		if err := json.Compact(buf, body); err != nil {
+			return err
+		}
+	} else {
+		buf.Write(body)
+	}
+	return buf.WriteByte('\n')
+}
+
+// Bulk performs items in a single request and returns their results in the same order.
+// When some items failed, the error is a *BulkError listing them.
+// An empty refresh and the other options of the request take the WriteDefaults of Config.
+func (es *_elasticsearch) Bulk(items []*BulkItem, refresh RefreshPolicy) (StatusCode, []*BulkItemResult, error) {
+	if len(items) == 0 {
+		return StatusSuccess, []*BulkItemResult{}, nil
+	}
+
+	var events []*WriteEvent
+	if es.prefix != "" || es.defaults.Routing != nil || es.defaults.ID != nil || len(es.hooks) > 0 {
+		copied := make([]*BulkItem, len(items))
+		for i, item := range items {
+			c := *item
+			c.Index = es.indexName(item.Index)
+			if c.Routing == "" && es.defaults.Routing != nil {
+				c.Routing = es.defaults.Routing(&Document{Index: item.Index, ID: item.ID, Body: item.Body})
+			}
+			copied[i] = &c
+
+			action := item.Action
+			if action == "" {
+				action = BulkIndex
+			}
+			generateID := c.ID == "" && es.defaults.ID != nil && (action == BulkIndex || action == BulkCreate)
+			if !generateID && len(es.hooks) == 0 {
+				continue
+			}
+
+			// The body is serialized once for the ID, the hooks, which may change it, and the request.
+			var body []byte
+			if action != BulkDelete && item.Body != nil {
+				var err error
+				if body, err = marshalBody(es.codec, item.Body); err != nil {
+					return StatusInternalError, []*BulkItemResult{}, fmt.Errorf("bulk item %d: %w", i, err)
+				}
+				c.Body = json.RawMessage(body)
+			}
+			if generateID && body != nil {
+				var err error
+				if c.ID, err = es.defaults.ID(item.Index, body); err != nil {
+					return StatusInternalError, []*BulkItemResult{}, fmt.Errorf("bulk item %d: %w", i, err)
+				}
+			}
+			if len(es.hooks) > 0 {
+				events = append(events, &WriteEvent{Action: action, Index: item.Index, ID: c.ID, Routing: c.Routing, Body: body})
+			}
+		}
+		items = copied
+	}
+	if refresh == "" {
+		refresh = es.defaults.Refresh
+	}
+
+	if err := es.beforeWrite(events); err != nil {
+		return StatusInternalError, []*BulkItemResult{}, err
+	}
+	for i, event := range events {
+		if event.Body != nil {
+			items[i].Body = json.RawMessage(event.Body)
+		}
+	}
+
+	body, err := bulkBody(es.codec, items)
+	if err != nil {
+		if len(es.hooks) > 0 {
+			es.afterWrite(events, err)
+		}
+		return StatusInternalError, []*BulkItemResult{}, err
+	}
+	if es.metrics != nil {
+		es.metrics.ObserveBulk(len(items), len(body))
+	}
+
+	req := esapi.BulkRequest{
+		Body:                bytes.NewReader(body),
+		Refresh:             string(refresh),
+		WaitForActiveShards: es.defaults.WaitForActiveShards,
+		Pipeline:            es.defaults.Pipeline,
+	}
+
+	res, err := req.Do(es.ctx, es.client)
+
+	var r struct {
+		Errors bool                             `json:"errors"`
+		Items  []map[BulkAction]*BulkItemResult `json:"items"`
+	}
+	if status, err := es.handleResponse(fmt.Sprintf("bulk of %d items", len(items)), res, err, &r); err != nil {
+		if len(es.hooks) > 0 {
+			es.afterWrite(events, err)
+		}
+		return status, []*BulkItemResult{}, err
+	}
+
+	results := make([]*BulkItemResult, 0, len(r.Items))
+	failed := []*BulkItemResult{}
+	for _, item := range r.Items {
+		for action, result := range item {
+			result.Action = action
+			result.Index = es.trimIndexPrefix(result.Index)
+			results = append(results, result)
+			if result.Error != nil {
+				failed = append(failed, result)
+			}
+		}
+	}
+
+	if len(es.hooks) > 0 {
+		for i, event := range events {
+			if i >= len(results) {
+				break
+			}
+			event.ID, event.Result = results[i].ID, results[i].Result
+			if err := results[i].esError(); err != nil {
+				event.Err = err
+			}
+		}
+		es.afterWrite(events, nil)
+	}
+
+	if len(failed) > 0 {
+		es.logger.Errorf("Error bulk: %d of %d items failed", len(failed), len(items))
+		return StatusError, results, &BulkError{Failed: failed}
+	}
+	return StatusSuccess, results, nil
+}
@@ -0,0 +1,197 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// CSVType is the type a CSV value is converted to.
+type CSVType int
+
+const (
+	CSVString CSVType = iota
+	CSVInteger
+	CSVFloat
+	// CSVBoolean accepts the values of strconv.ParseBool, e.g. "true", "1" and "F".
+	CSVBoolean
+	// CSVStrings splits the value on CSVMapping.Separator into an array of strings.
+	CSVStrings
+)
+
+// CSVField is the field of the documents a CSV column is indexed into.
+type CSVField struct {
+	// Name is the field name. Dots make objects, e.g. "address.city". Default: the header of the column.
+	Name string
+	Type CSVType
+}
+
+// CSVMapping tells BulkFromCSV how to make documents from the rows of a CSV with a header line.
+type CSVMapping struct {
+	// Columns maps the headers of the columns to index to their fields. When nil, every column
+	// is indexed as a string field named after its header.
+	Columns map[string]*CSVField
+	// IDColumn is the header of the column holding the IDs of the documents. It is indexed as a field
+	// only if it is in Columns. When empty, Elasticsearch generates the IDs.
+	IDColumn string
+	// Comma is the field delimiter. Default: ','.
+	Comma rune
+	// Separator splits the values of CSVStrings columns. Default: ";".
+	Separator string
+	// KeepEmpty indexes the empty values as null. By default, the fields of empty values are omitted.
+	KeepEmpty bool
+}
+
+// BulkFromCSV indexes the rows of r into index with bulk requests, one document per row, as mapping tells:
+//
+//	es.BulkFromCSV(ctx, "countries", f, &CSVMapping{
+//		IDColumn: "code",
+//		Columns: map[string]*CSVField{
+//			"code":       {},
+//			"name":       {},
+//			"population": {Type: CSVInteger},
+//		},
+//	})
+//
+// The first line of r is the header. A nil mapping indexes every column as a string.
+// It returns the number of documents indexed, like BulkFromNDJSON,
+// and stops at the first value which cannot be converted.
+func (es *_elasticsearch) BulkFromCSV(ctx context.Context, index string, r io.Reader, mapping *CSVMapping, opts ...BulkStreamOption) (StatusCode, int, error) {
+	return es.bulkStream(ctx, "CSV", opts, func(add func(item *BulkItem) error) error {
+		return readCSV(index, r, mapping, add)
+	})
+}
+
+// readCSV gives the documents of the rows of r to add.
+func readCSV(index string, r io.Reader, mapping *CSVMapping, add func(item *BulkItem) error) error {
+	if mapping == nil {
+		mapping = &CSVMapping{}
+	}
+	cr := csv.NewReader(r)
+	if mapping.Comma != 0 {
+		cr.Comma = mapping.Comma
+	}
+	cr.ReuseRecord = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("invalid CSV: %w", err)
+	}
+	header = append([]string(nil), header...)
+
+	idColumn := -1
+	fields := make([]*CSVField, len(header))
+	for i, name := range header {
+		if name == mapping.IDColumn {
+			idColumn = i
+		}
+		if mapping.Columns == nil {
+			fields[i] = &CSVField{Name: name}
+			continue
+		}
+		if f, ok := mapping.Columns[name]; ok {
+			fields[i] = &CSVField{Name: f.Name, Type: f.Type}
+			if fields[i].Name == "" {
+				fields[i].Name = name
+			}
+		}
+	}
+	if mapping.IDColumn != "" && idColumn < 0 {
+		return fmt.Errorf("invalid CSV: no ID column %q", mapping.IDColumn)
+	}
+	for name := range mapping.Columns {
+		if !contains(header, name) {
+			return fmt.Errorf("invalid CSV: no column %q", name)
+		}
+	}
+
+	separator := mapping.Separator
+	if separator == "" {
+		separator = ";"
+	}
+
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("invalid CSV: %w", err)
+		}
+		line, _ := cr.FieldPos(0)
+
+		doc := map[string]interface{}{}
+		for i, f := range fields {
+			if f == nil {
+				continue
+			}
+			if record[i] == "" {
+				if mapping.KeepEmpty {
+					setField(doc, f.Name, nil)
+				}
+				continue
+			}
+			v, err := csvValue(record[i], f.Type, separator)
+			if err != nil {
+				return fmt.Errorf("invalid CSV: line %d column %q: %w", line, header[i], err)
+			}
+			setField(doc, f.Name, v)
+		}
+
+		item := &BulkItem{Index: index, Body: doc}
+		if idColumn >= 0 {
+			if item.ID = record[idColumn]; item.ID == "" {
+				return fmt.Errorf("invalid CSV: line %d: empty ID", line)
+			}
+		}
+		if err := add(item); err != nil {
+			return err
+		}
+	}
+}
+
+func csvValue(s string, t CSVType, separator string) (interface{}, error) {
+	switch t {
+	case CSVInteger:
+		return strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	case CSVFloat:
+		return strconv.ParseFloat(strings.TrimSpace(s), 64)
+	case CSVBoolean:
+		return strconv.ParseBool(strings.TrimSpace(s))
+	case CSVStrings:
+		return strings.Split(s, separator), nil
+	case CSVString:
+		return s, nil
+	}
+	return nil, errors.New("unknown type")
+}
+
+// setField sets the field name of doc to v, making objects for the dots of name.
+func setField(doc map[string]interface{}, name string, v interface{}) {
+	parts := strings.Split(name, ".")
+	for _, part := range parts[:len(parts)-1] {
+		child, ok := doc[part].(map[string]interface{})
+		if !ok {
+			child = map[string]interface{}{}
+			doc[part] = child
+		}
+		doc = child
+	}
+	doc[parts[len(parts)-1]] = v
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package elasticsearch
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadCSV(t *testing.T) {
+	read := func(csv string, mapping *CSVMapping) ([]*BulkItem, error) {
+		items := []*BulkItem{}
+		err := readCSV("a", strings.NewReader(csv), mapping, func(item *BulkItem) error {
+			items = append(items, item)
+			return nil
+		})
+		return items, err
+	}
+
+	t.Run("Mapping", func(t *testing.T) {
+		csv := "code,name,population,city,tags,un\nJP,Japan, 125000000 ,Tokyo,asia;island,true\nXX,,,,,\n"
+		items, err := read(csv, &CSVMapping{
+			IDColumn: "code",
+			Columns: map[string]*CSVField{
+				"name":       {},
+				"population": {Type: CSVInteger},
+				"city":       {Name: "capital.name"},
+				"tags":       {Type: CSVStrings},
+				"un":         {Name: "un_member", Type: CSVBoolean},
+			},
+		})
+
+		assert.NoError(t, err)
+		assert.Len(t, items, 2)
+		assert.Equal(t, "JP", items[0].ID)
+		assert.Equal(t, map[string]interface{}{
+			"name":       "Japan",
+			"population": int64(125000000),
+			"capital":    map[string]interface{}{"name": "Tokyo"},
+			"tags":       []string{"asia", "island"},
+			"un_member":  true,
+		}, items[0].Body)
+		assert.Equal(t, map[string]interface{}{}, items[1].Body)
+	})
+
+	t.Run("All Columns", func(t *testing.T) {
+		items, err := read("a;b\n1;\n", &CSVMapping{Comma: ';', KeepEmpty: true})
+
+		assert.NoError(t, err)
+		assert.Empty(t, items[0].ID)
+		assert.Equal(t, map[string]interface{}{"a": "1", "b": nil}, items[0].Body)
+	})
+
+	t.Run("Nil Mapping", func(t *testing.T) {
+		items, err := read("a,b\n1,\n", nil)
+
+		assert.NoError(t, err)
+		assert.Len(t, items, 1)
+		assert.Equal(t, map[string]interface{}{"a": "1"}, items[0].Body)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := read("a,b\n1,x\n", &CSVMapping{Columns: map[string]*CSVField{"b": {Type: CSVFloat}}})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), `line 2 column "b"`)
+
+		_, err = read("a,b\n1,2\n", &CSVMapping{IDColumn: "id"})
+		assert.Error(t, err)
+
+		_, err = read("a,b\n1,2\n", &CSVMapping{Columns: map[string]*CSVField{"c": {}}})
+		assert.Error(t, err)
+	})
+}
+
+func TestBulkFromCSV(t *testing.T) {
+	es := NewFake()
+
+	status, indexed, err := es.BulkFromCSV(context.Background(), "a", strings.NewReader("id,s\n1,a\n2,b\n"), &CSVMapping{IDColumn: "id"})
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSuccess, status)
+	assert.Equal(t, 2, indexed)
+
+	_, total, _ := es.Count("a", "")
+	assert.Equal(t, 2, total)
+}
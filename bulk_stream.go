@@ -0,0 +1,163 @@
+package elasticsearch
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+type bulkStreamOptions struct {
+	batchSize   int
+	concurrency int
+}
+
+// BulkStreamOption changes how BulkFromNDJSON and BulkFromCSV run.
+type BulkStreamOption func(o *bulkStreamOptions)
+
+// BatchSize is the number of documents of each bulk request. Default: 500.
+func BatchSize(n int) BulkStreamOption {
+	return func(o *bulkStreamOptions) {
+		o.batchSize = n
+	}
+}
+
+// Concurrency is the number of bulk requests in flight. Default: 1, which indexes the documents in order.
+func Concurrency(n int) BulkStreamOption {
+	return func(o *bulkStreamOptions) {
+		o.concurrency = n
+	}
+}
+
+// BulkFromNDJSON indexes the documents of r, one JSON object per line, into index with bulk requests,
+// reading r as it goes so that large exports and log files are not loaded in memory:
+//
+//	f, _ := os.Open("export.ndjson")
+//	defer f.Close()
+//	status, indexed, err := es.BulkFromNDJSON(ctx, "articles", f, BatchSize(1000), Concurrency(4))
+//
+// A document with an "_id" field is indexed with that ID, like LoadFixtures. It returns the number of
+// documents indexed. When some documents failed, the error is a *BulkError listing them and the others
+// are indexed. It stops at the first invalid line or failed request, or when ctx is done.
+func (es *_elasticsearch) BulkFromNDJSON(ctx context.Context, index string, r io.Reader, opts ...BulkStreamOption) (StatusCode, int, error) {
+	return es.bulkStream(ctx, "NDJSON", opts, func(add func(item *BulkItem) error) error {
+		return readNDJSON(index, r, add)
+	})
+}
+
+// bulkStream indexes the items given to add by read in batches. read must return the error of add.
+func (es *_elasticsearch) bulkStream(ctx context.Context, source string, opts []BulkStreamOption, read func(add func(item *BulkItem) error) error) (StatusCode, int, error) {
+	o := &bulkStreamOptions{batchSize: 500, concurrency: 1}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.batchSize <= 0 || o.concurrency <= 0 {
+		return StatusInternalError, 0, errors.New("batch size and concurrency must be positive")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	client := es.WithContext(ctx)
+
+	var (
+		mu       sync.Mutex
+		indexed  int
+		failed   []*BulkItemResult
+		status   = StatusSuccess
+		firstErr error
+	)
+	fail := func(s StatusCode, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr == nil {
+			status, firstErr = s, err
+			cancel()
+		}
+	}
+
+	batches := make(chan []*BulkItem)
+	var wg sync.WaitGroup
+	for i := 0; i < o.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range batches {
+				s, results, err := client.Bulk(batch, "")
+				var bulkErr *BulkError
+				if err != nil && !errors.As(err, &bulkErr) {
+					fail(s, err)
+					continue
+				}
+
+				mu.Lock()
+				indexed += len(results)
+				if bulkErr != nil {
+					indexed -= len(bulkErr.Failed)
+					failed = append(failed, bulkErr.Failed...)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	batch := make([]*BulkItem, 0, o.batchSize)
+	send := func() error {
+		select {
+		case batches <- batch:
+			batch = make([]*BulkItem, 0, o.batchSize)
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	readErr := read(func(item *BulkItem) error {
+		batch = append(batch, item)
+		if len(batch) < o.batchSize {
+			return nil
+		}
+		return send()
+	})
+	if readErr == nil && len(batch) > 0 {
+		readErr = send()
+	}
+	close(batches)
+	wg.Wait()
+
+	if firstErr != nil {
+		return status, indexed, firstErr
+	}
+	if readErr != nil {
+		return StatusInternalError, indexed, readErr
+	}
+	if len(failed) > 0 {
+		es.logger.Errorf("Error bulk from %s: %d documents failed", source, len(failed))
+		return StatusError, indexed, &BulkError{Failed: failed}
+	}
+	return StatusSuccess, indexed, nil
+}
+
+// readNDJSON gives the documents of r to add.
+func readNDJSON(index string, r io.Reader, add func(item *BulkItem) error) error {
+	br := bufio.NewReader(r)
+	for n := 1; ; n++ {
+		line, err := br.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if doc := bytes.TrimSpace(line); len(doc) > 0 {
+			item, itemErr := fixtureItem(index, append([]byte(nil), doc...))
+			if itemErr != nil {
+				return fmt.Errorf("invalid NDJSON: line %d %w", n, itemErr)
+			}
+			if err := add(item); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package elasticsearch
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBulkFromNDJSON(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		bodies []string
+	)
+	server, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/_bulk" {
+			w.Write([]byte(`{}`))
+			return
+		}
+		b, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, string(b))
+		mu.Unlock()
+
+		var items []string
+		for _, line := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+			if strings.Contains(line, `"index"`) {
+				items = append(items, `{"index": {"_index": "a", "status": 201}}`)
+			} else if strings.Contains(line, `"bad"`) {
+				items[len(items)-1] = `{"index": {"_index": "a", "status": 400, "error": {"type": "mapper_parsing_exception", "reason": "bad"}}}`
+			}
+		}
+		w.Write([]byte(`{"errors": true, "items": [` + strings.Join(items, ",") + `]}`))
+	})
+	es, err := New(&Config{Address: []string{server.URL}, Logger: NopLogger()})
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	t.Run("Success", func(t *testing.T) {
+		bodies = nil
+		ndjson := "{\"_id\": \"1\", \"s\": \"a\"}\n\n{\"s\": \"b\"}\n{\"s\": \"c\"}"
+		status, indexed, err := es.BulkFromNDJSON(ctx, "a", strings.NewReader(ndjson), BatchSize(2), Concurrency(2))
+
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+		assert.Equal(t, 3, indexed)
+		assert.Len(t, bodies, 2)
+	})
+
+	t.Run("Failed Documents", func(t *testing.T) {
+		ndjson := "{\"s\": \"a\"}\n{\"s\": \"bad\"}\n"
+		status, indexed, err := es.BulkFromNDJSON(ctx, "a", strings.NewReader(ndjson))
+
+		var bulkErr *BulkError
+		assert.ErrorAs(t, err, &bulkErr)
+		assert.Len(t, bulkErr.Failed, 1)
+		assert.Equal(t, StatusError, status)
+		assert.Equal(t, 1, indexed)
+	})
+
+	t.Run("Invalid Line", func(t *testing.T) {
+		bodies = nil
+		ndjson := "{\"s\": \"a\"}\n[1]\n{\"s\": \"c\"}\n"
+		status, indexed, err := es.BulkFromNDJSON(ctx, "a", strings.NewReader(ndjson), BatchSize(1))
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "line 2")
+		assert.Equal(t, StatusInternalError, status)
+		assert.Equal(t, 1, indexed)
+		assert.Len(t, bodies, 1)
+	})
+}
@@ -0,0 +1,144 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/bxcodec/faker/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBulkBody(t *testing.T) {
+	body, err := bulkBody(jsonCodec{}, []*BulkItem{
+		{Index: "x", ID: "1", Body: map[string]interface{}{"a": 1}},
+		{Action: BulkCreate, Index: "x", Body: map[string]interface{}{"a": 2}},
+		{Action: BulkUpdate, Index: "x", ID: "1", Routing: "r", Body: map[string]interface{}{"a": 3}},
+		{Action: BulkDelete, Index: "x", ID: "2"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, `{"index":{"_id":"1","_index":"x"}}
+{"a":1}
+{"create":{"_index":"x"}}
+{"a":2}
+{"update":{"_id":"1","_index":"x","routing":"r"}}
+{"doc":{"a":3}}
+{"delete":{"_id":"2","_index":"x"}}
+`, string(body))
+
+	_, err = bulkBody(jsonCodec{}, []*BulkItem{{Index: "x"}})
+	assert.Error(t, err)
+
+	t.Run("External version", func(t *testing.T) {
+		body, err := bulkBody(jsonCodec{}, []*BulkItem{
+			{Index: "x", ID: "1", Version: 3, Body: map[string]interface{}{"a": 1}},
+			{Action: BulkDelete, Index: "x", ID: "2", Version: 4},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, `{"index":{"_id":"1","_index":"x","version":3,"version_type":"external"}}
+{"a":1}
+{"delete":{"_id":"2","_index":"x","version":4,"version_type":"external"}}
+`, string(body))
+	})
+
+	t.Run("Raw", func(t *testing.T) {
+		body, err := bulkBody(jsonCodec{}, []*BulkItem{
+			{Index: "x", ID: "1", Body: json.RawMessage(`{"a": 1}`)},
+			{Index: "x", ID: "2", Body: strings.NewReader("{\n  \"a\": 2\n}")},
+			{Action: BulkUpdate, Index: "x", ID: "1", Body: []byte(`{"a":3}`)},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, `{"index":{"_id":"1","_index":"x"}}
+{"a": 1}
+{"index":{"_id":"2","_index":"x"}}
+{"a":2}
+{"update":{"_id":"1","_index":"x"}}
+{"doc":{"a":3}}
+`, string(body))
+
+		_, err = bulkBody(jsonCodec{}, []*BulkItem{{Index: "x", Body: []byte("{\n")}})
+		assert.Error(t, err)
+	})
+
+	t.Run("Options", func(t *testing.T) {
+		body, err := bulkBody(jsonCodec{}, []*BulkItem{
+			{Index: "x", ID: "1", Pipeline: "enrich", Body: json.RawMessage(`{"a":1}`)},
+			{Action: BulkCreate, Index: "x", ID: "2", Pipeline: NoPipeline, Body: json.RawMessage(`{"a":2}`)},
+			{Action: BulkDelete, Index: "x", ID: "3", Pipeline: "enrich"},
+			{Action: BulkUpdate, Index: "x", ID: "4", RetryOnConflict: 3, Body: json.RawMessage(`{"a":4}`)},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, `{"index":{"_id":"1","_index":"x","pipeline":"enrich"}}
+{"a":1}
+{"create":{"_id":"2","_index":"x","pipeline":"_none"}}
+{"a":2}
+{"delete":{"_id":"3","_index":"x"}}
+{"update":{"_id":"4","_index":"x","retry_on_conflict":3}}
+{"doc":{"a":4}}
+`, string(body))
+	})
+}
+
+func TestBulk(t *testing.T) {
+	es := newElasticsearch()
+	defer es.DeleteIndeces(indexName)
+
+	ids := []string{faker.UUIDDigit(), faker.UUIDDigit()}
+
+	t.Run("Success", func(t *testing.T) {
+		status, results, err := es.Bulk([]*BulkItem{
+			{Index: indexName, ID: ids[0], Body: DocBody{Id: ids[0], S: "a"}},
+			{Index: indexName, ID: ids[1], Body: DocBody{Id: ids[1], S: "b"}},
+			{Action: BulkUpdate, Index: indexName, ID: ids[0], Body: map[string]interface{}{"s": "c"}},
+		}, RefreshTrue)
+
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+		require.Len(t, results, 3)
+		assert.Equal(t, BulkUpdate, results[2].Action)
+		assert.Equal(t, "updated", results[2].Result)
+
+		var doc DocBody
+		es.GetSource(indexName, ids[0], &doc)
+		assert.Equal(t, "c", doc.S)
+	})
+
+	t.Run("Partial failure", func(t *testing.T) {
+		status, results, err := es.Bulk([]*BulkItem{
+			{Action: BulkCreate, Index: indexName, ID: ids[0], Body: DocBody{Id: ids[0]}},
+			{Action: BulkDelete, Index: indexName, ID: ids[1]},
+		}, RefreshTrue)
+
+		var bulkErr *BulkError
+		require.ErrorAs(t, err, &bulkErr)
+		assert.Equal(t, StatusError, status)
+		require.Len(t, results, 2)
+		require.Len(t, bulkErr.Failed, 1)
+		assert.Equal(t, http.StatusConflict, bulkErr.Failed[0].Status)
+		assert.Equal(t, "version_conflict_engine_exception", bulkErr.Failed[0].Error.Type)
+		assert.Equal(t, "deleted", results[1].Result)
+	})
+}
+
+func TestBulkMetrics(t *testing.T) {
+	server, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"errors": false, "items": [{"index": {"_index": "x", "_id": "1", "status": 201, "result": "created"}}]}`))
+	})
+
+	metrics := &recordingMetrics{}
+	es, err := New(&Config{Address: []string{server.URL}, Metrics: metrics})
+	assert.NoError(t, err)
+
+	_, results, err := es.Bulk([]*BulkItem{{Index: "x", ID: "1", Body: map[string]interface{}{"a": 1}}}, "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "created", results[0].Result)
+	assert.Equal(t, [][2]int{{1, 43}}, metrics.bulks)
+	assert.Equal(t, OperationWrite, metrics.requests[len(metrics.requests)-1].op)
+}
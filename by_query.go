@@ -0,0 +1,147 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+)
+
+type byQueryOptions struct {
+	slices    interface{}
+	conflicts string
+	refresh   bool
+}
+
+// ByQueryOption changes how DeleteByQuery and UpdateByQuery run.
+type ByQueryOption func(o *byQueryOptions)
+
+// Slices runs the operation on n disjoint slices in parallel. n <= 0 lets Elasticsearch choose
+// one slice per shard.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/docs-delete-by-query.html#docs-delete-by-query-slice
+func Slices(n int) ByQueryOption {
+	return func(o *byQueryOptions) {
+		if n <= 0 {
+			o.slices = "auto"
+		} else {
+			o.slices = n
+		}
+	}
+}
+
+// ProceedOnConflicts counts the version conflicts instead of aborting on the first one.
+func ProceedOnConflicts() ByQueryOption {
+	return func(o *byQueryOptions) {
+		o.conflicts = "proceed"
+	}
+}
+
+// RefreshAfter refreshes the shards involved once the operation is done.
+func RefreshAfter() ByQueryOption {
+	return func(o *byQueryOptions) {
+		o.refresh = true
+	}
+}
+
+func newByQueryOptions(opts []ByQueryOption) *byQueryOptions {
+	o := &byQueryOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+type byQueryResponse struct {
+	Total            int               `json:"total"`
+	Deleted          int               `json:"deleted"`
+	Updated          int               `json:"updated"`
+	VersionConflicts int               `json:"version_conflicts"`
+	Failures         []*ByQueryFailure `json:"failures"`
+}
+
+// ByQueryFailure is the failure of a document of DeleteByQuery or UpdateByQuery.
+type ByQueryFailure struct {
+	Index  string      `json:"index"`
+	ID     string      `json:"id"`
+	Status int         `json:"status"`
+	Cause  *ErrorCause `json:"cause"`
+}
+
+// ByQueryError is returned when some documents failed. The operation stops at the first failures,
+// and the documents processed before them stay deleted or updated.
+type ByQueryError struct {
+	Failures []*ByQueryFailure
+}
+
+func (e *ByQueryError) Error() string {
+	first := e.Failures[0]
+	reason := ""
+	if first.Cause != nil {
+		reason = fmt.Sprintf(": [%s] %s", first.Cause.Type, first.Cause.Reason)
+	}
+	return fmt.Sprintf("elasticsearch: %d documents failed, first ID=%s%s", len(e.Failures), first.ID, reason)
+}
+
+func (es *_elasticsearch) handleByQueryResponse(op string, res *esapi.Response, err error) (StatusCode, *byQueryResponse, error) {
+	r := &byQueryResponse{}
+	if status, err := es.handleResponse(op, res, err, r); err != nil {
+		return status, r, err
+	}
+	if len(r.Failures) > 0 {
+		for _, f := range r.Failures {
+			f.Index = es.trimIndexPrefix(f.Index)
+		}
+		es.logger.Errorf("Error %s: %d documents failed", op, len(r.Failures))
+		return StatusError, r, &ByQueryError{Failures: r.Failures}
+	}
+	return StatusSuccess, r, nil
+}
+
+// DeleteByQuery deletes the documents of index matching query and returns the number deleted.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/docs-delete-by-query.html
+func (es *_elasticsearch) DeleteByQuery(index, query string, opts ...ByQueryOption) (StatusCode, int, error) {
+	o := newByQueryOptions(opts)
+	d := es.client.DeleteByQuery
+	fs := []func(*esapi.DeleteByQueryRequest){
+		d.WithContext(es.ctx),
+		d.WithRefresh(o.refresh),
+	}
+	if o.slices != nil {
+		fs = append(fs, d.WithSlices(o.slices))
+	}
+	if o.conflicts != "" {
+		fs = append(fs, d.WithConflicts(o.conflicts))
+	}
+
+	res, err := d(es.indexNames([]string{index}), strings.NewReader(query), fs...)
+
+	status, r, err := es.handleByQueryResponse("delete by query index="+index, res, err)
+	return status, r.Deleted, err
+}
+
+// UpdateByQuery updates the documents of index matching query with its script, e.g.
+// `{"query": {...}, "script": {"source": "ctx._source.count++"}}`, and returns the number updated.
+// Without a script, it reindexes the documents in place, e.g. to pick up a new mapping.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/docs-update-by-query.html
+func (es *_elasticsearch) UpdateByQuery(index, query string, opts ...ByQueryOption) (StatusCode, int, error) {
+	o := newByQueryOptions(opts)
+	u := es.client.UpdateByQuery
+	fs := []func(*esapi.UpdateByQueryRequest){
+		u.WithContext(es.ctx),
+		u.WithRefresh(o.refresh),
+	}
+	if query != "" {
+		fs = append(fs, u.WithBody(strings.NewReader(query)))
+	}
+	if o.slices != nil {
+		fs = append(fs, u.WithSlices(o.slices))
+	}
+	if o.conflicts != "" {
+		fs = append(fs, u.WithConflicts(o.conflicts))
+	}
+
+	res, err := u(es.indexNames([]string{index}), fs...)
+
+	status, r, err := es.handleByQueryResponse("update by query index="+index, res, err)
+	return status, r.Updated, err
+}
@@ -0,0 +1,58 @@
+package elasticsearch
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/bxcodec/faker/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestByQueryRequests(t *testing.T) {
+	server, requests := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"total": 3, "updated": 2, "failures": [{"index": "dev-a", "id": "3", "status": 400,
+			"cause": {"type": "mapper_parsing_exception", "reason": "failed to parse"}}]}`))
+	})
+	es, err := New(&Config{Address: []string{server.URL}, IndexPrefix: "dev-"})
+	assert.NoError(t, err)
+
+	status, updated, err := es.UpdateByQuery("a", SearchBody(MatchAllQuery()), Slices(0), ProceedOnConflicts())
+
+	var byQueryErr *ByQueryError
+	assert.ErrorAs(t, err, &byQueryErr)
+	assert.Equal(t, "a", byQueryErr.Failures[0].Index)
+	assert.Equal(t, StatusError, status)
+	assert.Equal(t, 2, updated)
+
+	reqs := requests()
+	req := reqs[len(reqs)-1]
+	assert.Equal(t, "/dev-a/_update_by_query", req.URL.Path)
+	assert.Equal(t, "auto", req.URL.Query().Get("slices"))
+	assert.Equal(t, "proceed", req.URL.Query().Get("conflicts"))
+
+	es.DeleteByQuery("a", SearchBody(MatchAllQuery()), Slices(4))
+	reqs = requests()
+	assert.Equal(t, "/dev-a/_delete_by_query", reqs[len(reqs)-1].URL.Path)
+	assert.Equal(t, "4", reqs[len(reqs)-1].URL.Query().Get("slices"))
+}
+
+func TestByQuery(t *testing.T) {
+	es := newElasticsearch()
+	defer es.DeleteIndeces(indexName)
+
+	for i := 0; i < 4; i++ {
+		id := faker.UUIDDigit()
+		es.CreateDocument(&Document{Index: indexName, ID: id, Body: DocBody{Id: id, S: "by query"}})
+	}
+	es.Refresh(indexName)
+
+	status, updated, err := es.UpdateByQuery(indexName, SearchBody(MatchAllQuery()), Slices(2), RefreshAfter())
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSuccess, status)
+	assert.Equal(t, 4, updated)
+
+	status, deleted, err := es.DeleteByQuery(indexName, SearchBody(MatchAllQuery()), Slices(0), RefreshAfter())
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSuccess, status)
+	assert.Equal(t, 4, deleted)
+}
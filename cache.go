@@ -0,0 +1,441 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheConfig configures NewCache.
+type CacheConfig struct {
+	// TTL is how long a result is served from the cache. Default: 10s.
+	TTL time.Duration
+	// MaxEntries bounds the number of cached results. When full, the entry expiring first is evicted. Default: 1000.
+	MaxEntries int
+	// Codec decodes the cached hits and sources into the data of the callers.
+	// It should be the Config.Codec of the wrapped client. Default: encoding/json.
+	Codec Codec
+}
+
+// Cache is a read-through cache of Search, SearchWithResult, Count and GetSource, for dashboards
+// issuing the same queries every few seconds. Results are keyed on the index, the query and the
+// SearchOptions, and are dropped after the TTL or as soon as a write through the Cache targets the same index.
+// Writes through another client, or through an alias of a cached index, are only seen after the TTL;
+// Invalidate drops the results of an index explicitly.
+// A result is not cached when a write through the Cache to its indices ran during the request.
+// Errors and timed out searches are not cached. Each caller gets its own copy of the hits, but the other fields
+// of a cached SearchResult, e.g. the aggregations, are shared by the callers and must not be modified.
+// The other methods are those of the wrapped client.
+type Cache struct {
+	Elasticsearch
+
+	store *cacheStore
+}
+
+func (c *Cache) unwrap() Elasticsearch {
+	return c.Elasticsearch
+}
+
+type cacheStore struct {
+	ttl   time.Duration
+	max   int
+	codec Codec
+	now   func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+	// generation counts the invalidations, and generations holds the last of each invalidated index,
+	// to skip caching the results of requests running during a write.
+	generation  uint64
+	generations map[string]uint64
+}
+
+type cacheEntry struct {
+	indices []string
+	expires time.Time
+
+	status StatusCode
+	result *SearchResult
+	count  int
+	source json.RawMessage
+}
+
+func NewCache(es Elasticsearch, config *CacheConfig) *Cache {
+	if config == nil {
+		config = &CacheConfig{}
+	}
+	store := &cacheStore{
+		ttl:     config.TTL,
+		max:     config.MaxEntries,
+		codec:   config.Codec,
+		now:     time.Now,
+		entries: map[string]*cacheEntry{},
+
+		generations: map[string]uint64{},
+	}
+	if store.ttl <= 0 {
+		store.ttl = 10 * time.Second
+	}
+	if store.max <= 0 {
+		store.max = 1000
+	}
+	if store.codec == nil {
+		store.codec = jsonCodec{}
+	}
+	return &Cache{Elasticsearch: es, store: store}
+}
+
+// WithContext returns a Cache of the client bound to ctx, sharing the cached results of c.
+func (c *Cache) WithContext(ctx context.Context) Elasticsearch {
+	return &Cache{Elasticsearch: c.Elasticsearch.WithContext(ctx), store: c.store}
+}
+
+// WithIndexPrefix returns a Cache of the client with prefix, with its own cached results
+// since the same index names refer to other indices.
+func (c *Cache) WithIndexPrefix(prefix string) Elasticsearch {
+	store := &cacheStore{
+		ttl:     c.store.ttl,
+		max:     c.store.max,
+		codec:   c.store.codec,
+		now:     c.store.now,
+		entries: map[string]*cacheEntry{},
+
+		generations: map[string]uint64{},
+	}
+	return &Cache{Elasticsearch: c.Elasticsearch.WithIndexPrefix(prefix), store: store}
+}
+
+// WithPreference returns a Cache of the client with preference, sharing the cached results of c.
+func (c *Cache) WithPreference(preference string) Elasticsearch {
+	return &Cache{Elasticsearch: c.Elasticsearch.WithPreference(preference), store: c.store}
+}
+
+// Invalidate drops the cached results of the searches, counts and sources of index,
+// including those of searches on several indices or wildcards matching it.
+func (c *Cache) Invalidate(index ...string) {
+	c.store.invalidate(index...)
+}
+
+// Purge drops all cached results.
+func (c *Cache) Purge() {
+	c.store.mu.Lock()
+	defer c.store.mu.Unlock()
+	c.store.entries = map[string]*cacheEntry{}
+	c.store.generation++
+	c.store.generations["*"] = c.store.generation
+}
+
+func (c *Cache) Search(index string, query string, data interface{}, opts ...SearchOption) (StatusCode, []*HitData, int, error) {
+	status, result, err := c.SearchWithResult(index, query, data, opts...)
+	return status, result.Hits, result.Total, err
+}
+
+func (c *Cache) SearchWithResult(index string, query string, data interface{}, opts ...SearchOption) (StatusCode, *SearchResult, error) {
+	key := cacheKey("search", index, query, opts)
+	if e := c.store.get(key); e != nil {
+		result := *e.result
+		result.Hits = copyHits(e.result.Hits)
+		if data != nil {
+			if err := c.store.codec.Unmarshal(joinSources(result.Hits), data); err != nil {
+				return StatusParseError, &result, &ParseError{Err: err}
+			}
+		}
+		return e.status, &result, nil
+	}
+
+	generation := c.store.current()
+	status, result, err := c.Elasticsearch.SearchWithResult(index, query, data, opts...)
+	if err == nil && !result.TimedOut {
+		cached := *result
+		cached.Hits = copyHits(result.Hits)
+		c.store.put(key, index, generation, &cacheEntry{status: status, result: &cached})
+	}
+	return status, result, err
+}
+
+func (c *Cache) Count(index string, query string, opts ...SearchOption) (StatusCode, int, error) {
+	key := cacheKey("count", index, query, opts)
+	if e := c.store.get(key); e != nil {
+		return e.status, e.count, nil
+	}
+
+	generation := c.store.current()
+	status, count, err := c.Elasticsearch.Count(index, query, opts...)
+	if err == nil {
+		c.store.put(key, index, generation, &cacheEntry{status: status, count: count})
+	}
+	return status, count, err
+}
+
+func (c *Cache) GetSource(index string, id string, result any, opts ...GetOption) (StatusCode, error) {
+	o := newGetOptions(opts)
+	key := cacheKey("source", index, id+o.key(), nil)
+	e := c.store.get(key)
+	// GetRefresh reads through the cache.
+	if e == nil || o.refresh {
+		var source json.RawMessage
+		generation := c.store.current()
+		status, err := c.Elasticsearch.GetSource(index, id, &source, opts...)
+		if err != nil {
+			return status, err
+		}
+		e = &cacheEntry{status: status, source: source}
+		c.store.put(key, index, generation, e)
+	}
+
+	if len(e.source) == 0 {
+		return e.status, nil
+	}
+	if err := c.store.codec.Unmarshal(e.source, result); err != nil {
+		return StatusParseError, &ParseError{Err: err}
+	}
+	return e.status, nil
+}
+
+func (c *Cache) CreateIndex(index, body string) (StatusCode, error) {
+	defer c.store.invalidate(index)
+	return c.Elasticsearch.CreateIndex(index, body)
+}
+
+func (c *Cache) DeleteIndices(index string, opts ...DeleteIndicesOption) (StatusCode, error) {
+	defer c.store.invalidate(strings.Split(index, ",")...)
+	return c.Elasticsearch.DeleteIndices(index, opts...)
+}
+
+func (c *Cache) CreateDocument(doc *Document) (StatusCode, error) {
+	defer c.store.invalidate(doc.Index)
+	return c.Elasticsearch.CreateDocument(doc)
+}
+
+func (c *Cache) UpdateDocument(doc *Document) (StatusCode, error) {
+	defer c.store.invalidate(doc.Index)
+	return c.Elasticsearch.UpdateDocument(doc)
+}
+
+func (c *Cache) RemoveDocument(doc *Document) (StatusCode, error) {
+	defer c.store.invalidate(doc.Index)
+	return c.Elasticsearch.RemoveDocument(doc)
+}
+
+func (c *Cache) Bulk(items []*BulkItem, refresh RefreshPolicy) (StatusCode, []*BulkItemResult, error) {
+	indices := make([]string, 0, len(items))
+	for _, item := range items {
+		indices = append(indices, item.Index)
+	}
+	defer c.store.invalidate(indices...)
+	return c.Elasticsearch.Bulk(items, refresh)
+}
+
+func (c *Cache) LoadFixtures(index string, r io.Reader) (StatusCode, error) {
+	defer c.store.invalidate(index)
+	return c.Elasticsearch.LoadFixtures(index, r)
+}
+
+func (c *Cache) BulkFromNDJSON(ctx context.Context, index string, r io.Reader, opts ...BulkStreamOption) (StatusCode, int, error) {
+	defer c.store.invalidate(index)
+	return c.Elasticsearch.BulkFromNDJSON(ctx, index, r, opts...)
+}
+
+func (c *Cache) BulkFromCSV(ctx context.Context, index string, r io.Reader, mapping *CSVMapping, opts ...BulkStreamOption) (StatusCode, int, error) {
+	defer c.store.invalidate(index)
+	return c.Elasticsearch.BulkFromCSV(ctx, index, r, mapping, opts...)
+}
+
+func (c *Cache) DeleteByQuery(index, query string, opts ...ByQueryOption) (StatusCode, int, error) {
+	defer c.store.invalidate(strings.Split(index, ",")...)
+	return c.Elasticsearch.DeleteByQuery(index, query, opts...)
+}
+
+func (c *Cache) UpdateByQuery(index, query string, opts ...ByQueryOption) (StatusCode, int, error) {
+	defer c.store.invalidate(strings.Split(index, ",")...)
+	return c.Elasticsearch.UpdateByQuery(index, query, opts...)
+}
+
+func (c *Cache) Reindex(source, dest string) (StatusCode, int, error) {
+	defer c.store.invalidate(dest)
+	return c.Elasticsearch.Reindex(source, dest)
+}
+
+// cacheKey returns the key of a request of kind on index. The SearchOptions are part of the key,
+// since they change the results.
+func cacheKey(kind, index, query string, opts []SearchOption) string {
+	o := newSearchOptions(opts)
+	key := kind + "\x00" + index + "\x00" + query
+	if o.ignoreUnavailable != nil {
+		key += fmt.Sprintf("\x00ignore_unavailable=%t", *o.ignoreUnavailable)
+	}
+	if o.allowNoIndices != nil {
+		key += fmt.Sprintf("\x00allow_no_indices=%t", *o.allowNoIndices)
+	}
+	if o.terminateAfter != nil {
+		key += fmt.Sprintf("\x00terminate_after=%d", *o.terminateAfter)
+	}
+	if o.preference != nil {
+		key += "\x00preference=" + *o.preference
+	}
+	if o.slice != nil {
+		key += fmt.Sprintf("\x00slice=%d/%d", o.slice[0], o.slice[1])
+	}
+	if o.minScore != nil {
+		key += fmt.Sprintf("\x00min_score=%g", *o.minScore)
+	}
+	if o.timeout != nil {
+		key += "\x00timeout=" + o.timeout.String()
+	}
+	if o.profile {
+		key += "\x00profile"
+	}
+	if o.pit != nil {
+		key += "\x00pit=" + o.pit.ID
+	}
+	if o.trackTotalHits != nil {
+		key += fmt.Sprintf("\x00track_total_hits=%v", o.trackTotalHits)
+	}
+	if o.allowPartial != nil {
+		key += fmt.Sprintf("\x00allow_partial=%t", *o.allowPartial)
+	}
+	return key
+}
+
+func (s *cacheStore) get(key string) *cacheEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok {
+		return nil
+	}
+	if !s.now().Before(e.expires) {
+		delete(s.entries, key)
+		return nil
+	}
+	return e
+}
+
+// current returns the generation to give put for the result of a request starting now.
+func (s *cacheStore) current() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.generation
+}
+
+// put caches e unless its indices were invalidated since generation.
+func (s *cacheStore) put(key, index string, generation uint64, e *cacheEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// An empty index searches all indices.
+	e.indices = []string{"*"}
+	if index != "" {
+		e.indices = strings.Split(index, ",")
+	}
+	if s.generation != generation {
+		for invalidated, g := range s.generations {
+			if g > generation && cacheEntryMatches(e, []string{invalidated}) {
+				return
+			}
+		}
+	}
+
+	now := s.now()
+	if _, ok := s.entries[key]; !ok && len(s.entries) >= s.max {
+		var oldest string
+		for k, entry := range s.entries {
+			if !now.Before(entry.expires) {
+				delete(s.entries, k)
+				continue
+			}
+			if oldest == "" || entry.expires.Before(s.entries[oldest].expires) {
+				oldest = k
+			}
+		}
+		if len(s.entries) >= s.max {
+			delete(s.entries, oldest)
+		}
+	}
+
+	e.expires = now.Add(s.ttl)
+	s.entries[key] = e
+}
+
+func (s *cacheStore) invalidate(indices ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.generation++
+	for _, index := range indices {
+		if index != "" {
+			s.generations[index] = s.generation
+		}
+	}
+
+	for key, e := range s.entries {
+		if cacheEntryMatches(e, indices) {
+			delete(s.entries, key)
+		}
+	}
+}
+
+// cacheEntryMatches reports whether an entry searching e.indices may include a document written to indices.
+// Either side may be a wildcard.
+func cacheEntryMatches(e *cacheEntry, indices []string) bool {
+	for _, pattern := range e.indices {
+		for _, index := range indices {
+			if index == "" {
+				continue
+			}
+			if pattern == index {
+				return true
+			}
+			if ok, _ := path.Match(pattern, index); ok {
+				return true
+			}
+			if ok, _ := path.Match(index, pattern); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// copyHits returns a copy of hits which the callers can modify without changing the cached ones.
+func copyHits(hits []*HitData) []*HitData {
+	if hits == nil {
+		return nil
+	}
+	copied := make([]*HitData, len(hits))
+	for i, hit := range hits {
+		h := *hit
+		if hit.Sort != nil {
+			h.Sort = append([]interface{}{}, hit.Sort...)
+		}
+		if hit.Source != nil {
+			h.Source = append(json.RawMessage{}, hit.Source...)
+		}
+		h.Nested = copyNested(hit.Nested)
+		if hit.InnerHits != nil {
+			h.InnerHits = make(map[string]*InnerHits, len(hit.InnerHits))
+			for name, inner := range hit.InnerHits {
+				ih := *inner
+				ih.Hits = copyHits(inner.Hits)
+				h.InnerHits[name] = &ih
+			}
+		}
+		copied[i] = &h
+	}
+	return copied
+}
+
+func copyNested(nested *NestedIdentity) *NestedIdentity {
+	if nested == nil {
+		return nil
+	}
+	n := *nested
+	n.Nested = copyNested(nested.Nested)
+	return &n
+}
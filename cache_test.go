@@ -0,0 +1,187 @@
+package elasticsearch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countingFake counts the reads reaching the Fake behind a Cache.
+type countingFake struct {
+	*Fake
+	searches, counts, sources int
+	// searching runs during the searches, after they read the documents.
+	searching func()
+}
+
+func (f *countingFake) SearchWithResult(index string, query string, data interface{}, opts ...SearchOption) (StatusCode, *SearchResult, error) {
+	f.searches++
+	status, result, err := f.Fake.SearchWithResult(index, query, data, opts...)
+	if f.searching != nil {
+		f.searching()
+	}
+	return status, result, err
+}
+
+func (f *countingFake) Count(index string, query string, opts ...SearchOption) (StatusCode, int, error) {
+	f.counts++
+	return f.Fake.Count(index, query, opts...)
+}
+
+func (f *countingFake) GetSource(index string, id string, result any, opts ...GetOption) (StatusCode, error) {
+	f.sources++
+	return f.Fake.GetSource(index, id, result, opts...)
+}
+
+func TestCache(t *testing.T) {
+	type doc struct {
+		ID string `json:"id"`
+	}
+	setup := func() (*countingFake, *Cache, *time.Time) {
+		fake := &countingFake{Fake: NewFake()}
+		fake.CreateDocument(&Document{Index: "a", ID: "1", Body: doc{ID: "1"}})
+		cache := NewCache(fake, &CacheConfig{TTL: time.Minute})
+		now := time.Now()
+		cache.store.now = func() time.Time { return now }
+		return fake, cache, &now
+	}
+
+	t.Run("Search", func(t *testing.T) {
+		fake, cache, _ := setup()
+
+		for i := 0; i < 2; i++ {
+			var docs []*doc
+			status, hits, total, err := cache.Search("a", "", &docs)
+
+			assert.NoError(t, err)
+			assert.Equal(t, StatusSuccess, status)
+			assert.Len(t, hits, 1)
+			assert.Equal(t, 1, total)
+			assert.Equal(t, []*doc{{ID: "1"}}, docs)
+		}
+		assert.Equal(t, 1, fake.searches)
+
+		cache.Search("a", "", nil, Preference("x"))
+		cache.Search("b", "", nil)
+		assert.Equal(t, 3, fake.searches)
+	})
+
+	t.Run("Copied hits", func(t *testing.T) {
+		fake, cache, _ := setup()
+
+		for i := 0; i < 3; i++ {
+			_, result, err := cache.SearchWithResult("a", "", nil)
+
+			assert.NoError(t, err)
+			assert.Equal(t, "1", result.Hits[0].Id)
+			assert.JSONEq(t, `{"id":"1"}`, string(result.Hits[0].Source))
+			result.Hits[0].Id = "modified"
+			result.Hits[0].Source[2] = 'x'
+			result.Hits = append(result.Hits[:0], &HitData{Id: "appended"})
+		}
+		assert.Equal(t, 1, fake.searches)
+	})
+
+	t.Run("Write during Search", func(t *testing.T) {
+		fake, cache, _ := setup()
+		fake.searching = func() {
+			fake.searching = nil
+			cache.CreateDocument(&Document{Index: "a", ID: "2", Body: doc{ID: "2"}})
+		}
+
+		_, _, total, _ := cache.Search("a", "", nil)
+		assert.Equal(t, 1, total)
+		_, _, total, _ = cache.Search("a", "", nil)
+		assert.Equal(t, 2, total)
+		assert.Equal(t, 2, fake.searches)
+
+		cache.Search("a", "", nil)
+		assert.Equal(t, 2, fake.searches)
+	})
+
+	t.Run("Count and GetSource", func(t *testing.T) {
+		fake, cache, _ := setup()
+
+		for i := 0; i < 2; i++ {
+			_, count, err := cache.Count("a", "")
+			assert.NoError(t, err)
+			assert.Equal(t, 1, count)
+
+			var d doc
+			status, err := cache.GetSource("a", "1", &d)
+			assert.NoError(t, err)
+			assert.Equal(t, StatusSuccess, status)
+			assert.Equal(t, "1", d.ID)
+
+			// Errors are not cached.
+			status, err = cache.GetSource("a", "2", &d)
+			assert.ErrorIs(t, err, ErrNotFound)
+			assert.Equal(t, StatusNotFoundError, status)
+		}
+		assert.Equal(t, 1, fake.counts)
+		assert.Equal(t, 3, fake.sources)
+
+		var d doc
+		cache.GetSource("a", "1", &d, GetSourceIncludes("id"))
+		assert.Equal(t, 4, fake.sources)
+		cache.GetSource("a", "1", &d, GetRefresh())
+		assert.Equal(t, 5, fake.sources)
+	})
+
+	t.Run("TTL", func(t *testing.T) {
+		fake, cache, now := setup()
+
+		cache.Count("a", "")
+		*now = now.Add(time.Minute)
+		cache.Count("a", "")
+
+		assert.Equal(t, 2, fake.counts)
+	})
+
+	t.Run("Invalidation", func(t *testing.T) {
+		fake, cache, _ := setup()
+
+		cache.Count("a", "")
+		cache.Count("a*", "")
+		cache.Count("", "")
+		cache.Count("b", "")
+		cache.CreateDocument(&Document{Index: "a", ID: "2", Body: doc{ID: "2"}})
+		_, count, _ := cache.Count("a", "")
+		cache.Count("a*", "")
+		cache.Count("", "")
+		cache.Count("b", "")
+
+		assert.Equal(t, 2, count)
+		assert.Equal(t, 7, fake.counts)
+
+		cache.Invalidate("b")
+		cache.Count("b", "")
+		assert.Equal(t, 8, fake.counts)
+	})
+
+	t.Run("Bulk", func(t *testing.T) {
+		fake, cache, _ := setup()
+
+		cache.Count(Indices("a", "b"), "")
+		cache.Bulk([]*BulkItem{{Index: "b", ID: "1", Body: doc{ID: "1"}}}, RefreshTrue)
+		_, count, _ := cache.Count(Indices("a", "b"), "")
+
+		assert.Equal(t, 2, count)
+		assert.Equal(t, 2, fake.counts)
+	})
+
+	t.Run("MaxEntries", func(t *testing.T) {
+		fake, cache, now := setup()
+		cache.store.max = 2
+
+		cache.Count("a", "")
+		*now = now.Add(time.Second)
+		cache.Count("b", "")
+		cache.Count("c", "")
+		cache.Count("b", "")
+		cache.Count("a", "")
+
+		assert.Equal(t, 4, fake.counts)
+	})
+}
@@ -0,0 +1,159 @@
+package elasticsearch
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+)
+
+// ErrCircuitOpen is returned without sending the request while the circuit breaker is open.
+var ErrCircuitOpen = errors.New("elasticsearch: circuit breaker is open")
+
+// CircuitBreaker fails requests fast with ErrCircuitOpen after FailureThreshold consecutive failures
+// (network errors and 5xx responses) instead of waiting for an unhealthy cluster to time out.
+// After ResetTimeout a single trial request is let through: the circuit is closed again if it succeeds,
+// and opened again otherwise.
+type CircuitBreaker struct {
+	// FailureThreshold is the number of consecutive failures opening the circuit. Default: 5.
+	FailureThreshold int
+	// ResetTimeout is how long the circuit stays open before a trial request. Default: 30s.
+	ResetTimeout time.Duration
+}
+
+func (cb *CircuitBreaker) validate() error {
+	if cb.FailureThreshold < 0 {
+		return errors.New("CircuitBreaker.FailureThreshold must not be negative")
+	}
+	if cb.ResetTimeout < 0 {
+		return errors.New("CircuitBreaker.ResetTimeout must not be negative")
+	}
+	return nil
+}
+
+// CircuitState is the state of the circuit breaker reported to MetricsHook.
+type CircuitState int
+
+const (
+	CircuitClosed CircuitState = iota
+	CircuitOpen
+	// CircuitHalfOpen lets a trial request through.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return "closed"
+}
+
+type circuit struct {
+	threshold    int
+	resetTimeout time.Duration
+	now          func() time.Time
+	logger       Logger
+	metrics      MetricsHook
+
+	mu       sync.Mutex
+	state    CircuitState
+	failures int
+	openedAt time.Time
+}
+
+func newCircuit(cb *CircuitBreaker, logger Logger, metrics MetricsHook) *circuit {
+	c := &circuit{
+		threshold:    cb.FailureThreshold,
+		resetTimeout: cb.ResetTimeout,
+		now:          time.Now,
+		logger:       logger,
+		metrics:      metrics,
+	}
+	if c.threshold == 0 {
+		c.threshold = 5
+	}
+	if c.resetTimeout == 0 {
+		c.resetTimeout = 30 * time.Second
+	}
+	return c
+}
+
+// setState changes the state with c.mu held.
+func (c *circuit) setState(state CircuitState) {
+	if c.state == state {
+		return
+	}
+	c.state = state
+	if c.metrics != nil {
+		c.metrics.ObserveCircuitState(state)
+	}
+}
+
+// allow reports whether a request may be sent.
+func (c *circuit) allow() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch c.state {
+	case CircuitOpen:
+		if c.now().Sub(c.openedAt) < c.resetTimeout {
+			return false
+		}
+		c.setState(CircuitHalfOpen)
+		return true
+	case CircuitHalfOpen:
+		// A trial request is in flight.
+		return false
+	}
+	return true
+}
+
+func (c *circuit) record(failed bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !failed {
+		c.setState(CircuitClosed)
+		c.failures = 0
+		return
+	}
+
+	c.failures++
+	if c.state == CircuitHalfOpen || c.failures >= c.threshold {
+		if c.state != CircuitOpen {
+			c.logger.Warnf("Circuit breaker opened after %d failures", c.failures)
+		}
+		c.setState(CircuitOpen)
+		c.openedAt = c.now()
+	}
+}
+
+func circuitBreakerMiddleware(c *circuit) middleware {
+	return func(next esapi.Transport) esapi.Transport {
+		return transportFunc(func(req *http.Request) (*http.Response, error) {
+			if !c.allow() {
+				return nil, ErrCircuitOpen
+			}
+
+			res, err := next.Perform(req)
+			if err != nil && (errors.Is(err, context.Canceled) || req.Context().Err() == context.Canceled) {
+				// Cancelled by the caller: says nothing about the cluster, but releases a trial.
+				// An expired deadline is a slow cluster, counted as a failure.
+				c.mu.Lock()
+				if c.state == CircuitHalfOpen {
+					c.setState(CircuitOpen)
+				}
+				c.mu.Unlock()
+				return res, err
+			}
+			c.record(err != nil || res.StatusCode >= 500)
+			return res, err
+		})
+	}
+}
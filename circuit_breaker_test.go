@@ -0,0 +1,98 @@
+package elasticsearch
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuit(t *testing.T) {
+	now := time.Now()
+	c := newCircuit(&CircuitBreaker{FailureThreshold: 2, ResetTimeout: time.Minute}, NopLogger(), nil)
+	c.now = func() time.Time { return now }
+
+	assert.True(t, c.allow())
+	c.record(true)
+	assert.True(t, c.allow())
+	c.record(true)
+	assert.False(t, c.allow(), "opened after 2 failures")
+
+	now = now.Add(time.Minute)
+	assert.True(t, c.allow(), "trial request after the reset timeout")
+	assert.False(t, c.allow(), "only one trial request")
+	c.record(true)
+	assert.False(t, c.allow(), "opened again by a failed trial")
+
+	now = now.Add(time.Minute)
+	assert.True(t, c.allow())
+	c.record(false)
+	assert.True(t, c.allow(), "closed by a successful trial")
+	c.record(true)
+	assert.True(t, c.allow(), "failures are counted again from 0")
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	var calls int32
+	server, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			w.Write([]byte(`{}`))
+			return
+		}
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"error": {"type": "exception", "reason": "unhealthy"}, "status": 500}`))
+	})
+
+	es, err := New(&Config{
+		Address:        []string{server.URL},
+		DisableRetry:   true,
+		CircuitBreaker: &CircuitBreaker{FailureThreshold: 3, ResetTimeout: time.Minute},
+	})
+	assert.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		_, _, _, err := es.Search("x", `{"query": {"match_all": {}}}`, nil)
+		assert.Error(t, err)
+		assert.False(t, errors.Is(err, ErrCircuitOpen))
+	}
+
+	status, _, _, err := es.Search("x", `{"query": {"match_all": {}}}`, nil)
+
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, StatusRequestError, status)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestCircuitBreakerContextErrors(t *testing.T) {
+	c := newCircuit(&CircuitBreaker{FailureThreshold: 2, ResetTimeout: time.Minute}, NopLogger(), nil)
+	var failure error
+	transport := circuitBreakerMiddleware(c)(transportFunc(func(req *http.Request) (*http.Response, error) {
+		return nil, failure
+	}))
+	perform := func(ctx context.Context) error {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost/_search", nil)
+		_, err := transport.Perform(req)
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	failure = context.Canceled
+	for i := 0; i < 3; i++ {
+		assert.ErrorIs(t, perform(ctx), context.Canceled)
+	}
+	assert.True(t, c.allow(), "cancellations are not failures")
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+	failure = context.DeadlineExceeded
+	assert.ErrorIs(t, perform(ctx), context.DeadlineExceeded)
+	assert.ErrorIs(t, perform(ctx), context.DeadlineExceeded)
+	assert.ErrorIs(t, perform(ctx), ErrCircuitOpen, "opened by the expired deadlines")
+}
@@ -0,0 +1,293 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+)
+
+// ClusterSettings are the dynamic settings of the cluster keyed by their flat names,
+// e.g. "cluster.routing.allocation.disk.watermark.low". Persistent settings survive a full
+// cluster restart, unlike transient ones. A nil value resets a setting to its default.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/cluster-update-settings.html
+type ClusterSettings struct {
+	Persistent map[string]interface{} `json:"persistent,omitempty"`
+	Transient  map[string]interface{} `json:"transient,omitempty"`
+	// Defaults are the default values of the other settings, returned by GetClusterSettings with includeDefaults.
+	Defaults map[string]interface{} `json:"defaults,omitempty"`
+}
+
+func (es *_elasticsearch) GetClusterSettings(includeDefaults bool) (StatusCode, *ClusterSettings, error) {
+	flat := true
+	req := esapi.ClusterGetSettingsRequest{
+		FlatSettings:    &flat,
+		IncludeDefaults: &includeDefaults,
+	}
+
+	res, err := req.Do(es.ctx, es.client)
+
+	settings := &ClusterSettings{}
+	if status, err := es.handleResponse("get cluster settings", res, err, settings); err != nil {
+		return status, &ClusterSettings{}, err
+	}
+	return StatusSuccess, settings, nil
+}
+
+// PutClusterSettings updates the persistent and transient settings of settings, leaving the others unchanged.
+func (es *_elasticsearch) PutClusterSettings(settings *ClusterSettings) (StatusCode, error) {
+	body, err := json.Marshal(&ClusterSettings{
+		Persistent: settings.Persistent,
+		Transient:  settings.Transient,
+	})
+	if err != nil {
+		return StatusInternalError, err
+	}
+
+	req := esapi.ClusterPutSettingsRequest{
+		Body: bytes.NewReader(body),
+	}
+
+	res, err := req.Do(es.ctx, es.client)
+	return es.handleResponse("put cluster settings", res, err, nil)
+}
+
+// Health statuses of ClusterHealth.
+const (
+	HealthGreen  = "green"
+	HealthYellow = "yellow"
+	HealthRed    = "red"
+)
+
+// ClusterHealth is the status of the shards of the cluster: red when a primary shard is unassigned,
+// yellow when a replica is, and green otherwise.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/cluster-health.html
+type ClusterHealth struct {
+	ClusterName         string                  `json:"cluster_name"`
+	Status              string                  `json:"status"`
+	NumberOfNodes       int                     `json:"number_of_nodes"`
+	NumberOfDataNodes   int                     `json:"number_of_data_nodes"`
+	ActivePrimaryShards int                     `json:"active_primary_shards"`
+	ActiveShards        int                     `json:"active_shards"`
+	RelocatingShards    int                     `json:"relocating_shards"`
+	InitializingShards  int                     `json:"initializing_shards"`
+	UnassignedShards    int                     `json:"unassigned_shards"`
+	Indices             map[string]*IndexHealth `json:"indices"`
+}
+
+type IndexHealth struct {
+	Status              string `json:"status"`
+	NumberOfShards      int    `json:"number_of_shards"`
+	NumberOfReplicas    int    `json:"number_of_replicas"`
+	ActivePrimaryShards int    `json:"active_primary_shards"`
+	ActiveShards        int    `json:"active_shards"`
+	UnassignedShards    int    `json:"unassigned_shards"`
+}
+
+// ClusterHealth returns the health of the cluster and of each of its indices.
+// The indices outside the index prefix of the client are left out.
+func (es *_elasticsearch) ClusterHealth() (StatusCode, *ClusterHealth, error) {
+	req := esapi.ClusterHealthRequest{
+		Level: "indices",
+	}
+
+	res, err := req.Do(es.ctx, es.client)
+
+	var health ClusterHealth
+	if status, err := es.handleResponse("cluster health", res, err, &health); err != nil {
+		return status, &ClusterHealth{Indices: map[string]*IndexHealth{}}, err
+	}
+
+	indices := map[string]*IndexHealth{}
+	for name, index := range health.Indices {
+		if es.prefix == "" || strings.HasPrefix(name, es.prefix) {
+			indices[es.trimIndexPrefix(name)] = index
+		}
+	}
+	health.Indices = indices
+	return StatusSuccess, &health, nil
+}
+
+// ClusterInfo identifies the cluster and the node answering the requests.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/rest-api-root.html
+type ClusterInfo struct {
+	// Name is the name of the node.
+	Name        string         `json:"name"`
+	ClusterName string         `json:"cluster_name"`
+	ClusterUUID string         `json:"cluster_uuid"`
+	Version     ClusterVersion `json:"version"`
+	Tagline     string         `json:"tagline"`
+}
+
+type ClusterVersion struct {
+	// Number is the version of the node, e.g. "7.17.9".
+	Number string `json:"number"`
+	// Distribution is "opensearch" on OpenSearch, and empty on Elasticsearch.
+	Distribution                     string `json:"distribution"`
+	BuildFlavor                      string `json:"build_flavor"`
+	BuildType                        string `json:"build_type"`
+	BuildHash                        string `json:"build_hash"`
+	BuildDate                        string `json:"build_date"`
+	BuildSnapshot                    bool   `json:"build_snapshot"`
+	LuceneVersion                    string `json:"lucene_version"`
+	MinimumWireCompatibilityVersion  string `json:"minimum_wire_compatibility_version"`
+	MinimumIndexCompatibilityVersion string `json:"minimum_index_compatibility_version"`
+}
+
+// Info returns the name, UUID and version of the cluster, e.g. to log the cluster connected to.
+func (es *_elasticsearch) Info() (StatusCode, *ClusterInfo, error) {
+	res, err := es.client.Info(es.client.Info.WithContext(es.ctx))
+
+	info := &ClusterInfo{}
+	if status, err := es.handleResponse("info", res, err, info); err != nil {
+		return status, &ClusterInfo{}, err
+	}
+	return StatusSuccess, info, nil
+}
+
+// AllocationExplanation tells why a shard is unassigned or where it can move.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/cluster-allocation-explain.html
+type AllocationExplanation struct {
+	Index          string `json:"index"`
+	Shard          int    `json:"shard"`
+	Primary        bool   `json:"primary"`
+	CurrentState   string `json:"current_state"`
+	UnassignedInfo *struct {
+		Reason               string `json:"reason"`
+		At                   string `json:"at"`
+		Details              string `json:"details"`
+		LastAllocationStatus string `json:"last_allocation_status"`
+	} `json:"unassigned_info,omitempty"`
+	CanAllocate             string            `json:"can_allocate"`
+	CanRemainOnCurrentNode  string            `json:"can_remain_on_current_node"`
+	AllocateExplanation     string            `json:"allocate_explanation"`
+	NodeAllocationDecisions []*NodeAllocation `json:"node_allocation_decisions"`
+}
+
+type NodeAllocation struct {
+	NodeID       string               `json:"node_id"`
+	NodeName     string               `json:"node_name"`
+	NodeDecision string               `json:"node_decision"`
+	Deciders     []*AllocationDecider `json:"deciders"`
+}
+
+type AllocationDecider struct {
+	Decider     string `json:"decider"`
+	Decision    string `json:"decision"`
+	Explanation string `json:"explanation"`
+}
+
+// AllocationExplain explains the allocation of a copy of shard of index.
+// An empty index explains the first unassigned shard found, and fails when there is none.
+func (es *_elasticsearch) AllocationExplain(index string, shard int, primary bool) (StatusCode, *AllocationExplanation, error) {
+	req := esapi.ClusterAllocationExplainRequest{}
+	if index != "" {
+		body, err := json.Marshal(map[string]interface{}{
+			"index":   es.indexName(index),
+			"shard":   shard,
+			"primary": primary,
+		})
+		if err != nil {
+			return StatusInternalError, &AllocationExplanation{}, err
+		}
+		req.Body = bytes.NewReader(body)
+	}
+
+	res, err := req.Do(es.ctx, es.client)
+
+	explanation := &AllocationExplanation{}
+	if status, err := es.handleResponse("allocation explain", res, err, explanation); err != nil {
+		return status, &AllocationExplanation{}, err
+	}
+	explanation.Index = es.trimIndexPrefix(explanation.Index)
+	return StatusSuccess, explanation, nil
+}
+
+// RerouteCommand is a command of Reroute, made by MoveShard or CancelShard.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/cluster-reroute.html
+type RerouteCommand map[string]interface{}
+
+// MoveShard moves the started copy of shard of index from the node fromNode to toNode.
+func MoveShard(index string, shard int, fromNode, toNode string) RerouteCommand {
+	return RerouteCommand{"move": map[string]interface{}{
+		"index":     index,
+		"shard":     shard,
+		"from_node": fromNode,
+		"to_node":   toNode,
+	}}
+}
+
+// CancelShard cancels the allocation or recovery of the copy of shard of index on node.
+// Cancelling a primary requires allowPrimary, and loses its unreplicated writes.
+func CancelShard(index string, shard int, node string, allowPrimary bool) RerouteCommand {
+	return RerouteCommand{"cancel": map[string]interface{}{
+		"index":         index,
+		"shard":         shard,
+		"node":          node,
+		"allow_primary": allowPrimary,
+	}}
+}
+
+type RerouteOptions struct {
+	// RetryFailed retries the allocation of the shards which failed too many times, e.g. after fixing their cause.
+	RetryFailed bool
+	// DryRun explains the commands without applying them.
+	DryRun bool
+}
+
+// RerouteExplanation is the decision of the cluster on a command of Reroute.
+type RerouteExplanation struct {
+	Command   string               `json:"command"`
+	Decisions []*AllocationDecider `json:"decisions"`
+}
+
+// Reroute runs commands and returns their explanations. Only the move and cancel commands are accepted:
+// the allocate_stale_primary and allocate_empty_primary commands, which may lose data, are refused.
+// Reroute fails without applying any command when one of them is rejected.
+func (es *_elasticsearch) Reroute(opts RerouteOptions, commands ...RerouteCommand) (StatusCode, []*RerouteExplanation, error) {
+	for _, command := range commands {
+		for name, params := range command {
+			if name != "move" && name != "cancel" {
+				return StatusInternalError, []*RerouteExplanation{}, fmt.Errorf("reroute command %q is not allowed", name)
+			}
+			if p, ok := params.(map[string]interface{}); ok {
+				if index, ok := p["index"].(string); ok {
+					p["index"] = es.indexName(index)
+				}
+			}
+		}
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"commands": commands})
+	if err != nil {
+		return StatusInternalError, []*RerouteExplanation{}, err
+	}
+
+	explain := true
+	req := esapi.ClusterRerouteRequest{
+		Body:    bytes.NewReader(body),
+		Explain: &explain,
+		Metric:  []string{"none"},
+	}
+	if opts.RetryFailed {
+		req.RetryFailed = &opts.RetryFailed
+	}
+	if opts.DryRun {
+		req.DryRun = &opts.DryRun
+	}
+
+	res, err := req.Do(es.ctx, es.client)
+
+	var r struct {
+		Explanations []*RerouteExplanation `json:"explanations"`
+	}
+	if status, err := es.handleResponse("reroute", res, err, &r); err != nil {
+		return status, []*RerouteExplanation{}, err
+	}
+	if r.Explanations == nil {
+		r.Explanations = []*RerouteExplanation{}
+	}
+	return StatusSuccess, r.Explanations, nil
+}
@@ -0,0 +1,151 @@
+package elasticsearch
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClusterSettings(t *testing.T) {
+	es := newElasticsearch()
+	const key = "indices.recovery.max_bytes_per_sec"
+
+	status, err := es.PutClusterSettings(&ClusterSettings{
+		Transient: map[string]interface{}{key: "50mb"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSuccess, status)
+
+	status, settings, err := es.GetClusterSettings(false)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSuccess, status)
+	assert.Equal(t, "50mb", settings.Transient[key])
+	assert.Empty(t, settings.Defaults)
+
+	es.PutClusterSettings(&ClusterSettings{
+		Transient: map[string]interface{}{key: nil},
+	})
+
+	_, settings, err = es.GetClusterSettings(true)
+	assert.NoError(t, err)
+	assert.NotContains(t, settings.Transient, key)
+	assert.Contains(t, settings.Defaults, key)
+}
+
+func TestClusterHealth(t *testing.T) {
+	server, requests := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"cluster_name": "c", "status": "yellow", "number_of_nodes": 3, "unassigned_shards": 1,
+			"indices": {
+				"p-a": {"status": "green", "number_of_shards": 1, "active_shards": 2},
+				"p-b": {"status": "yellow", "number_of_shards": 1, "unassigned_shards": 1},
+				"other": {"status": "green"}
+			}
+		}`))
+	})
+	es, err := New(&Config{Address: []string{server.URL}, IndexPrefix: "p-"})
+	assert.NoError(t, err)
+
+	status, health, err := es.ClusterHealth()
+
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSuccess, status)
+	reqs := requests()
+	assert.Equal(t, "/_cluster/health", reqs[len(reqs)-1].URL.Path)
+	assert.Equal(t, "indices", reqs[len(reqs)-1].URL.Query().Get("level"))
+	assert.Equal(t, HealthYellow, health.Status)
+	assert.Equal(t, 3, health.NumberOfNodes)
+	assert.Len(t, health.Indices, 2)
+	assert.Equal(t, HealthGreen, health.Indices["a"].Status)
+	assert.Equal(t, 1, health.Indices["b"].UnassignedShards)
+}
+
+func TestInfo(t *testing.T) {
+	server, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"name": "node-1", "cluster_name": "c", "cluster_uuid": "uuid",
+			"version": {"number": "7.17.9", "build_flavor": "default", "lucene_version": "8.11.1", "minimum_wire_compatibility_version": "6.8.0"},
+			"tagline": "You Know, for Search"
+		}`))
+	})
+	es, err := New(&Config{Address: []string{server.URL}})
+	assert.NoError(t, err)
+
+	status, info, err := es.Info()
+
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSuccess, status)
+	assert.Equal(t, "node-1", info.Name)
+	assert.Equal(t, "c", info.ClusterName)
+	assert.Equal(t, "uuid", info.ClusterUUID)
+	assert.Equal(t, "7.17.9", info.Version.Number)
+	assert.Equal(t, "8.11.1", info.Version.LuceneVersion)
+	assert.Equal(t, "6.8.0", info.Version.MinimumWireCompatibilityVersion)
+}
+
+func TestReroute(t *testing.T) {
+	var body string
+	server, requests := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		w.Write([]byte(`{"acknowledged": true, "explanations": [{"command": "move", "decisions": [{"decider": "move_allocation_command", "decision": "YES"}]}]}`))
+	})
+	es, err := New(&Config{Address: []string{server.URL}, IndexPrefix: "dev-"})
+	assert.NoError(t, err)
+
+	t.Run("Move", func(t *testing.T) {
+		status, explanations, err := es.Reroute(RerouteOptions{RetryFailed: true}, MoveShard("a", 0, "node-1", "node-2"))
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+		assert.Len(t, explanations, 1)
+		assert.Equal(t, "YES", explanations[0].Decisions[0].Decision)
+
+		reqs := requests()
+		req := reqs[len(reqs)-1]
+		assert.Equal(t, "/_cluster/reroute", req.URL.Path)
+		assert.Equal(t, "true", req.URL.Query().Get("explain"))
+		assert.Equal(t, "true", req.URL.Query().Get("retry_failed"))
+		assert.Empty(t, req.URL.Query().Get("dry_run"))
+		assert.JSONEq(t, `{"commands": [{"move": {"index": "dev-a", "shard": 0, "from_node": "node-1", "to_node": "node-2"}}]}`, body)
+	})
+
+	t.Run("Refused", func(t *testing.T) {
+		count := len(requests())
+		status, _, err := es.Reroute(RerouteOptions{}, CancelShard("a", 0, "node-1", false), RerouteCommand{
+			"allocate_empty_primary": map[string]interface{}{"index": "a", "shard": 0, "node": "node-1", "accept_data_loss": true},
+		})
+		assert.Error(t, err)
+		assert.Equal(t, StatusInternalError, status)
+		assert.Len(t, requests(), count)
+	})
+}
+
+func TestAllocationExplain(t *testing.T) {
+	var body string
+	server, requests := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		w.Write([]byte(`{"index": "dev-a", "shard": 0, "primary": false, "current_state": "unassigned",
+			"unassigned_info": {"reason": "INDEX_CREATED", "last_allocation_status": "no_attempt"},
+			"can_allocate": "no", "node_allocation_decisions": [{"node_name": "node-1", "node_decision": "no",
+			"deciders": [{"decider": "same_shard", "decision": "NO"}]}]}`))
+	})
+	es, err := New(&Config{Address: []string{server.URL}, IndexPrefix: "dev-"})
+	assert.NoError(t, err)
+
+	status, explanation, err := es.AllocationExplain("a", 0, false)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSuccess, status)
+	assert.Equal(t, "a", explanation.Index)
+	assert.Equal(t, "INDEX_CREATED", explanation.UnassignedInfo.Reason)
+	assert.Equal(t, "same_shard", explanation.NodeAllocationDecisions[0].Deciders[0].Decider)
+
+	reqs := requests()
+	assert.Equal(t, "/_cluster/allocation/explain", reqs[len(reqs)-1].URL.Path)
+	assert.JSONEq(t, `{"index": "dev-a", "shard": 0, "primary": false}`, body)
+
+	es.AllocationExplain("", 0, false)
+	assert.Empty(t, body)
+}
@@ -0,0 +1,51 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// Codec marshals the documents sent and unmarshals the responses received, e.g. with
+// jsoniter or sonic instead of encoding/json when JSON dominates the CPU of bulk ingest.
+// Both packages provide a compatible Marshal and Unmarshal:
+//
+//	type sonicCodec struct{}
+//
+//	func (sonicCodec) Marshal(v interface{}) ([]byte, error)      { return sonic.Marshal(v) }
+//	func (sonicCodec) Unmarshal(data []byte, v interface{}) error { return sonic.Unmarshal(data, v) }
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// streamCodec is implemented by the codecs decoding from a reader without reading it whole first.
+type streamCodec interface {
+	Decode(r io.Reader, v interface{}) error
+}
+
+func decode(codec Codec, r io.Reader, v interface{}) error {
+	if sc, ok := codec.(streamCodec); ok {
+		return sc.Decode(r, v)
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return codec.Unmarshal(data, v)
+}
+
+// jsonCodec is the default Codec, using encoding/json.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) Decode(r io.Reader, v interface{}) error {
+	return json.NewDecoder(r).Decode(v)
+}
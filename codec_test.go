@@ -0,0 +1,56 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countingCodec is a Codec without Decode, counting its calls.
+type countingCodec struct {
+	marshal, unmarshal int
+}
+
+func (c *countingCodec) Marshal(v interface{}) ([]byte, error) {
+	c.marshal++
+	return json.Marshal(v)
+}
+
+func (c *countingCodec) Unmarshal(data []byte, v interface{}) error {
+	c.unmarshal++
+	return json.Unmarshal(data, v)
+}
+
+func TestCodec(t *testing.T) {
+	server, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/_search") {
+			w.Write([]byte(`{"hits": {"total": {"value": 1}, "hits": [{"_index": "x", "_id": "1", "_source": {"id": "1"},
+				"inner_hits": {"comments": {"hits": {"total": {"value": 1}, "hits": [{"_id": "1", "_source": {"author": "a"}}]}}}}]}}`))
+			return
+		}
+		w.Write([]byte(`{"_id": "1", "_version": 1, "result": "created"}`))
+	})
+
+	codec := &countingCodec{}
+	es, err := New(&Config{Address: []string{server.URL}, Codec: codec})
+	assert.NoError(t, err)
+
+	_, err = es.CreateDocument(&Document{Index: "x", ID: "1", Body: DocBody{Id: "1"}})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, codec.marshal)
+	assert.Equal(t, 1, codec.unmarshal)
+
+	var docs []DocBody
+	_, hits, _, err := es.Search("x", SearchBody(MatchAllQuery()), &docs)
+	assert.NoError(t, err)
+	assert.Equal(t, "1", docs[0].Id)
+	assert.Equal(t, 3, codec.unmarshal)
+
+	var comments []CommentBody
+	assert.NoError(t, hits[0].InnerHits["comments"].Decode(&comments))
+	assert.Equal(t, []CommentBody{{Author: "a"}}, comments)
+	assert.Equal(t, 4, codec.unmarshal)
+}
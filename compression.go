@@ -0,0 +1,86 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+)
+
+// defaultCompressionMinSize is the default of Config.CompressionMinSize.
+const defaultCompressionMinSize = 1024
+
+// compressionMiddleware gzips the request bodies of at least minSize bytes, and decompresses the gzipped
+// responses the HTTP transport did not, such as when Config.Header sets Accept-Encoding.
+func compressionMiddleware(minSize int) middleware {
+	if minSize <= 0 {
+		minSize = defaultCompressionMinSize
+	}
+	return func(next esapi.Transport) esapi.Transport {
+		return transportFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Body != nil && req.Body != http.NoBody && req.Header.Get("Content-Encoding") == "" {
+				if err := compressBody(req, minSize); err != nil {
+					return nil, err
+				}
+			}
+
+			res, err := next.Perform(req)
+			if err != nil || !strings.EqualFold(res.Header.Get("Content-Encoding"), "gzip") {
+				return res, err
+			}
+
+			gz, err := gzip.NewReader(res.Body)
+			if err != nil {
+				res.Body.Close()
+				return nil, err
+			}
+			res.Body = &gzipBody{Reader: gz, body: res.Body}
+			res.Header.Del("Content-Encoding")
+			res.Header.Del("Content-Length")
+			res.ContentLength = -1
+			return res, nil
+		})
+	}
+}
+
+func compressBody(req *http.Request, minSize int) error {
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return err
+	}
+
+	if len(body) >= minSize {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		if _, err := gz.Write(body); err != nil {
+			return err
+		}
+		if err := gz.Close(); err != nil {
+			return err
+		}
+		body = buf.Bytes()
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+
+	req.ContentLength = int64(len(body))
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	return nil
+}
+
+// gzipBody closes the response body together with its decompressor.
+type gzipBody struct {
+	*gzip.Reader
+	body io.ReadCloser
+}
+
+func (b *gzipBody) Close() error {
+	b.Reader.Close()
+	return b.body.Close()
+}
@@ -0,0 +1,77 @@
+package elasticsearch
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompressRequestBody(t *testing.T) {
+	var encoding, body string
+	server, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		encoding = r.Header.Get("Content-Encoding")
+		reader := io.Reader(r.Body)
+		if encoding == "gzip" {
+			gz, err := gzip.NewReader(r.Body)
+			assert.NoError(t, err)
+			reader = gz
+		}
+		b, _ := io.ReadAll(reader)
+		body = string(b)
+
+		response := `{"count": 3}`
+		if r.URL.Path == "/" {
+			response = `{}`
+		}
+		if r.Header.Get("Accept-Encoding") == "gzip" {
+			w.Header().Set("Content-Encoding", "gzip")
+			gz := gzip.NewWriter(w)
+			defer gz.Close()
+			gz.Write([]byte(response))
+			return
+		}
+		w.Write([]byte(response))
+	})
+
+	t.Run("Large body", func(t *testing.T) {
+		es, err := New(&Config{Address: []string{server.URL}, CompressRequestBody: true, CompressionMinSize: 100})
+		assert.NoError(t, err)
+
+		query := SearchBody(TermsQuery("id", strings.Repeat("x", 100)))
+		_, count, err := es.Count("a", query)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, count)
+		assert.Equal(t, "gzip", encoding)
+		assert.Equal(t, query, body)
+	})
+
+	t.Run("Small body", func(t *testing.T) {
+		es, err := New(&Config{Address: []string{server.URL}, CompressRequestBody: true})
+		assert.NoError(t, err)
+
+		_, _, err = es.Count("a", SearchBody(MatchAllQuery()))
+
+		assert.NoError(t, err)
+		assert.Empty(t, encoding)
+		assert.Equal(t, SearchBody(MatchAllQuery()), body)
+	})
+
+	t.Run("Gzipped response", func(t *testing.T) {
+		es, err := New(&Config{
+			Address:             []string{server.URL},
+			Header:              http.Header{"Accept-Encoding": []string{"gzip"}},
+			CompressRequestBody: true,
+		})
+		assert.NoError(t, err)
+
+		_, count, err := es.Count("a", "")
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, count)
+	})
+}
@@ -0,0 +1,350 @@
+package elasticsearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	goElasticsearch "github.com/elastic/go-elasticsearch/v7"
+	"github.com/elastic/go-elasticsearch/v7/estransport"
+	elasticsearch8 "github.com/elastic/go-elasticsearch/v8"
+)
+
+type Config struct {
+	Address []string
+	CloudID string
+	APIKey  string
+
+	// Username and Password are sent with HTTP Basic Authentication.
+	Username string
+	Password string
+	// ServiceToken is a service account token sent as a bearer token.
+	ServiceToken string
+	// BearerToken is an access token from the token service or an OAuth2 provider.
+	BearerToken string
+
+	// RunAs runs every request as another user, with the roles of that user instead of those of the
+	// authenticated user, who needs the run_as privilege. See also WithRunAs.
+	RunAs string
+
+	// AWS signs requests for Amazon OpenSearch Service domains instead of sending credentials.
+	AWS *AWSAuth
+
+	// CompatibilityMode asks Elasticsearch 8 to accept the requests and return the responses
+	// of Elasticsearch 7, as this package is built on the 7.x client.
+	// https://www.elastic.co/guide/en/elasticsearch/reference/current/rest-api-compatibility.html
+	//
+	// Elasticsearch 8 also enables security by default: use CertificateFingerprint or CACert
+	// with the credentials printed on its first start.
+	CompatibilityMode bool
+	// Driver is the go-elasticsearch client sending the requests. DriverV8 implies CompatibilityMode
+	// and gives the typed API of the 8.x client with TypedClient. Default: DriverV7.
+	Driver Driver
+
+	// IndexPrefix is prepended to every index, alias and index template name, e.g. "staging-",
+	// so that environments or tenants sharing a cluster cannot collide. The names returned in
+	// results are given back without it. See also WithIndexPrefix.
+	IndexPrefix string
+
+	// Preference is the default preference of searches and counts, e.g. PreferenceLocal.
+	// See also WithPreference and the Preference search option.
+	Preference string
+
+	// DisallowPartialResults makes the searches failing on some shards fail with a *PartialResultsError
+	// instead of succeeding with the hits of the other shards. See also the AllowPartialResults search option.
+	DisallowPartialResults bool
+
+	// WriteDefaults are the options of the writes that leave them empty.
+	WriteDefaults WriteDefaults
+
+	// DeletableIndices are the patterns, such as "test-*", of the only indices DeleteIndices may delete.
+	// Names are matched without IndexPrefix. When empty, any index may be deleted by name.
+	DeletableIndices []string
+
+	// Header is added to every request.
+	Header http.Header
+	// HeaderFromContext returns the header of the requests made with a context, e.g. tags found in it.
+	// The header of WithRequestHeader replaces its values of the same keys.
+	HeaderFromContext func(ctx context.Context) http.Header
+
+	// CACert is PEM-encoded certificate authorities trusted instead of the system pool.
+	CACert []byte
+	// ClientCert and ClientKey are a PEM-encoded certificate and key for mutual TLS.
+	ClientCert []byte
+	ClientKey  []byte
+	// CertificateFingerprint is the hex SHA-256 fingerprint of the CA or server certificate,
+	// as printed by Elasticsearch 8 on first start. When set, it replaces chain verification.
+	CertificateFingerprint string
+	// InsecureSkipVerify disables the verification of the server certificate.
+	InsecureSkipVerify bool
+
+	// Transport replaces the HTTP transport of the client.
+	Transport http.RoundTripper
+	// ProxyURL is the URL of the HTTP proxy all requests go through, e.g. "http://proxy:3128".
+	ProxyURL string
+	// DialTimeout is the maximum time to establish a connection. Default: 30s.
+	DialTimeout time.Duration
+	// KeepAlive is the interval of TCP keep-alive probes; negative disables them. Default: 30s.
+	KeepAlive time.Duration
+	// MaxIdleConns is the number of idle connections kept across all nodes. Default: 100.
+	MaxIdleConns int
+	// MaxIdleConnsPerHost is the number of idle connections kept per node. Default: 2.
+	MaxIdleConnsPerHost int
+	// IdleConnTimeout closes the connections idle for longer. Default: 90s.
+	IdleConnTimeout time.Duration
+	// ResponseHeaderTimeout is the maximum time to wait for the headers of a response after sending
+	// the request. Zero waits as long as the context allows, which suits slow searches.
+	ResponseHeaderTimeout time.Duration
+	// DisableKeepAlives opens a new connection for every request.
+	DisableKeepAlives bool
+	// CompressRequestBody gzips the request bodies of at least CompressionMinSize bytes, such as large bulk
+	// requests, and decompresses the gzipped responses. The HTTP transport already asks for gzipped responses
+	// and decompresses them, which Elasticsearch sends when http.compression is enabled (the default).
+	CompressRequestBody bool
+	// CompressionMinSize is the size in bytes of the smallest request body compressed. Default: 1024.
+	CompressionMinSize int
+
+	// Retry configures the retries of failed requests. When nil, the go-elasticsearch transport
+	// retries up to 3 times on 502, 503, 504 and network errors, without backoff.
+	Retry *RetryPolicy
+	// DisableRetry disables every retry.
+	DisableRetry bool
+	// CircuitBreaker makes requests fail fast with ErrCircuitOpen while the cluster is failing.
+	CircuitBreaker *CircuitBreaker
+	// RateLimit throttles all the requests of the client.
+	RateLimit *RateLimit
+	// OperationRateLimits throttles the requests of each operation separately,
+	// on top of RateLimit, e.g. to keep bulk writes of a backfill from starving searches.
+	OperationRateLimits map[Operation]RateLimit
+
+	// DiscoverNodesOnStart replaces Address with the HTTP nodes of the cluster when the client is created.
+	DiscoverNodesOnStart bool
+	// DiscoverNodesInterval discovers the nodes again periodically. Zero disables it.
+	DiscoverNodesInterval time.Duration
+	// NodeSelector chooses the node of each request among the live nodes,
+	// e.g. RoundRobinSelector (the default) or ZoneAwareSelector.
+	NodeSelector estransport.Selector
+
+	// OpaqueIDFromContext returns the X-Opaque-Id of the requests made with a context
+	// without WithOpaqueID, e.g. the ID of the application request found in it.
+	OpaqueIDFromContext func(ctx context.Context) string
+
+	// Metrics receives the metrics of the requests, retries and circuit breaker.
+	Metrics MetricsHook
+
+	// WriteHooks are called around the document writes of CreateDocument, UpdateDocument, RemoveDocument
+	// and Bulk, in order.
+	WriteHooks []WriteHook
+	// Validators check the documents indexed or updated in the indices matching their pattern,
+	// e.g. "logs-*", before the WriteHooks. All the validators of the matching patterns are called.
+	Validators map[string]Validator
+	// Redactions transform fields of the documents indexed or updated in the indices matching their pattern,
+	// after the Validators and before the WriteHooks.
+	Redactions map[string][]Redaction
+	// RedactionKey is the key of the HMAC of RedactHash. Default: none, a plain SHA-256.
+	RedactionKey []byte
+
+	// DryRun logs the requests writing documents or changing indices and the cluster instead of
+	// sending them, and returns simulated successful results. Their bodies are still checked,
+	// and the queries of update and delete by query validated by the cluster.
+	DryRun bool
+
+	// SlowThreshold logs a warning with the query of every request taking longer. Zero disables it.
+	SlowThreshold time.Duration
+	// SlowQueryLimit is the number of bytes of the queries logged by SlowThreshold. Default: 1024.
+	SlowQueryLimit int
+
+	// Debug logs every request and response with their bodies and duration at the debug level,
+	// hiding credentials and secret fields such as "password".
+	Debug bool
+	// DebugBodyLimit is the number of bytes of the bodies logged by Debug. Default: 4096.
+	DebugBodyLimit int
+
+	// Codec marshals the documents and unmarshals the responses. Default: encoding/json.
+	Codec Codec
+
+	// Logger receives the logs of the client. Default: all levels to the standard log package.
+	Logger Logger
+
+	// Context closes the client as by Close when it is done, e.g. the root context of the application
+	// cancelled on SIGTERM, stopping the AsyncIndexers, HealthCheckers and WriteAheadLogs of the client.
+	Context context.Context
+	// DrainTimeout bounds the time Close waits for the pending writes and the requests in flight.
+	// The work left is abandoned and logged. Default: no limit.
+	DrainTimeout time.Duration
+
+	// WaitForReady makes New ping the cluster and fail when it does not answer.
+	WaitForReady bool
+	// ReadyRetries is the number of pings retried by WaitForReady before New fails.
+	ReadyRetries int
+	// ReadyBackoff is the wait before the first retried ping, doubled on every retry. Default: 1s.
+	ReadyBackoff time.Duration
+}
+
+func (config *Config) validate() error {
+	if config == nil {
+		return errors.New("Required config")
+	}
+	if len(config.Address) > 0 && config.CloudID != "" {
+		return errors.New("both Address and CloudID are set")
+	}
+	for _, address := range config.Address {
+		u, err := url.Parse(address)
+		if err != nil {
+			return fmt.Errorf("invalid address %q: %w", address, err)
+		}
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid address %q: must be http(s)://host[:port]", address)
+		}
+	}
+	credentials := 0
+	for _, set := range []bool{config.APIKey != "", config.ServiceToken != "", config.BearerToken != "", config.Username != "", config.AWS != nil} {
+		if set {
+			credentials++
+		}
+	}
+	if credentials > 1 {
+		return errors.New("only one of APIKey, ServiceToken, BearerToken, Username and AWS can be set")
+	}
+	if config.AWS != nil {
+		if err := config.AWS.validate(); err != nil {
+			return err
+		}
+	}
+	if config.Driver != DriverV7 && config.Driver != DriverV8 {
+		return fmt.Errorf("invalid driver %s", config.Driver)
+	}
+	if config.Driver == DriverV8 && config.AWS != nil {
+		return errors.New("AWS cannot be used with DriverV8, as Amazon OpenSearch Service is not Elasticsearch 8")
+	}
+	if config.Driver == DriverV8 && config.NodeSelector != nil {
+		return errors.New("NodeSelector cannot be used with DriverV8, as it selects the connections of the 7.x client")
+	}
+	if config.Password != "" && config.Username == "" {
+		return errors.New("Password is set without Username")
+	}
+	if config.Retry != nil {
+		if err := config.Retry.validate(); err != nil {
+			return err
+		}
+	}
+	if config.CircuitBreaker != nil {
+		if err := config.CircuitBreaker.validate(); err != nil {
+			return err
+		}
+	}
+	if config.RateLimit != nil {
+		if err := config.RateLimit.validate(); err != nil {
+			return err
+		}
+	}
+	for _, l := range config.OperationRateLimits {
+		if err := l.validate(); err != nil {
+			return err
+		}
+	}
+	if config.DiscoverNodesInterval < 0 {
+		return errors.New("DiscoverNodesInterval must not be negative")
+	}
+	if config.ReadyRetries < 0 {
+		return errors.New("ReadyRetries must not be negative")
+	}
+	if config.DrainTimeout < 0 {
+		return errors.New("DrainTimeout must not be negative")
+	}
+	return nil
+}
+
+func (config *Config) logger() Logger {
+	if config.Logger == nil {
+		return NewStdLogger(nil, LevelDebug)
+	}
+	return config.Logger
+}
+
+func (config *Config) codec() Codec {
+	if config.Codec == nil {
+		return jsonCodec{}
+	}
+	return config.Codec
+}
+
+// writeHooks returns the WriteHooks, after the validation of the Validators and the Redactions.
+func (config *Config) writeHooks() []WriteHook {
+	var hooks []WriteHook
+	if len(config.Validators) > 0 {
+		hooks = append(hooks, newValidationHook(config.Validators))
+	}
+	if len(config.Redactions) > 0 {
+		hooks = append(hooks, newRedactionHook(config.Redactions, config.RedactionKey))
+	}
+	return append(hooks, config.WriteHooks...)
+}
+
+// connectElasticsearch returns the go-elasticsearch client of config and its HTTP transport.
+func connectElasticsearch(config *Config) (driver, http.RoundTripper, error) {
+	transport, err := config.transport()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The transport sends both service and bearer tokens as "Authorization: Bearer".
+	serviceToken := config.ServiceToken
+	if config.BearerToken != "" {
+		serviceToken = config.BearerToken
+	}
+
+	if config.Driver == DriverV8 {
+		client, err := elasticsearch8.NewClient(elasticsearch8.Config{
+			Addresses:    config.Address,
+			CloudID:      config.CloudID,
+			APIKey:       config.APIKey,
+			Username:     config.Username,
+			Password:     config.Password,
+			ServiceToken: serviceToken,
+			Header:       config.Header.Clone(),
+			Transport:    transport,
+
+			EnableMetrics:        true,
+			DiscoverNodesOnStart: config.DiscoverNodesOnStart,
+			DisableRetry:         config.DisableRetry || config.Retry != nil,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		return v8Driver{client}, transport, nil
+	}
+
+	cfg := goElasticsearch.Config{
+		Addresses:    config.Address,
+		CloudID:      config.CloudID,
+		APIKey:       config.APIKey,
+		Username:     config.Username,
+		Password:     config.Password,
+		ServiceToken: serviceToken,
+		Header:       config.Header.Clone(),
+		Transport:    transport,
+
+		// Metrics are read by Stats.
+		EnableMetrics: true,
+
+		// The periodic discovery is done by the client, to be stopped by Close.
+		DiscoverNodesOnStart: config.DiscoverNodesOnStart,
+		Selector:             config.NodeSelector,
+
+		// Retries are done by retryMiddleware when a policy is configured.
+		DisableRetry: config.DisableRetry || config.Retry != nil,
+	}
+	if config.AWS != nil {
+		cfg.Transport = &awsSigner{auth: config.AWS, next: transport, now: time.Now}
+	}
+
+	client, err := goElasticsearch.NewClient(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return v7Driver{client}, transport, nil
+}
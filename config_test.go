@@ -0,0 +1,91 @@
+package elasticsearch
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestServer starts a server answering like Elasticsearch with handler,
+// recording every request it receives.
+func newTestServer(t *testing.T, handler http.HandlerFunc) (*httptest.Server, func() []*http.Request) {
+	var (
+		mu       sync.Mutex
+		requests []*http.Request
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r)
+		mu.Unlock()
+
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+		w.Header().Set("Content-Type", "application/json")
+		if handler == nil {
+			w.Write([]byte(`{}`))
+			return
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []*http.Request {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]*http.Request{}, requests...)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		assert.NoError(t, (&Config{}).validate())
+		assert.NoError(t, (&Config{Address: []string{"https://127.0.0.1:9200"}, Username: "elastic", Password: "changeme"}).validate())
+	})
+
+	t.Run("Failure", func(t *testing.T) {
+		t.Run("Multiple credentials", func(t *testing.T) {
+			assert.Error(t, (&Config{APIKey: "key", Username: "elastic"}).validate())
+			assert.Error(t, (&Config{ServiceToken: "token", BearerToken: "token"}).validate())
+		})
+
+		t.Run("Password without Username", func(t *testing.T) {
+			assert.Error(t, (&Config{Password: "changeme"}).validate())
+		})
+	})
+}
+
+func TestConfigAuthentication(t *testing.T) {
+	server, requests := newTestServer(t, nil)
+
+	t.Run("Basic", func(t *testing.T) {
+		es, err := New(&Config{Address: []string{server.URL}, Username: "elastic", Password: "changeme"})
+		assert.NoError(t, err)
+		assert.NoError(t, es.Ping())
+
+		reqs := requests()
+		username, password, ok := reqs[len(reqs)-1].BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "elastic", username)
+		assert.Equal(t, "changeme", password)
+	})
+
+	t.Run("Bearer", func(t *testing.T) {
+		es, err := New(&Config{Address: []string{server.URL}, BearerToken: "token"})
+		assert.NoError(t, err)
+		assert.NoError(t, es.Ping())
+
+		reqs := requests()
+		assert.Equal(t, "Bearer token", reqs[len(reqs)-1].Header.Get("Authorization"))
+	})
+
+	t.Run("Header", func(t *testing.T) {
+		es, err := New(&Config{Address: []string{server.URL}, Header: http.Header{"X-Tag": []string{"batch"}}})
+		assert.NoError(t, err)
+		assert.NoError(t, es.Ping())
+
+		reqs := requests()
+		assert.Equal(t, "batch", reqs[len(reqs)-1].Header.Get("X-Tag"))
+	})
+}
@@ -65,4 +65,12 @@ func TestCount(t *testing.T) {
 		assert.Equal(t, StatusSuccess, status)
 		assert.Equal(t, 0, count)
 	})
+
+	t.Run("No query", func(t *testing.T) {
+		status, count, err := es.Count(indexName, "")
+
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+		assert.GreaterOrEqual(t, count, targetSize+dummySize)
+	})
 }
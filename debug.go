@@ -0,0 +1,109 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+)
+
+const defaultDebugBodyLimit = 4096
+
+var (
+	redactedHeaders = map[string]bool{
+		"Authorization":        true,
+		"X-Amz-Security-Token": true,
+		"Cookie":               true,
+	}
+	redactedFields = regexp.MustCompile(`"(password|passwd|secret|token|api_key|access_token|refresh_token|client_secret)"(\s*:\s*)"(?:[^"\\]|\\.)*"`)
+)
+
+// redact hides the values of secret JSON fields in body, e.g. the password of a new user.
+func redact(body []byte) []byte {
+	return redactedFields.ReplaceAll(body, []byte(`"$1"$2"[REDACTED]"`))
+}
+
+func truncate(body []byte, limit int) string {
+	if len(body) <= limit {
+		return string(body)
+	}
+	return fmt.Sprintf("%s... (%d bytes truncated)", body[:limit], len(body)-limit)
+}
+
+func dumpHeader(header http.Header) string {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		value := strings.Join(header[name], ", ")
+		if redactedHeaders[http.CanonicalHeaderKey(name)] {
+			value = "[REDACTED]"
+		}
+		fmt.Fprintf(&b, "%s: %s\n", name, value)
+	}
+	return b.String()
+}
+
+// peekBody returns the body of req without consuming it.
+func peekBody(req *http.Request) []byte {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil
+		}
+		defer body.Close()
+		b, _ := io.ReadAll(body)
+		return b
+	}
+
+	b, _ := io.ReadAll(req.Body)
+	req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(b))
+	return b
+}
+
+// debugMiddleware logs every request and response with their headers, bodies and duration.
+func debugMiddleware(logger Logger, limit int) middleware {
+	if limit <= 0 {
+		limit = defaultDebugBodyLimit
+	}
+
+	return func(next esapi.Transport) esapi.Transport {
+		return transportFunc(func(req *http.Request) (*http.Response, error) {
+			reqBody := peekBody(req)
+			logger.Debugf("> %s %s\n%s\n%s", req.Method, req.URL.RequestURI(), dumpHeader(req.Header), truncate(redact(reqBody), limit))
+
+			start := time.Now()
+			res, err := next.Perform(req)
+			duration := time.Since(start)
+			if err != nil {
+				logger.Debugf("< %s %s failed in %s: %s", req.Method, req.URL.Path, duration, err)
+				return res, err
+			}
+
+			resBody, readErr := io.ReadAll(res.Body)
+			res.Body.Close()
+			res.Body = io.NopCloser(bytes.NewReader(resBody))
+			if readErr != nil {
+				return res, readErr
+			}
+			logger.Debugf("< %s %s in %s\n%s\n%s", res.Status, req.URL.Path, duration, dumpHeader(res.Header), truncate(redact(resBody), limit))
+
+			return res, nil
+		})
+	}
+}
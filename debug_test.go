@@ -0,0 +1,50 @@
+package elasticsearch
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedact(t *testing.T) {
+	body := `{"username": "u", "password": "p\"ss", "nested": {"api_key":"k"}, "token_type": "t"}`
+
+	assert.Equal(t,
+		`{"username": "u", "password": "[REDACTED]", "nested": {"api_key":"[REDACTED]"}, "token_type": "t"}`,
+		string(redact([]byte(body))),
+	)
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "abc", truncate([]byte("abc"), 3))
+	assert.Equal(t, "ab... (1 bytes truncated)", truncate([]byte("abc"), 2))
+}
+
+func TestDebug(t *testing.T) {
+	server, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"hits": {"total": {"value": 0, "relation": "eq"}, "hits": []}}`))
+	})
+
+	logger := &recordingLogger{}
+	es, err := New(&Config{
+		Address: []string{server.URL},
+		Header:  http.Header{"Authorization": []string{"Bearer secret"}},
+		Logger:  logger,
+		Debug:   true,
+	})
+	assert.NoError(t, err)
+
+	query := `{"query": {"match_all": {}}}`
+	_, _, total, err := es.Search("x", query, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, total, "the response body is still decoded")
+
+	logs := strings.Join(logger.logs, "\n")
+	assert.Contains(t, logs, "DEBUG > POST /x/_search")
+	assert.Contains(t, logs, query)
+	assert.Contains(t, logs, "DEBUG < 200 OK /x/_search in ")
+	assert.Contains(t, logs, `"relation": "eq"`)
+	assert.NotContains(t, logs, "secret")
+}
@@ -0,0 +1,118 @@
+package elasticsearch
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/elastic/elastic-transport-go/v8/elastictransport"
+	goElasticsearch "github.com/elastic/go-elasticsearch/v7"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+	"github.com/elastic/go-elasticsearch/v7/estransport"
+	elasticsearch8 "github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/typedapi"
+)
+
+// Driver is the major version of the go-elasticsearch client sending the requests.
+type Driver int
+
+const (
+	// DriverV7 is the 7.x client, for Elasticsearch 7 and OpenSearch,
+	// and for Elasticsearch 8 with CompatibilityMode.
+	DriverV7 Driver = iota
+	// DriverV8 is the 8.x client, for Elasticsearch 8. The requests of this package are sent in
+	// compatibility mode, and TypedClient gives the typed API of the 8.x client.
+	DriverV8
+)
+
+func (d Driver) String() string {
+	switch d {
+	case DriverV7:
+		return "v7"
+	case DriverV8:
+		return "v8"
+	default:
+		return fmt.Sprintf("Driver(%d)", int(d))
+	}
+}
+
+// ErrTypedAPIUnavailable is returned by TypedClient when the client is not a DriverV8 client.
+var ErrTypedAPIUnavailable = errors.New("elasticsearch: the typed API requires DriverV8")
+
+// TypedClient returns the typed API of the 8.x client for the requests this package has no method for.
+// Its requests go through the same middlewares, e.g. the retries, the metrics and Close, as those of es.
+func TypedClient(es Elasticsearch) (*typedapi.API, error) {
+	e, ok := base(es)
+	if !ok || e.client.driver != DriverV8 {
+		return nil, ErrTypedAPIUnavailable
+	}
+	return typedapi.New(e.client), nil
+}
+
+// driver is the go-elasticsearch client below the middlewares.
+type driver interface {
+	esapi.Transport
+	DiscoverNodes() error
+	// transport returns the transport of the client, which does not check the product of the server.
+	transport() esapi.Transport
+	// metrics sets the request counts and the nodes of stats.
+	metrics(stats *ClientStats) error
+}
+
+type v7Driver struct {
+	*goElasticsearch.Client
+}
+
+func (d v7Driver) transport() esapi.Transport {
+	return d.Transport
+}
+
+func (d v7Driver) metrics(stats *ClientStats) error {
+	m, err := d.Metrics()
+	if err != nil {
+		return err
+	}
+	stats.Requests, stats.Failures = m.Requests, m.Failures
+	for code, n := range m.Responses {
+		stats.Responses[code] = n
+	}
+	for _, c := range m.Connections {
+		if cm, ok := c.(estransport.ConnectionMetric); ok {
+			stats.Nodes = append(stats.Nodes, newNodeStats(cm.URL, cm.Meta.ID, cm.Meta.Name, cm.IsDead, cm.Failures, cm.DeadSince))
+		}
+	}
+	return nil
+}
+
+type v8Driver struct {
+	*elasticsearch8.Client
+}
+
+func (d v8Driver) transport() esapi.Transport {
+	return d.Transport
+}
+
+func (d v8Driver) metrics(stats *ClientStats) error {
+	m, err := d.Metrics()
+	if err != nil {
+		return err
+	}
+	stats.Requests, stats.Failures = m.Requests, m.Failures
+	for code, n := range m.Responses {
+		stats.Responses[code] = n
+	}
+	for _, c := range m.Connections {
+		if cm, ok := c.(elastictransport.ConnectionMetric); ok {
+			stats.Nodes = append(stats.Nodes, newNodeStats(cm.URL, cm.Meta.ID, cm.Meta.Name, cm.IsDead, cm.Failures, cm.DeadSince))
+		}
+	}
+	return nil
+}
+
+func newNodeStats(url, id, name string, dead bool, failures int, deadSince *time.Time) *NodeStats {
+	node := &NodeStats{URL: url, ID: id, Name: name, Dead: dead, Failures: failures}
+	if deadSince != nil {
+		node.DeadSince = *deadSince
+	}
+	return node
+}
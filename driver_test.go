@@ -0,0 +1,88 @@
+package elasticsearch
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDriverV8(t *testing.T) {
+	server, requests := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Write([]byte(`{"name": "node-1", "cluster_name": "c", "cluster_uuid": "u", "version": {"number": "8.11.0"}, "tagline": "You Know, for Search"}`))
+		default:
+			w.Write([]byte(`{"hits": {"total": {"value": 1, "relation": "eq"}, "hits": [{"_index": "x", "_id": "1", "_score": 1.0, "_source": {}}]}}`))
+		}
+	})
+
+	es, err := New(&Config{
+		Address: []string{server.URL},
+		Driver:  DriverV8,
+	})
+	assert.NoError(t, err)
+
+	t.Run("Compatibility mode", func(t *testing.T) {
+		status, hits, total, err := es.Search("x", `{"query": {"match_all": {}}}`, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+		assert.Equal(t, 1, total)
+		assert.Equal(t, "1", hits[0].Id)
+
+		reqs := requests()
+		req := reqs[len(reqs)-1]
+		assert.Equal(t, "application/vnd.elasticsearch+json;compatible-with=7", req.Header.Get("Accept"))
+		assert.Contains(t, req.Header.Get("X-Elastic-Client-Meta"), "es=8.")
+	})
+
+	t.Run("Stats", func(t *testing.T) {
+		stats := es.Stats()
+		assert.NotZero(t, stats.Requests)
+		assert.Equal(t, 1, stats.LiveNodes)
+	})
+
+	t.Run("Typed API", func(t *testing.T) {
+		api, err := TypedClient(NewCache(es, &CacheConfig{}))
+		assert.NoError(t, err)
+
+		info, err := api.Info().Do(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, "c", info.ClusterName)
+
+		reqs := requests()
+		req := reqs[len(reqs)-1]
+		assert.Equal(t, "application/vnd.elasticsearch+json;compatible-with=8", req.Header.Get("Accept"))
+	})
+
+	t.Run("Typed API of DriverV7", func(t *testing.T) {
+		es, err := New(&Config{Address: []string{server.URL}})
+		assert.NoError(t, err)
+
+		_, err = TypedClient(es)
+		assert.ErrorIs(t, err, ErrTypedAPIUnavailable)
+
+		_, err = TypedClient(NewFake())
+		assert.ErrorIs(t, err, ErrTypedAPIUnavailable)
+	})
+
+	t.Run("Closed", func(t *testing.T) {
+		es, err := New(&Config{Address: []string{server.URL}, Driver: DriverV8})
+		assert.NoError(t, err)
+		api, err := TypedClient(es)
+		assert.NoError(t, err)
+		assert.NoError(t, es.Close())
+
+		_, err = api.Info().Do(context.Background())
+		assert.ErrorIs(t, err, ErrClientClosed)
+	})
+
+	t.Run("Invalid config", func(t *testing.T) {
+		assert.Error(t, (&Config{Driver: DriverV8, AWS: &AWSAuth{Region: "us-east-1", Credentials: EnvAWSCredentials()}}).validate())
+		assert.Error(t, (&Config{Driver: DriverV8, NodeSelector: RoundRobinSelector()}).validate())
+		assert.Error(t, (&Config{Driver: Driver(2)}).validate())
+	})
+}
@@ -0,0 +1,216 @@
+package elasticsearch
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync/atomic"
+
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+)
+
+// isMutating reports whether req changes documents, indices or the cluster.
+func isMutating(req *http.Request) bool {
+	switch requestOperation(req) {
+	case OperationWrite:
+		return true
+	case OperationAdmin:
+		if req.Method == http.MethodGet || req.Method == http.MethodHead {
+			return false
+		}
+		return !strings.HasSuffix(req.URL.Path, "/_refresh")
+	}
+	return false
+}
+
+// dryRunMiddleware logs the mutating requests instead of sending them and answers them with
+// simulated successful responses. Their bodies are checked to be valid JSON, or valid bulk requests,
+// and the queries of update and delete by query are validated by Elasticsearch.
+func dryRunMiddleware(logger Logger) middleware {
+	var ids int64
+
+	return func(next esapi.Transport) esapi.Transport {
+		return transportFunc(func(req *http.Request) (*http.Response, error) {
+			if !isMutating(req) {
+				return next.Perform(req)
+			}
+
+			body := peekBody(req)
+			logger.Infof("Dry run: %s %s %s", req.Method, req.URL.RequestURI(), truncate(redact(body), defaultSlowLogQueryLimit))
+
+			segments := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+			endpoint := ""
+			for _, segment := range segments {
+				if strings.HasPrefix(segment, "_") {
+					endpoint = segment
+					break
+				}
+			}
+			index := requestIndex(req)
+			id := ""
+			if len(segments) == 3 && index != "" {
+				id = segments[2]
+			}
+			newID := func() string {
+				return fmt.Sprintf("dry-run-%d", atomic.AddInt64(&ids, 1))
+			}
+
+			if endpoint == "_bulk" {
+				items, err := dryRunBulk(body, index, newID)
+				if err != nil {
+					return dryRunResponse(req, http.StatusBadRequest, dryRunError(err))
+				}
+				return dryRunResponse(req, http.StatusOK, map[string]interface{}{"took": 0, "errors": false, "items": items})
+			}
+
+			if len(body) > 0 && !json.Valid(body) {
+				return dryRunResponse(req, http.StatusBadRequest, dryRunError(fmt.Errorf("invalid JSON body")))
+			}
+
+			switch endpoint {
+			case "_doc", "_create":
+				if req.Method == http.MethodDelete {
+					return dryRunResponse(req, http.StatusOK, dryRunDocument(index, id, "deleted"))
+				}
+				if id == "" {
+					id = newID()
+				}
+				return dryRunResponse(req, http.StatusCreated, dryRunDocument(index, id, "created"))
+			case "_update":
+				return dryRunResponse(req, http.StatusOK, dryRunDocument(index, id, "updated"))
+			case "_update_by_query", "_delete_by_query":
+				return dryRunByQuery(next, req, index, body)
+			}
+			return dryRunResponse(req, http.StatusOK, map[string]interface{}{"acknowledged": true})
+		})
+	}
+}
+
+func dryRunDocument(index, id, result string) map[string]interface{} {
+	return map[string]interface{}{
+		"_index":   index,
+		"_id":      id,
+		"_version": 1,
+		"result":   result,
+		"_shards":  map[string]int{"total": 1, "successful": 1, "failed": 0},
+	}
+}
+
+func dryRunError(err error) map[string]interface{} {
+	return map[string]interface{}{
+		"error":  map[string]string{"type": "dry_run_exception", "reason": err.Error()},
+		"status": http.StatusBadRequest,
+	}
+}
+
+// dryRunBulk checks a bulk request body and returns the results of its items.
+func dryRunBulk(body []byte, defaultIndex string, newID func() string) ([]map[string]interface{}, error) {
+	items := []map[string]interface{}{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	scanner.Buffer(make([]byte, 64*1024), len(body)+1)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var action map[string]struct {
+			Index string `json:"_index"`
+			ID    string `json:"_id"`
+		}
+		if err := json.Unmarshal(line, &action); err != nil || len(action) != 1 {
+			return nil, fmt.Errorf("invalid bulk action line %d", len(items)+1)
+		}
+
+		for name, meta := range action {
+			index, id := meta.Index, meta.ID
+			if index == "" {
+				index = defaultIndex
+			}
+
+			status, result := http.StatusOK, ""
+			switch BulkAction(name) {
+			case BulkIndex, BulkCreate:
+				status, result = http.StatusCreated, "created"
+				if id == "" {
+					id = newID()
+				}
+			case BulkUpdate:
+				result = "updated"
+			case BulkDelete:
+				result = "deleted"
+			default:
+				return nil, fmt.Errorf("unknown bulk action [%s]", name)
+			}
+
+			if BulkAction(name) != BulkDelete {
+				if !scanner.Scan() || !json.Valid(scanner.Bytes()) {
+					return nil, fmt.Errorf("invalid bulk source of %s ID=%s", name, id)
+				}
+			}
+
+			item := dryRunDocument(index, id, result)
+			item["status"] = status
+			items = append(items, map[string]interface{}{name: item})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// dryRunByQuery validates the query of an update or delete by query request.
+func dryRunByQuery(next esapi.Transport, req *http.Request, index string, body []byte) (*http.Response, error) {
+	var r struct {
+		Query json.RawMessage `json:"query"`
+	}
+	json.Unmarshal(body, &r)
+	if len(r.Query) == 0 {
+		return dryRunResponse(req, http.StatusOK, map[string]interface{}{"total": 0, "updated": 0, "deleted": 0, "failures": []interface{}{}})
+	}
+
+	validateBody, _ := json.Marshal(map[string]json.RawMessage{"query": r.Query})
+	validate, err := http.NewRequestWithContext(req.Context(), http.MethodPost, (&url.URL{Path: "/" + path.Join(index, "_validate/query")}).String(), bytes.NewReader(validateBody))
+	if err != nil {
+		return nil, err
+	}
+	validate.Header.Set("Content-Type", "application/json")
+
+	res, err := next.Perform(validate)
+	if err != nil || res.StatusCode >= 300 {
+		return res, err
+	}
+	defer res.Body.Close()
+
+	var v struct {
+		Valid bool   `json:"valid"`
+		Error string `json:"error"`
+	}
+	json.NewDecoder(res.Body).Decode(&v)
+	if !v.Valid {
+		return dryRunResponse(req, http.StatusBadRequest, dryRunError(fmt.Errorf("invalid query: %s", v.Error)))
+	}
+	return dryRunResponse(req, http.StatusOK, map[string]interface{}{"total": 0, "updated": 0, "deleted": 0, "failures": []interface{}{}})
+}
+
+func dryRunResponse(req *http.Request, statusCode int, body interface{}) (*http.Response, error) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		Status:     fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode)),
+		StatusCode: statusCode,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(b)),
+		Request:    req,
+	}, nil
+}
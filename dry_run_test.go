@@ -0,0 +1,109 @@
+package elasticsearch
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsMutating(t *testing.T) {
+	cases := []struct {
+		method string
+		path   string
+		want   bool
+	}{
+		{http.MethodPut, "/x/_doc/1", true},
+		{http.MethodPost, "/_bulk", true},
+		{http.MethodDelete, "/x", true},
+		{http.MethodPut, "/_index_template/t", true},
+		{http.MethodGet, "/x/_doc/1", false},
+		{http.MethodPost, "/x/_search", false},
+		{http.MethodPost, "/x/_refresh", false},
+		{http.MethodGet, "/_cluster/health", false},
+	}
+	for _, c := range cases {
+		req := &http.Request{Method: c.method, URL: &url.URL{Path: c.path}}
+		assert.Equal(t, c.want, isMutating(req), "%s %s", c.method, c.path)
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	server, requests := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"hits": {"total": {"value": 0, "relation": "eq"}, "hits": []}}`))
+	})
+
+	logger := &recordingLogger{}
+	es, err := New(&Config{
+		Address: []string{server.URL},
+		Logger:  logger,
+		DryRun:  true,
+	})
+	assert.NoError(t, err)
+
+	t.Run("Writes are not sent", func(t *testing.T) {
+		status, err := es.CreateDocument(&Document{Index: "x", ID: "1", Body: DocBody{Id: "1"}})
+		assert.NoError(t, err)
+		assert.Equal(t, StatusCreated, status)
+
+		status, err = es.UpdateDocument(&Document{Index: "x", ID: "1", Body: DocBody{Id: "1"}})
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+
+		status, err = es.RemoveDocument(&Document{Index: "x", ID: "1"})
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+
+		status, results, err := es.Bulk([]*BulkItem{
+			{Index: "x", Body: DocBody{Id: "2"}},
+			{Action: BulkDelete, Index: "x", ID: "3"},
+		}, RefreshTrue)
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+		assert.Equal(t, "created", results[0].Result)
+		assert.NotEmpty(t, results[0].ID)
+		assert.Equal(t, "deleted", results[1].Result)
+
+		_, err = es.DeleteIndeces("x")
+		assert.NoError(t, err)
+
+		for _, req := range requests() {
+			assert.Equal(t, "/", req.URL.Path, "only the product check is sent")
+		}
+		assert.Contains(t, strings.Join(logger.logs, "\n"), "INFO Dry run: PUT /x/_doc/1")
+	})
+
+	t.Run("Reads are sent", func(t *testing.T) {
+		_, _, _, err := es.Search("x", `{"query": {"match_all": {}}}`, nil)
+		assert.NoError(t, err)
+
+		reqs := requests()
+		assert.Equal(t, "/x/_search", reqs[len(reqs)-1].URL.Path)
+	})
+}
+
+func TestDryRunBulk(t *testing.T) {
+	newID := func() string { return "generated" }
+
+	items, err := dryRunBulk([]byte(`{"index": {"_index": "x"}}
+{"a": 1}
+{"update": {"_id": "1"}}
+{"doc": {"a": 2}}
+{"delete": {"_index": "x", "_id": "2"}}
+`), "default", newID)
+	assert.NoError(t, err)
+	assert.Len(t, items, 3)
+	assert.Equal(t, "generated", items[0]["index"].(map[string]interface{})["_id"])
+	assert.Equal(t, "default", items[1]["update"].(map[string]interface{})["_index"])
+
+	for _, body := range []string{
+		`{"index": {}}` + "\n" + `{"a": `,
+		`{"upsert": {}}` + "\n" + `{}`,
+		`{"index": {}}`,
+	} {
+		_, err := dryRunBulk([]byte(body), "x", newID)
+		assert.Error(t, err, body)
+	}
+}
@@ -0,0 +1,166 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Errors to branch on with errors.Is, matched by the *ESError of the corresponding status.
+var (
+	ErrBadRequest = errors.New("elasticsearch: bad request")
+	ErrNotFound   = errors.New("elasticsearch: not found")
+	ErrConflict   = errors.New("elasticsearch: conflict")
+)
+
+// ErrDeleteRefused is returned by DeleteIndices for the names it refuses to delete, without sending the request.
+var ErrDeleteRefused = errors.New("elasticsearch: refused to delete indices")
+
+// RequestError is returned when a request could not be sent or its response not received,
+// e.g. on network errors, cancelled contexts and ErrCircuitOpen.
+type RequestError struct {
+	Err error
+}
+
+func (e *RequestError) Error() string {
+	return fmt.Sprintf("elasticsearch: request failed: %s", e.Err)
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// ParseError is returned when the body of a response cannot be decoded.
+type ParseError struct {
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("elasticsearch: cannot parse the response: %s", e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// ESError is returned for the error responses of Elasticsearch, e.g.
+//
+//	var esErr *ESError
+//	if errors.As(err, &esErr) && esErr.Type == "version_conflict_engine_exception" { ... }
+//
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/common-options.html#common-options-error-options
+type ESError struct {
+	StatusCode int
+	// Type is the type of the error, e.g. "index_not_found_exception". Empty when the body has no error.
+	Type   string
+	Reason string
+	Index  string
+
+	RootCause []*ErrorCause
+	CausedBy  *ErrorCause
+	// FailedShards are the failures of the shards of a search failing on all of them.
+	FailedShards []*ShardFailure
+}
+
+// ErrorCause is an error reported by Elasticsearch, or one of its causes.
+type ErrorCause struct {
+	Type     string      `json:"type"`
+	Reason   string      `json:"reason"`
+	Index    string      `json:"index,omitempty"`
+	CausedBy *ErrorCause `json:"caused_by,omitempty"`
+}
+
+// ShardFailure is the failure of a search on one shard.
+type ShardFailure struct {
+	Shard  int         `json:"shard"`
+	Index  string      `json:"index"`
+	Node   string      `json:"node"`
+	Reason *ErrorCause `json:"reason"`
+}
+
+// PartialResultsError is returned by the searches failing on some shards when partial results are
+// not allowed. The search result holds the hits of the other shards.
+type PartialResultsError struct {
+	Shards *ShardsInfo
+}
+
+func (e *PartialResultsError) Error() string {
+	msg := fmt.Sprintf("elasticsearch: search failed on %d of %d shards", e.Shards.Failed, e.Shards.Total)
+	if len(e.Shards.Failures) > 0 && e.Shards.Failures[0].Reason != nil {
+		msg += ": " + e.Shards.Failures[0].Reason.Reason
+	}
+	return msg
+}
+
+// RefreshError is returned by Refresh and RefreshIndices when some shards failed to refresh.
+// The other shards are refreshed.
+type RefreshError struct {
+	Shards *ShardsInfo
+}
+
+func (e *RefreshError) Error() string {
+	msg := fmt.Sprintf("elasticsearch: refresh failed on %d of %d shards", e.Shards.Failed, e.Shards.Total)
+	if len(e.Shards.Failures) > 0 && e.Shards.Failures[0].Reason != nil {
+		msg += fmt.Sprintf(": [%s] %s", e.Shards.Failures[0].Index, e.Shards.Failures[0].Reason.Reason)
+	}
+	return msg
+}
+
+// Indices returns the indices of the failed shards, in order of their first failure.
+func (e *RefreshError) Indices() []string {
+	indices := []string{}
+	seen := map[string]bool{}
+	for _, f := range e.Shards.Failures {
+		if !seen[f.Index] {
+			seen[f.Index] = true
+			indices = append(indices, f.Index)
+		}
+	}
+	return indices
+}
+
+func newESError(statusCode int, body []byte) *ESError {
+	e := &ESError{StatusCode: statusCode}
+
+	var r struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if err := json.Unmarshal(body, &r); err != nil || len(r.Error) == 0 {
+		return e
+	}
+
+	// The error is an object, or a string for a few APIs.
+	var cause struct {
+		ErrorCause
+		RootCause    []*ErrorCause   `json:"root_cause"`
+		FailedShards []*ShardFailure `json:"failed_shards"`
+	}
+	if err := json.Unmarshal(r.Error, &cause); err != nil {
+		json.Unmarshal(r.Error, &e.Reason)
+		return e
+	}
+	e.Type, e.Reason, e.Index, e.CausedBy = cause.Type, cause.Reason, cause.Index, cause.CausedBy
+	e.RootCause, e.FailedShards = cause.RootCause, cause.FailedShards
+	return e
+}
+
+func (e *ESError) Error() string {
+	if e.Type == "" && e.Reason == "" {
+		return fmt.Sprintf("elasticsearch: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("elasticsearch: %d %s: [%s] %s", e.StatusCode, http.StatusText(e.StatusCode), e.Type, e.Reason)
+}
+
+// Is matches ErrBadRequest, ErrNotFound and ErrConflict.
+func (e *ESError) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	}
+	return false
+}
@@ -0,0 +1,203 @@
+package elasticsearch
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrors(t *testing.T) {
+	newTestElasticsearch := func(t *testing.T, handler http.HandlerFunc) Elasticsearch {
+		server, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/" {
+				w.Write([]byte(`{}`))
+				return
+			}
+			handler(w, r)
+		})
+		es, err := New(&Config{Address: []string{server.URL}, Logger: NopLogger()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return es
+	}
+
+	t.Run("Request error", func(t *testing.T) {
+		server, _ := newTestServer(t, nil)
+		es, err := New(&Config{Address: []string{server.URL}, Logger: NopLogger(), DisableRetry: true})
+		assert.NoError(t, err)
+		server.Close()
+
+		status, _, err := es.Count("x", `{}`)
+		var requestErr *RequestError
+		assert.True(t, errors.As(err, &requestErr))
+		assert.Equal(t, StatusRequestError, status)
+
+		status, err = es.GetSource("x", "1", &map[string]interface{}{})
+		assert.True(t, errors.As(err, &requestErr))
+		assert.Equal(t, StatusRequestError, status)
+	})
+
+	t.Run("Error response", func(t *testing.T) {
+		es := newTestElasticsearch(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"error": {"type": "security_exception", "reason": "unauthorized"}, "status": 403}`))
+		})
+
+		status, _, err := es.Count("x", `{}`)
+		var esErr *ESError
+		assert.True(t, errors.As(err, &esErr))
+		assert.Equal(t, http.StatusForbidden, esErr.StatusCode)
+		assert.Equal(t, "security_exception", esErr.Type)
+		assert.Equal(t, StatusError, status)
+
+		status, err = es.GetSource("x", "1", &map[string]interface{}{})
+		assert.True(t, errors.As(err, &esErr))
+		assert.Equal(t, http.StatusForbidden, esErr.StatusCode)
+		assert.Equal(t, StatusError, status)
+	})
+
+	t.Run("Parse error", func(t *testing.T) {
+		es := newTestElasticsearch(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"count": "many"`))
+		})
+
+		status, _, err := es.Count("x", `{}`)
+		var parseErr *ParseError
+		assert.True(t, errors.As(err, &parseErr))
+		assert.Equal(t, StatusParseError, status)
+
+		_, err = es.GetSource("x", "1", &map[string]interface{}{})
+		assert.True(t, errors.As(err, &parseErr))
+	})
+}
+
+func TestESError(t *testing.T) {
+	t.Run("Error object", func(t *testing.T) {
+		err := newESError(404, []byte(`{
+			"error": {
+				"root_cause": [{"type": "index_not_found_exception", "reason": "no such index [x]", "index": "x"}],
+				"type": "index_not_found_exception",
+				"reason": "no such index [x]",
+				"index": "x"
+			},
+			"status": 404
+		}`))
+
+		assert.Equal(t, "index_not_found_exception", err.Type)
+		assert.Equal(t, "no such index [x]", err.Reason)
+		assert.Equal(t, "x", err.Index)
+		assert.Equal(t, "elasticsearch: 404 Not Found: [index_not_found_exception] no such index [x]", err.Error())
+	})
+
+	t.Run("Error string", func(t *testing.T) {
+		err := newESError(405, []byte(`{"error": "Incorrect HTTP method", "status": 405}`))
+
+		assert.Empty(t, err.Type)
+		assert.Equal(t, "Incorrect HTTP method", err.Reason)
+	})
+
+	t.Run("No error", func(t *testing.T) {
+		err := newESError(404, []byte(`{"_index": "x", "_id": "1", "found": false}`))
+
+		assert.Equal(t, "elasticsearch: 404 Not Found", err.Error())
+	})
+
+	t.Run("Sentinels", func(t *testing.T) {
+		err := fmt.Errorf("updating: %w", newESError(409, nil))
+
+		assert.ErrorIs(t, err, ErrConflict)
+		assert.False(t, errors.Is(err, ErrNotFound))
+		assert.ErrorIs(t, newESError(404, nil), ErrNotFound)
+		assert.ErrorIs(t, newESError(400, nil), ErrBadRequest)
+	})
+}
+
+func TestShardFailures(t *testing.T) {
+	t.Run("All shards failed", func(t *testing.T) {
+		err := newESError(400, []byte(`{
+			"error": {
+				"root_cause": [{"type": "query_shard_exception", "reason": "failed to create query", "index": "x"}],
+				"type": "search_phase_execution_exception",
+				"reason": "all shards failed",
+				"phase": "query",
+				"grouped": true,
+				"failed_shards": [{
+					"shard": 0,
+					"index": "x",
+					"node": "n1",
+					"reason": {
+						"type": "query_shard_exception",
+						"reason": "failed to create query",
+						"index": "x",
+						"caused_by": {"type": "number_format_exception", "reason": "For input string: \"a\""}
+					}
+				}]
+			},
+			"status": 400
+		}`))
+
+		assert.Equal(t, "search_phase_execution_exception", err.Type)
+		assert.Equal(t, "query_shard_exception", err.RootCause[0].Type)
+		assert.Len(t, err.FailedShards, 1)
+		assert.Equal(t, "n1", err.FailedShards[0].Node)
+		assert.Equal(t, "number_format_exception", err.FailedShards[0].Reason.CausedBy.Type)
+	})
+
+	t.Run("Partial failure", func(t *testing.T) {
+		server, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{
+				"_shards": {
+					"total": 2, "successful": 1, "skipped": 0, "failed": 1,
+					"failures": [{"shard": 1, "index": "x", "node": "n2", "reason": {"type": "node_disconnected_exception", "reason": "disconnected"}}]
+				},
+				"hits": {"total": {"value": 0, "relation": "eq"}, "hits": []}
+			}`))
+		})
+		es, err := New(&Config{Address: []string{server.URL}, Logger: NopLogger()})
+		assert.NoError(t, err)
+
+		status, result, err := es.SearchWithResult("x", `{"query": {"match_all": {}}}`, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+		assert.Equal(t, 1, result.Shards.Failed)
+		assert.Equal(t, "node_disconnected_exception", result.Shards.Failures[0].Reason.Type)
+	})
+
+	t.Run("Partial results disallowed", func(t *testing.T) {
+		var query url.Values
+		server, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			query = r.URL.Query()
+			w.Write([]byte(`{
+				"_shards": {
+					"total": 2, "successful": 1, "skipped": 0, "failed": 1,
+					"failures": [{"shard": 1, "index": "p-x", "node": "n2", "reason": {"type": "node_disconnected_exception", "reason": "disconnected"}}]
+				},
+				"hits": {"total": {"value": 1, "relation": "eq"}, "hits": [{"_id": "1"}]}
+			}`))
+		})
+		es, err := New(&Config{Address: []string{server.URL}, IndexPrefix: "p-", DisallowPartialResults: true, Logger: NopLogger()})
+		assert.NoError(t, err)
+
+		status, result, err := es.SearchWithResult("x", "", nil)
+
+		var partialErr *PartialResultsError
+		assert.ErrorAs(t, err, &partialErr)
+		assert.EqualError(t, err, "elasticsearch: search failed on 1 of 2 shards: disconnected")
+		assert.Equal(t, StatusError, status)
+		assert.Equal(t, "false", query.Get("allow_partial_search_results"))
+		assert.Len(t, result.Hits, 1)
+		assert.Equal(t, "x", partialErr.Shards.Failures[0].Index)
+
+		status, _, _, err = es.Search("x", "", nil, AllowPartialResults(true))
+
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+		assert.Empty(t, query.Get("allow_partial_search_results"))
+	})
+}
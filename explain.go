@@ -0,0 +1,50 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+)
+
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/search-explain.html
+type Explanation struct {
+	Value       float64        `json:"value"`
+	Description string         `json:"description"`
+	Details     []*Explanation `json:"details"`
+}
+
+// String renders the explanation as an indented tree, one node per line.
+func (e *Explanation) String() string {
+	var b strings.Builder
+	e.write(&b, 0)
+	return b.String()
+}
+
+func (e *Explanation) write(b *strings.Builder, depth int) {
+	fmt.Fprintf(b, "%s%g %s\n", strings.Repeat("  ", depth), e.Value, e.Description)
+	for _, d := range e.Details {
+		d.write(b, depth+1)
+	}
+}
+
+// Explain reports whether the document id matches query and how its score is computed.
+func (es *_elasticsearch) Explain(index, id, query string) (StatusCode, bool, *Explanation, error) {
+	req := esapi.ExplainRequest{
+		Index:      es.indexName(index),
+		DocumentID: id,
+		Body:       strings.NewReader(query),
+	}
+
+	res, err := req.Do(es.ctx, es.client)
+
+	var r struct {
+		Matched     bool         `json:"matched"`
+		Explanation *Explanation `json:"explanation"`
+	}
+	if status, err := es.handleResponse("explain doc ID="+id, res, err, &r); err != nil {
+		return status, false, nil, err
+	}
+
+	return StatusSuccess, r.Matched, r.Explanation, nil
+}
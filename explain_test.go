@@ -0,0 +1,62 @@
+package elasticsearch
+
+import (
+	"testing"
+
+	"github.com/bxcodec/faker/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplanationString(t *testing.T) {
+	e := &Explanation{
+		Value:       2,
+		Description: "sum of:",
+		Details: []*Explanation{
+			{Value: 1.5, Description: "weight(s:foo)"},
+			{Value: 0.5, Description: "weight(s:bar)"},
+		},
+	}
+
+	assert.Equal(t, "2 sum of:\n  1.5 weight(s:foo)\n  0.5 weight(s:bar)\n", e.String())
+}
+
+func TestExplain(t *testing.T) {
+	es := newElasticsearch()
+
+	var data DocBody
+	faker.FakeData(&data)
+	data.Id = faker.UUIDDigit()
+	es.CreateDocument(&Document{
+		Index:   indexName,
+		ID:      data.Id,
+		Body:    data,
+		Refresh: RefreshTrue,
+	})
+
+	t.Run("Matched", func(t *testing.T) {
+		status, matched, explanation, err := es.Explain(indexName, data.Id, SearchBody(TermQuery("id", data.Id)))
+
+		require.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+		assert.True(t, matched)
+		require.NotNil(t, explanation)
+		assert.Greater(t, explanation.Value, 0.0)
+		assert.NotEmpty(t, explanation.Description)
+	})
+
+	t.Run("Not matched", func(t *testing.T) {
+		status, matched, _, err := es.Explain(indexName, data.Id, SearchBody(TermQuery("id", "not-exists")))
+
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+		assert.False(t, matched)
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		status, _, _, err := es.Explain(indexName, faker.UUIDDigit(), SearchBody(MatchAllQuery()))
+
+		assert.Error(t, err)
+		assert.Equal(t, StatusNotFoundError, status)
+	})
+}
@@ -0,0 +1,246 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+type exportOptions struct {
+	slices   int
+	pageSize int
+	gzip     bool
+	progress func(exported, total int)
+}
+
+// ExportOption changes how Export runs.
+type ExportOption func(o *exportOptions)
+
+// ExportSlices exports n disjoint slices of the index in parallel. Default: 1.
+func ExportSlices(n int) ExportOption {
+	return func(o *exportOptions) {
+		o.slices = n
+	}
+}
+
+// ExportPageSize is the number of documents fetched per request and slice. Default: 1000.
+func ExportPageSize(n int) ExportOption {
+	return func(o *exportOptions) {
+		o.pageSize = n
+	}
+}
+
+// ExportGzip compresses the output with gzip.
+func ExportGzip() ExportOption {
+	return func(o *exportOptions) {
+		o.gzip = true
+	}
+}
+
+// ExportProgress calls fn after each page with the number of documents exported so far
+// and the total number to export, which grows until every slice has answered once.
+// fn may be called concurrently with ExportSlices.
+func ExportProgress(fn func(exported, total int)) ExportOption {
+	return func(o *exportOptions) {
+		o.progress = fn
+	}
+}
+
+// exportScroll is how long a scroll is kept between the pages of Export.
+const exportScroll = 5 * time.Minute
+
+// Export writes the documents of index matching query to w as NDJSON, scrolling through the index.
+// Each line is the _source of a document with its ID in an "_id" field, so that BulkFromNDJSON and
+// LoadFixtures restore it with the same ID:
+//
+//	f, _ := os.Create("articles.ndjson.gz")
+//	defer f.Close()
+//	status, exported, err := es.Export(ctx, "articles", "", f, ExportSlices(4), ExportGzip())
+//
+// An empty query exports all documents. It returns the number of documents exported.
+// The order of the documents is unspecified.
+func (es *_elasticsearch) Export(ctx context.Context, index, query string, w io.Writer, opts ...ExportOption) (StatusCode, int, error) {
+	o := &exportOptions{slices: 1, pageSize: 1000}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.slices <= 0 || o.pageSize <= 0 {
+		return StatusInternalError, 0, errors.New("slices and page size must be positive")
+	}
+
+	out := w
+	var zw *gzip.Writer
+	if o.gzip {
+		zw = gzip.NewWriter(w)
+		out = zw
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	c := *es
+	c.ctx = ctx
+
+	var (
+		mu       sync.Mutex
+		exported int
+		total    int
+		status   = StatusSuccess
+		firstErr error
+	)
+	write := func(lines []byte, n int) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if _, err := out.Write(lines); err != nil {
+			return err
+		}
+		exported += n
+		if o.progress != nil {
+			o.progress(exported, total)
+		}
+		return nil
+	}
+	addTotal := func(n int) {
+		mu.Lock()
+		total += n
+		mu.Unlock()
+	}
+
+	var wg sync.WaitGroup
+	body, err := exportBody(query, o.pageSize)
+	if err != nil {
+		return StatusInternalError, 0, err
+	}
+	for i := 0; i < o.slices; i++ {
+		var opts []SearchOption
+		if o.slices > 1 {
+			opts = append(opts, Slice(i, o.slices))
+		}
+
+		wg.Add(1)
+		go func(opts []SearchOption) {
+			defer wg.Done()
+			if s, err := c.exportSlice(index, body, opts, addTotal, write); err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					status, firstErr = s, err
+					cancel()
+				}
+				mu.Unlock()
+			}
+		}(opts)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return status, exported, firstErr
+	}
+	if zw != nil {
+		if err := zw.Close(); err != nil {
+			return StatusInternalError, exported, err
+		}
+	}
+	return StatusSuccess, exported, nil
+}
+
+// exportBody adds the size and the sort by _doc to query.
+func exportBody(query string, size int) (string, error) {
+	body := map[string]interface{}{}
+	if strings.TrimSpace(query) != "" {
+		if err := json.Unmarshal([]byte(query), &body); err != nil {
+			return "", fmt.Errorf("invalid query: %w", err)
+		}
+	}
+	body["size"] = size
+	if _, ok := body["sort"]; !ok {
+		// The most efficient order for scrolling.
+		body["sort"] = []string{"_doc"}
+	}
+
+	b, err := json.Marshal(body)
+	return string(b), err
+}
+
+// exportSlice scrolls through the documents of body in the slice of opts, passing each page to write as NDJSON lines.
+func (es *_elasticsearch) exportSlice(index, body string, opts []SearchOption, addTotal func(n int), write func(lines []byte, n int) error) (StatusCode, error) {
+	res, err := es.client.Search(append(es.searchRequest(index, body, opts), es.client.Search.WithScroll(exportScroll))...)
+
+	var r struct {
+		searchResponse
+		ScrollID string `json:"_scroll_id"`
+	}
+	if status, err := es.handleResponse("export index="+index, res, err, &r); err != nil {
+		return status, err
+	}
+	if r.Hits != nil && r.Hits.Total != nil {
+		addTotal(r.Hits.Total.Value)
+	}
+	defer func() {
+		if r.ScrollID != "" {
+			es.clearScroll(r.ScrollID)
+		}
+	}()
+
+	for r.Hits != nil && len(r.Hits.Hits) > 0 {
+		lines, err := exportLines(r.Hits.Hits)
+		if err != nil {
+			return StatusParseError, &ParseError{Err: err}
+		}
+		if err := write(lines, len(r.Hits.Hits)); err != nil {
+			return StatusInternalError, err
+		}
+
+		res, err := es.client.Scroll(
+			es.client.Scroll.WithContext(es.ctx),
+			es.client.Scroll.WithScrollID(r.ScrollID),
+			es.client.Scroll.WithScroll(exportScroll),
+		)
+		r.Hits = nil
+		if status, err := es.handleResponse("export scroll index="+index, res, err, &r); err != nil {
+			return status, err
+		}
+	}
+	return StatusSuccess, nil
+}
+
+// exportLines returns the _source of hits with their IDs, one per line.
+func exportLines(hits []*HitData) ([]byte, error) {
+	var buf, source bytes.Buffer
+	for _, hit := range hits {
+		id, _ := json.Marshal(hit.Id)
+		buf.WriteString(`{"_id":`)
+		buf.Write(id)
+
+		// The _source is returned as indexed, possibly on several lines.
+		source.Reset()
+		if len(hit.Source) > 0 {
+			if err := json.Compact(&source, hit.Source); err != nil {
+				return nil, fmt.Errorf("_source of ID=%s: %w", hit.Id, err)
+			}
+		}
+		if fields := source.Bytes(); len(fields) > 2 {
+			buf.WriteByte(',')
+			buf.Write(fields[1:])
+		} else {
+			buf.WriteByte('}')
+		}
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+func (es *_elasticsearch) clearScroll(scrollID string) {
+	// Not bound to es.ctx, which may be cancelled.
+	res, err := es.client.ClearScroll(es.client.ClearScroll.WithScrollID(scrollID))
+	if err != nil {
+		es.logger.Warnf("Error clear scroll: %s", err)
+		return
+	}
+	res.Body.Close()
+}
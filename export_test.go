@@ -0,0 +1,133 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newScrollServer answers the scrolls of every slice with two pages of one document each.
+func newScrollServer(t *testing.T) (string, func() []string) {
+	var (
+		mu      sync.Mutex
+		pages   = map[string]int{}
+		cleared []string
+	)
+	server, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+
+		var scrollID string
+		switch {
+		case r.Method == http.MethodDelete:
+			cleared = append(cleared, r.URL.Path)
+			w.Write([]byte(`{}`))
+			return
+		case r.URL.Path == "/_search/scroll":
+			var body struct {
+				ScrollID string `json:"scroll_id"`
+			}
+			json.Unmarshal(b, &body)
+			scrollID = body.ScrollID
+			if scrollID == "" {
+				scrollID = r.URL.Query().Get("scroll_id")
+			}
+		case strings.HasSuffix(r.URL.Path, "/_search"):
+			var body struct {
+				Slice struct {
+					ID int `json:"id"`
+				} `json:"slice"`
+			}
+			json.Unmarshal(b, &body)
+			scrollID = fmt.Sprint("slice-", body.Slice.ID)
+		default:
+			w.Write([]byte(`{}`))
+			return
+		}
+
+		page := pages[scrollID]
+		pages[scrollID]++
+		hits := "[]"
+		if page < 2 {
+			hits = fmt.Sprintf(`[{"_index": "a", "_id": "%s-%d", "_source": {"page": %d,
+				"s": "x"}}]`, scrollID, page, page)
+		}
+		fmt.Fprintf(w, `{"_scroll_id": "%s", "hits": {"total": {"value": 2}, "hits": %s}}`, scrollID, hits)
+	})
+	return server.URL, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string{}, cleared...)
+	}
+}
+
+func TestExport(t *testing.T) {
+	t.Run("Slices", func(t *testing.T) {
+		url, cleared := newScrollServer(t)
+		es, err := New(&Config{Address: []string{url}})
+		assert.NoError(t, err)
+
+		var buf bytes.Buffer
+		var progress [][2]int
+		status, exported, err := es.Export(context.Background(), "a", SearchBody(MatchAllQuery()), &buf,
+			ExportSlices(2), ExportProgress(func(exported, total int) {
+				progress = append(progress, [2]int{exported, total})
+			}))
+
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+		assert.Equal(t, 4, exported)
+		assert.Len(t, progress, 4)
+		assert.Equal(t, [2]int{4, 4}, progress[3])
+		assert.Len(t, cleared(), 2)
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		sort.Strings(lines)
+		assert.Equal(t, []string{
+			`{"_id":"slice-0-0","page":0,"s":"x"}`,
+			`{"_id":"slice-0-1","page":1,"s":"x"}`,
+			`{"_id":"slice-1-0","page":0,"s":"x"}`,
+			`{"_id":"slice-1-1","page":1,"s":"x"}`,
+		}, lines)
+	})
+
+	t.Run("Gzip", func(t *testing.T) {
+		url, _ := newScrollServer(t)
+		es, err := New(&Config{Address: []string{url}})
+		assert.NoError(t, err)
+
+		var buf bytes.Buffer
+		_, exported, err := es.Export(context.Background(), "a", "", &buf, ExportGzip())
+		assert.NoError(t, err)
+		assert.Equal(t, 2, exported)
+
+		zr, err := gzip.NewReader(&buf)
+		assert.NoError(t, err)
+		b, _ := io.ReadAll(zr)
+		assert.Equal(t, 2, strings.Count(string(b), "\n"))
+	})
+}
+
+func TestExportBody(t *testing.T) {
+	body, err := exportBody(SearchBody(MatchAllQuery()), 100)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"query": {"match_all": {}}, "size": 100, "sort": ["_doc"]}`, body)
+
+	body, err = exportBody("", 10)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"size": 10, "sort": ["_doc"]}`, body)
+
+	_, err = exportBody("{", 10)
+	assert.Error(t, err)
+}
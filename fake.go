@@ -0,0 +1,599 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Fake is an in-memory Elasticsearch for the unit tests of code using this package.
+// It supports CreateIndexTemplate (ignored), CreateIndex (ignoring settings and mappings), CreateDocument, UpdateDocument,
+// RemoveDocument, GetSource, GetDocument, Bulk, LoadFixtures, Search, SearchWithResult and Count with the match_all,
+// term, terms and bool queries, and from and size. The writes check Document.IfSeqNo against the sequence number of
+// the last write of the document, and the primary term is always 1.
+// term and terms compare values exactly, as on keyword fields, and all hits score 1.
+// Documents are searchable as soon as they are written. Hits are ordered by index and ID.
+// SearchOptions and GetOptions are ignored: missing indices match nothing.
+// Info and Version report a 7.14.0 cluster named "fake", and ClusterHealth a green cluster and indices. Refresh, RefreshIndices and Close do nothing.
+// The other methods panic.
+type Fake struct {
+	Elasticsearch
+
+	mu      sync.RWMutex
+	indices map[string]map[string]json.RawMessage
+	nextID  int
+	// seqNos are the sequence numbers of the last writes of the documents, keyed by fakeKey.
+	seqNos map[string]int64
+	seqNo  int64
+}
+
+func NewFake() *Fake {
+	return &Fake{indices: map[string]map[string]json.RawMessage{}, seqNos: map[string]int64{}}
+}
+
+func fakeKey(index, id string) string {
+	return index + "\x00" + id
+}
+
+// checkSeqNo returns a conflict when doc was not last written at doc.IfSeqNo. f.mu must be locked.
+func (f *Fake) checkSeqNo(doc *Document) error {
+	if doc.IfSeqNo == nil && doc.IfPrimaryTerm == nil {
+		return nil
+	}
+	seqNo, ok := f.seqNos[fakeKey(doc.Index, doc.ID)]
+	if !ok || (doc.IfSeqNo != nil && *doc.IfSeqNo != seqNo) || (doc.IfPrimaryTerm != nil && *doc.IfPrimaryTerm != 1) {
+		return fakeError(http.StatusConflict, "version_conflict_engine_exception", "[%s]: version conflict", doc.ID)
+	}
+	return nil
+}
+
+// written records a write of doc. f.mu must be locked.
+func (f *Fake) written(doc *Document) {
+	f.seqNo++
+	f.seqNos[fakeKey(doc.Index, doc.ID)] = f.seqNo
+}
+
+func (f *Fake) WithContext(ctx context.Context) Elasticsearch {
+	return f
+}
+
+// WithIndexPrefix returns f: prefixes are ignored.
+func (f *Fake) WithIndexPrefix(prefix string) Elasticsearch {
+	return f
+}
+
+// WithPreference returns f: preferences are ignored.
+func (f *Fake) WithPreference(preference string) Elasticsearch {
+	return f
+}
+
+func (f *Fake) Refresh(index ...string) error {
+	return nil
+}
+
+func (f *Fake) RefreshIndices(index string, opts ...RefreshOption) (StatusCode, *ShardsInfo, error) {
+	return StatusSuccess, &ShardsInfo{Total: 1, Successful: 1}, nil
+}
+
+func (f *Fake) Ping() error {
+	return nil
+}
+
+// Info returns a 7.14.0 cluster named "fake".
+func (f *Fake) Info() (StatusCode, *ClusterInfo, error) {
+	return StatusSuccess, &ClusterInfo{
+		Name:        "fake",
+		ClusterName: "fake",
+		ClusterUUID: "fake",
+		Version:     ClusterVersion{Number: "7.14.0", BuildFlavor: "default"},
+		Tagline:     "You Know, for Search",
+	}, nil
+}
+
+func (f *Fake) Version() (StatusCode, *ServerVersion, error) {
+	return StatusSuccess, &ServerVersion{Number: "7.14.0", Major: 7, Minor: 14}, nil
+}
+
+// ClusterHealth returns a green cluster of a single node, with every index green.
+func (f *Fake) ClusterHealth() (StatusCode, *ClusterHealth, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	health := &ClusterHealth{
+		ClusterName:       "fake",
+		Status:            HealthGreen,
+		NumberOfNodes:     1,
+		NumberOfDataNodes: 1,
+		Indices:           map[string]*IndexHealth{},
+	}
+	for name := range f.indices {
+		health.Indices[name] = &IndexHealth{Status: HealthGreen, NumberOfShards: 1, ActivePrimaryShards: 1, ActiveShards: 1}
+		health.ActivePrimaryShards++
+		health.ActiveShards++
+	}
+	return StatusSuccess, health, nil
+}
+
+// Stats returns empty statistics.
+func (f *Fake) Stats() *ClientStats {
+	return &ClientStats{Responses: map[int]int{}, Nodes: []*NodeStats{}}
+}
+
+func (f *Fake) Close() error {
+	return nil
+}
+
+func (f *Fake) CreateIndexTemplate(name, templates string) (StatusCode, error) {
+	return StatusSuccess, nil
+}
+
+func (f *Fake) CreateIndex(index, body string) (StatusCode, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.indices[index]; ok {
+		return StatusBadRequestError, fakeError(http.StatusBadRequest, "resource_already_exists_exception", "index [%s] already exists", index)
+	}
+	f.indices[index] = map[string]json.RawMessage{}
+	return StatusSuccess, nil
+}
+
+func (f *Fake) CreateDocument(doc *Document) (StatusCode, error) {
+	if doc.Body == nil {
+		return StatusInternalError, errors.New("Required body")
+	}
+	body, err := marshalBody(jsonCodec{}, doc.Body)
+	if err != nil {
+		return StatusInternalError, err
+	}
+	if !json.Valid(body) {
+		return StatusBadRequestError, fakeError(http.StatusBadRequest, "mapper_parsing_exception", "failed to parse")
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if doc.ID == "" {
+		d := *doc
+		f.nextID++
+		d.ID = fmt.Sprintf("fake-%d", f.nextID)
+		doc = &d
+	}
+	if err := f.checkSeqNo(doc); err != nil {
+		return StatusError, err
+	}
+	if f.indices[doc.Index] == nil {
+		f.indices[doc.Index] = map[string]json.RawMessage{}
+	}
+	f.indices[doc.Index][doc.ID] = body
+	f.written(doc)
+	return StatusCreated, nil
+}
+
+func (f *Fake) UpdateDocument(doc *Document) (StatusCode, error) {
+	if doc.Body == nil {
+		return StatusInternalError, errors.New("Required body")
+	}
+	body, err := marshalBody(jsonCodec{}, doc.Body)
+	if err != nil {
+		return StatusInternalError, err
+	}
+	if !json.Valid(body) {
+		return StatusBadRequestError, fakeError(http.StatusBadRequest, "mapper_parsing_exception", "failed to parse")
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return StatusInternalError, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	source, ok := f.indices[doc.Index][doc.ID]
+	if !ok {
+		return StatusNotFoundError, fakeError(http.StatusNotFound, "document_missing_exception", "[%s]: document missing", doc.ID)
+	}
+	if err := f.checkSeqNo(doc); err != nil {
+		return StatusError, err
+	}
+	var merged map[string]interface{}
+	json.Unmarshal(source, &merged)
+	for k, v := range fields {
+		merged[k] = v
+	}
+	f.indices[doc.Index][doc.ID], _ = json.Marshal(merged)
+	f.written(doc)
+	return StatusSuccess, nil
+}
+
+func (f *Fake) RemoveDocument(doc *Document) (StatusCode, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.indices[doc.Index][doc.ID]; !ok {
+		return StatusNotFoundError, fakeError(http.StatusNotFound, "not_found", "[%s]: document missing", doc.ID)
+	}
+	if err := f.checkSeqNo(doc); err != nil {
+		return StatusError, err
+	}
+	delete(f.indices[doc.Index], doc.ID)
+	delete(f.seqNos, fakeKey(doc.Index, doc.ID))
+	return StatusSuccess, nil
+}
+
+func (f *Fake) GetSource(index string, id string, result any, opts ...GetOption) (StatusCode, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	source, ok := f.indices[index][id]
+	if !ok {
+		return StatusNotFoundError, fakeError(http.StatusNotFound, "not_found", "[%s]: document missing", id)
+	}
+	if err := json.Unmarshal(source, result); err != nil {
+		return StatusParseError, &ParseError{Err: err}
+	}
+	return StatusSuccess, nil
+}
+
+func (f *Fake) GetDocument(index, id string, result any, opts ...GetOption) (StatusCode, *DocumentMeta, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	source, ok := f.indices[index][id]
+	if !ok {
+		return StatusNotFoundError, nil, fakeError(http.StatusNotFound, "not_found", "[%s]: document missing", id)
+	}
+	meta := &DocumentMeta{Index: index, ID: id, SeqNo: f.seqNos[fakeKey(index, id)], PrimaryTerm: 1}
+	if result != nil {
+		if err := json.Unmarshal(source, result); err != nil {
+			return StatusParseError, meta, &ParseError{Err: err}
+		}
+	}
+	return StatusSuccess, meta, nil
+}
+
+func (f *Fake) Bulk(items []*BulkItem, refresh RefreshPolicy) (StatusCode, []*BulkItemResult, error) {
+	results := make([]*BulkItemResult, 0, len(items))
+	failed := []*BulkItemResult{}
+
+	for _, item := range items {
+		action := item.Action
+		if action == "" {
+			action = BulkIndex
+		}
+		doc := &Document{Index: item.Index, ID: item.ID, Body: item.Body, Routing: item.Routing}
+		result := &BulkItemResult{Action: action, Index: item.Index, ID: item.ID}
+
+		var err error
+		switch action {
+		case BulkIndex, BulkCreate:
+			if doc.ID == "" {
+				f.mu.Lock()
+				f.nextID++
+				doc.ID = fmt.Sprintf("fake-%d", f.nextID)
+				f.mu.Unlock()
+				result.ID = doc.ID
+			}
+			f.mu.RLock()
+			_, exists := f.indices[doc.Index][doc.ID]
+			f.mu.RUnlock()
+			if action == BulkCreate && exists {
+				err = fakeError(http.StatusConflict, "version_conflict_engine_exception", "[%s]: version conflict, document already exists", doc.ID)
+			} else if _, err = f.CreateDocument(doc); err == nil {
+				result.Status, result.Result = http.StatusCreated, "created"
+				if exists {
+					result.Status, result.Result = http.StatusOK, "updated"
+				}
+			}
+		case BulkUpdate:
+			if _, err = f.UpdateDocument(doc); err == nil {
+				result.Status, result.Result = http.StatusOK, "updated"
+			}
+		case BulkDelete:
+			if _, err = f.RemoveDocument(doc); err == nil {
+				result.Status, result.Result = http.StatusOK, "deleted"
+			}
+		}
+
+		var esErr *ESError
+		if errors.As(err, &esErr) {
+			result.Status = esErr.StatusCode
+			result.Error = &ErrorCause{Type: esErr.Type, Reason: esErr.Reason, Index: item.Index}
+			failed = append(failed, result)
+		} else if err != nil {
+			return StatusInternalError, results, err
+		}
+		results = append(results, result)
+	}
+
+	if len(failed) > 0 {
+		return StatusError, results, &BulkError{Failed: failed}
+	}
+	return StatusSuccess, results, nil
+}
+
+func (f *Fake) LoadFixtures(index string, r io.Reader) (StatusCode, error) {
+	items, err := parseFixtures(index, r)
+	if err != nil {
+		return StatusInternalError, err
+	}
+
+	status, _, err := f.Bulk(items, RefreshTrue)
+	return status, err
+}
+
+// BulkFromNDJSON indexes the documents of r in a single batch, ignoring opts.
+func (f *Fake) BulkFromNDJSON(ctx context.Context, index string, r io.Reader, opts ...BulkStreamOption) (StatusCode, int, error) {
+	items, err := parseFixtures(index, r)
+	if err != nil {
+		return StatusInternalError, 0, err
+	}
+	return f.bulkAll(items)
+}
+
+// BulkFromCSV indexes the rows of r in a single batch, ignoring opts.
+func (f *Fake) BulkFromCSV(ctx context.Context, index string, r io.Reader, mapping *CSVMapping, opts ...BulkStreamOption) (StatusCode, int, error) {
+	items := []*BulkItem{}
+	if err := readCSV(index, r, mapping, func(item *BulkItem) error {
+		items = append(items, item)
+		return nil
+	}); err != nil {
+		return StatusInternalError, 0, err
+	}
+	return f.bulkAll(items)
+}
+
+// bulkAll indexes items and returns the number indexed.
+func (f *Fake) bulkAll(items []*BulkItem) (StatusCode, int, error) {
+	status, results, err := f.Bulk(items, "")
+	var bulkErr *BulkError
+	if errors.As(err, &bulkErr) {
+		return status, len(results) - len(bulkErr.Failed), err
+	}
+	return status, len(results), err
+}
+
+// DeleteIndices deletes the indices matching index, ignoring opts.
+func (f *Fake) DeleteIndices(index string, opts ...DeleteIndicesOption) (StatusCode, error) {
+	return f.DeleteIndeces(index)
+}
+
+func (f *Fake) DeleteIndeces(index ...string) (StatusCode, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, name := range f.matchIndices(strings.Join(index, ",")) {
+		delete(f.indices, name)
+	}
+	return StatusSuccess, nil
+}
+
+func (f *Fake) Search(index string, query string, data interface{}, opts ...SearchOption) (StatusCode, []*HitData, int, error) {
+	status, result, err := f.SearchWithResult(index, query, data)
+	return status, result.Hits, result.Total, err
+}
+
+func (f *Fake) SearchWithResult(index string, query string, data interface{}, opts ...SearchOption) (StatusCode, *SearchResult, error) {
+	var req struct {
+		Query map[string]interface{} `json:"query"`
+		From  int                    `json:"from"`
+		Size  *int                   `json:"size"`
+	}
+	if err := fakeUnmarshalQuery(query, &req); err != nil {
+		return StatusBadRequestError, &SearchResult{Hits: []*HitData{}}, fakeError(http.StatusBadRequest, "parsing_exception", "%s", err)
+	}
+
+	hits, err := f.search(index, req.Query)
+	if err != nil {
+		return StatusBadRequestError, &SearchResult{Hits: []*HitData{}}, err
+	}
+
+	total := len(hits)
+	size := 10
+	if req.Size != nil {
+		size = *req.Size
+	}
+	from, to := req.From, req.From+size
+	if from > total {
+		from = total
+	}
+	if to > total {
+		to = total
+	}
+	hits = hits[from:to]
+
+	if data != nil {
+		if err := json.Unmarshal(joinSources(hits), data); err != nil {
+			return StatusParseError, &SearchResult{Hits: []*HitData{}}, &ParseError{Err: err}
+		}
+	}
+
+	return StatusSuccess, &SearchResult{Hits: hits, Total: total}, nil
+}
+
+func (f *Fake) Count(index string, query string, opts ...SearchOption) (StatusCode, int, error) {
+	var req struct {
+		Query map[string]interface{} `json:"query"`
+	}
+	if err := fakeUnmarshalQuery(query, &req); err != nil {
+		return StatusBadRequestError, 0, fakeError(http.StatusBadRequest, "parsing_exception", "%s", err)
+	}
+
+	hits, err := f.search(index, req.Query)
+	if err != nil {
+		return StatusBadRequestError, 0, err
+	}
+	return StatusSuccess, len(hits), nil
+}
+
+func (f *Fake) search(index string, query map[string]interface{}) ([]*HitData, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	hits := []*HitData{}
+	for _, name := range f.matchIndices(index) {
+		ids := make([]string, 0, len(f.indices[name]))
+		for id := range f.indices[name] {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+
+		for _, id := range ids {
+			var doc map[string]interface{}
+			json.Unmarshal(f.indices[name][id], &doc)
+
+			matched, err := fakeMatch(query, doc)
+			if err != nil {
+				return nil, err
+			}
+			if matched {
+				hits = append(hits, &HitData{Index: name, Id: id, Score: 1, Source: f.indices[name][id]})
+			}
+		}
+	}
+	return hits, nil
+}
+
+// matchIndices returns the sorted indices matching the comma-separated names or wildcards of index.
+func (f *Fake) matchIndices(index string) []string {
+	names := []string{}
+	for name := range f.indices {
+		for _, pattern := range strings.Split(index, ",") {
+			if pattern == "" || pattern == "_all" {
+				pattern = "*"
+			}
+			if ok, _ := path.Match(pattern, name); ok {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func fakeMatch(query map[string]interface{}, doc map[string]interface{}) (bool, error) {
+	if len(query) == 0 {
+		return true, nil
+	}
+	if len(query) > 1 {
+		return false, fakeError(http.StatusBadRequest, "parsing_exception", "query has more than one clause")
+	}
+
+	for kind, clause := range query {
+		params, _ := clause.(map[string]interface{})
+		switch kind {
+		case "match_all":
+			return true, nil
+
+		case "term":
+			for field, value := range params {
+				if v, ok := value.(map[string]interface{}); ok {
+					value = v["value"]
+				}
+				return fakeFieldEquals(doc, field, value), nil
+			}
+			return false, nil
+
+		case "terms":
+			for field, values := range params {
+				for _, value := range toSlice(values) {
+					if fakeFieldEquals(doc, field, value) {
+						return true, nil
+					}
+				}
+			}
+			return false, nil
+
+		case "bool":
+			return fakeMatchBool(params, doc)
+		}
+		return false, fakeError(http.StatusBadRequest, "parsing_exception", "unsupported query [%s] in the fake", kind)
+	}
+	return false, nil
+}
+
+func fakeMatchBool(params map[string]interface{}, doc map[string]interface{}) (bool, error) {
+	clauses := func(name string) []map[string]interface{} {
+		qs := []map[string]interface{}{}
+		for _, q := range toSlice(params[name]) {
+			if m, ok := q.(map[string]interface{}); ok {
+				qs = append(qs, m)
+			}
+		}
+		return qs
+	}
+
+	for _, name := range []string{"must", "filter"} {
+		for _, q := range clauses(name) {
+			if ok, err := fakeMatch(q, doc); err != nil || !ok {
+				return false, err
+			}
+		}
+	}
+	for _, q := range clauses("must_not") {
+		if ok, err := fakeMatch(q, doc); err != nil || ok {
+			return false, err
+		}
+	}
+
+	should := clauses("should")
+	if len(should) == 0 || len(clauses("must"))+len(clauses("filter")) > 0 {
+		return true, nil
+	}
+	for _, q := range should {
+		if ok, err := fakeMatch(q, doc); err != nil || ok {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+// fakeFieldEquals compares the value of field (dotted for objects) of doc with value,
+// any element matching for arrays.
+func fakeFieldEquals(doc map[string]interface{}, field string, value interface{}) bool {
+	var v interface{} = doc
+	for _, name := range strings.Split(field, ".") {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		v = m[name]
+	}
+
+	for _, element := range toSlice(v) {
+		if fmt.Sprint(element) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func toSlice(v interface{}) []interface{} {
+	if s, ok := v.([]interface{}); ok {
+		return s
+	}
+	if v == nil {
+		return nil
+	}
+	return []interface{}{v}
+}
+
+func fakeError(statusCode int, errorType, format string, args ...interface{}) *ESError {
+	return &ESError{StatusCode: statusCode, Type: errorType, Reason: fmt.Sprintf(format, args...)}
+}
+
+// fakeUnmarshalQuery unmarshals a search body, leaving req empty for an empty query.
+func fakeUnmarshalQuery(query string, req interface{}) error {
+	if query == "" {
+		return nil
+	}
+	return json.Unmarshal([]byte(query), req)
+}
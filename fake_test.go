@@ -0,0 +1,156 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeDoc struct {
+	Id   string   `json:"id"`
+	Kind string   `json:"kind"`
+	N    int      `json:"n"`
+	Tags []string `json:"tags"`
+}
+
+func TestFake(t *testing.T) {
+	var es Elasticsearch = NewFake()
+
+	for i := 0; i < 5; i++ {
+		kind := "odd"
+		if i%2 == 0 {
+			kind = "even"
+		}
+		status, err := es.CreateDocument(&Document{
+			Index: "fake",
+			ID:    fmt.Sprint(i),
+			Body:  fakeDoc{Id: fmt.Sprint(i), Kind: kind, N: i, Tags: []string{"all", kind}},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, StatusCreated, status)
+	}
+
+	t.Run("GetSource", func(t *testing.T) {
+		var doc fakeDoc
+		status, err := es.GetSource("fake", "1", &doc)
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+		assert.Equal(t, "odd", doc.Kind)
+
+		status, err = es.GetSource("fake", "9", &doc)
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.Equal(t, StatusNotFoundError, status)
+	})
+
+	t.Run("Search", func(t *testing.T) {
+		var docs []fakeDoc
+		status, hits, total, err := es.Search("fake", SearchBody(TermQuery("kind", "even")), &docs)
+
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+		assert.Equal(t, 3, total)
+		assert.Equal(t, "0", hits[0].Id)
+		assert.Equal(t, "fake", hits[0].Index)
+		assert.Equal(t, []string{"0", "2", "4"}, []string{docs[0].Id, docs[1].Id, docs[2].Id})
+	})
+
+	t.Run("Size and from", func(t *testing.T) {
+		_, hits, total, err := es.Search("fake*", `{"query": {"match_all": {}}, "from": 1, "size": 2}`, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 5, total)
+		assert.Len(t, hits, 2)
+		assert.Equal(t, "1", hits[0].Id)
+	})
+
+	t.Run("Count", func(t *testing.T) {
+		cases := map[string]int{
+			SearchBody(MatchAllQuery()):                5,
+			SearchBody(TermsQuery("n", 1, 2, 7)):       2,
+			SearchBody(TermQuery("tags", "odd")):       2,
+			`{"query": {"term": {"n": {"value": 3}}}}`: 1,
+			`{"query": {"bool": {"filter": [{"term": {"kind": "even"}}], "must_not": {"term": {"n": 0}}}}}`: 2,
+			`{"query": {"bool": {"should": [{"term": {"n": 0}}, {"term": {"n": 1}}]}}}`:                     2,
+		}
+		for query, want := range cases {
+			status, count, err := es.Count("fake", query)
+			assert.NoError(t, err, query)
+			assert.Equal(t, StatusSuccess, status)
+			assert.Equal(t, want, count, query)
+		}
+	})
+
+	t.Run("Unsupported query", func(t *testing.T) {
+		status, _, err := es.Count("fake", SearchBody(MatchQuery("kind", "odd")))
+
+		assert.ErrorIs(t, err, ErrBadRequest)
+		assert.Equal(t, StatusBadRequestError, status)
+	})
+
+	t.Run("UpdateDocument", func(t *testing.T) {
+		status, err := es.UpdateDocument(&Document{Index: "fake", ID: "1", Body: map[string]interface{}{"kind": "updated"}})
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+
+		var doc fakeDoc
+		es.GetSource("fake", "1", &doc)
+		assert.Equal(t, "updated", doc.Kind)
+		assert.Equal(t, 1, doc.N)
+
+		_, err = es.UpdateDocument(&Document{Index: "fake", ID: "9", Body: map[string]interface{}{}})
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("RemoveDocument", func(t *testing.T) {
+		status, err := es.RemoveDocument(&Document{Index: "fake", ID: "0"})
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+
+		status, err = es.RemoveDocument(&Document{Index: "fake", ID: "0"})
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.Equal(t, StatusNotFoundError, status)
+	})
+
+	t.Run("CreateDocument without ID", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			status, err := es.CreateDocument(&Document{Index: "fake-auto", Body: fakeDoc{Kind: "auto"}})
+			assert.NoError(t, err)
+			assert.Equal(t, StatusCreated, status)
+		}
+
+		_, count, err := es.Count("fake-auto", SearchBody(MatchAllQuery()))
+		assert.NoError(t, err)
+		assert.Equal(t, 2, count)
+	})
+
+	t.Run("DeleteIndeces", func(t *testing.T) {
+		_, err := es.DeleteIndeces("fake")
+		assert.NoError(t, err)
+
+		_, count, _ := es.Count("fake", SearchBody(MatchAllQuery()))
+		assert.Zero(t, count)
+	})
+}
+
+func TestInterfaces(t *testing.T) {
+	fake := NewFake()
+	var (
+		writer DocumentWriter = fake
+		reader DocumentReader = fake
+		admin  IndexAdmin     = fake
+	)
+
+	_, err := admin.CreateIndex("fake", "")
+	assert.NoError(t, err)
+	_, err = writer.CreateDocument(&Document{Index: "fake", ID: "1", Body: fakeDoc{Id: "1"}})
+	assert.NoError(t, err)
+	_, count, err := reader.Count("fake", "")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	var cluster ClusterAdmin = fake
+	_, health, err := cluster.ClusterHealth()
+	assert.NoError(t, err)
+	assert.Equal(t, HealthGreen, health.Indices["fake"].Status)
+}
@@ -0,0 +1,83 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// parseFixtures reads documents from a JSON array, or from JSON objects one after another
+// such as NDJSON. The "_id" field of a document is used as its ID and removed from it.
+func parseFixtures(index string, r io.Reader) ([]*BulkItem, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimSpace(data)
+
+	docs := []json.RawMessage{}
+	if bytes.HasPrefix(data, []byte("[")) {
+		if err := json.Unmarshal(data, &docs); err != nil {
+			return nil, fmt.Errorf("invalid fixtures: %w", err)
+		}
+	} else {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		for {
+			var doc json.RawMessage
+			if err := dec.Decode(&doc); err == io.EOF {
+				break
+			} else if err != nil {
+				return nil, fmt.Errorf("invalid fixtures: document %d: %w", len(docs)+1, err)
+			}
+			docs = append(docs, doc)
+		}
+	}
+
+	items := make([]*BulkItem, 0, len(docs))
+	for i, doc := range docs {
+		item, err := fixtureItem(index, doc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fixtures: document %d %w", i+1, err)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// fixtureItem returns the item indexing doc into index, with the "_id" field of doc as its ID.
+func fixtureItem(index string, doc json.RawMessage) (*BulkItem, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(doc, &fields); err != nil {
+		return nil, errors.New("is not an object")
+	}
+
+	item := &BulkItem{Index: index, Body: doc}
+	if id, ok := fields["_id"]; ok {
+		if err := json.Unmarshal(id, &item.ID); err != nil {
+			return nil, errors.New("has an _id which is not a string")
+		}
+		delete(fields, "_id")
+		item.Body = fields
+	}
+	return item, nil
+}
+
+// LoadFixtures indexes the documents of r into index and refreshes it, e.g. to seed a test index:
+//
+//	f, _ := os.Open("testdata/articles.ndjson")
+//	defer f.Close()
+//	es.LoadFixtures("articles", f)
+//
+// r holds a JSON array of documents, or documents one after another such as NDJSON.
+// A document with an "_id" field is indexed with that ID.
+func (es *_elasticsearch) LoadFixtures(index string, r io.Reader) (StatusCode, error) {
+	items, err := parseFixtures(index, r)
+	if err != nil {
+		return StatusInternalError, err
+	}
+
+	status, _, err := es.Bulk(items, RefreshTrue)
+	return status, err
+}
@@ -0,0 +1,73 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFixtures(t *testing.T) {
+	t.Run("NDJSON", func(t *testing.T) {
+		items, err := parseFixtures("x", strings.NewReader(`{"_id": "1", "s": "a"}
+{"s": "b"}
+`))
+
+		assert.NoError(t, err)
+		assert.Len(t, items, 2)
+		assert.Equal(t, "1", items[0].ID)
+		body, _ := json.Marshal(items[0].Body)
+		assert.JSONEq(t, `{"s": "a"}`, string(body))
+		assert.Empty(t, items[1].ID)
+		assert.Equal(t, "x", items[1].Index)
+	})
+
+	t.Run("JSON array", func(t *testing.T) {
+		items, err := parseFixtures("x", strings.NewReader(`[
+			{"_id": "1", "s": "a"},
+			{"_id": "2", "s": "b"}
+		]`))
+
+		assert.NoError(t, err)
+		assert.Len(t, items, 2)
+		assert.Equal(t, "2", items[1].ID)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		for _, fixtures := range []string{`{"s": `, `[1, 2]`, `{"_id": 1}`} {
+			_, err := parseFixtures("x", strings.NewReader(fixtures))
+			assert.Error(t, err, fixtures)
+		}
+	})
+}
+
+func TestLoadFixtures(t *testing.T) {
+	fixtures := `{"_id": "1", "id": "1", "s": "fixture"}
+{"_id": "2", "id": "2", "s": "fixture"}
+{"id": "3", "s": "fixture"}`
+
+	t.Run("Fake", func(t *testing.T) {
+		es := NewFake()
+
+		status, err := es.LoadFixtures("fixtures", strings.NewReader(fixtures))
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+
+		_, count, _ := es.Count("fixtures", SearchBody(TermQuery("s", "fixture")))
+		assert.Equal(t, 3, count)
+	})
+
+	t.Run("Elasticsearch", func(t *testing.T) {
+		es := newElasticsearch()
+		defer es.DeleteIndeces(indexName)
+
+		status, err := es.LoadFixtures(indexName, strings.NewReader(fixtures))
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+
+		var doc DocBody
+		es.GetSource(indexName, "2", &doc)
+		assert.Equal(t, "2", doc.Id)
+	})
+}
@@ -0,0 +1,74 @@
+package elasticsearch
+
+// GeoPoint is the value of a geo_point field.
+// It is encoded as {"lat": .., "lon": ..}, which avoids the [lon, lat] order of the array form.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/geo-point.html
+type GeoPoint struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+// Valid reports whether the point is within the range of latitudes and longitudes.
+func (p GeoPoint) Valid() bool {
+	return p.Lat >= -90 && p.Lat <= 90 && p.Lon >= -180 && p.Lon <= 180
+}
+
+// GeoPointMapping returns the mapping of a geo_point field, to be placed under "properties".
+func GeoPointMapping() map[string]interface{} {
+	return map[string]interface{}{"type": "geo_point"}
+}
+
+// GeoShape is a GeoJSON geometry such as
+// GeoShape{Type: "envelope", Coordinates: [][]float64{{lon1, lat1}, {lon2, lat2}}}.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/geo-shape.html
+type GeoShape struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates"`
+}
+
+// GeoDistanceQuery matches documents within distance (e.g. "10km") of point.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/query-dsl-geo-distance-query.html
+func GeoDistanceQuery(field string, point GeoPoint, distance string) Query {
+	return Query{"geo_distance": map[string]interface{}{
+		"distance": distance,
+		field:      point,
+	}}
+}
+
+// GeoBoundingBoxQuery matches documents whose point lies in the box.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/query-dsl-geo-bounding-box-query.html
+func GeoBoundingBoxQuery(field string, topLeft, bottomRight GeoPoint) Query {
+	return Query{"geo_bounding_box": map[string]interface{}{
+		field: map[string]interface{}{
+			"top_left":     topLeft,
+			"bottom_right": bottomRight,
+		},
+	}}
+}
+
+// GeoShapeQuery matches documents whose shape has relation (intersects, disjoint, within, contains)
+// with shape. An empty relation uses intersects.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/query-dsl-geo-shape-query.html
+func GeoShapeQuery(field string, shape GeoShape, relation string) Query {
+	params := map[string]interface{}{
+		"shape": shape,
+	}
+	if relation != "" {
+		params["relation"] = relation
+	}
+	return Query{"geo_shape": map[string]interface{}{field: params}}
+}
+
+// GeoDistanceSort sorts by distance from point in order ("asc" or "desc").
+// The sort values of the hits are distances in unit (e.g. "km"); an empty unit uses meters.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/sort-search-results.html#geo-sorting
+func GeoDistanceSort(field string, point GeoPoint, order, unit string) Sort {
+	params := map[string]interface{}{
+		field:   point,
+		"order": order,
+	}
+	if unit != "" {
+		params["unit"] = unit
+	}
+	return Sort{"_geo_distance": params}
+}
@@ -0,0 +1,104 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/bxcodec/faker/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+const geoIndexName = "test-es-geo"
+
+type PlaceDocBody struct {
+	Id       string   `json:"id"`
+	Location GeoPoint `json:"location"`
+}
+
+func TestGeoQueries(t *testing.T) {
+	tokyo := GeoPoint{Lat: 35.681, Lon: 139.767}
+
+	t.Run("Distance", func(t *testing.T) {
+		assertQueryJSON(t, `{
+			"geo_distance": {"distance": "10km", "location": {"lat": 35.681, "lon": 139.767}}
+		}`, GeoDistanceQuery("location", tokyo, "10km"))
+	})
+
+	t.Run("BoundingBox", func(t *testing.T) {
+		assertQueryJSON(t, `{
+			"geo_bounding_box": {"location": {
+				"top_left": {"lat": 36, "lon": 139},
+				"bottom_right": {"lat": 35, "lon": 140}
+			}}
+		}`, GeoBoundingBoxQuery("location", GeoPoint{Lat: 36, Lon: 139}, GeoPoint{Lat: 35, Lon: 140}))
+	})
+
+	t.Run("Shape", func(t *testing.T) {
+		shape := GeoShape{Type: "envelope", Coordinates: [][]float64{{139, 36}, {140, 35}}}
+
+		assertQueryJSON(t, `{
+			"geo_shape": {"area": {
+				"shape": {"type": "envelope", "coordinates": [[139, 36], [140, 35]]},
+				"relation": "within"
+			}}
+		}`, GeoShapeQuery("area", shape, "within"))
+	})
+
+	t.Run("Sort", func(t *testing.T) {
+		assert.JSONEq(t, `{
+			"query": {"match_all": {}},
+			"sort": [{"_geo_distance": {"location": {"lat": 35.681, "lon": 139.767}, "order": "asc", "unit": "km"}}]
+		}`, SearchBody(MatchAllQuery(), GeoDistanceSort("location", tokyo, "asc", "km")))
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		assert.True(t, tokyo.Valid())
+		assert.False(t, GeoPoint{Lat: 139.767, Lon: 35.681}.Valid())
+	})
+}
+
+func TestSearchGeoDistance(t *testing.T) {
+	es := newElasticsearch()
+
+	templates, _ := json.Marshal(map[string]interface{}{
+		"index_patterns": []string{geoIndexName},
+		"template": map[string]interface{}{
+			"mappings": map[string]interface{}{
+				"properties": map[string]interface{}{
+					"location": GeoPointMapping(),
+				},
+			},
+		},
+	})
+	if _, err := es.CreateIndexTemplate(geoIndexName, string(templates)); err != nil {
+		t.FailNow()
+	}
+	defer es.DeleteIndeces(geoIndexName)
+
+	places := []PlaceDocBody{
+		{Id: faker.UUIDDigit(), Location: GeoPoint{Lat: 35.690, Lon: 139.700}}, // Shinjuku
+		{Id: faker.UUIDDigit(), Location: GeoPoint{Lat: 35.681, Lon: 139.767}}, // Tokyo
+		{Id: faker.UUIDDigit(), Location: GeoPoint{Lat: 34.702, Lon: 135.496}}, // Osaka
+	}
+	for _, p := range places {
+		es.CreateDocument(&Document{
+			Index: geoIndexName,
+			ID:    p.Id,
+			Body:  p,
+		})
+	}
+	es.Refresh(geoIndexName)
+
+	var list []PlaceDocBody
+	status, hits, total, err := es.Search(geoIndexName, SearchBody(
+		GeoDistanceQuery("location", places[1].Location, "50km"),
+		GeoDistanceSort("location", places[1].Location, "asc", "km"),
+	), &list)
+
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSuccess, status)
+	assert.Equal(t, 2, total)
+	assert.Equal(t, places[1].Id, list[0].Id)
+	assert.Equal(t, places[0].Id, list[1].Id)
+	assert.Less(t, hits[1].Sort[0].(float64), 10.0)
+}
@@ -2,34 +2,189 @@ package elasticsearch
 
 import (
 	"encoding/json"
-	"io"
-	"log"
+	"fmt"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v7/esapi"
 )
 
-func (es *_elasticsearch) GetSource(index string, id string, result any) (int, error) {
-	res, err := es.client.GetSource(index, id)
-	defer res.Body.Close()
+// GetOption is an option of GetSource and GetDocument.
+type GetOption func(o *getOptions)
+
+type getOptions struct {
+	realtime   *bool
+	refresh    bool
+	routing    string
+	preference *string
+	includes   []string
+	excludes   []string
+}
+
+func newGetOptions(opts []GetOption) *getOptions {
+	o := &getOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
 
-	if err != nil {
-		log.Fatalf("Error getting response: %s", err)
-		return res.StatusCode, err
+// GetRealtime set to false reads the document as of the last refresh instead of its last write.
+func GetRealtime(realtime bool) GetOption {
+	return func(o *getOptions) {
+		o.realtime = &realtime
 	}
+}
 
-	if res.StatusCode == 404 {
-		return res.StatusCode, nil
+// GetRefresh refreshes the shard of the document before reading it.
+func GetRefresh() GetOption {
+	return func(o *getOptions) {
+		o.refresh = true
 	}
+}
 
-	body, err := io.ReadAll(res.Body)
-	if err != nil {
-		log.Fatalf("Error reading response: %s", err)
-		return res.StatusCode, err
+// GetRouting is the routing of the document, required when it was written with one.
+func GetRouting(routing string) GetOption {
+	return func(o *getOptions) {
+		o.routing = routing
 	}
+}
 
-	err = json.Unmarshal(body, result)
-	if err != nil {
-		log.Fatalf("Error parsing response: %s", err)
-		return res.StatusCode, err
+// GetPreference chooses the shard copy read, overriding WithPreference.
+func GetPreference(preference string) GetOption {
+	return func(o *getOptions) {
+		o.preference = &preference
 	}
+}
 
-	return res.StatusCode, nil
+// GetSourceIncludes reads only the fields of the _source matching fields, e.g. "user.*".
+func GetSourceIncludes(fields ...string) GetOption {
+	return func(o *getOptions) {
+		o.includes = append(o.includes, fields...)
+	}
+}
+
+// GetSourceExcludes leaves out the fields of the _source matching fields.
+func GetSourceExcludes(fields ...string) GetOption {
+	return func(o *getOptions) {
+		o.excludes = append(o.excludes, fields...)
+	}
+}
+
+// withSourceField keeps field in the _source read despite GetSourceIncludes and GetSourceExcludes.
+func withSourceField(field string) GetOption {
+	return func(o *getOptions) {
+		if len(o.includes) > 0 {
+			o.includes = append(o.includes, field)
+		}
+		excludes := o.excludes[:0:0]
+		for _, f := range o.excludes {
+			if f != field {
+				excludes = append(excludes, f)
+			}
+		}
+		o.excludes = excludes
+	}
+}
+
+func (o *getOptions) refreshParam() *bool {
+	if !o.refresh {
+		return nil
+	}
+	refresh := true
+	return &refresh
+}
+
+func (o *getOptions) preferenceOr(preference string) string {
+	if o.preference != nil {
+		return *o.preference
+	}
+	return preference
+}
+
+// key returns the options changing the document read, for Cache.
+func (o *getOptions) key() string {
+	var key string
+	if o.realtime != nil {
+		key += fmt.Sprintf("\x00realtime=%t", *o.realtime)
+	}
+	if o.routing != "" {
+		key += "\x00routing=" + o.routing
+	}
+	if o.preference != nil {
+		key += "\x00preference=" + *o.preference
+	}
+	if len(o.includes) > 0 {
+		key += "\x00includes=" + strings.Join(o.includes, ",")
+	}
+	if len(o.excludes) > 0 {
+		key += "\x00excludes=" + strings.Join(o.excludes, ",")
+	}
+	return key
+}
+
+// GetSource decodes the _source of the document into result.
+// A missing document returns StatusNotFoundError and an error matching ErrNotFound.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/docs-get.html
+func (es *_elasticsearch) GetSource(index string, id string, result any, opts ...GetOption) (StatusCode, error) {
+	o := newGetOptions(opts)
+	req := esapi.GetSourceRequest{
+		Index:          es.indexName(index),
+		DocumentID:     id,
+		Preference:     o.preferenceOr(es.preference),
+		Realtime:       o.realtime,
+		Refresh:        o.refreshParam(),
+		Routing:        o.routing,
+		SourceIncludes: o.includes,
+		SourceExcludes: o.excludes,
+	}
+
+	res, err := req.Do(es.ctx, es.client)
+	return es.handleResponse("get source doc ID="+id, res, err, result)
+}
+
+// DocumentMeta is the metadata of a document returned by GetDocument.
+type DocumentMeta struct {
+	Index   string `json:"_index"`
+	ID      string `json:"_id"`
+	Version int64  `json:"_version"`
+	// SeqNo and PrimaryTerm identify the last write of the document, to be given to the
+	// IfSeqNo and IfPrimaryTerm of the next one.
+	SeqNo       int64  `json:"_seq_no"`
+	PrimaryTerm int64  `json:"_primary_term"`
+	Routing     string `json:"_routing"`
+}
+
+// GetDocument decodes the _source of the document into result and returns its metadata.
+// A missing document returns ErrNotFound.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/docs-get.html
+func (es *_elasticsearch) GetDocument(index, id string, result any, opts ...GetOption) (StatusCode, *DocumentMeta, error) {
+	o := newGetOptions(opts)
+	req := esapi.GetRequest{
+		Index:          es.indexName(index),
+		DocumentID:     id,
+		Preference:     o.preferenceOr(es.preference),
+		Realtime:       o.realtime,
+		Refresh:        o.refreshParam(),
+		Routing:        o.routing,
+		SourceIncludes: o.includes,
+		SourceExcludes: o.excludes,
+	}
+
+	res, err := req.Do(es.ctx, es.client)
+
+	var r struct {
+		DocumentMeta
+		Source json.RawMessage `json:"_source"`
+	}
+	if status, err := es.handleResponse("get doc ID="+id, res, err, &r); err != nil {
+		return status, nil, err
+	}
+
+	r.Index = es.trimIndexPrefix(r.Index)
+	if result != nil && len(r.Source) > 0 {
+		if err := es.codec.Unmarshal(r.Source, result); err != nil {
+			return StatusParseError, &r.DocumentMeta, &ParseError{Err: err}
+		}
+	}
+	return StatusSuccess, &r.DocumentMeta, nil
 }
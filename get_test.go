@@ -1,6 +1,7 @@
 package elasticsearch
 
 import (
+	"net/http"
 	"testing"
 
 	"github.com/bxcodec/faker/v3"
@@ -27,7 +28,7 @@ func TestGetSource(t *testing.T) {
 		var res DocBody
 		status, err := es.GetSource(indexName, id, &res)
 		assert.NoError(t, err)
-		assert.Equal(t, 200, status)
+		assert.Equal(t, StatusSuccess, status)
 		assert.Equal(t, data.Id, res.Id)
 		assert.Equal(t, data.I, res.I)
 		assert.Equal(t, data.S, res.S)
@@ -37,7 +38,76 @@ func TestGetSource(t *testing.T) {
 	t.Run("Not Found", func(t *testing.T) {
 		var res DocBody
 		status, err := es.GetSource(indexName, faker.UUIDDigit(), &res)
-		assert.NoError(t, err)
-		assert.Equal(t, 404, status)
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.Equal(t, StatusNotFoundError, status)
+	})
+}
+
+func TestGetSourceOptions(t *testing.T) {
+	server, requests := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/a/_doc/1/_source", "/a/_source/1":
+			w.Write([]byte(`{"id": "1"}`))
+		default:
+			w.Write([]byte(`{}`))
+		}
+	})
+	es, err := New(&Config{Address: []string{server.URL}, Logger: NopLogger()})
+	assert.NoError(t, err)
+
+	var doc DocBody
+	status, err := es.WithPreference("_local").GetSource("a", "1", &doc,
+		GetRealtime(false), GetRefresh(), GetRouting("r"), GetSourceIncludes("id", "s"), GetSourceExcludes("b"))
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSuccess, status)
+	assert.Equal(t, "1", doc.Id)
+
+	reqs := requests()
+	q := reqs[len(reqs)-1].URL.Query()
+	assert.Equal(t, "_local", q.Get("preference"))
+	assert.Equal(t, "false", q.Get("realtime"))
+	assert.Equal(t, "true", q.Get("refresh"))
+	assert.Equal(t, "r", q.Get("routing"))
+	assert.Equal(t, "id,s", q.Get("_source_includes"))
+	assert.Equal(t, "b", q.Get("_source_excludes"))
+
+	es.WithPreference("_local").GetSource("a", "1", &doc, GetPreference("_primary"))
+	reqs = requests()
+	assert.Equal(t, "_primary", reqs[len(reqs)-1].URL.Query().Get("preference"))
+}
+
+func TestGetDocument(t *testing.T) {
+	server, requests := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/p-a/_doc/1":
+			w.Write([]byte(`{"_index": "p-a", "_id": "1", "_version": 3, "_seq_no": 7, "_primary_term": 2, "found": true, "_source": {"id": "1"}}`))
+		case "/p-a/_doc/2":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"_index": "p-a", "_id": "2", "found": false}`))
+		default:
+			w.Write([]byte(`{}`))
+		}
+	})
+	es, err := New(&Config{Address: []string{server.URL}, IndexPrefix: "p-", Logger: NopLogger()})
+	assert.NoError(t, err)
+
+	var doc DocBody
+	status, meta, err := es.GetDocument("a", "1", &doc)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSuccess, status)
+	assert.Equal(t, &DocumentMeta{Index: "a", ID: "1", Version: 3, SeqNo: 7, PrimaryTerm: 2}, meta)
+	assert.Equal(t, "1", doc.Id)
+
+	status, _, err = es.GetDocument("a", "2", &doc)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, StatusNotFoundError, status)
+
+	t.Run("IfSeqNo", func(t *testing.T) {
+		es.CreateDocument(&Document{Index: "a", ID: "1", Body: doc, IfSeqNo: &meta.SeqNo, IfPrimaryTerm: &meta.PrimaryTerm})
+
+		reqs := requests()
+		q := reqs[len(reqs)-1].URL.Query()
+		assert.Equal(t, "7", q.Get("if_seq_no"))
+		assert.Equal(t, "2", q.Get("if_primary_term"))
 	})
 }
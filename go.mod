@@ -4,7 +4,9 @@ go 1.18
 
 require (
 	github.com/bxcodec/faker/v3 v3.6.0
+	github.com/elastic/elastic-transport-go/v8 v8.3.0
 	github.com/elastic/go-elasticsearch/v7 v7.14.0
+	github.com/elastic/go-elasticsearch/v8 v8.11.0
 	github.com/joho/godotenv v1.3.0
 	github.com/stretchr/testify v1.7.0
 )
@@ -0,0 +1,220 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+type HealthCheckerConfig struct {
+	// Interval is the interval of the checks of Run. Default: 10s.
+	Interval time.Duration
+	// Timeout is the timeout of a check. Default: 5s.
+	Timeout time.Duration
+	// Indices must exist and not be red for the cluster to be healthy.
+	Indices []string
+	// RequireGreen makes a yellow cluster, or a yellow index of Indices, unhealthy.
+	RequireGreen bool
+	// FailureThreshold is the number of consecutive failed checks making a healthy cluster unhealthy,
+	// so that a single slow check does not flap a readiness probe. Default: 1.
+	FailureThreshold int
+	// Logger receives the logs of the changes of health. Default: all levels to the standard log package.
+	Logger Logger
+}
+
+// HealthReport is the result of the last check of a HealthChecker.
+type HealthReport struct {
+	Healthy bool `json:"healthy"`
+	// Error is why the last check failed, empty when it succeeded.
+	Error     string        `json:"error,omitempty"`
+	CheckedAt time.Time     `json:"checked_at"`
+	Latency   time.Duration `json:"latency"`
+	// ConsecutiveFailures is the number of failed checks since the last successful one.
+	ConsecutiveFailures int `json:"consecutive_failures"`
+
+	ClusterName   string `json:"cluster_name,omitempty"`
+	ClusterStatus string `json:"cluster_status,omitempty"`
+	Nodes         int    `json:"nodes"`
+	// Indices are the statuses of HealthCheckerConfig.Indices, "missing" for those that do not exist.
+	Indices map[string]string `json:"indices,omitempty"`
+}
+
+// HealthChecker checks the health of the cluster periodically, e.g. for the readiness probe of a service:
+//
+//	hc, _ := elasticsearch.NewHealthChecker(es, &elasticsearch.HealthCheckerConfig{Indices: []string{"articles"}})
+//	go hc.Run(ctx)
+//	http.Handle("/readyz", hc)
+//
+// The cluster is unhealthy until the first check succeeds.
+type HealthChecker struct {
+	es     Elasticsearch
+	config HealthCheckerConfig
+	logger Logger
+
+	mu     sync.RWMutex
+	report *HealthReport
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	// deregister removes Stop from the closers of the client.
+	deregister func()
+}
+
+func NewHealthChecker(es Elasticsearch, config *HealthCheckerConfig) (*HealthChecker, error) {
+	if es == nil {
+		return nil, errors.New("Required client")
+	}
+	hc := &HealthChecker{es: es, report: &HealthReport{Error: "not checked yet"}, stop: make(chan struct{})}
+	if config != nil {
+		hc.config = *config
+	}
+	if hc.config.Interval < 0 || hc.config.Timeout < 0 || hc.config.FailureThreshold < 0 {
+		return nil, errors.New("HealthCheckerConfig durations and thresholds must not be negative")
+	}
+	if hc.config.Interval == 0 {
+		hc.config.Interval = 10 * time.Second
+	}
+	if hc.config.Timeout == 0 {
+		hc.config.Timeout = 5 * time.Second
+	}
+	if hc.config.FailureThreshold == 0 {
+		hc.config.FailureThreshold = 1
+	}
+	hc.logger = hc.config.Logger
+	if hc.logger == nil {
+		hc.logger = NewStdLogger(nil, LevelDebug)
+	}
+	hc.deregister = onClose(es, hc.Stop)
+	return hc, nil
+}
+
+// Run checks the health every Interval until ctx is done or Stop is called.
+func (hc *HealthChecker) Run(ctx context.Context) {
+	ticker := time.NewTicker(hc.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-hc.stop:
+			return
+		default:
+		}
+		hc.Check(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-hc.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stop makes Run return. It is called by the Close of the client.
+func (hc *HealthChecker) Stop() {
+	hc.stopOnce.Do(func() {
+		close(hc.stop)
+		hc.deregister()
+	})
+}
+
+// Check checks the health once and returns the new report.
+func (hc *HealthChecker) Check(ctx context.Context) *HealthReport {
+	ctx, cancel := context.WithTimeout(ctx, hc.config.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	report := &HealthReport{CheckedAt: start}
+	_, health, err := hc.es.WithContext(ctx).ClusterHealth()
+	report.Latency = time.Since(start)
+	if err == nil {
+		err = hc.evaluate(health, report)
+	}
+
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+
+	previous := hc.report
+	if err == nil {
+		report.Healthy = true
+	} else {
+		report.Error = err.Error()
+		report.ConsecutiveFailures = previous.ConsecutiveFailures + 1
+		// A healthy cluster stays healthy until FailureThreshold checks fail in a row.
+		report.Healthy = previous.Healthy && report.ConsecutiveFailures < hc.config.FailureThreshold
+	}
+
+	switch {
+	case previous.Healthy && !report.Healthy:
+		hc.logger.Errorf("Elasticsearch is unhealthy: %s", report.Error)
+	case !previous.Healthy && report.Healthy:
+		hc.logger.Infof("Elasticsearch is healthy")
+	}
+
+	hc.report = report
+	return report
+}
+
+// evaluate fills report with health and returns why the cluster is unhealthy.
+func (hc *HealthChecker) evaluate(health *ClusterHealth, report *HealthReport) error {
+	report.ClusterName = health.ClusterName
+	report.ClusterStatus = health.Status
+	report.Nodes = health.NumberOfNodes
+
+	var err error
+	if !hc.acceptable(health.Status) {
+		err = fmt.Errorf("cluster status is %s", health.Status)
+	}
+
+	if len(hc.config.Indices) > 0 {
+		report.Indices = map[string]string{}
+	}
+	indices := append([]string{}, hc.config.Indices...)
+	sort.Strings(indices)
+	for _, name := range indices {
+		status := "missing"
+		if index, ok := health.Indices[name]; ok {
+			status = index.Status
+		}
+		report.Indices[name] = status
+		if err == nil && !hc.acceptable(status) {
+			err = fmt.Errorf("index %s is %s", name, status)
+		}
+	}
+	return err
+}
+
+func (hc *HealthChecker) acceptable(status string) bool {
+	return status == HealthGreen || (status == HealthYellow && !hc.config.RequireGreen)
+}
+
+// Healthy returns whether the cluster was healthy at the last check.
+func (hc *HealthChecker) Healthy() bool {
+	hc.mu.RLock()
+	defer hc.mu.RUnlock()
+	return hc.report.Healthy
+}
+
+// Report returns the report of the last check.
+func (hc *HealthChecker) Report() *HealthReport {
+	hc.mu.RLock()
+	defer hc.mu.RUnlock()
+	r := *hc.report
+	return &r
+}
+
+// ServeHTTP answers readiness probes with the report of the last check as JSON,
+// with status 200 when the cluster is healthy and 503 otherwise.
+func (hc *HealthChecker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	report := hc.Report()
+	w.Header().Set("Content-Type", "application/json")
+	if !report.Healthy {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,107 @@
+package elasticsearch
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// healthFake returns health, or err, instead of the health of the Fake.
+type healthFake struct {
+	*Fake
+	health *ClusterHealth
+	err    error
+}
+
+func (f *healthFake) WithContext(ctx context.Context) Elasticsearch {
+	return f
+}
+
+func (f *healthFake) ClusterHealth() (StatusCode, *ClusterHealth, error) {
+	if f.err != nil {
+		return StatusRequestError, &ClusterHealth{}, f.err
+	}
+	if f.health != nil {
+		return StatusSuccess, f.health, nil
+	}
+	return f.Fake.ClusterHealth()
+}
+
+func TestHealthChecker(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Indices", func(t *testing.T) {
+		fake := NewFake()
+		hc, err := NewHealthChecker(fake, &HealthCheckerConfig{Indices: []string{"a"}, Logger: NopLogger()})
+		assert.NoError(t, err)
+		assert.False(t, hc.Healthy())
+
+		report := hc.Check(ctx)
+		assert.False(t, report.Healthy)
+		assert.Equal(t, "index a is missing", report.Error)
+		assert.Equal(t, map[string]string{"a": "missing"}, report.Indices)
+
+		fake.CreateIndex("a", "")
+		report = hc.Check(ctx)
+		assert.True(t, report.Healthy)
+		assert.Empty(t, report.Error)
+		assert.Equal(t, HealthGreen, report.ClusterStatus)
+		assert.Equal(t, 1, report.Nodes)
+		assert.True(t, hc.Healthy())
+	})
+
+	t.Run("Status", func(t *testing.T) {
+		fake := &healthFake{Fake: NewFake(), health: &ClusterHealth{Status: HealthYellow}}
+		hc, _ := NewHealthChecker(fake, &HealthCheckerConfig{Logger: NopLogger()})
+		assert.True(t, hc.Check(ctx).Healthy)
+
+		hc, _ = NewHealthChecker(fake, &HealthCheckerConfig{RequireGreen: true, Logger: NopLogger()})
+		assert.Equal(t, "cluster status is yellow", hc.Check(ctx).Error)
+
+		fake.health.Status = HealthRed
+		hc, _ = NewHealthChecker(fake, &HealthCheckerConfig{Logger: NopLogger()})
+		assert.False(t, hc.Check(ctx).Healthy)
+	})
+
+	t.Run("Failure threshold", func(t *testing.T) {
+		fake := &healthFake{Fake: NewFake()}
+		hc, _ := NewHealthChecker(fake, &HealthCheckerConfig{FailureThreshold: 2, Logger: NopLogger()})
+		assert.True(t, hc.Check(ctx).Healthy)
+
+		fake.err = errors.New("connection refused")
+		report := hc.Check(ctx)
+		assert.True(t, report.Healthy)
+		assert.Equal(t, 1, report.ConsecutiveFailures)
+		assert.Equal(t, "connection refused", report.Error)
+
+		report = hc.Check(ctx)
+		assert.False(t, report.Healthy)
+		assert.Equal(t, 2, report.ConsecutiveFailures)
+
+		fake.err = nil
+		report = hc.Check(ctx)
+		assert.True(t, report.Healthy)
+		assert.Equal(t, 0, report.ConsecutiveFailures)
+	})
+
+	t.Run("ServeHTTP", func(t *testing.T) {
+		fake := &healthFake{Fake: NewFake(), err: errors.New("connection refused")}
+		hc, _ := NewHealthChecker(fake, &HealthCheckerConfig{Logger: NopLogger()})
+
+		w := httptest.NewRecorder()
+		hc.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Contains(t, w.Body.String(), `"error":"not checked yet"`)
+
+		fake.err = nil
+		hc.Check(ctx)
+		w = httptest.NewRecorder()
+		hc.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"healthy":true`)
+	})
+}
@@ -0,0 +1,120 @@
+package elasticsearch
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// GeneratedID returns a WriteDefaults.ID giving the documents indexed without ID an ID of gen,
+// e.g. NewUUIDv7, NewULID or the ID method of a Snowflake, instead of an ID generated by Elasticsearch.
+// Callers needing the ID before the write call gen themselves and set Document.ID.
+func GeneratedID(gen func() string) func(index string, body json.RawMessage) (string, error) {
+	return func(index string, body json.RawMessage) (string, error) {
+		return gen(), nil
+	}
+}
+
+// randomBytes fills b from crypto/rand, and panics if it fails.
+func randomBytes(b []byte) {
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("elasticsearch: generate ID: %s", err))
+	}
+}
+
+// NewUUIDv7 returns a UUID version 7, sorted by its millisecond, e.g. "018d5e7a-3c1e-7b3a-9f0e-2a1c4b5d6e7f".
+// https://www.rfc-editor.org/rfc/rfc9562#name-uuid-version-7
+func NewUUIDv7() string {
+	var u [16]byte
+	randomBytes(u[6:])
+	ms := uint64(time.Now().UnixMilli())
+	u[0], u[1], u[2], u[3], u[4], u[5] = byte(ms>>40), byte(ms>>32), byte(ms>>24), byte(ms>>16), byte(ms>>8), byte(ms)
+	u[6] = u[6]&0x0f | 0x70
+	u[8] = u[8]&0x3f | 0x80
+
+	var s [36]byte
+	hex.Encode(s[0:8], u[0:4])
+	s[8] = '-'
+	hex.Encode(s[9:13], u[4:6])
+	s[13] = '-'
+	hex.Encode(s[14:18], u[6:8])
+	s[18] = '-'
+	hex.Encode(s[19:23], u[8:10])
+	s[23] = '-'
+	hex.Encode(s[24:], u[10:])
+	return string(s[:])
+}
+
+// crockford is the alphabet of ULIDs.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// NewULID returns a ULID, sorted by its millisecond, e.g. "01HQ5X8V3C9D2E4F6G8H0J2K4M".
+// https://github.com/ulid/spec
+func NewULID() string {
+	var u [16]byte
+	binary.BigEndian.PutUint64(u[:8], uint64(time.Now().UnixMilli())<<16)
+	randomBytes(u[6:])
+
+	// 128 bits in 26 characters of 5 bits, the first one holding 3 bits.
+	hi, lo := binary.BigEndian.Uint64(u[:8]), binary.BigEndian.Uint64(u[8:])
+	var s [26]byte
+	for i := 25; i >= 0; i-- {
+		s[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(s[:])
+}
+
+// SnowflakeEpoch is the epoch of the timestamps of the Snowflake IDs, 2020-01-01 UTC.
+var SnowflakeEpoch = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Snowflake generates the 64-bit IDs of a node, of 41 bits of milliseconds since SnowflakeEpoch,
+// 10 bits of node and 12 bits of sequence: 4096 IDs per millisecond and node, strictly increasing on a node.
+// The IDs are zero-padded to 19 digits, so that they also sort as strings.
+type Snowflake struct {
+	node int64
+
+	mu   sync.Mutex
+	last int64
+	seq  int64
+}
+
+// NewSnowflake returns the Snowflake of node, which must be unique among the running generators, from 0 to 1023.
+func NewSnowflake(node int64) (*Snowflake, error) {
+	if node < 0 || node > 1023 {
+		return nil, errors.New("Snowflake node must be from 0 to 1023")
+	}
+	return &Snowflake{node: node}, nil
+}
+
+// ID returns the next ID, waiting for the next millisecond after 4096 IDs in the same one.
+func (s *Snowflake) ID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ms := time.Since(SnowflakeEpoch).Milliseconds()
+	if ms < s.last {
+		// The clock went back: keep counting from the last millisecond.
+		ms = s.last
+	}
+	if ms == s.last {
+		s.seq = (s.seq + 1) & 0xfff
+		if s.seq == 0 {
+			for ms <= s.last {
+				time.Sleep(100 * time.Microsecond)
+				ms = time.Since(SnowflakeEpoch).Milliseconds()
+			}
+		}
+	} else {
+		s.seq = 0
+	}
+	s.last = ms
+
+	return fmt.Sprintf("%019d", ms<<22|s.node<<12|s.seq)
+}
@@ -0,0 +1,56 @@
+package elasticsearch
+
+import (
+	"regexp"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIDGenerators(t *testing.T) {
+	t.Run("UUIDv7", func(t *testing.T) {
+		a := NewUUIDv7()
+		time.Sleep(2 * time.Millisecond)
+		b := NewUUIDv7()
+
+		assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), a)
+		assert.Less(t, a, b)
+	})
+
+	t.Run("ULID", func(t *testing.T) {
+		a := NewULID()
+		time.Sleep(2 * time.Millisecond)
+		b := NewULID()
+
+		assert.Regexp(t, regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Z]{25}$`), a)
+		assert.Less(t, a, b)
+	})
+
+	t.Run("Snowflake", func(t *testing.T) {
+		_, err := NewSnowflake(1024)
+		assert.Error(t, err)
+
+		s, err := NewSnowflake(3)
+		assert.NoError(t, err)
+		ids := make([]string, 5000)
+		for i := range ids {
+			ids[i] = s.ID()
+		}
+
+		assert.Len(t, ids[0], 19)
+		assert.True(t, sort.StringsAreSorted(ids))
+		unique := map[string]bool{}
+		for _, id := range ids {
+			unique[id] = true
+		}
+		assert.Len(t, unique, len(ids))
+	})
+
+	t.Run("GeneratedID", func(t *testing.T) {
+		id, err := GeneratedID(func() string { return "x" })("a", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "x", id)
+	})
+}
@@ -0,0 +1,94 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// WithIndexPrefix returns a copy of the client whose index names are prefixed with prefix
+// instead of Config.IndexPrefix, e.g. the prefix of a tenant. An empty prefix disables prefixing.
+func (es *_elasticsearch) WithIndexPrefix(prefix string) Elasticsearch {
+	c := *es
+	c.prefix = prefix
+	return &c
+}
+
+// indexName prefixes the comma-separated index names, wildcards and aliases of index.
+// "", "_all" and "*" are narrowed to the indices of the prefix.
+func (es *_elasticsearch) indexName(index string) string {
+	if es.prefix == "" {
+		return index
+	}
+
+	names := strings.Split(index, ",")
+	for i, name := range names {
+		exclude := strings.HasPrefix(name, "-")
+		name = strings.TrimPrefix(name, "-")
+
+		cluster, name := splitRemoteIndex(name)
+		if name == "" || name == "_all" {
+			name = "*"
+		}
+
+		names[i] = cluster + es.prefix + name
+		if exclude {
+			names[i] = "-" + names[i]
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+func (es *_elasticsearch) indexNames(index []string) []string {
+	if es.prefix == "" {
+		return index
+	}
+	if len(index) == 0 {
+		return []string{es.prefix + "*"}
+	}
+
+	names := make([]string, len(index))
+	for i, name := range index {
+		names[i] = es.indexName(name)
+	}
+	return names
+}
+
+// trimIndexPrefix returns the index name given to the client for the index name returned by Elasticsearch.
+func (es *_elasticsearch) trimIndexPrefix(index string) string {
+	cluster, name := splitRemoteIndex(index)
+	return cluster + strings.TrimPrefix(name, es.prefix)
+}
+
+// prefixTemplate prefixes the index_patterns of an index template.
+func (es *_elasticsearch) prefixTemplate(templates string) (string, error) {
+	if es.prefix == "" {
+		return templates, nil
+	}
+
+	var t map[string]interface{}
+	if err := json.Unmarshal([]byte(templates), &t); err != nil {
+		return "", err
+	}
+
+	switch patterns := t["index_patterns"].(type) {
+	case string:
+		t["index_patterns"] = es.indexName(patterns)
+	case []interface{}:
+		for i, pattern := range patterns {
+			if s, ok := pattern.(string); ok {
+				patterns[i] = es.indexName(s)
+			}
+		}
+	}
+
+	b, err := json.Marshal(t)
+	return string(b), err
+}
+
+// indexList returns the index list argument of a request on index, which is empty for all indices.
+func (es *_elasticsearch) indexList(index string) []string {
+	if name := es.indexName(index); name != "" {
+		return []string{name}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package elasticsearch
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndexName(t *testing.T) {
+	es := &_elasticsearch{prefix: "staging-"}
+
+	assert.Equal(t, "staging-articles", es.indexName("articles"))
+	assert.Equal(t, "staging-a,staging-b*,-staging-b1", es.indexName("a,b*,-b1"))
+	assert.Equal(t, "staging-*", es.indexName(""))
+	assert.Equal(t, "staging-*", es.indexName("_all"))
+	assert.Equal(t, "remote:staging-articles", es.indexName("remote:articles"))
+	assert.Equal(t, []string{"staging-*"}, es.indexNames(nil))
+	assert.Equal(t, "articles", es.trimIndexPrefix("staging-articles"))
+	assert.Equal(t, "remote:articles", es.trimIndexPrefix("remote:staging-articles"))
+
+	templates, err := es.prefixTemplate(`{"index_patterns": ["articles-*"], "template": {}}`)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"index_patterns": ["staging-articles-*"], "template": {}}`, templates)
+
+	tenant := es.WithIndexPrefix("tenant1-").(*_elasticsearch)
+	assert.Equal(t, "tenant1-articles", tenant.indexName("articles"))
+	assert.Equal(t, "staging-articles", es.indexName("articles"))
+
+	none := &_elasticsearch{}
+	assert.Equal(t, "_all", none.indexName("_all"))
+	assert.Equal(t, []string(nil), none.indexNames(nil))
+}
+
+func TestIndexPrefix(t *testing.T) {
+	var bodies []string
+	server, requests := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/_search"):
+			w.Write([]byte(`{"hits": {"total": {"value": 1}, "hits": [{"_index": "staging-articles", "_id": "1", "_source": {}}]}}`))
+		case r.URL.Path == "/_bulk":
+			w.Write([]byte(`{"errors": false, "items": [{"index": {"_index": "staging-articles", "_id": "1", "status": 201}}]}`))
+		default:
+			w.Write([]byte(`{}`))
+		}
+	})
+
+	es, err := New(&Config{Address: []string{server.URL}, IndexPrefix: "staging-"})
+	assert.NoError(t, err)
+
+	_, hits, _, err := es.Search("articles", SearchBody(MatchAllQuery()), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "articles", hits[0].Index)
+
+	_, results, err := es.Bulk([]*BulkItem{{Index: "articles", ID: "1", Body: DocBody{Id: "1"}}}, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "articles", results[0].Index)
+
+	_, err = es.WithIndexPrefix("tenant1-").CreateDocument(&Document{Index: "articles", ID: "1", Body: DocBody{Id: "1"}})
+	assert.NoError(t, err)
+
+	reqs := requests()
+	assert.Equal(t, "/staging-articles/_search", reqs[len(reqs)-3].URL.Path)
+	assert.Contains(t, bodies[len(bodies)-2], `"_index":"staging-articles"`)
+	assert.Equal(t, "/tenant1-articles/_doc/1", reqs[len(reqs)-1].URL.Path)
+}
@@ -0,0 +1,408 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+)
+
+// CreateIndex creates index with body holding its settings, mappings and aliases. body may be empty.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-create-index.html
+func (es *_elasticsearch) CreateIndex(index, body string) (StatusCode, error) {
+	req := esapi.IndicesCreateRequest{
+		Index: es.indexName(index),
+	}
+	if body != "" {
+		req.Body = strings.NewReader(body)
+	}
+
+	res, err := req.Do(es.ctx, es.client)
+	return es.handleResponse("create index "+index, res, err, nil)
+}
+
+func (es *_elasticsearch) DeleteIndexTemplate(name string) (StatusCode, error) {
+	if err := es.requireVersion("composable index template", 7, 8); err != nil {
+		return StatusInternalError, err
+	}
+
+	req := esapi.IndicesDeleteIndexTemplateRequest{
+		Name: es.prefix + name,
+	}
+
+	res, err := req.Do(es.ctx, es.client)
+	return es.handleResponse("delete index template "+name, res, err, nil)
+}
+
+// Reindex copies the documents of source into dest, waiting for completion, and returns the number of copied documents.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/docs-reindex.html
+func (es *_elasticsearch) Reindex(source, dest string) (StatusCode, int, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"source": map[string]string{"index": es.indexName(source)},
+		"dest":   map[string]string{"index": es.indexName(dest)},
+	})
+	if err != nil {
+		return StatusInternalError, 0, err
+	}
+
+	refresh, wait := true, true
+	req := esapi.ReindexRequest{
+		Body:              bytes.NewReader(body),
+		Refresh:           &refresh,
+		WaitForCompletion: &wait,
+	}
+
+	res, err := req.Do(es.ctx, es.client)
+
+	var r struct {
+		Total    int `json:"total"`
+		Failures []struct {
+			ID    string      `json:"id"`
+			Cause *ErrorCause `json:"cause"`
+		} `json:"failures"`
+	}
+	if status, err := es.handleResponse("reindex "+source+" to "+dest, res, err, &r); err != nil {
+		return status, 0, err
+	}
+	if len(r.Failures) > 0 {
+		first := r.Failures[0]
+		if first.Cause == nil {
+			first.Cause = &ErrorCause{}
+		}
+		return StatusError, r.Total - len(r.Failures), fmt.Errorf("elasticsearch: %d documents failed to reindex from %s to %s, first ID=%s: [%s] %s",
+			len(r.Failures), source, dest, first.ID, first.Cause.Type, first.Cause.Reason)
+	}
+
+	return StatusSuccess, r.Total, nil
+}
+
+// SwapAlias atomically moves alias from the index from to the index to.
+// An empty from only adds alias to to.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/aliases.html
+func (es *_elasticsearch) SwapAlias(alias, from, to string) (StatusCode, error) {
+	actions := []map[string]interface{}{}
+	if from != "" {
+		actions = append(actions, map[string]interface{}{
+			"remove": map[string]string{"index": es.indexName(from), "alias": es.indexName(alias)},
+		})
+	}
+	actions = append(actions, map[string]interface{}{
+		"add": map[string]string{"index": es.indexName(to), "alias": es.indexName(alias)},
+	})
+
+	return es.updateAliases("swap alias "+alias+" to "+to, actions...)
+}
+
+// Alias configures an alias created by PutAlias.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/aliases.html
+type Alias struct {
+	// Filter limits the documents searched through the alias, e.g. to those of a tenant.
+	Filter Query
+	// Routing routes the searches and writes through the alias to the shard of a single routing value.
+	Routing string
+}
+
+// PutAlias creates or replaces alias of index. A nil options creates a plain alias.
+func (es *_elasticsearch) PutAlias(index, alias string, options *Alias) (StatusCode, error) {
+	add := map[string]interface{}{"index": es.indexName(index), "alias": es.indexName(alias)}
+	if options != nil {
+		if options.Filter != nil {
+			add["filter"] = options.Filter
+		}
+		if options.Routing != "" {
+			add["routing"] = options.Routing
+		}
+	}
+	return es.updateAliases("put alias "+alias+" to "+index, map[string]interface{}{"add": add})
+}
+
+// DeleteAlias deletes alias of index.
+func (es *_elasticsearch) DeleteAlias(index, alias string) (StatusCode, error) {
+	remove := map[string]string{"index": es.indexName(index), "alias": es.indexName(alias)}
+	return es.updateAliases("delete alias "+alias+" of "+index, map[string]interface{}{"remove": remove})
+}
+
+func (es *_elasticsearch) updateAliases(op string, actions ...map[string]interface{}) (StatusCode, error) {
+	body, err := json.Marshal(map[string]interface{}{"actions": actions})
+	if err != nil {
+		return StatusInternalError, err
+	}
+
+	req := esapi.IndicesUpdateAliasesRequest{
+		Body: bytes.NewReader(body),
+	}
+
+	res, err := req.Do(es.ctx, es.client)
+	return es.handleResponse(op, res, err, nil)
+}
+
+type deleteIndicesOptions struct {
+	ignoreUnavailable bool
+	expandWildcards   string
+}
+
+// DeleteIndicesOption changes how DeleteIndices runs.
+type DeleteIndicesOption func(o *deleteIndicesOptions)
+
+// DeleteIgnoreUnavailable makes DeleteIndices succeed on missing indices instead of returning ErrNotFound.
+func DeleteIgnoreUnavailable() DeleteIndicesOption {
+	return func(o *deleteIndicesOptions) {
+		o.ignoreUnavailable = true
+	}
+}
+
+// DeleteExpandWildcards sets the indices matched by wildcards: "open" (the default), "closed", "hidden", "none" or "all".
+func DeleteExpandWildcards(expand ...string) DeleteIndicesOption {
+	return func(o *deleteIndicesOptions) {
+		o.expandWildcards = strings.Join(expand, ",")
+	}
+}
+
+// DeleteIndices deletes the comma-separated indices of index, see Indices.
+// It refuses with ErrDeleteRefused an empty index, "_all" and "*", and the names matching
+// none of the DeletableIndices of Config.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-delete-index.html
+func (es *_elasticsearch) DeleteIndices(index string, opts ...DeleteIndicesOption) (StatusCode, error) {
+	if err := es.checkDeletable(index); err != nil {
+		es.logger.Errorf("Error delete indices %s: %s", index, err)
+		return StatusInternalError, err
+	}
+
+	o := &deleteIndicesOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	req := esapi.IndicesDeleteRequest{
+		Index:           []string{es.indexName(index)},
+		ExpandWildcards: o.expandWildcards,
+	}
+	if o.ignoreUnavailable {
+		req.IgnoreUnavailable = &o.ignoreUnavailable
+	}
+
+	res, err := req.Do(es.ctx, es.client)
+	return es.handleResponse("delete indices "+index, res, err, nil)
+}
+
+type refreshOptions struct {
+	ignoreUnavailable *bool
+	allowNoIndices    *bool
+	expandWildcards   string
+}
+
+// RefreshOption changes how RefreshIndices runs.
+type RefreshOption func(o *refreshOptions)
+
+// RefreshIgnoreUnavailable makes RefreshIndices skip missing indices instead of returning ErrNotFound.
+func RefreshIgnoreUnavailable() RefreshOption {
+	return func(o *refreshOptions) {
+		ignore := true
+		o.ignoreUnavailable = &ignore
+	}
+}
+
+// RefreshAllowNoIndices set to false makes RefreshIndices return ErrNotFound when a wildcard matches no index.
+func RefreshAllowNoIndices(allow bool) RefreshOption {
+	return func(o *refreshOptions) {
+		o.allowNoIndices = &allow
+	}
+}
+
+// RefreshExpandWildcards sets the indices matched by wildcards: "open" (the default), "closed", "hidden", "none" or "all".
+func RefreshExpandWildcards(expand ...string) RefreshOption {
+	return func(o *refreshOptions) {
+		o.expandWildcards = strings.Join(expand, ",")
+	}
+}
+
+// RefreshIndices refreshes the comma-separated indices of index, all of them when it is empty, and returns
+// the shards refreshed. A missing index returns ErrNotFound, and the failure of some shards a *RefreshError.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-refresh.html
+func (es *_elasticsearch) RefreshIndices(index string, opts ...RefreshOption) (StatusCode, *ShardsInfo, error) {
+	o := &refreshOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	req := esapi.IndicesRefreshRequest{
+		IgnoreUnavailable: o.ignoreUnavailable,
+		AllowNoIndices:    o.allowNoIndices,
+		ExpandWildcards:   o.expandWildcards,
+	}
+	if index != "" || es.prefix != "" {
+		req.Index = []string{es.indexName(index)}
+	}
+
+	res, err := req.Do(es.ctx, es.client)
+
+	var r struct {
+		Shards *ShardsInfo `json:"_shards"`
+	}
+	if status, err := es.handleResponse("refresh "+index, res, err, &r); err != nil {
+		return status, &ShardsInfo{}, err
+	}
+	if r.Shards == nil {
+		r.Shards = &ShardsInfo{}
+	}
+	for _, f := range r.Shards.Failures {
+		f.Index = es.trimIndexPrefix(f.Index)
+	}
+	if r.Shards.Failed > 0 {
+		err := &RefreshError{Shards: r.Shards}
+		es.logger.Errorf("Error refresh %s: %s", index, err)
+		return StatusError, r.Shards, err
+	}
+	return StatusSuccess, r.Shards, nil
+}
+
+func (es *_elasticsearch) checkDeletable(index string) error {
+	for _, name := range strings.Split(index, ",") {
+		if name == "" || name == "_all" || strings.Trim(name, "*") == "" {
+			return fmt.Errorf("%w: %q matches every index", ErrDeleteRefused, name)
+		}
+		if len(es.deletable) == 0 {
+			continue
+		}
+
+		allowed := false
+		for _, pattern := range es.deletable {
+			if ok, _ := path.Match(pattern, name); ok {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("%w: %q is not in DeletableIndices", ErrDeleteRefused, name)
+		}
+	}
+	return nil
+}
+
+// OpenIndex opens the closed indices of index so that they can be searched and written again.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-open-close.html
+func (es *_elasticsearch) OpenIndex(index string) (StatusCode, error) {
+	req := esapi.IndicesOpenRequest{
+		Index: []string{es.indexName(index)},
+	}
+
+	res, err := req.Do(es.ctx, es.client)
+	return es.handleResponse("open index "+index, res, err, nil)
+}
+
+// CloseIndex closes the indices of index, which then use no resources but cannot be searched or written.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-close.html
+func (es *_elasticsearch) CloseIndex(index string) (StatusCode, error) {
+	req := esapi.IndicesCloseRequest{
+		Index: []string{es.indexName(index)},
+	}
+
+	res, err := req.Do(es.ctx, es.client)
+	return es.handleResponse("close index "+index, res, err, nil)
+}
+
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/index-modules-blocks.html
+type IndexBlock string
+
+const (
+	// BlockMetadata disables changing the settings and mappings.
+	BlockMetadata IndexBlock = "metadata"
+	// BlockRead disables searching and getting documents.
+	BlockRead IndexBlock = "read"
+	// BlockReadOnly disables writing documents and changing the settings and mappings,
+	// e.g. before shrinking the index.
+	BlockReadOnly IndexBlock = "read_only"
+	// BlockWrite disables writing documents but still allows changing the settings and mappings.
+	BlockWrite IndexBlock = "write"
+)
+
+func (es *_elasticsearch) AddIndexBlock(index string, block IndexBlock) (StatusCode, error) {
+	req := esapi.IndicesAddBlockRequest{
+		Index: []string{es.indexName(index)},
+		Block: string(block),
+	}
+
+	res, err := req.Do(es.ctx, es.client)
+	return es.handleResponse(fmt.Sprintf("add %s block to index %s", block, index), res, err, nil)
+}
+
+// RemoveIndexBlock removes block by setting index.blocks.<block> to false.
+func (es *_elasticsearch) RemoveIndexBlock(index string, block IndexBlock) (StatusCode, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"index.blocks." + string(block): false,
+	})
+	if err != nil {
+		return StatusInternalError, err
+	}
+
+	req := esapi.IndicesPutSettingsRequest{
+		Index: []string{es.indexName(index)},
+		Body:  bytes.NewReader(body),
+	}
+
+	res, err := req.Do(es.ctx, es.client)
+	return es.handleResponse(fmt.Sprintf("remove %s block from index %s", block, index), res, err, nil)
+}
+
+// ForceMerge, Flush and ClearCache apply to all indices when index is empty.
+
+// ForceMerge merges the segments of the shards of index down to maxNumSegments each,
+// e.g. 1 for an index that will not be written anymore. A zero maxNumSegments only merges as needed.
+// It waits for the merge to complete, which can take long on large indices.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-forcemerge.html
+func (es *_elasticsearch) ForceMerge(index string, maxNumSegments int) (StatusCode, error) {
+	req := esapi.IndicesForcemergeRequest{
+		Index: es.indexList(index),
+	}
+	if maxNumSegments > 0 {
+		req.MaxNumSegments = &maxNumSegments
+	}
+
+	res, err := req.Do(es.ctx, es.client)
+	return es.handleShardsResponse("force merge index "+index, res, err)
+}
+
+// Flush writes the transaction log of index to its segments on disk.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-flush.html
+func (es *_elasticsearch) Flush(index string) (StatusCode, error) {
+	req := esapi.IndicesFlushRequest{
+		Index: es.indexList(index),
+	}
+
+	res, err := req.Do(es.ctx, es.client)
+	return es.handleShardsResponse("flush index "+index, res, err)
+}
+
+// ClearCache clears the query, request and field data caches of index.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-clearcache.html
+func (es *_elasticsearch) ClearCache(index string) (StatusCode, error) {
+	req := esapi.IndicesClearCacheRequest{
+		Index: es.indexList(index),
+	}
+
+	res, err := req.Do(es.ctx, es.client)
+	return es.handleShardsResponse("clear cache of index "+index, res, err)
+}
+
+// handleShardsResponse handles the response of a broadcast request like handleResponse,
+// and returns an error when it failed on some shards.
+func (es *_elasticsearch) handleShardsResponse(op string, res *esapi.Response, err error) (StatusCode, error) {
+	var r struct {
+		Shards ShardsInfo `json:"_shards"`
+	}
+	if status, err := es.handleResponse(op, res, err, &r); err != nil {
+		return status, err
+	}
+	if r.Shards.Failed > 0 {
+		reason := ""
+		if len(r.Shards.Failures) > 0 && r.Shards.Failures[0].Reason != nil {
+			reason = ": " + r.Shards.Failures[0].Reason.Reason
+		}
+		es.logger.Errorf("Error %s: failed on %d of %d shards%s", op, r.Shards.Failed, r.Shards.Total, reason)
+		return StatusError, fmt.Errorf("elasticsearch: %s failed on %d of %d shards%s", op, r.Shards.Failed, r.Shards.Total, reason)
+	}
+	return StatusSuccess, nil
+}
@@ -0,0 +1,229 @@
+package elasticsearch
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/bxcodec/faker/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReindex(t *testing.T) {
+	es := newElasticsearch()
+	dest := indexName + "-dest"
+	defer es.DeleteIndeces(indexName, dest)
+
+	status, err := es.CreateIndex(dest, `{"settings": {"number_of_shards": 1}}`)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSuccess, status)
+
+	for i := 0; i < 3; i++ {
+		id := faker.UUIDDigit()
+		es.CreateDocument(&Document{Index: indexName, ID: id, Body: DocBody{Id: id}, Refresh: RefreshTrue})
+	}
+
+	status, n, err := es.Reindex(indexName, dest)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSuccess, status)
+	assert.Equal(t, 3, n)
+
+	_, count, _ := es.Count(dest, SearchBody(MatchAllQuery()))
+	assert.Equal(t, 3, count)
+
+	t.Run("Swap Alias", func(t *testing.T) {
+		alias := indexName + "-alias"
+
+		status, err := es.SwapAlias(alias, "", indexName)
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+
+		status, err = es.SwapAlias(alias, indexName, dest)
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+	})
+
+	t.Run("Index Exists", func(t *testing.T) {
+		status, err := es.CreateIndex(dest, "")
+		assert.ErrorIs(t, err, ErrBadRequest)
+		assert.Equal(t, StatusBadRequestError, status)
+	})
+}
+
+func TestDeleteIndices(t *testing.T) {
+	server, requests := newTestServer(t, nil)
+	es, err := New(&Config{Address: []string{server.URL}, DeletableIndices: []string{"test-*"}})
+	assert.NoError(t, err)
+
+	t.Run("Refused", func(t *testing.T) {
+		for _, index := range []string{"", "_all", "*", "test-a,*", "prod-a", Indices("test-a", "prod-a")} {
+			status, err := es.DeleteIndices(index)
+			assert.ErrorIs(t, err, ErrDeleteRefused, index)
+			assert.Equal(t, StatusInternalError, status)
+		}
+	})
+
+	t.Run("Options", func(t *testing.T) {
+		status, err := es.DeleteIndices(Indices("test-a", "test-b-*"), DeleteIgnoreUnavailable(), DeleteExpandWildcards("open", "closed"))
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+
+		reqs := requests()
+		req := reqs[len(reqs)-1]
+		assert.Equal(t, http.MethodDelete, req.Method)
+		assert.Equal(t, "/test-a,test-b-*", req.URL.Path)
+		assert.Equal(t, "true", req.URL.Query().Get("ignore_unavailable"))
+		assert.Equal(t, "open,closed", req.URL.Query().Get("expand_wildcards"))
+	})
+
+	t.Run("Deprecated name", func(t *testing.T) {
+		_, err := es.DeleteIndeces("test-a", "test-b")
+		assert.NoError(t, err)
+
+		reqs := requests()
+		assert.Equal(t, "/test-a,test-b", reqs[len(reqs)-1].URL.Path)
+	})
+}
+
+func TestDeleteIndicesNotFound(t *testing.T) {
+	es := newElasticsearch()
+	missing := indexName + "-missing"
+
+	status, err := es.DeleteIndices(missing)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, StatusNotFoundError, status)
+
+	status, err = es.DeleteIndices(missing, DeleteIgnoreUnavailable())
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSuccess, status)
+}
+
+func TestRefreshIndices(t *testing.T) {
+	server, requests := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/p-a,p-b/_refresh":
+			w.Write([]byte(`{"_shards": {"total": 4, "successful": 3, "failed": 1, "failures": [
+				{"index": "p-b", "shard": 0, "reason": {"type": "illegal_index_shard_state_exception", "reason": "shard closed"}}
+			]}}`))
+		case "/p-*/_refresh":
+			w.Write([]byte(`{"_shards": {"total": 2, "successful": 2, "failed": 0}}`))
+		default:
+			w.Write([]byte(`{}`))
+		}
+	})
+	es, err := New(&Config{Address: []string{server.URL}, IndexPrefix: "p-", Logger: NopLogger()})
+	assert.NoError(t, err)
+
+	status, shards, err := es.RefreshIndices("", RefreshIgnoreUnavailable(), RefreshAllowNoIndices(false), RefreshExpandWildcards("open"))
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSuccess, status)
+	assert.Equal(t, &ShardsInfo{Total: 2, Successful: 2}, shards)
+	reqs := requests()
+	q := reqs[len(reqs)-1].URL.Query()
+	assert.Equal(t, "true", q.Get("ignore_unavailable"))
+	assert.Equal(t, "false", q.Get("allow_no_indices"))
+	assert.Equal(t, "open", q.Get("expand_wildcards"))
+
+	status, shards, err = es.RefreshIndices(Indices("a", "b"))
+	var refreshErr *RefreshError
+	assert.ErrorAs(t, err, &refreshErr)
+	assert.Equal(t, StatusError, status)
+	assert.Equal(t, 1, shards.Failed)
+	assert.Equal(t, []string{"b"}, refreshErr.Indices())
+	assert.Equal(t, "elasticsearch: refresh failed on 1 of 4 shards: [b] shard closed", err.Error())
+
+	assert.ErrorAs(t, es.Refresh("a", "b"), &refreshErr)
+}
+
+func TestIndexBlocks(t *testing.T) {
+	es := newElasticsearch()
+	defer es.DeleteIndices(indexName)
+
+	id := faker.UUIDDigit()
+	es.CreateDocument(&Document{Index: indexName, ID: id, Body: DocBody{Id: id}, Refresh: RefreshTrue})
+
+	t.Run("Write block", func(t *testing.T) {
+		status, err := es.AddIndexBlock(indexName, BlockWrite)
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+
+		_, err = es.CreateDocument(&Document{Index: indexName, ID: id, Body: DocBody{Id: id}})
+		assert.Error(t, err)
+
+		status, err = es.RemoveIndexBlock(indexName, BlockWrite)
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+
+		_, err = es.CreateDocument(&Document{Index: indexName, ID: id, Body: DocBody{Id: id}})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Close and open", func(t *testing.T) {
+		status, err := es.CloseIndex(indexName)
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+
+		_, _, err = es.Count(indexName, "")
+		assert.ErrorIs(t, err, ErrBadRequest)
+
+		status, err = es.OpenIndex(indexName)
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+	})
+}
+
+func TestShardsResponse(t *testing.T) {
+	server, requests := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/_flush") {
+			w.Write([]byte(`{"_shards": {"total": 2, "successful": 1, "failed": 1, "failures": [{"shard": 0, "index": "a", "reason": {"type": "x", "reason": "broken"}}]}}`))
+			return
+		}
+		w.Write([]byte(`{"_shards": {"total": 2, "successful": 2, "failed": 0}}`))
+	})
+	es, err := New(&Config{Address: []string{server.URL}, Logger: NopLogger()})
+	assert.NoError(t, err)
+
+	status, err := es.ForceMerge("a", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSuccess, status)
+	reqs := requests()
+	assert.Equal(t, "/a/_forcemerge", reqs[len(reqs)-1].URL.Path)
+	assert.Equal(t, "1", reqs[len(reqs)-1].URL.Query().Get("max_num_segments"))
+
+	status, err = es.ClearCache("")
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSuccess, status)
+	reqs = requests()
+	assert.Equal(t, "/_cache/clear", reqs[len(reqs)-1].URL.Path)
+
+	status, err = es.Flush("a")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "broken")
+	assert.Equal(t, StatusError, status)
+}
+
+func TestPutAlias(t *testing.T) {
+	var bodies []string
+	server, requests := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		w.Write([]byte(`{"acknowledged": true}`))
+	})
+	es, err := New(&Config{Address: []string{server.URL}, IndexPrefix: "p-"})
+	assert.NoError(t, err)
+	bodies = nil
+
+	status, err := es.PutAlias("articles", "articles-acme", &Alias{Filter: TermQuery("tenant_id", "acme"), Routing: "acme"})
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSuccess, status)
+
+	status, err = es.DeleteAlias("articles", "articles-acme")
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSuccess, status)
+
+	reqs := requests()
+	assert.Equal(t, "/_aliases", reqs[len(reqs)-1].URL.Path)
+	assert.JSONEq(t, `{"actions": [{"add": {"index": "p-articles", "alias": "p-articles-acme", "filter": {"term": {"tenant_id": "acme"}}, "routing": "acme"}}]}`, bodies[len(bodies)-2])
+	assert.JSONEq(t, `{"actions": [{"remove": {"index": "p-articles", "alias": "p-articles-acme"}}]}`, bodies[len(bodies)-1])
+}
@@ -0,0 +1,161 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+)
+
+// InnerHitsOptions requests the matching nested or child documents of each hit.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/inner-hits.html
+type InnerHitsOptions struct {
+	Name string        `json:"name,omitempty"`
+	From int           `json:"from,omitempty"`
+	Size int           `json:"size,omitempty"`
+	Sort []interface{} `json:"sort,omitempty"`
+}
+
+func withInnerHits(query map[string]interface{}, innerHits *InnerHitsOptions) Query {
+	if innerHits != nil {
+		query["inner_hits"] = innerHits
+	}
+	return query
+}
+
+// NestedQuery matches documents whose nested objects under path match q.
+// scoreMode is one of avg, max, min, none, sum and may be empty.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/query-dsl-nested-query.html
+func NestedQuery(path string, q Query, scoreMode string, innerHits *InnerHitsOptions) Query {
+	nested := map[string]interface{}{
+		"path":  path,
+		"query": q,
+	}
+	if scoreMode != "" {
+		nested["score_mode"] = scoreMode
+	}
+	return Query{"nested": withInnerHits(nested, innerHits)}
+}
+
+// HasChildQuery matches parent documents having children of childType that match q.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/query-dsl-has-child-query.html
+func HasChildQuery(childType string, q Query, scoreMode string, innerHits *InnerHitsOptions) Query {
+	hasChild := map[string]interface{}{
+		"type":  childType,
+		"query": q,
+	}
+	if scoreMode != "" {
+		hasChild["score_mode"] = scoreMode
+	}
+	return Query{"has_child": withInnerHits(hasChild, innerHits)}
+}
+
+// HasParentQuery matches child documents whose parent of parentType matches q.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/query-dsl-has-parent-query.html
+func HasParentQuery(parentType string, q Query, score bool, innerHits *InnerHitsOptions) Query {
+	hasParent := map[string]interface{}{
+		"parent_type": parentType,
+		"query":       q,
+		"score":       score,
+	}
+	return Query{"has_parent": withInnerHits(hasParent, innerHits)}
+}
+
+// ParentIDQuery matches child documents of childType joined to the parent with id.
+func ParentIDQuery(childType, id string) Query {
+	return Query{"parent_id": map[string]interface{}{
+		"type": childType,
+		"id":   id,
+	}}
+}
+
+// JoinField is the value of a join field, e.g. JoinField{Name: "answer", Parent: "1"}.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/parent-join.html
+type JoinField struct {
+	Name   string `json:"name"`
+	Parent string `json:"parent,omitempty"`
+}
+
+// JoinFieldMapping returns the mapping of a join field, to be placed under "properties".
+// relations maps each parent name to its child names.
+func JoinFieldMapping(relations map[string][]string) map[string]interface{} {
+	r := map[string]interface{}{}
+	for parent, children := range relations {
+		if len(children) == 1 {
+			r[parent] = children[0]
+		} else {
+			r[parent] = children
+		}
+	}
+	return map[string]interface{}{
+		"type":      "join",
+		"relations": r,
+	}
+}
+
+// NewChildDocument returns a document routed to the shard of its parent,
+// as required for every write of a child document.
+// body must set its join field to JoinField{Name: <child>, Parent: parentID}.
+func NewChildDocument(index, id, parentID string, body interface{}) *Document {
+	return &Document{
+		Index:   index,
+		ID:      id,
+		Body:    body,
+		Routing: parentID,
+	}
+}
+
+// NestedIdentity locates a nested inner hit within its root document.
+type NestedIdentity struct {
+	Field  string          `json:"field"`
+	Offset int             `json:"offset"`
+	Nested *NestedIdentity `json:"_nested,omitempty"`
+}
+
+// InnerHits are the nested, child or collapsed documents matched for a hit,
+// keyed by the inner_hits name in HitData.InnerHits.
+type InnerHits struct {
+	Total int
+	Hits  []*HitData
+
+	// codec is the Config.Codec of the client which searched the hits.
+	codec Codec
+}
+
+func (ih *InnerHits) UnmarshalJSON(data []byte) error {
+	var r struct {
+		Hits struct {
+			Total struct {
+				Value int `json:"value"`
+			} `json:"total"`
+			Hits []*HitData `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := json.Unmarshal(data, &r); err != nil {
+		return err
+	}
+
+	ih.Total = r.Hits.Total.Value
+	ih.Hits = r.Hits.Hits
+	if ih.Hits == nil {
+		ih.Hits = []*HitData{}
+	}
+	return nil
+}
+
+// Decode unmarshals the _source of the inner hits into data, which should be a pointer to a slice,
+// with the Config.Codec of the client which searched them.
+func (ih *InnerHits) Decode(data interface{}) error {
+	codec := ih.codec
+	if codec == nil {
+		codec = jsonCodec{}
+	}
+	return codec.Unmarshal(joinSources(ih.Hits), data)
+}
+
+// setInnerHitsCodec sets the codec of the inner hits of hits, at any depth.
+func setInnerHitsCodec(hits []*HitData, codec Codec) {
+	for _, hit := range hits {
+		for _, ih := range hit.InnerHits {
+			ih.codec = codec
+			setInnerHitsCodec(ih.Hits, codec)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/bxcodec/faker/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+const joinIndexName = "test-es-join"
+
+type QuestionDocBody struct {
+	Id       string    `json:"id"`
+	Text     string    `json:"text"`
+	Relation JoinField `json:"relation"`
+}
+
+func TestJoinQueries(t *testing.T) {
+	t.Run("Nested", func(t *testing.T) {
+		q := NestedQuery("comments", TermQuery("comments.author", "a"), "max", &InnerHitsOptions{Size: 3})
+
+		assertQueryJSON(t, `{
+			"nested": {
+				"path": "comments",
+				"query": {"term": {"comments.author": "a"}},
+				"score_mode": "max",
+				"inner_hits": {"size": 3}
+			}
+		}`, q)
+	})
+
+	t.Run("HasChild", func(t *testing.T) {
+		q := HasChildQuery("answer", MatchAllQuery(), "", nil)
+
+		assertQueryJSON(t, `{"has_child": {"type": "answer", "query": {"match_all": {}}}}`, q)
+	})
+
+	t.Run("HasParent", func(t *testing.T) {
+		q := HasParentQuery("question", MatchAllQuery(), true, &InnerHitsOptions{})
+
+		assertQueryJSON(t, `{
+			"has_parent": {"parent_type": "question", "query": {"match_all": {}}, "score": true, "inner_hits": {}}
+		}`, q)
+	})
+
+	t.Run("Mapping", func(t *testing.T) {
+		assertQueryJSON(t, `{
+			"type": "join",
+			"relations": {"question": "answer", "answer": ["vote", "comment"]}
+		}`, JoinFieldMapping(map[string][]string{
+			"question": {"answer"},
+			"answer":   {"vote", "comment"},
+		}))
+	})
+}
+
+func TestJoinDocuments(t *testing.T) {
+	es := newElasticsearch()
+
+	templates, _ := json.Marshal(map[string]interface{}{
+		"index_patterns": []string{joinIndexName},
+		"template": map[string]interface{}{
+			"mappings": map[string]interface{}{
+				"properties": map[string]interface{}{
+					"id":       map[string]interface{}{"type": "keyword"},
+					"relation": JoinFieldMapping(map[string][]string{"question": {"answer"}}),
+				},
+			},
+		},
+	})
+	if _, err := es.CreateIndexTemplate(joinIndexName, string(templates)); err != nil {
+		t.FailNow()
+	}
+	defer es.DeleteIndeces(joinIndexName)
+
+	questionID := faker.UUIDDigit()
+	es.CreateDocument(&Document{
+		Index: joinIndexName,
+		ID:    questionID,
+		Body: QuestionDocBody{
+			Id:       questionID,
+			Text:     faker.Sentence(),
+			Relation: JoinField{Name: "question"},
+		},
+	})
+
+	answerID := faker.UUIDDigit()
+	status, err := es.CreateDocument(NewChildDocument(joinIndexName, answerID, questionID, QuestionDocBody{
+		Id:       answerID,
+		Text:     faker.Sentence(),
+		Relation: JoinField{Name: "answer", Parent: questionID},
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, StatusCreated, status)
+	es.Refresh(joinIndexName)
+
+	t.Run("HasChild", func(t *testing.T) {
+		var list []QuestionDocBody
+		status, hits, total, err := es.Search(joinIndexName, SearchBody(
+			HasChildQuery("answer", TermQuery("id", answerID), "", nil),
+		), &list)
+
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+		assert.Equal(t, 1, total)
+		assert.Equal(t, questionID, hits[0].Id)
+	})
+
+	t.Run("HasParent", func(t *testing.T) {
+		var list []QuestionDocBody
+		status, hits, total, err := es.Search(joinIndexName, SearchBody(
+			HasParentQuery("question", TermQuery("id", questionID), false, nil),
+		), &list)
+
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+		assert.Equal(t, 1, total)
+		assert.Equal(t, answerID, hits[0].Id)
+		assert.Equal(t, questionID, hits[0].Routing)
+	})
+
+	t.Run("Remove child", func(t *testing.T) {
+		status, err := es.RemoveDocument(&Document{
+			Index:   joinIndexName,
+			ID:      answerID,
+			Routing: questionID,
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+	})
+}
+
+type CommentBody struct {
+	Author string `json:"author"`
+	Text   string `json:"text"`
+}
+
+type PostDocBody struct {
+	Id       string        `json:"id"`
+	Comments []CommentBody `json:"comments"`
+}
+
+func TestInnerHitsUnmarshalJSON(t *testing.T) {
+	var innerHits map[string]*InnerHits
+	err := json.Unmarshal([]byte(`{
+		"comments": {
+			"hits": {
+				"total": {"value": 1, "relation": "eq"},
+				"hits": [{
+					"_index": "posts",
+					"_type": "_doc",
+					"_id": "1",
+					"_nested": {"field": "comments", "offset": 2},
+					"_score": 1.5,
+					"_source": {"author": "a", "text": "hello"}
+				}]
+			}
+		}
+	}`), &innerHits)
+	assert.NoError(t, err)
+
+	comments := innerHits["comments"]
+	assert.Equal(t, 1, comments.Total)
+	assert.Equal(t, "1", comments.Hits[0].Id)
+	assert.Equal(t, "comments", comments.Hits[0].Nested.Field)
+	assert.Equal(t, 2, comments.Hits[0].Nested.Offset)
+	assert.Equal(t, 1.5, comments.Hits[0].Score)
+
+	var list []CommentBody
+	assert.NoError(t, comments.Decode(&list))
+	assert.Equal(t, []CommentBody{{Author: "a", Text: "hello"}}, list)
+}
+
+func TestSearchInnerHits(t *testing.T) {
+	es := newElasticsearch()
+	index := joinIndexName + "-nested"
+
+	templates, _ := json.Marshal(map[string]interface{}{
+		"index_patterns": []string{index},
+		"template": map[string]interface{}{
+			"mappings": map[string]interface{}{
+				"properties": map[string]interface{}{
+					"id":       map[string]interface{}{"type": "keyword"},
+					"comments": map[string]interface{}{"type": "nested"},
+				},
+			},
+		},
+	})
+	if _, err := es.CreateIndexTemplate(index, string(templates)); err != nil {
+		t.FailNow()
+	}
+	defer es.DeleteIndeces(index)
+
+	post := PostDocBody{
+		Id: faker.UUIDDigit(),
+		Comments: []CommentBody{
+			{Author: "alice", Text: faker.Sentence()},
+			{Author: "bob", Text: faker.Sentence()},
+		},
+	}
+	es.CreateDocument(&Document{
+		Index:   index,
+		ID:      post.Id,
+		Body:    post,
+		Refresh: RefreshTrue,
+	})
+
+	var list []PostDocBody
+	status, hits, total, err := es.Search(index, SearchBody(
+		NestedQuery("comments", MatchQuery("comments.author", "bob"), "", &InnerHitsOptions{Name: "matched"}),
+	), &list)
+
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSuccess, status)
+	assert.Equal(t, 1, total)
+
+	matched := hits[0].InnerHits["matched"]
+	assert.Equal(t, 1, matched.Total)
+	assert.Equal(t, 1, matched.Hits[0].Nested.Offset)
+
+	var comments []CommentBody
+	assert.NoError(t, matched.Decode(&comments))
+	assert.Equal(t, post.Comments[1], comments[0])
+}
@@ -0,0 +1,179 @@
+package elasticsearch
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrClientClosed is returned, wrapped in a *RequestError, by the requests made after Close.
+var ErrClientClosed = errors.New("elasticsearch: client is closed")
+
+// ErrDrainTimeout is returned by Close when the pending writes and the requests in flight did not complete
+// within Config.DrainTimeout.
+var ErrDrainTimeout = errors.New("elasticsearch: drain timeout exceeded")
+
+// lifecycle is the state of a client shared by its copies, closed by Close.
+type lifecycle struct {
+	mu      sync.Mutex
+	closers []*closer
+	closing bool
+	// closed is set once the closers ran, and makes the requests fail with ErrClientClosed.
+	closed int32
+	done   chan struct{}
+	// idle closes the idle connections of the HTTP transport, if it can.
+	idle interface{ CloseIdleConnections() }
+	// drainTimeout is Config.DrainTimeout.
+	drainTimeout time.Duration
+}
+
+// closer is a function registered with onClose, compared by pointer to be deregistered.
+type closer struct {
+	fn func()
+}
+
+func newLifecycle() *lifecycle {
+	return &lifecycle{done: make(chan struct{})}
+}
+
+// onClose registers fn to be called by Close before the client stops sending requests,
+// and returns the function deregistering it.
+func (l *lifecycle) onClose(fn func()) func() {
+	c := &closer{fn: fn}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.closers = append(l.closers, c)
+
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		for i, other := range l.closers {
+			if other == c {
+				// A new array, as Close may be running the closers of the old one.
+				l.closers = append(l.closers[:i:i], l.closers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Close stops the client gracefully, for the shutdown of a service: it closes the AsyncIndexers of the client,
+// which flush their pending items, stops its HealthCheckers, and stops its WriteAheadLogs after a last Replay,
+// then makes the new requests fail with ErrClientClosed, stops the periodic node discovery, waits for the
+// requests in flight, and closes the idle connections. It waits at most Config.DrainTimeout: the writes of
+// a WriteAheadLog not replayed by then stay in its log.
+// It is safe to call concurrently and more than once, and shared by the copies of the client.
+func (es *_elasticsearch) Close() error {
+	l := es.client.lifecycle
+
+	l.mu.Lock()
+	if l.closing {
+		l.mu.Unlock()
+		<-l.done
+		return nil
+	}
+	l.closing = true
+	closers := l.closers
+	l.mu.Unlock()
+
+	var deadline <-chan time.Time
+	if l.drainTimeout > 0 {
+		timer := time.NewTimer(l.drainTimeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	drained := make(chan struct{})
+	go func() {
+		defer close(drained)
+		for i := len(closers) - 1; i >= 0; i-- {
+			closers[i].fn()
+		}
+	}()
+
+	expired := false
+	select {
+	case <-drained:
+	case <-deadline:
+		expired = true
+	}
+	atomic.StoreInt32(&l.closed, 1)
+
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for !expired && atomic.LoadInt64(&es.client.inFlight) > 0 {
+		select {
+		case <-ticker.C:
+		case <-deadline:
+			expired = true
+		}
+	}
+	if l.idle != nil {
+		l.idle.CloseIdleConnections()
+	}
+	close(l.done)
+
+	if expired {
+		es.logger.Warnf("Closed the client after the drain timeout of %s with %d requests in flight", l.drainTimeout, atomic.LoadInt64(&es.client.inFlight))
+		return ErrDrainTimeout
+	}
+	return nil
+}
+
+// closeOnDone closes the client when ctx is done, unless it is closed before.
+func (es *_elasticsearch) closeOnDone(ctx context.Context) {
+	select {
+	case <-ctx.Done():
+		es.Close()
+	case <-es.client.lifecycle.done:
+	}
+}
+
+// discoverNodes discovers the nodes of the cluster every interval until the client is closed.
+// The periodic discovery of go-elasticsearch cannot be stopped, so it is done here instead.
+func (es *_elasticsearch) discoverNodes(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-es.client.lifecycle.done:
+			return
+		case <-ticker.C:
+		}
+		if atomic.LoadInt32(&es.client.lifecycle.closed) == 1 {
+			return
+		}
+		if err := es.client.es.DiscoverNodes(); err != nil {
+			es.logger.Warnf("Error discovering nodes: %s", err)
+		}
+	}
+}
+
+// onClose registers fn to be called by the Close of the client behind es, through the decorators of this package,
+// and returns the function deregistering it, to be called when the component of fn is closed on its own.
+// It does nothing when es is not such a client, e.g. a Fake.
+func onClose(es Elasticsearch, fn func()) func() {
+	e, ok := base(es)
+	if !ok {
+		return func() {}
+	}
+	return e.client.lifecycle.onClose(fn)
+}
+
+// base returns the client behind es, through the decorators of this package.
+// It reports false when es is not such a client, e.g. a Fake.
+func base(es Elasticsearch) (*_elasticsearch, bool) {
+	for {
+		switch e := es.(type) {
+		case *_elasticsearch:
+			return e, true
+		case interface{ unwrap() Elasticsearch }:
+			es = e.unwrap()
+		default:
+			return nil, false
+		}
+	}
+}
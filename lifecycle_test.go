@@ -0,0 +1,134 @@
+package elasticsearch
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClose(t *testing.T) {
+	server, requests := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/_bulk"):
+			w.Write([]byte(`{"items":[{"index":{"_index":"a","_id":"1","status":201}}]}`))
+		default:
+			w.Write([]byte(`{}`))
+		}
+	})
+
+	t.Run("Drains and rejects requests", func(t *testing.T) {
+		es, err := New(&Config{Address: []string{server.URL}, Logger: NopLogger()})
+		assert.NoError(t, err)
+
+		indexer := NewAsyncIndexer(NewCache(es, nil), &AsyncIndexerConfig{FlushInterval: time.Hour})
+		assert.NoError(t, indexer.Add(context.Background(), &BulkItem{Action: BulkIndex, Index: "a", ID: "1", Body: `{}`}))
+
+		hc, err := NewHealthChecker(es, &HealthCheckerConfig{Interval: time.Hour, Logger: NopLogger()})
+		assert.NoError(t, err)
+		stopped := make(chan struct{})
+		go func() {
+			hc.Run(context.Background())
+			close(stopped)
+		}()
+
+		assert.NoError(t, es.Close())
+
+		var bulk int
+		for _, r := range requests() {
+			if strings.HasSuffix(r.URL.Path, "/_bulk") {
+				bulk++
+			}
+		}
+		assert.Equal(t, 1, bulk)
+		select {
+		case <-stopped:
+		case <-time.After(time.Second):
+			t.Fatal("HealthChecker.Run did not return")
+		}
+
+		err = es.Ping()
+		assert.True(t, errors.Is(err, ErrClientClosed), err)
+		status, err := es.WithContext(context.Background()).CreateDocument(&Document{Index: "a", ID: "2", Body: `{}`})
+		assert.Equal(t, StatusRequestError, status)
+		assert.True(t, errors.Is(err, ErrClientClosed), err)
+
+		assert.NoError(t, es.Close())
+	})
+
+	t.Run("Context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		es, err := New(&Config{Address: []string{server.URL}, Context: ctx, Logger: NopLogger()})
+		assert.NoError(t, err)
+		hc, _ := NewHealthChecker(es, &HealthCheckerConfig{Interval: time.Hour, Logger: NopLogger()})
+		stopped := make(chan struct{})
+		go func() {
+			hc.Run(context.Background())
+			close(stopped)
+		}()
+		assert.NoError(t, es.Ping())
+
+		cancel()
+		select {
+		case <-stopped:
+		case <-time.After(time.Second):
+			t.Fatal("HealthChecker.Run did not return")
+		}
+		assert.NoError(t, es.Close())
+		assert.True(t, errors.Is(es.Ping(), ErrClientClosed))
+	})
+
+	t.Run("DrainTimeout", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+		server, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasSuffix(r.URL.Path, "/_bulk") {
+				<-release
+			}
+			w.Write([]byte(`{}`))
+		})
+
+		es, err := New(&Config{Address: []string{server.URL}, DrainTimeout: 50 * time.Millisecond, DisableRetry: true, Logger: NopLogger()})
+		assert.NoError(t, err)
+		indexer := NewAsyncIndexer(es, &AsyncIndexerConfig{FlushInterval: time.Hour})
+		assert.NoError(t, indexer.Add(context.Background(), &BulkItem{Action: BulkIndex, Index: "a", ID: "1", Body: `{}`}))
+
+		start := time.Now()
+		assert.ErrorIs(t, es.Close(), ErrDrainTimeout)
+		assert.Less(t, time.Since(start), time.Second)
+		assert.True(t, errors.Is(es.Ping(), ErrClientClosed))
+	})
+
+	t.Run("Deregistered", func(t *testing.T) {
+		es, err := New(&Config{Address: []string{server.URL}, Logger: NopLogger()})
+		assert.NoError(t, err)
+		l := es.(*_elasticsearch).client.lifecycle
+
+		for i := 0; i < 3; i++ {
+			NewAsyncIndexer(es, &AsyncIndexerConfig{}).Close()
+
+			hc, err := NewHealthChecker(NewCache(es, nil), &HealthCheckerConfig{Logger: NopLogger()})
+			assert.NoError(t, err)
+			hc.Stop()
+
+			wal, err := OpenWriteAheadLog(es, &WriteAheadLogConfig{Path: filepath.Join(t.TempDir(), "writes.log"), Logger: NopLogger()})
+			assert.NoError(t, err)
+			assert.NoError(t, wal.Close())
+		}
+		assert.Empty(t, l.closers)
+
+		indexer := NewAsyncIndexer(es, &AsyncIndexerConfig{})
+		assert.Len(t, l.closers, 1)
+		assert.NoError(t, es.Close())
+		assert.ErrorIs(t, indexer.Add(context.Background(), &BulkItem{Index: "a"}), ErrIndexerClosed)
+	})
+
+	t.Run("Fake", func(t *testing.T) {
+		assert.NoError(t, NewFake().Close())
+	})
+}
@@ -0,0 +1,69 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"log"
+)
+
+// Logger receives the logs of the client. Set Config.Logger to send them elsewhere
+// than the standard log package, e.g. to a structured logger.
+type Logger interface {
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+type LogLevel int
+
+const (
+	LevelDebug LogLevel = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+	// LevelNone disables all logs.
+	LevelNone
+)
+
+// NewStdLogger returns a Logger writing the logs of level and above to l,
+// or to the standard logger when l is nil.
+func NewStdLogger(l *log.Logger, level LogLevel) Logger {
+	return &stdLogger{logger: l, level: level}
+}
+
+// NopLogger discards all logs.
+func NopLogger() Logger {
+	return NewStdLogger(nil, LevelNone)
+}
+
+type stdLogger struct {
+	logger *log.Logger
+	level  LogLevel
+}
+
+func (l *stdLogger) logf(level LogLevel, format string, args ...interface{}) {
+	if level < l.level {
+		return
+	}
+	if l.logger == nil {
+		log.Output(3, fmt.Sprintf(format, args...))
+		return
+	}
+	l.logger.Output(3, fmt.Sprintf(format, args...))
+}
+
+func (l *stdLogger) Debugf(format string, args ...interface{}) {
+	l.logf(LevelDebug, format, args...)
+}
+
+func (l *stdLogger) Infof(format string, args ...interface{}) {
+	l.logf(LevelInfo, format, args...)
+}
+
+func (l *stdLogger) Warnf(format string, args ...interface{}) {
+	l.logf(LevelWarn, format, args...)
+}
+
+func (l *stdLogger) Errorf(format string, args ...interface{}) {
+	l.logf(LevelError, format, args...)
+}
@@ -0,0 +1,73 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingLogger struct {
+	mu   sync.Mutex
+	logs []string
+}
+
+func (l *recordingLogger) record(level, format string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.logs = append(l.logs, level+" "+fmt.Sprintf(format, args...))
+}
+
+func (l *recordingLogger) Debugf(format string, args ...interface{}) {
+	l.record("DEBUG", format, args...)
+}
+func (l *recordingLogger) Infof(format string, args ...interface{}) {
+	l.record("INFO", format, args...)
+}
+func (l *recordingLogger) Warnf(format string, args ...interface{}) {
+	l.record("WARN", format, args...)
+}
+func (l *recordingLogger) Errorf(format string, args ...interface{}) {
+	l.record("ERROR", format, args...)
+}
+
+func TestStdLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewStdLogger(log.New(&buf, "", 0), LevelWarn)
+
+	logger.Debugf("debug")
+	logger.Infof("info")
+	logger.Warnf("warn %d", 1)
+	logger.Errorf("error %d", 2)
+
+	assert.Equal(t, "warn 1\nerror 2\n", buf.String())
+}
+
+func TestConfigLogger(t *testing.T) {
+	server, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			w.Write([]byte(`{}`))
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error": {"type": "parsing_exception", "reason": "unknown query [foo]"}, "status": 400}`))
+	})
+
+	logger := &recordingLogger{}
+	es, err := New(&Config{
+		Address: []string{server.URL},
+		Logger:  logger,
+	})
+	assert.NoError(t, err)
+
+	status, _, err := es.Count("x", `{"query": {"foo": {}}}`)
+
+	assert.Error(t, err)
+	assert.Equal(t, StatusBadRequestError, status)
+	assert.Len(t, logger.logs, 1)
+	assert.Contains(t, logger.logs[0], "ERROR [400 Bad Request] Error getting count")
+}
@@ -6,10 +6,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
+	"io"
 	"strings"
+	"time"
 
-	goElasticsearch "github.com/elastic/go-elasticsearch/v7"
 	"github.com/elastic/go-elasticsearch/v7/esapi"
 )
 
@@ -28,12 +28,6 @@ const (
 	StatusError           StatusCode = 599
 )
 
-type Config struct {
-	Address []string
-	CloudID string
-	APIKey  string
-}
-
 // https://www.elastic.co/guide/en/elasticsearch/reference/current/docs-refresh.html
 type RefreshPolicy string
 
@@ -43,82 +37,338 @@ const (
 	RefreshWaitFor RefreshPolicy = "wait_for"
 )
 
+// Document is the target of a write. The empty options take the WriteDefaults of Config.
 type Document struct {
-	Index   string
-	ID      string
+	Index string
+	ID    string
+	// Body is marshaled to JSON, except a json.RawMessage, []byte or io.Reader holding
+	// serialized JSON, which is sent as is.
 	Body    interface{}
 	Refresh RefreshPolicy
+	Routing string
+	// WaitForActiveShards is the number of shard copies that must be active before writing, or "all".
+	WaitForActiveShards string
+	// Pipeline is the ingest pipeline of the document, or NoPipeline, ignored by UpdateDocument and RemoveDocument.
+	Pipeline string
+	// IfSeqNo and IfPrimaryTerm make the write fail with ErrConflict unless the document was last changed
+	// by the write of the DocumentMeta returned by GetDocument, for optimistic concurrency control.
+	IfSeqNo       *int64
+	IfPrimaryTerm *int64
+	// RetryOnConflict is the number of times UpdateDocument retries the update when the document is changed
+	// concurrently, e.g. for counters incremented by several writers. It cannot be used with IfSeqNo.
+	RetryOnConflict int
+}
+
+// casParams returns the if_seq_no and if_primary_term of doc for esapi.
+func (doc *Document) casParams() (*int, *int) {
+	var seqNo, primaryTerm *int
+	if doc.IfSeqNo != nil {
+		n := int(*doc.IfSeqNo)
+		seqNo = &n
+	}
+	if doc.IfPrimaryTerm != nil {
+		n := int(*doc.IfPrimaryTerm)
+		primaryTerm = &n
+	}
+	return seqNo, primaryTerm
+}
+
+// marshalBody returns the JSON of a document body. json.RawMessage, []byte and io.Reader
+// bodies are already serialized and returned as is.
+func marshalBody(codec Codec, body interface{}) ([]byte, error) {
+	switch b := body.(type) {
+	case json.RawMessage:
+		return b, nil
+	case []byte:
+		return b, nil
+	case io.Reader:
+		return io.ReadAll(b)
+	}
+	return codec.Marshal(body)
+}
+
+// marshalUpdateBody returns the body of an update request merging the fields of body into the document.
+func marshalUpdateBody(codec Codec, body interface{}) ([]byte, error) {
+	doc, err := marshalBody(codec, body)
+	if err != nil {
+		return nil, err
+	}
+	return updateBody(doc), nil
 }
 
-// for UpdateRequest
-type documentBody struct {
-	Doc interface{} `json:"doc"`
+// updateBody returns the body of an update request merging the serialized fields into the document.
+func updateBody(fields []byte) []byte {
+	// https://discuss.elastic.co/t/updating-elasticsearch-document/265705
+	b := make([]byte, 0, len(fields)+8)
+	b = append(b, `{"doc":`...)
+	b = append(b, fields...)
+	return append(b, '}')
 }
 
 type HitData struct {
-	Index string        `json:"_index"`
-	Type  string        `json:"_type"`
-	Id    string        `json:"_id"`
-	Score float64       `json:"_score"`
-	Sort  []interface{} `json:"sort"`
+	Index   string `json:"_index"`
+	Type    string `json:"_type"`
+	Id      string `json:"_id"`
+	Routing string `json:"_routing"`
+	// Version is returned when the search body has "version": true.
+	Version   int64                 `json:"_version"`
+	Nested    *NestedIdentity       `json:"_nested"`
+	Score     float64               `json:"_score"`
+	Sort      []interface{}         `json:"sort"`
+	InnerHits map[string]*InnerHits `json:"inner_hits"`
+	// Source is the raw _source of the hit, e.g. to decode the hits of several indices into different types.
+	Source json.RawMessage `json:"_source"`
+}
+
+type SearchResult struct {
+	Hits  []*HitData
+	Total int
+	// TimedOut is true when the search stopped at the Timeout option with the hits collected until then.
+	TimedOut bool
+	// TotalRelation is "eq" when Total is exact and "gte" when it is a lower bound.
+	TotalRelation string
+	Suggest       map[string][]*SuggestEntry
+	Aggregations  map[string]json.RawMessage
+	// Shards reports the shards searched. A search failing on some shards only succeeds
+	// with the hits of the other shards, and Shards.Failures tells why.
+	Shards *ShardsInfo
+	// Clusters reports the clusters searched, and is nil unless the search targets a remote index.
+	Clusters *ClustersInfo
+	// PITID is the ID of the point in time of a search with the PIT option, to be used by the next search.
+	PITID string
+	// Profile is the profile of a search with the Profile option.
+	Profile *ProfileResult
+}
+
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/search-search.html#search-api-response-body
+type ShardsInfo struct {
+	Total      int             `json:"total"`
+	Successful int             `json:"successful"`
+	Skipped    int             `json:"skipped"`
+	Failed     int             `json:"failed"`
+	Failures   []*ShardFailure `json:"failures,omitempty"`
 }
 
+// Elasticsearch is the client of this package: the union of DocumentReader, DocumentWriter,
+// IndexAdmin and ClusterAdmin, which services may depend on instead, to mock only what they use.
 type Elasticsearch interface {
-	Refresh(index ...string) error
+	DocumentReader
+	DocumentWriter
+	IndexAdmin
+	ClusterAdmin
+
+	WithContext(ctx context.Context) Elasticsearch
+	WithIndexPrefix(prefix string) Elasticsearch
+	WithPreference(preference string) Elasticsearch
+
 	Ping() error
+	Stats() *ClientStats
+	Close() error
+}
 
-	CreateIndexTemplate(name, templates string) (StatusCode, error)
+// DocumentReader gets, searches and counts documents.
+type DocumentReader interface {
+	GetSource(index string, id string, result any, opts ...GetOption) (StatusCode, error)
+	GetDocument(index, id string, result any, opts ...GetOption) (StatusCode, *DocumentMeta, error)
+	Search(index string, query string, data interface{}, opts ...SearchOption) (StatusCode, []*HitData, int, error)
+	SearchWithResult(index string, query string, data interface{}, opts ...SearchOption) (StatusCode, *SearchResult, error)
+	SearchPage(index, query string, page Page, data interface{}, opts ...SearchOption) (StatusCode, []*HitData, *PageInfo, error)
+	SearchStream(index, query string, fn func(hit *HitData) error, opts ...SearchOption) (StatusCode, int, error)
+	Export(ctx context.Context, index, query string, w io.Writer, opts ...ExportOption) (StatusCode, int, error)
+	OpenPIT(index string, keepAlive time.Duration) (StatusCode, string, error)
+	ClosePIT(id string) (StatusCode, error)
+	Count(index string, query string, opts ...SearchOption) (StatusCode, int, error)
+	Autocomplete(index, field, prefix string, size int) (StatusCode, []*Completion, error)
+	Suggest(index, field, text string) (StatusCode, *Suggestions, error)
+	MoreLikeThis(index, id string, fields []string, data interface{}, opts ...MoreLikeThisOption) (StatusCode, []*HitData, int, error)
+	Explain(index, id, query string) (StatusCode, bool, *Explanation, error)
+	ValidateQuery(index, query string) (StatusCode, *QueryValidation, error)
+	TermVectors(index, id string, fields []string) (StatusCode, map[string]*TermVector, error)
+	MTermVectors(index string, ids []string, fields []string) (StatusCode, map[string]map[string]*TermVector, error)
+	TermsEnum(index, field, prefix string, size int) (StatusCode, []string, bool, error)
+	SearchWithTemplate(index, templateID string, params map[string]any, data interface{}) (StatusCode, []*HitData, int, error)
+	ScanCompositeAggregation(index, query string, agg *CompositeAggregation, fn func(bucket *CompositeBucket) error) (StatusCode, error)
+}
+
+// DocumentWriter creates, updates and deletes documents.
+type DocumentWriter interface {
 	CreateDocument(doc *Document) (StatusCode, error)
 	UpdateDocument(doc *Document) (StatusCode, error)
 	RemoveDocument(doc *Document) (StatusCode, error)
+	Bulk(items []*BulkItem, refresh RefreshPolicy) (StatusCode, []*BulkItemResult, error)
+	LoadFixtures(index string, r io.Reader) (StatusCode, error)
+	BulkFromNDJSON(ctx context.Context, index string, r io.Reader, opts ...BulkStreamOption) (StatusCode, int, error)
+	BulkFromCSV(ctx context.Context, index string, r io.Reader, mapping *CSVMapping, opts ...BulkStreamOption) (StatusCode, int, error)
+	DeleteByQuery(index, query string, opts ...ByQueryOption) (StatusCode, int, error)
+	UpdateByQuery(index, query string, opts ...ByQueryOption) (StatusCode, int, error)
+}
 
-	Search(index string, query string, data interface{}) (StatusCode, []*HitData, int, error)
-	GetSource(index string, id string, result any) (int, error)
-	Count(index string, query string) (StatusCode, int, error)
-
+// IndexAdmin manages indices, their templates and aliases.
+type IndexAdmin interface {
+	Refresh(index ...string) error
+	RefreshIndices(index string, opts ...RefreshOption) (StatusCode, *ShardsInfo, error)
+	CreateIndexTemplate(name, templates string) (StatusCode, error)
+	DeleteIndexTemplate(name string) (StatusCode, error)
+	CreateIndex(index, body string) (StatusCode, error)
+	OpenIndex(index string) (StatusCode, error)
+	CloseIndex(index string) (StatusCode, error)
+	AddIndexBlock(index string, block IndexBlock) (StatusCode, error)
+	RemoveIndexBlock(index string, block IndexBlock) (StatusCode, error)
+	ShrinkIndex(source, target string, shards int, wait time.Duration) (StatusCode, error)
+	SplitIndex(source, target string, shards int, wait time.Duration) (StatusCode, error)
+	CloneIndex(source, target string, wait time.Duration) (StatusCode, error)
+	ForceMerge(index string, maxNumSegments int) (StatusCode, error)
+	Flush(index string) (StatusCode, error)
+	ClearCache(index string) (StatusCode, error)
+	SwapAlias(alias, from, to string) (StatusCode, error)
+	PutAlias(index, alias string, options *Alias) (StatusCode, error)
+	DeleteAlias(index, alias string) (StatusCode, error)
+	Reindex(source, dest string) (StatusCode, int, error)
+	DeleteIndices(index string, opts ...DeleteIndicesOption) (StatusCode, error)
+	// Deprecated: use DeleteIndices.
 	DeleteIndeces(index ...string) (StatusCode, error)
 }
 
-func New(config *Config) Elasticsearch {
-	return &_elasticsearch{client: connectElasticsearch(config)}
+// ClusterAdmin manages the cluster: its settings, shards, remote clusters, pipelines, watches,
+// search templates and security.
+type ClusterAdmin interface {
+	GetClusterSettings(includeDefaults bool) (StatusCode, *ClusterSettings, error)
+	PutClusterSettings(settings *ClusterSettings) (StatusCode, error)
+	ClusterHealth() (StatusCode, *ClusterHealth, error)
+	Info() (StatusCode, *ClusterInfo, error)
+	Version() (StatusCode, *ServerVersion, error)
+	AllocationExplain(index string, shard int, primary bool) (StatusCode, *AllocationExplanation, error)
+	Reroute(opts RerouteOptions, commands ...RerouteCommand) (StatusCode, []*RerouteExplanation, error)
+	PutRemoteCluster(name string, skipUnavailable bool, seeds ...string) (StatusCode, error)
+	DeleteRemoteCluster(name string) (StatusCode, error)
+	GetRemoteClusters() (StatusCode, map[string]*RemoteCluster, error)
+
+	PutPipeline(id, body string) (StatusCode, error)
+	DeletePipeline(id string) (StatusCode, error)
+	PutWatch(id, body string, active bool) (StatusCode, error)
+	GetWatch(id string) (StatusCode, *Watch, error)
+	DeleteWatch(id string) (StatusCode, error)
+	ExecuteWatch(id string, ignoreCondition bool) (StatusCode, *WatchRecord, error)
+	PutSearchTemplate(id, source string) (StatusCode, error)
+	DeleteSearchTemplate(id string) (StatusCode, error)
+
+	CreateAPIKey(key *APIKeyRequest) (StatusCode, *APIKey, error)
+	InvalidateAPIKey(ids ...string) (StatusCode, int, error)
+	GetAPIKeys(name string) (StatusCode, []*APIKeyInfo, error)
+	PutRole(name string, role *Role) (StatusCode, error)
+	GetRoles(names ...string) (StatusCode, map[string]*Role, error)
+	DeleteRole(name string) (StatusCode, error)
+	PutUser(username string, user *User) (StatusCode, error)
+	GetUsers(usernames ...string) (StatusCode, map[string]*User, error)
+	DeleteUser(username string) (StatusCode, error)
+	PutRoleMapping(name string, mapping *RoleMapping) (StatusCode, error)
+	GetRoleMappings(names ...string) (StatusCode, map[string]*RoleMapping, error)
+	DeleteRoleMapping(name string) (StatusCode, error)
 }
 
-func (es *_elasticsearch) Ping() error {
-	_, err := es.client.Ping()
-	return err
+func New(config *Config, opts ...Option) (Elasticsearch, error) {
+	config = config.apply(opts)
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	client, transport, err := connectElasticsearch(config)
+	if err != nil {
+		return nil, err
+	}
+
+	es := &_elasticsearch{
+		client:          newAPIClient(client, config.AWS != nil, config.middlewares()...),
+		ctx:             context.Background(),
+		logger:          config.logger(),
+		metrics:         config.Metrics,
+		prefix:          config.IndexPrefix,
+		preference:      config.Preference,
+		disallowPartial: config.DisallowPartialResults,
+		defaults:        config.WriteDefaults,
+		codec:           config.codec(),
+		deletable:       config.DeletableIndices,
+		hooks:           config.writeHooks(),
+	}
+	if idle, ok := transport.(interface{ CloseIdleConnections() }); ok {
+		es.client.lifecycle.idle = idle
+	}
+	es.client.lifecycle.drainTimeout = config.DrainTimeout
+	es.client.driver = config.Driver
+
+	if config.WaitForReady {
+		if err := es.waitForReady(config.ReadyRetries, config.ReadyBackoff); err != nil {
+			return nil, err
+		}
+	}
+
+	if config.DiscoverNodesInterval > 0 {
+		go es.discoverNodes(config.DiscoverNodesInterval)
+	}
+	if config.Context != nil {
+		go es.closeOnDone(config.Context)
+	}
+
+	return es, nil
 }
 
-func (es *_elasticsearch) CreateIndexTemplate(name, templates string) (StatusCode, error) {
-	req := esapi.IndicesPutIndexTemplateRequest{
-		Body: strings.NewReader(templates),
-		Name: name,
+func (es *_elasticsearch) waitForReady(retries int, backoff time.Duration) error {
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+
+	var err error
+	for i := 0; ; i++ {
+		if err = es.ping(); err == nil {
+			return nil
+		}
+		if i >= retries {
+			return fmt.Errorf("cluster is not ready: %w", err)
+		}
+		es.logger.Warnf("Cluster is not ready, retrying in %s: %s", backoff, err)
+		time.Sleep(backoff)
+		backoff *= 2
 	}
+}
 
-	res, err := req.Do(context.Background(), es.client)
+// ping fails on transport errors and on error responses, unlike Ping.
+func (es *_elasticsearch) ping() error {
+	res, err := es.client.Ping(es.client.Ping.WithContext(es.ctx))
+	_, err = es.handleResponse("ping", res, err, nil)
+	return err
+}
 
+func (es *_elasticsearch) Ping() error {
+	res, err := es.client.Ping(es.client.Ping.WithContext(es.ctx))
 	if err != nil {
+		return &RequestError{Err: err}
+	}
+	res.Body.Close()
+	return nil
+}
+
+func (es *_elasticsearch) CreateIndexTemplate(name, templates string) (StatusCode, error) {
+	if err := es.requireVersion("composable index template", 7, 8); err != nil {
 		return StatusInternalError, err
 	}
 
-	defer res.Body.Close()
+	templates, err := es.prefixTemplate(templates)
+	if err != nil {
+		return StatusInternalError, err
+	}
 
-	if res.IsError() {
-		log.Printf("[%s] Error Create Index Template %s", res.Status(), templates)
-		switch res.StatusCode {
-		case 400:
-			return StatusBadRequestError, errors.New("bad request")
-		}
-		return StatusError, err
+	req := esapi.IndicesPutIndexTemplateRequest{
+		Body: strings.NewReader(templates),
+		Name: es.prefix + name,
 	}
 
-	return StatusSuccess, err
+	res, err := req.Do(es.ctx, es.client)
+	return es.handleResponse("create index template "+name, res, err, nil)
 }
 
+// Refresh refreshes the indices, all of them when none is given. See RefreshIndices.
 func (es *_elasticsearch) Refresh(index ...string) error {
-	_, err := es.client.Indices.Refresh(func(req *esapi.IndicesRefreshRequest) {
-		req.Index = index
-	})
-
+	_, _, err := es.RefreshIndices(strings.Join(index, ","))
 	return err
 }
 
@@ -127,44 +377,52 @@ func (es *_elasticsearch) CreateDocument(doc *Document) (StatusCode, error) {
 		return StatusInternalError, errors.New("Required body")
 	}
 
-	body, err := json.Marshal(doc.Body)
+	body, err := marshalBody(es.codec, doc.Body)
 	if err != nil {
 		return StatusInternalError, err
 	}
 
-	req := esapi.IndexRequest{
-		Index:      doc.Index,
-		DocumentID: doc.ID,
-		Body:       bytes.NewReader(body),
-		Refresh:    string(doc.Refresh),
+	doc = es.withDefaults(doc)
+	if doc.ID == "" && es.defaults.ID != nil {
+		if doc.ID, err = es.defaults.ID(doc.Index, body); err != nil {
+			return StatusInternalError, err
+		}
+	}
+	event := &WriteEvent{Action: BulkIndex, Index: doc.Index, ID: doc.ID, Routing: doc.Routing, Body: body}
+	if err := es.beforeWrite([]*WriteEvent{event}); err != nil {
+		return StatusInternalError, err
 	}
 
-	res, err := req.Do(context.Background(), es.client)
-	if err != nil {
-		log.Printf("Error getting response: %s", err)
-		return StatusRequestError, err
+	ifSeqNo, ifPrimaryTerm := doc.casParams()
+	req := esapi.IndexRequest{
+		Index:               es.indexName(doc.Index),
+		DocumentID:          doc.ID,
+		Body:                bytes.NewReader(event.Body),
+		Refresh:             string(doc.Refresh),
+		Routing:             doc.Routing,
+		WaitForActiveShards: doc.WaitForActiveShards,
+		Pipeline:            doc.Pipeline,
+		IfSeqNo:             ifSeqNo,
+		IfPrimaryTerm:       ifPrimaryTerm,
 	}
-	defer res.Body.Close()
 
-	if res.IsError() {
-		log.Printf("[%s] Error indexing doc ID=%s", res.Status(), doc.ID)
-		switch res.StatusCode {
-		case 400:
-			return StatusBadRequestError, errors.New("bad request")
-		}
-		return StatusError, err
-	} else {
-		// Deserialize the response into a map.
-		var r map[string]interface{}
-		if err := json.NewDecoder(res.Body).Decode(&r); err != nil {
-			log.Printf("Error parsing the response body: %s", err)
-			return StatusUnexpectedError, nil
-		} else {
-			log.Printf("[%s] %s; version=%d ; id=%s", res.Status(), r["result"], int(r["_version"].(float64)), r["_id"])
+	res, err := req.Do(es.ctx, es.client)
+
+	var r documentResult
+	status, err := es.handleResponse("indexing doc ID="+doc.ID, res, err, &r)
+	if len(es.hooks) > 0 {
+		if r.ID != "" {
+			event.ID = r.ID
 		}
+		event.Result = r.Result
+		es.afterWrite([]*WriteEvent{event}, err)
+	}
+	if err != nil {
+		return status, err
 	}
+	es.logger.Debugf("[%s] %s; version=%d ; id=%s", res.Status(), r.Result, r.Version, r.ID)
 
-	return StatusCreated, err
+	return StatusCreated, nil
 }
 
 func (es *_elasticsearch) UpdateDocument(doc *Document) (StatusCode, error) {
@@ -172,193 +430,216 @@ func (es *_elasticsearch) UpdateDocument(doc *Document) (StatusCode, error) {
 		return StatusInternalError, errors.New("Required body")
 	}
 
-	body, err := json.Marshal(&documentBody{
-		Doc: doc.Body, // https://discuss.elastic.co/t/updating-elasticsearch-document/265705
-	})
+	fields, err := marshalBody(es.codec, doc.Body)
 	if err != nil {
 		return StatusInternalError, err
 	}
 
+	doc = es.withDefaults(doc)
+	event := &WriteEvent{Action: BulkUpdate, Index: doc.Index, ID: doc.ID, Routing: doc.Routing, Body: fields}
+	if err := es.beforeWrite([]*WriteEvent{event}); err != nil {
+		return StatusInternalError, err
+	}
+
+	ifSeqNo, ifPrimaryTerm := doc.casParams()
 	req := esapi.UpdateRequest{
-		Index:      doc.Index,
-		DocumentID: doc.ID,
-		Body:       bytes.NewReader(body),
+		Index:               es.indexName(doc.Index),
+		DocumentID:          doc.ID,
+		Body:                bytes.NewReader(updateBody(event.Body)),
+		Refresh:             string(doc.Refresh),
+		Routing:             doc.Routing,
+		WaitForActiveShards: doc.WaitForActiveShards,
+		IfSeqNo:             ifSeqNo,
+		IfPrimaryTerm:       ifPrimaryTerm,
 	}
+	if doc.RetryOnConflict > 0 {
+		req.RetryOnConflict = &doc.RetryOnConflict
+	}
+
+	res, err := req.Do(es.ctx, es.client)
 
-	res, err := req.Do(context.Background(), es.client)
+	var r documentResult
+	status, err := es.handleResponse("updating doc ID="+doc.ID, res, err, &r)
+	if len(es.hooks) > 0 {
+		event.Result = r.Result
+		es.afterWrite([]*WriteEvent{event}, err)
+	}
 	if err != nil {
-		log.Printf("Error getting response: %s", err)
-		return StatusRequestError, err
+		return status, err
 	}
-	defer res.Body.Close()
+	es.logger.Debugf("[%s] %s; version=%d ; id=%s", res.Status(), r.Result, r.Version, r.ID)
 
-	if res.IsError() {
-		log.Printf("[%s] Error indexing doc ID=%s : %s", res.Status(), doc.ID, res.String())
-		switch res.StatusCode {
-		case 400:
-			return StatusBadRequestError, errors.New("bad request")
-		}
-		return StatusError, err
-	} else {
-		// Deserialize the response into a map.
-		var r map[string]interface{}
-		if err := json.NewDecoder(res.Body).Decode(&r); err != nil {
-			log.Printf("Error parsing the response body: %s", err)
-			return StatusUnexpectedError, err
-		} else {
-			log.Printf("[%s] %s; version=%d ; id=%s", res.Status(), r["result"], int(r["_version"].(float64)), r["_id"])
-		}
-	}
-	return StatusSuccess, err
+	return StatusSuccess, nil
 }
 
 func (es *_elasticsearch) RemoveDocument(doc *Document) (StatusCode, error) {
+	doc = es.withDefaults(doc)
+	event := &WriteEvent{Action: BulkDelete, Index: doc.Index, ID: doc.ID, Routing: doc.Routing}
+	if err := es.beforeWrite([]*WriteEvent{event}); err != nil {
+		return StatusInternalError, err
+	}
+
+	ifSeqNo, ifPrimaryTerm := doc.casParams()
 	req := esapi.DeleteRequest{
-		Index:      doc.Index,
-		DocumentID: doc.ID,
+		Index:               es.indexName(doc.Index),
+		DocumentID:          doc.ID,
+		Refresh:             string(doc.Refresh),
+		Routing:             doc.Routing,
+		WaitForActiveShards: doc.WaitForActiveShards,
+		IfSeqNo:             ifSeqNo,
+		IfPrimaryTerm:       ifPrimaryTerm,
 	}
 
-	res, err := req.Do(context.Background(), es.client)
-	if err != nil {
-		log.Printf("Error getting response: %s", err)
-		return StatusRequestError, err
-	}
-	if res.IsError() {
-		log.Printf("[%s] Error indexing doc ID=%s", res.Status(), doc.Index)
-		switch res.StatusCode {
-		case 400:
-			return StatusBadRequestError, errors.New("bad request")
-		case 404:
-			return StatusNotFoundError, errors.New("not found")
-		}
-		return StatusError, err
-	} else {
-		// Deserialize the response into a map.
-		var r map[string]interface{}
-		if err := json.NewDecoder(res.Body).Decode(&r); err != nil {
-			log.Printf("Error parsing the response body: %s", err)
-			return StatusUnexpectedError, errors.New("parse error")
-		}
+	res, err := req.Do(es.ctx, es.client)
+
+	var r documentResult
+	status, err := es.handleResponse("removing doc ID="+doc.ID, res, err, &r)
+	if len(es.hooks) > 0 {
+		event.Result = r.Result
+		es.afterWrite([]*WriteEvent{event}, err)
 	}
+	return status, err
+}
 
-	return StatusSuccess, err
+// Search decodes the _source of the hits matching query into data, which should be a pointer to a slice.
+// index may be several indices, see Indices.
+func (es *_elasticsearch) Search(index string, query string, data interface{}, opts ...SearchOption) (StatusCode, []*HitData, int, error) {
+	status, result, err := es.SearchWithResult(index, query, data, opts...)
+	return status, result.Hits, result.Total, err
 }
 
-func (es *_elasticsearch) Search(index string, query string, data interface{}) (StatusCode, []*HitData, int, error) {
+func (es *_elasticsearch) SearchWithResult(index string, query string, data interface{}, opts ...SearchOption) (StatusCode, *SearchResult, error) {
 	// Perform the search request.
-	res, err := es.client.Search(
-		es.client.Search.WithContext(context.Background()),
-		es.client.Search.WithIndex(index),
-		es.client.Search.WithBody(strings.NewReader(query)),
-		es.client.Search.WithTrackTotalHits(true),
-		es.client.Search.WithPretty(),
-	)
-	defer res.Body.Close()
+	res, err := es.client.Search(es.searchRequest(index, query, opts)...)
 
-	if err != nil {
-		log.Printf("Error getting response: %s", err)
-		return StatusRequestError, []*HitData{}, 0, err
+	var r searchResponse
+	if status, err := es.handleResponse("search index="+index, res, err, &r); err != nil {
+		return status, &SearchResult{Hits: []*HitData{}}, err
 	}
 
-	if res.IsError() {
-		var e map[string]interface{}
-		if err := json.NewDecoder(res.Body).Decode(&e); err != nil {
-			log.Printf("Error parsing the response body: %s", err)
-		} else {
-			//Print the response status and error information.
-			log.Printf("[%s] %s: %s",
-				res.Status(),
-				e["error"].(map[string]interface{})["type"],
-				e["error"].(map[string]interface{})["reason"],
-			)
+	status, searchResult, err := decodeSearchResult(es.codec, &r, data)
+	for _, hit := range searchResult.Hits {
+		hit.Index = es.trimIndexPrefix(hit.Index)
+	}
+	if shards := searchResult.Shards; shards != nil && shards.Failed > 0 {
+		for _, failure := range shards.Failures {
+			failure.Index = es.trimIndexPrefix(failure.Index)
 		}
-
-		switch res.StatusCode {
-		case 400:
-			return StatusBadRequestError, []*HitData{}, 0, err
+		if err == nil && !newSearchOptions(opts).allowPartialOr(!es.disallowPartial) {
+			return StatusError, searchResult, &PartialResultsError{Shards: shards}
 		}
-		return StatusError, []*HitData{}, 0, err
+		es.logger.Warnf("Search index=%s failed on %d of %d shards", index, shards.Failed, shards.Total)
 	}
+	return status, searchResult, err
+}
 
-	var result map[string]interface{}
-	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
-		return StatusParseError, []*HitData{}, 0, err
-	}
+// searchResponse is the body of a search response. The _source of the hits is kept raw,
+// to be decoded only once into the data of the caller.
+type searchResponse struct {
+	TimedOut     bool                       `json:"timed_out"`
+	Shards       *ShardsInfo                `json:"_shards"`
+	Clusters     *ClustersInfo              `json:"_clusters"`
+	PITID        string                     `json:"pit_id"`
+	Profile      *ProfileResult             `json:"profile"`
+	Suggest      map[string][]*SuggestEntry `json:"suggest"`
+	Aggregations map[string]json.RawMessage `json:"aggregations"`
+	Hits         *struct {
+		Total *struct {
+			Value    int    `json:"value"`
+			Relation string `json:"relation"`
+		} `json:"total"`
+		Hits []*HitData `json:"hits"`
+	} `json:"hits"`
+}
 
-	if _, ok := result["hits"]; !ok {
-		return StatusNoContent, []*HitData{}, 0, nil
+// decodeSearchResult returns the hits of a search response and decodes their _source into data with codec.
+func decodeSearchResult(codec Codec, r *searchResponse, data interface{}) (StatusCode, *SearchResult, error) {
+	searchResult := &SearchResult{
+		Hits:         []*HitData{},
+		Suggest:      r.Suggest,
+		Aggregations: r.Aggregations,
+		TimedOut:     r.TimedOut,
+		Shards:       r.Shards,
+		Clusters:     r.Clusters,
+		PITID:        r.PITID,
+		Profile:      r.Profile,
 	}
 
-	total := 0
-	if t, existsTotal := result["hits"].(map[string]interface{})["total"]; existsTotal {
-		total = int(t.(map[string]interface{})["value"].(float64))
+	if r.Hits == nil {
+		return StatusNoContent, searchResult, nil
+	}
+	if r.Hits.Total != nil {
+		searchResult.Total = r.Hits.Total.Value
+		searchResult.TotalRelation = r.Hits.Total.Relation
 	}
 
-	hits := result["hits"].(map[string]interface{})["hits"].([]interface{})
-
-	documents := make([]interface{}, len(hits))
-	hitsData := make([]*HitData, len(hits))
-
-	for i, hit := range hits {
-		documents[i] = hit.(map[string]interface{})["_source"]
-
-		h := &HitData{
-			Index: hit.(map[string]interface{})["_index"].(string),
-			Type:  hit.(map[string]interface{})["_type"].(string),
-			Id:    hit.(map[string]interface{})["_id"].(string),
-		}
+	searchResult.Hits = r.Hits.Hits
+	setInnerHitsCodec(searchResult.Hits, codec)
 
-		if score, _ := hit.(map[string]interface{})["_score"]; score != nil {
-			h.Score = score.(float64)
+	if data != nil {
+		if err := codec.Unmarshal(joinSources(r.Hits.Hits), data); err != nil {
+			return StatusParseError, searchResult, &ParseError{Err: err}
 		}
+	}
 
-		if sort, _ := hit.(map[string]interface{})["sort"]; sort != nil {
-			h.Sort = sort.([]interface{})
-		}
+	return StatusSuccess, searchResult, nil
+}
 
-		hitsData[i] = h
+// joinSources returns the JSON array of the _source of hits.
+func joinSources(hits []*HitData) []byte {
+	n := 2
+	for _, hit := range hits {
+		n += len(hit.Source) + 1
 	}
 
-	tmp, err := json.Marshal(documents)
-	if err := json.Unmarshal(tmp, data); err != nil {
-		return StatusParseError, []*HitData{}, 0, err
+	b := make([]byte, 0, n)
+	b = append(b, '[')
+	for i, hit := range hits {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		if len(hit.Source) == 0 {
+			b = append(b, "null"...)
+		} else {
+			b = append(b, hit.Source...)
+		}
 	}
-
-	return StatusSuccess, hitsData, total, nil
+	return append(b, ']')
 }
 
+// Deprecated: use DeleteIndices.
 func (es *_elasticsearch) DeleteIndeces(index ...string) (StatusCode, error) {
+	return es.DeleteIndices(Indices(index...))
+}
 
-	req := esapi.IndicesDeleteRequest{
-		Index: index,
-	}
-	res, err := req.Do(context.Background(), es.client)
-	if res.IsError() {
-		return StatusUnexpectedError, err
-	}
-
-	return StatusSuccess, err
+// documentResult is the response of the document APIs.
+type documentResult struct {
+	ID      string `json:"_id"`
+	Version int    `json:"_version"`
+	Result  string `json:"result"`
 }
 
 type _elasticsearch struct {
-	client *goElasticsearch.Client
+	client     *apiClient
+	ctx        context.Context
+	logger     Logger
+	metrics    MetricsHook
+	prefix     string
+	preference string
+	// disallowPartial is Config.DisallowPartialResults.
+	disallowPartial bool
+	defaults        WriteDefaults
+	codec           Codec
+	deletable       []string
+	hooks           []WriteHook
 }
 
-func connectElasticsearch(config *Config) *goElasticsearch.Client {
-
-	cfg := goElasticsearch.Config{
-		Addresses: config.Address,
-		CloudID:   config.CloudID,
-		APIKey:    config.APIKey,
-	}
-	client, err := goElasticsearch.NewClient(cfg)
-
-	if err != nil {
-		fmt.Printf("Error New: %s", err)
-
-	}
-
-	return client
+// WithContext returns a copy of the client whose requests are bound to ctx,
+// so they are cancelled together with it.
+func (es *_elasticsearch) WithContext(ctx context.Context) Elasticsearch {
+	c := *es
+	c.ctx = ctx
+	return &c
 }
 
 func refresh2string(r *bool) string {
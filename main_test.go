@@ -1,10 +1,13 @@
 package elasticsearch
 
 import (
+	"encoding/json"
 	"fmt"
 	"math/rand"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/bxcodec/faker/v3"
 	"github.com/joho/godotenv"
@@ -30,11 +33,59 @@ func TestMain(m *testing.M) {
 }
 
 func newElasticsearch() Elasticsearch {
-	return New(&Config{
+	es, err := New(&Config{
 		Address: []string{
 			fmt.Sprintf("http://127.0.0.1:%s", os.Getenv("PORT")),
 		},
 	})
+	if err != nil {
+		panic(err)
+	}
+	return es
+}
+
+func TestNew(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		es, err := New(&Config{
+			Address:      []string{fmt.Sprintf("http://127.0.0.1:%s", os.Getenv("PORT"))},
+			WaitForReady: true,
+		})
+
+		assert.NoError(t, err)
+		assert.NotNil(t, es)
+	})
+
+	t.Run("Failure", func(t *testing.T) {
+		t.Run("Config is nil", func(t *testing.T) {
+			_, err := New(nil)
+			assert.Error(t, err)
+		})
+
+		t.Run("Both Address and CloudID", func(t *testing.T) {
+			_, err := New(&Config{
+				Address: []string{"http://127.0.0.1:9200"},
+				CloudID: "name:" + faker.Word(),
+			})
+			assert.Error(t, err)
+		})
+
+		t.Run("Invalid address", func(t *testing.T) {
+			_, err := New(&Config{
+				Address: []string{"127.0.0.1:9200"},
+			})
+			assert.Error(t, err)
+		})
+
+		t.Run("Not ready", func(t *testing.T) {
+			_, err := New(&Config{
+				Address:      []string{"http://127.0.0.1:1"},
+				WaitForReady: true,
+				ReadyRetries: 1,
+				ReadyBackoff: time.Millisecond,
+			})
+			assert.Error(t, err)
+		})
+	})
 }
 
 func TestPing(t *testing.T) {
@@ -166,6 +217,28 @@ func TestCreateDocument(t *testing.T) {
 
 			assert.Equal(t, 1, total)
 		})
+
+		t.Run("Raw body", func(t *testing.T) {
+			id := faker.UUIDDigit()
+			for _, body := range []interface{}{
+				json.RawMessage(`{"id": "` + id + `"}`),
+				[]byte(`{"id": "` + id + `"}`),
+				strings.NewReader(`{"id": "` + id + `"}`),
+			} {
+				status, err := es.CreateDocument(&Document{
+					Index:   indexName,
+					ID:      id,
+					Body:    body,
+					Refresh: RefreshTrue,
+				})
+				assert.NoError(t, err)
+				assert.Equal(t, StatusCreated, status)
+
+				var source DocBody
+				es.GetSource(indexName, id, &source)
+				assert.Equal(t, id, source.Id)
+			}
+		})
 	})
 
 	t.Run("Failure", func(t *testing.T) {
@@ -267,6 +340,22 @@ func TestUpdateDocument(t *testing.T) {
 	})
 }
 
+func TestUpdateDocumentRetryOnConflict(t *testing.T) {
+	server, requests := newTestServer(t, nil)
+	es, err := New(&Config{Address: []string{server.URL}, Logger: NopLogger()})
+	assert.NoError(t, err)
+
+	_, err = es.UpdateDocument(&Document{Index: "a", ID: "1", Body: map[string]interface{}{"count": 1}, RetryOnConflict: 3})
+	assert.NoError(t, err)
+	reqs := requests()
+	assert.Equal(t, "3", reqs[len(reqs)-1].URL.Query().Get("retry_on_conflict"))
+
+	_, err = es.UpdateDocument(&Document{Index: "a", ID: "1", Body: map[string]interface{}{"count": 1}})
+	assert.NoError(t, err)
+	reqs = requests()
+	assert.False(t, reqs[len(reqs)-1].URL.Query().Has("retry_on_conflict"))
+}
+
 func TestRemoveDocument(t *testing.T) {
 	es := newElasticsearch()
 
@@ -460,3 +549,41 @@ func TestSearch(t *testing.T) {
 		}
 	})
 }
+
+func TestDecodeSearchResult(t *testing.T) {
+	var r searchResponse
+	err := json.Unmarshal([]byte(`{
+		"_shards": {"total": 2, "successful": 2, "skipped": 0, "failed": 0},
+		"hits": {
+			"total": {"value": 3},
+			"hits": [
+				{"_index": "x", "_id": "1", "_score": 1.5, "_source": {"id": "1", "i": 1}},
+				{"_index": "x", "_id": "2", "_score": null, "sort": [2], "_source": {"id": "2", "i": 2}},
+				{"_index": "x", "_id": "3", "_routing": "r"}
+			]
+		}
+	}`), &r)
+	assert.NoError(t, err)
+
+	var docs []*DocBody
+	status, result, err := decodeSearchResult(jsonCodec{}, &r, &docs)
+
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSuccess, status)
+	assert.Equal(t, 3, result.Total)
+	assert.Equal(t, 2, result.Shards.Total)
+	assert.Equal(t, []*DocBody{{Id: "1", I: 1}, {Id: "2", I: 2}, nil}, docs)
+	assert.Equal(t, 1.5, result.Hits[0].Score)
+	assert.Equal(t, []interface{}{float64(2)}, result.Hits[1].Sort)
+	assert.Equal(t, "r", result.Hits[2].Routing)
+	assert.JSONEq(t, `{"id": "1", "i": 1}`, string(result.Hits[0].Source))
+	assert.Empty(t, result.Hits[2].Source)
+
+	t.Run("No hits", func(t *testing.T) {
+		status, result, err := decodeSearchResult(jsonCodec{}, &searchResponse{}, &docs)
+
+		assert.NoError(t, err)
+		assert.Equal(t, StatusNoContent, status)
+		assert.Empty(t, result.Hits)
+	})
+}
@@ -0,0 +1,44 @@
+package elasticsearch
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+)
+
+// MetricsHook receives the metrics of the client, to be exported e.g. as Prometheus counters
+// and histograms. It is called synchronously on the request path, so it must be fast.
+//
+//	func (h *promHook) ObserveRequest(op Operation, statusCode int, d time.Duration, err error) {
+//		h.requests.WithLabelValues(string(op), strconv.Itoa(statusCode)).Inc()
+//		h.latency.WithLabelValues(string(op)).Observe(d.Seconds())
+//	}
+type MetricsHook interface {
+	// ObserveRequest is called once per request, after its retries.
+	// statusCode is 0 when no response was received, and err is the error of the transport.
+	ObserveRequest(op Operation, statusCode int, duration time.Duration, err error)
+	// ObserveRetry is called before each retry, attempt being the attempt which failed.
+	ObserveRetry(op Operation, attempt int)
+	// ObserveCircuitState is called when the circuit breaker changes state.
+	ObserveCircuitState(state CircuitState)
+	// ObserveBulk is called for each bulk request with its number of items and size in bytes.
+	ObserveBulk(items int, bytes int)
+}
+
+func metricsMiddleware(hook MetricsHook) middleware {
+	return func(next esapi.Transport) esapi.Transport {
+		return transportFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			res, err := next.Perform(req)
+
+			statusCode := 0
+			if res != nil {
+				statusCode = res.StatusCode
+			}
+			hook.ObserveRequest(requestOperation(req), statusCode, time.Since(start), err)
+
+			return res, err
+		})
+	}
+}
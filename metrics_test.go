@@ -0,0 +1,100 @@
+package elasticsearch
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type observedRequest struct {
+	op         Operation
+	statusCode int
+}
+
+type recordingMetrics struct {
+	mu       sync.Mutex
+	requests []observedRequest
+	retries  []int
+	states   []CircuitState
+	bulks    [][2]int
+}
+
+func (m *recordingMetrics) ObserveRequest(op Operation, statusCode int, duration time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests = append(m.requests, observedRequest{op, statusCode})
+}
+
+func (m *recordingMetrics) ObserveRetry(op Operation, attempt int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.retries = append(m.retries, attempt)
+}
+
+func (m *recordingMetrics) ObserveCircuitState(state CircuitState) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.states = append(m.states, state)
+}
+
+func (m *recordingMetrics) ObserveBulk(items int, bytes int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.bulks = append(m.bulks, [2]int{items, bytes})
+}
+
+func TestMetrics(t *testing.T) {
+	var calls int32
+	server, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			w.Write([]byte(`{}`))
+			return
+		}
+		if atomic.AddInt32(&calls, 1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"error": {"type": "unavailable_shards_exception", "reason": "unavailable"}, "status": 503}`))
+			return
+		}
+		w.Write([]byte(`{"hits": {"total": {"value": 0, "relation": "eq"}, "hits": []}}`))
+	})
+
+	metrics := &recordingMetrics{}
+	es, err := New(&Config{
+		Address:        []string{server.URL},
+		Logger:         NopLogger(),
+		Metrics:        metrics,
+		Retry:          &RetryPolicy{InitialBackoff: time.Millisecond},
+		CircuitBreaker: &CircuitBreaker{FailureThreshold: 1},
+	})
+	assert.NoError(t, err)
+
+	_, _, _, err = es.Search("x", `{"query": {"match_all": {}}}`, nil)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []observedRequest{{OperationSearch, 200}}, metrics.requests)
+	assert.Equal(t, []int{1, 2}, metrics.retries)
+	assert.Empty(t, metrics.states, "a request succeeding after retries does not open the circuit")
+
+	_, _, _, err = es.Search("x", `{"query": {"match_all": {}}}`, nil)
+	assert.NoError(t, err)
+	assert.Len(t, metrics.requests, 2)
+}
+
+func TestCircuitMetrics(t *testing.T) {
+	now := time.Now()
+	metrics := &recordingMetrics{}
+	c := newCircuit(&CircuitBreaker{FailureThreshold: 1, ResetTimeout: time.Minute}, NopLogger(), metrics)
+	c.now = func() time.Time { return now }
+
+	c.record(true)
+	now = now.Add(time.Minute)
+	c.allow()
+	c.record(false)
+
+	assert.Equal(t, []CircuitState{CircuitOpen, CircuitHalfOpen, CircuitClosed}, metrics.states)
+	assert.Equal(t, "half-open", CircuitHalfOpen.String())
+}
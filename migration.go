@@ -0,0 +1,243 @@
+package elasticsearch
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// DefaultMigrationIndex is the index where Migrator records the applied migrations.
+const DefaultMigrationIndex = "es-migrations"
+
+// MigrationStep is one change of a Migration, e.g. CreateIndexStep("articles-v2", mappings).
+type MigrationStep func(es Elasticsearch) error
+
+// Migration is a versioned change of indices, templates, pipelines or aliases.
+// Up applies it and Down reverts it; both run their steps in order.
+type Migration struct {
+	Version     int
+	Description string
+	Up          []MigrationStep
+	Down        []MigrationStep
+}
+
+type MigrationStatus struct {
+	Version     int
+	Description string
+	Applied     bool
+	AppliedAt   time.Time
+}
+
+type migrationRecord struct {
+	Version     int       `json:"version"`
+	Description string    `json:"description"`
+	AppliedAt   time.Time `json:"applied_at"`
+}
+
+// Migrator applies migrations in version order and records them in the index Index,
+// so that every environment evolves through the same steps.
+// A migration whose step fails is not recorded, but its previous steps are not reverted.
+// Migrators must not run concurrently against the same cluster.
+//
+//	m, _ := elasticsearch.NewMigrator(es,
+//		&elasticsearch.Migration{
+//			Version: 1,
+//			Up:      []elasticsearch.MigrationStep{elasticsearch.CreateIndexStep("articles-v1", mappings)},
+//			Down:    []elasticsearch.MigrationStep{elasticsearch.DeleteIndexStep("articles-v1")},
+//		},
+//	)
+//	m.Up()
+type Migrator struct {
+	// Index defaults to DefaultMigrationIndex.
+	Index string
+
+	es         Elasticsearch
+	migrations []*Migration
+}
+
+// NewMigrator returns a Migrator of migrations, which must have distinct positive versions.
+func NewMigrator(es Elasticsearch, migrations ...*Migration) (*Migrator, error) {
+	sorted := make([]*Migration, len(migrations))
+	copy(sorted, migrations)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+
+	for i, m := range sorted {
+		if m.Version <= 0 {
+			return nil, fmt.Errorf("migration version must be positive: %d", m.Version)
+		}
+		if i > 0 && sorted[i-1].Version == m.Version {
+			return nil, fmt.Errorf("duplicate migration version: %d", m.Version)
+		}
+	}
+
+	return &Migrator{es: es, migrations: sorted, Index: DefaultMigrationIndex}, nil
+}
+
+// Status returns every migration with whether it has been applied, in version order.
+func (m *Migrator) Status() ([]*MigrationStatus, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]*MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := &MigrationStatus{Version: migration.Version, Description: migration.Description}
+		if record, ok := applied[migration.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = record.AppliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Up applies the pending migrations and returns the number of applied ones.
+func (m *Migrator) Up() (int, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+		if err := runMigrationSteps(m.es, migration.Up); err != nil {
+			return n, fmt.Errorf("migration %d up: %w", migration.Version, err)
+		}
+
+		_, err := m.es.CreateDocument(&Document{
+			Index: m.Index,
+			ID:    strconv.Itoa(migration.Version),
+			Body: &migrationRecord{
+				Version:     migration.Version,
+				Description: migration.Description,
+				AppliedAt:   time.Now().UTC(),
+			},
+			Refresh: RefreshTrue,
+		})
+		if err != nil {
+			return n, fmt.Errorf("migration %d: %w", migration.Version, err)
+		}
+		n++
+	}
+	return n, nil
+}
+
+// Down reverts the last n applied migrations, latest first, and returns the number of reverted ones.
+// n <= 0 reverts all of them.
+func (m *Migrator) Down(n int) (int, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return 0, err
+	}
+
+	reverted := 0
+	for i := len(m.migrations) - 1; i >= 0 && (n <= 0 || reverted < n); i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+		if err := runMigrationSteps(m.es, migration.Down); err != nil {
+			return reverted, fmt.Errorf("migration %d down: %w", migration.Version, err)
+		}
+
+		_, err := m.es.RemoveDocument(&Document{
+			Index:   m.Index,
+			ID:      strconv.Itoa(migration.Version),
+			Refresh: RefreshTrue,
+		})
+		if err != nil {
+			return reverted, fmt.Errorf("migration %d: %w", migration.Version, err)
+		}
+		reverted++
+	}
+	return reverted, nil
+}
+
+func (m *Migrator) applied() (map[int]*migrationRecord, error) {
+	var records []*migrationRecord
+	_, _, _, err := m.es.Search(m.Index, `{"query":{"match_all":{}},"size":10000}`, &records)
+	if errors.Is(err, ErrNotFound) {
+		return map[int]*migrationRecord{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]*migrationRecord, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
+
+func runMigrationSteps(es Elasticsearch, steps []MigrationStep) error {
+	for i, step := range steps {
+		if err := step(es); err != nil {
+			return fmt.Errorf("step %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+func CreateIndexStep(index, body string) MigrationStep {
+	return func(es Elasticsearch) error {
+		_, err := es.CreateIndex(index, body)
+		return err
+	}
+}
+
+func DeleteIndexStep(index string) MigrationStep {
+	return func(es Elasticsearch) error {
+		_, err := es.DeleteIndices(index)
+		return err
+	}
+}
+
+func PutTemplateStep(name, templates string) MigrationStep {
+	return func(es Elasticsearch) error {
+		_, err := es.CreateIndexTemplate(name, templates)
+		return err
+	}
+}
+
+func DeleteTemplateStep(name string) MigrationStep {
+	return func(es Elasticsearch) error {
+		_, err := es.DeleteIndexTemplate(name)
+		return err
+	}
+}
+
+func PutPipelineStep(id, body string) MigrationStep {
+	return func(es Elasticsearch) error {
+		_, err := es.PutPipeline(id, body)
+		return err
+	}
+}
+
+func DeletePipelineStep(id string) MigrationStep {
+	return func(es Elasticsearch) error {
+		_, err := es.DeletePipeline(id)
+		return err
+	}
+}
+
+func ReindexStep(source, dest string) MigrationStep {
+	return func(es Elasticsearch) error {
+		_, _, err := es.Reindex(source, dest)
+		return err
+	}
+}
+
+// SwapAliasStep moves alias from the index from to the index to; see SwapAlias.
+func SwapAliasStep(alias, from, to string) MigrationStep {
+	return func(es Elasticsearch) error {
+		_, err := es.SwapAlias(alias, from, to)
+		return err
+	}
+}
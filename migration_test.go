@@ -0,0 +1,126 @@
+package elasticsearch
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMigrator(t *testing.T) {
+	_, err := NewMigrator(NewFake(), &Migration{Version: 0})
+	assert.Error(t, err)
+
+	_, err = NewMigrator(NewFake(), &Migration{Version: 1}, &Migration{Version: 1})
+	assert.Error(t, err)
+}
+
+func TestMigrator(t *testing.T) {
+	var log []string
+	step := func(s string) MigrationStep {
+		return func(es Elasticsearch) error {
+			log = append(log, s)
+			return nil
+		}
+	}
+	fail := func(es Elasticsearch) error {
+		return errors.New("failed")
+	}
+
+	es := NewFake()
+	m, err := NewMigrator(es,
+		&Migration{Version: 2, Description: "second", Up: []MigrationStep{step("up 2")}, Down: []MigrationStep{step("down 2")}},
+		&Migration{Version: 1, Description: "first", Up: []MigrationStep{step("up 1a"), step("up 1b")}, Down: []MigrationStep{step("down 1")}},
+	)
+	assert.NoError(t, err)
+
+	t.Run("Status Before Up", func(t *testing.T) {
+		statuses, err := m.Status()
+		assert.NoError(t, err)
+		assert.Len(t, statuses, 2)
+		assert.Equal(t, 1, statuses[0].Version)
+		assert.False(t, statuses[0].Applied)
+		assert.False(t, statuses[1].Applied)
+	})
+
+	t.Run("Up", func(t *testing.T) {
+		n, err := m.Up()
+		assert.NoError(t, err)
+		assert.Equal(t, 2, n)
+		assert.Equal(t, []string{"up 1a", "up 1b", "up 2"}, log)
+
+		statuses, err := m.Status()
+		assert.NoError(t, err)
+		assert.True(t, statuses[0].Applied)
+		assert.True(t, statuses[1].Applied)
+		assert.False(t, statuses[1].AppliedAt.IsZero())
+
+		n, err = m.Up()
+		assert.NoError(t, err)
+		assert.Equal(t, 0, n)
+	})
+
+	t.Run("Down", func(t *testing.T) {
+		log = nil
+		n, err := m.Down(1)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+		assert.Equal(t, []string{"down 2"}, log)
+
+		statuses, _ := m.Status()
+		assert.True(t, statuses[0].Applied)
+		assert.False(t, statuses[1].Applied)
+
+		n, err = m.Down(0)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+		assert.Equal(t, []string{"down 2", "down 1"}, log)
+	})
+
+	t.Run("Failed Step", func(t *testing.T) {
+		m, _ := NewMigrator(es,
+			&Migration{Version: 1, Up: []MigrationStep{step("up 1")}},
+			&Migration{Version: 2, Up: []MigrationStep{fail}},
+		)
+		n, err := m.Up()
+		assert.Error(t, err)
+		assert.Equal(t, 1, n)
+
+		statuses, _ := m.Status()
+		assert.True(t, statuses[0].Applied)
+		assert.False(t, statuses[1].Applied)
+	})
+}
+
+func TestMigratorSteps(t *testing.T) {
+	es := newElasticsearch()
+	v1, v2 := indexName+"-v1", indexName+"-v2"
+	defer es.DeleteIndeces(DefaultMigrationIndex, v1, v2)
+
+	m, err := NewMigrator(es,
+		&Migration{
+			Version: 1,
+			Up:      []MigrationStep{CreateIndexStep(v1, ""), SwapAliasStep(indexName, "", v1)},
+			Down:    []MigrationStep{DeleteIndexStep(v1)},
+		},
+		&Migration{
+			Version: 2,
+			Up: []MigrationStep{
+				PutPipelineStep(indexName, `{"processors": [{"lowercase": {"field": "title"}}]}`),
+				CreateIndexStep(v2, `{"settings": {"index.default_pipeline": "`+indexName+`"}}`),
+				ReindexStep(v1, v2),
+				SwapAliasStep(indexName, v1, v2),
+			},
+			Down: []MigrationStep{SwapAliasStep(indexName, v2, v1), DeleteIndexStep(v2), DeletePipelineStep(indexName)},
+		},
+	)
+	assert.NoError(t, err)
+
+	n, err := m.Up()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	n, err = m.Down(0)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+}
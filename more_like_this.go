@@ -0,0 +1,85 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+)
+
+// MoreLikeThisQuery matches documents similar to the document id in index, based on fields.
+// The document itself is not matched.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/query-dsl-mlt-query.html
+func MoreLikeThisQuery(index, id string, fields []string) Query {
+	mlt := map[string]interface{}{
+		"like": []map[string]interface{}{
+			{"_index": index, "_id": id},
+		},
+	}
+	if len(fields) > 0 {
+		mlt["fields"] = fields
+	}
+	return Query{"more_like_this": mlt}
+}
+
+type MoreLikeThisOption func(mlt map[string]interface{}, body map[string]interface{})
+
+// MLTMinTermFreq sets the minimum frequency of a term in the source document (default 2).
+func MLTMinTermFreq(n int) MoreLikeThisOption {
+	return func(mlt map[string]interface{}, body map[string]interface{}) {
+		mlt["min_term_freq"] = n
+	}
+}
+
+// MLTMinDocFreq sets the minimum number of documents a term must appear in (default 5).
+func MLTMinDocFreq(n int) MoreLikeThisOption {
+	return func(mlt map[string]interface{}, body map[string]interface{}) {
+		mlt["min_doc_freq"] = n
+	}
+}
+
+// MLTMaxQueryTerms sets the maximum number of terms selected from the source document (default 25).
+func MLTMaxQueryTerms(n int) MoreLikeThisOption {
+	return func(mlt map[string]interface{}, body map[string]interface{}) {
+		mlt["max_query_terms"] = n
+	}
+}
+
+// MLTMinimumShouldMatch sets how many of the selected terms must match (default "30%").
+func MLTMinimumShouldMatch(m string) MoreLikeThisOption {
+	return func(mlt map[string]interface{}, body map[string]interface{}) {
+		mlt["minimum_should_match"] = m
+	}
+}
+
+// MLTSize sets the number of similar documents returned (default 10).
+func MLTSize(n int) MoreLikeThisOption {
+	return func(mlt map[string]interface{}, body map[string]interface{}) {
+		body["size"] = n
+	}
+}
+
+// MLTFilter restricts similar documents to those matching filter.
+func MLTFilter(filter Query) MoreLikeThisOption {
+	return func(mlt map[string]interface{}, body map[string]interface{}) {
+		body["query"] = Query{"bool": map[string]interface{}{
+			"must":   body["query"],
+			"filter": filter,
+		}}
+	}
+}
+
+// MoreLikeThis searches index for documents similar to the document id and decodes them into data like Search.
+func (es *_elasticsearch) MoreLikeThis(index, id string, fields []string, data interface{}, opts ...MoreLikeThisOption) (StatusCode, []*HitData, int, error) {
+	q := MoreLikeThisQuery(es.indexName(index), id, fields)
+	body := map[string]interface{}{
+		"query": q,
+	}
+	for _, opt := range opts {
+		opt(q["more_like_this"].(map[string]interface{}), body)
+	}
+
+	b, err := json.Marshal(body)
+	if err != nil {
+		return StatusInternalError, []*HitData{}, 0, err
+	}
+
+	return es.Search(index, string(b), data)
+}
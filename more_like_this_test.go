@@ -0,0 +1,68 @@
+package elasticsearch
+
+import (
+	"testing"
+
+	"github.com/bxcodec/faker/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMoreLikeThisQuery(t *testing.T) {
+	assertQueryJSON(t, `{
+		"more_like_this": {
+			"like": [{"_index": "posts", "_id": "1"}],
+			"fields": ["title", "body"]
+		}
+	}`, MoreLikeThisQuery("posts", "1", []string{"title", "body"}))
+}
+
+func TestMoreLikeThis(t *testing.T) {
+	es := newElasticsearch()
+
+	word := faker.UUIDDigit()
+	ids := make([]string, 3)
+	for i := range ids {
+		var data DocBody
+		faker.FakeData(&data)
+		data.Id = faker.UUIDDigit()
+		data.S = word + " " + word + " " + faker.Word()
+		es.CreateDocument(&Document{
+			Index: indexName,
+			ID:    data.Id,
+			Body:  data,
+		})
+		ids[i] = data.Id
+	}
+	es.Refresh(indexName)
+
+	t.Run("Found", func(t *testing.T) {
+		var list []DocBody
+		status, hits, total, err := es.MoreLikeThis(indexName, ids[0], []string{"s"}, &list,
+			MLTMinTermFreq(1),
+			MLTMinDocFreq(1),
+			MLTSize(5),
+		)
+
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+		assert.Equal(t, 2, total)
+		for i, hit := range hits {
+			assert.NotEqual(t, ids[0], hit.Id)
+			assert.Contains(t, ids, list[i].Id)
+		}
+	})
+
+	t.Run("Filtered", func(t *testing.T) {
+		var list []DocBody
+		status, _, total, err := es.MoreLikeThis(indexName, ids[0], []string{"s"}, &list,
+			MLTMinTermFreq(1),
+			MLTMinDocFreq(1),
+			MLTFilter(TermQuery("id", ids[1])),
+		)
+
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+		assert.Equal(t, 1, total)
+		assert.Equal(t, ids[1], list[0].Id)
+	})
+}
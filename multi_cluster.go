@@ -0,0 +1,252 @@
+package elasticsearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ClusterMember is one of the clusters of a MultiCluster.
+type ClusterMember struct {
+	Name   string
+	Client Elasticsearch
+}
+
+// ReadPolicy selects the cluster the reads of a MultiCluster go to.
+type ReadPolicy int
+
+const (
+	// ReadActive sends reads to the active cluster, like writes.
+	ReadActive ReadPolicy = iota
+	// ReadStandby sends reads to the first healthy cluster other than the active one to offload it,
+	// and to the active cluster when there is none. Reads may then miss the latest writes.
+	ReadStandby
+)
+
+type MultiClusterConfig struct {
+	// Clusters are in order of preference: the first one is the primary, the others are the
+	// disaster recovery clusters failed over to.
+	Clusters []*ClusterMember
+	// ReadPolicy selects the cluster of reads. Writes always go to the active cluster.
+	ReadPolicy ReadPolicy
+
+	// CheckInterval is the interval of the health checks of Run. Default: 10s.
+	CheckInterval time.Duration
+	// CheckTimeout is the timeout of a health check. Default: 5s.
+	CheckTimeout time.Duration
+	// HealthCheck fails when the cluster is down. Default: a ping failing on request errors and error responses,
+	// or ClusterHealth for the clients of other implementations, such as a Fake.
+	HealthCheck func(ctx context.Context, es Elasticsearch) error
+	// FailureThreshold is the number of consecutive failed checks marking a cluster down. Default: 3.
+	FailureThreshold int
+	// RecoveryThreshold is the number of consecutive successful checks marking a cluster up again,
+	// so that a flapping primary is not failed back to on its first success. Default: 5.
+	RecoveryThreshold int
+
+	// OnFailover is called when the active cluster changes, including when failing back to the primary.
+	OnFailover func(from, to string)
+	// Logger receives the logs of failovers. Default: all levels to the standard log package.
+	Logger Logger
+}
+
+func (c *MultiClusterConfig) validate() error {
+	if len(c.Clusters) == 0 {
+		return errors.New("MultiClusterConfig.Clusters must not be empty")
+	}
+	names := map[string]bool{}
+	for _, m := range c.Clusters {
+		if m.Client == nil {
+			return fmt.Errorf("client of cluster %q must not be nil", m.Name)
+		}
+		if names[m.Name] {
+			return fmt.Errorf("cluster %q is duplicated", m.Name)
+		}
+		names[m.Name] = true
+	}
+	if c.FailureThreshold < 0 || c.RecoveryThreshold < 0 {
+		return errors.New("MultiClusterConfig thresholds must not be negative")
+	}
+	return nil
+}
+
+type memberState struct {
+	*ClusterMember
+	healthy   bool
+	failures  int
+	successes int
+}
+
+// MultiCluster holds the clients of several clusters and routes requests to the active one,
+// failing over to the next healthy cluster when the active one is down, and back to the
+// primary when it recovers. Health checks run with Run, or one round at a time with Check.
+type MultiCluster struct {
+	config  MultiClusterConfig
+	members []*memberState
+
+	mu     sync.RWMutex
+	active int
+}
+
+func NewMultiCluster(config *MultiClusterConfig) (*MultiCluster, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	mc := &MultiCluster{config: *config}
+	if mc.config.CheckInterval == 0 {
+		mc.config.CheckInterval = 10 * time.Second
+	}
+	if mc.config.CheckTimeout == 0 {
+		mc.config.CheckTimeout = 5 * time.Second
+	}
+	if mc.config.HealthCheck == nil {
+		mc.config.HealthCheck = pingCluster
+	}
+	if mc.config.FailureThreshold == 0 {
+		mc.config.FailureThreshold = 3
+	}
+	if mc.config.RecoveryThreshold == 0 {
+		mc.config.RecoveryThreshold = 5
+	}
+	if mc.config.Logger == nil {
+		mc.config.Logger = NewStdLogger(nil, LevelDebug)
+	}
+
+	for _, m := range config.Clusters {
+		mc.members = append(mc.members, &memberState{ClusterMember: m, healthy: true})
+	}
+	return mc, nil
+}
+
+// pingCluster is the default HealthCheck. Unlike Ping, it fails on error responses such as 503.
+func pingCluster(ctx context.Context, es Elasticsearch) error {
+	es = es.WithContext(ctx)
+	if e, ok := base(es); ok {
+		return e.ping()
+	}
+	_, _, err := es.ClusterHealth()
+	return err
+}
+
+// Write returns the client of the active cluster.
+func (mc *MultiCluster) Write() Elasticsearch {
+	mc.mu.RLock()
+	defer mc.mu.RUnlock()
+	return mc.members[mc.active].Client
+}
+
+// Read returns the client of the cluster selected by ReadPolicy.
+func (mc *MultiCluster) Read() Elasticsearch {
+	mc.mu.RLock()
+	defer mc.mu.RUnlock()
+
+	if mc.config.ReadPolicy == ReadStandby {
+		for i, m := range mc.members {
+			if i != mc.active && m.healthy {
+				return m.Client
+			}
+		}
+	}
+	return mc.members[mc.active].Client
+}
+
+// Active returns the name of the active cluster.
+func (mc *MultiCluster) Active() string {
+	mc.mu.RLock()
+	defer mc.mu.RUnlock()
+	return mc.members[mc.active].Name
+}
+
+// Healthy reports whether the cluster name is considered up.
+func (mc *MultiCluster) Healthy(name string) bool {
+	mc.mu.RLock()
+	defer mc.mu.RUnlock()
+	for _, m := range mc.members {
+		if m.Name == name {
+			return m.healthy
+		}
+	}
+	return false
+}
+
+// Run checks the health of the clusters every CheckInterval until ctx is done.
+func (mc *MultiCluster) Run(ctx context.Context) {
+	ticker := time.NewTicker(mc.config.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		mc.Check(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check checks the health of every cluster once, concurrently, and fails over if needed.
+func (mc *MultiCluster) Check(ctx context.Context) {
+	errs := make([]error, len(mc.members))
+	var wg sync.WaitGroup
+	for i, m := range mc.members {
+		wg.Add(1)
+		go func(i int, m *memberState) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, mc.config.CheckTimeout)
+			defer cancel()
+			errs[i] = mc.config.HealthCheck(ctx, m.Client)
+		}(i, m)
+	}
+	wg.Wait()
+
+	mc.mu.Lock()
+	for i, m := range mc.members {
+		mc.record(m, errs[i])
+	}
+	from := mc.members[mc.active].Name
+	to := mc.failover()
+	mc.mu.Unlock()
+
+	if to != "" && mc.config.OnFailover != nil {
+		mc.config.OnFailover(from, to)
+	}
+}
+
+// record updates the health of m with mc.mu held.
+func (mc *MultiCluster) record(m *memberState, err error) {
+	if err == nil {
+		m.failures = 0
+		m.successes++
+		if !m.healthy && m.successes >= mc.config.RecoveryThreshold {
+			mc.config.Logger.Infof("Cluster %s is up", m.Name)
+			m.healthy = true
+		}
+		return
+	}
+
+	m.successes = 0
+	m.failures++
+	if m.healthy && m.failures >= mc.config.FailureThreshold {
+		mc.config.Logger.Warnf("Cluster %s is down: %s", m.Name, err)
+		m.healthy = false
+	}
+}
+
+// failover activates the most preferred healthy cluster with mc.mu held, and returns its name
+// when it changed. The active cluster is kept when every cluster is down.
+func (mc *MultiCluster) failover() string {
+	for i, m := range mc.members {
+		if !m.healthy {
+			continue
+		}
+		if i == mc.active {
+			return ""
+		}
+		mc.config.Logger.Warnf("Failing over from cluster %s to %s", mc.members[mc.active].Name, m.Name)
+		mc.active = i
+		return m.Name
+	}
+	return ""
+}
@@ -0,0 +1,120 @@
+package elasticsearch
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultiCluster(t *testing.T) {
+	primary, dr := NewFake(), NewFake()
+	down := map[Elasticsearch]bool{}
+	var failovers []string
+
+	mc, err := NewMultiCluster(&MultiClusterConfig{
+		Clusters: []*ClusterMember{
+			{Name: "primary", Client: primary},
+			{Name: "dr", Client: dr},
+		},
+		ReadPolicy: ReadStandby,
+		HealthCheck: func(ctx context.Context, es Elasticsearch) error {
+			if down[es] {
+				return errors.New("down")
+			}
+			return nil
+		},
+		FailureThreshold:  2,
+		RecoveryThreshold: 3,
+		OnFailover: func(from, to string) {
+			failovers = append(failovers, from+"->"+to)
+		},
+		Logger: NopLogger(),
+	})
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	t.Run("Healthy", func(t *testing.T) {
+		mc.Check(ctx)
+		assert.Equal(t, "primary", mc.Active())
+		assert.Same(t, primary, mc.Write())
+		assert.Same(t, dr, mc.Read())
+	})
+
+	t.Run("Failover", func(t *testing.T) {
+		down[primary] = true
+		mc.Check(ctx)
+		assert.Equal(t, "primary", mc.Active())
+		assert.True(t, mc.Healthy("primary"))
+
+		mc.Check(ctx)
+		assert.Equal(t, "dr", mc.Active())
+		assert.False(t, mc.Healthy("primary"))
+		assert.Same(t, dr, mc.Write())
+		assert.Same(t, dr, mc.Read())
+	})
+
+	t.Run("Failback", func(t *testing.T) {
+		down[primary] = false
+		mc.Check(ctx)
+		mc.Check(ctx)
+		assert.Equal(t, "dr", mc.Active())
+
+		mc.Check(ctx)
+		assert.Equal(t, "primary", mc.Active())
+		assert.Same(t, primary, mc.Write())
+	})
+
+	t.Run("All Down", func(t *testing.T) {
+		down[primary], down[dr] = true, true
+		mc.Check(ctx)
+		mc.Check(ctx)
+		assert.Equal(t, "primary", mc.Active())
+		assert.Same(t, primary, mc.Read())
+	})
+
+	assert.Equal(t, []string{"primary->dr", "dr->primary"}, failovers)
+}
+
+func TestMultiClusterDefaultHealthCheck(t *testing.T) {
+	primaryServer, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"error": {"type": "cluster_block_exception", "reason": "blocked"}, "status": 503}`))
+	})
+	drServer, _ := newTestServer(t, nil)
+	primary, err := New(&Config{Address: []string{primaryServer.URL}, Logger: NopLogger(), Retry: &RetryPolicy{MaxAttempts: 1}})
+	assert.NoError(t, err)
+	dr, err := New(&Config{Address: []string{drServer.URL}, Logger: NopLogger()})
+	assert.NoError(t, err)
+
+	mc, err := NewMultiCluster(&MultiClusterConfig{
+		Clusters: []*ClusterMember{
+			{Name: "primary", Client: NewCache(primary, nil)},
+			{Name: "dr", Client: dr},
+		},
+		FailureThreshold: 1,
+		Logger:           NopLogger(),
+	})
+	assert.NoError(t, err)
+
+	mc.Check(context.Background())
+	assert.False(t, mc.Healthy("primary"))
+	assert.True(t, mc.Healthy("dr"))
+	assert.Equal(t, "dr", mc.Active())
+}
+
+func TestMultiClusterConfigValidate(t *testing.T) {
+	_, err := NewMultiCluster(&MultiClusterConfig{})
+	assert.Error(t, err)
+
+	_, err = NewMultiCluster(&MultiClusterConfig{Clusters: []*ClusterMember{{Name: "a"}}})
+	assert.Error(t, err)
+
+	_, err = NewMultiCluster(&MultiClusterConfig{Clusters: []*ClusterMember{
+		{Name: "a", Client: NewFake()},
+		{Name: "a", Client: NewFake()},
+	}})
+	assert.Error(t, err)
+}
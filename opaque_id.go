@@ -0,0 +1,42 @@
+package elasticsearch
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+)
+
+// X-Opaque-Id is shown in the slow logs, the tasks API and the deprecation logs of Elasticsearch.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/tasks.html#_identifying_running_tasks
+const opaqueIDHeader = "X-Opaque-Id"
+
+type opaqueIDKey struct{}
+
+// WithOpaqueID returns a context sending id as X-Opaque-Id with the requests made with it, e.g.
+//
+//	es.WithContext(elasticsearch.WithOpaqueID(ctx, requestID)).Search(...)
+func WithOpaqueID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, opaqueIDKey{}, id)
+}
+
+// OpaqueID returns the id set by WithOpaqueID.
+func OpaqueID(ctx context.Context) string {
+	id, _ := ctx.Value(opaqueIDKey{}).(string)
+	return id
+}
+
+func opaqueIDMiddleware(fromContext func(ctx context.Context) string) middleware {
+	return func(next esapi.Transport) esapi.Transport {
+		return transportFunc(func(req *http.Request) (*http.Response, error) {
+			id := OpaqueID(req.Context())
+			if id == "" && fromContext != nil {
+				id = fromContext(req.Context())
+			}
+			if id != "" {
+				req.Header.Set(opaqueIDHeader, id)
+			}
+			return next.Perform(req)
+		})
+	}
+}
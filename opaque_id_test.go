@@ -0,0 +1,48 @@
+package elasticsearch
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type requestIDKey struct{}
+
+func TestOpaqueID(t *testing.T) {
+	server, requests := newTestServer(t, nil)
+
+	es, err := New(&Config{
+		Address: []string{server.URL},
+		OpaqueIDFromContext: func(ctx context.Context) string {
+			id, _ := ctx.Value(requestIDKey{}).(string)
+			return id
+		},
+	})
+	assert.NoError(t, err)
+
+	lastOpaqueID := func() string {
+		reqs := requests()
+		return reqs[len(reqs)-1].Header.Get("X-Opaque-Id")
+	}
+
+	t.Run("WithOpaqueID", func(t *testing.T) {
+		ctx := WithOpaqueID(context.Background(), "job-42")
+		assert.Equal(t, "job-42", OpaqueID(ctx))
+
+		assert.NoError(t, es.WithContext(ctx).Ping())
+		assert.Equal(t, "job-42", lastOpaqueID())
+	})
+
+	t.Run("OpaqueIDFromContext", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), requestIDKey{}, "req-1")
+
+		assert.NoError(t, es.WithContext(ctx).Ping())
+		assert.Equal(t, "req-1", lastOpaqueID())
+	})
+
+	t.Run("None", func(t *testing.T) {
+		assert.NoError(t, es.Ping())
+		assert.Empty(t, lastOpaqueID())
+	})
+}
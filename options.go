@@ -0,0 +1,78 @@
+package elasticsearch
+
+import (
+	"net/http"
+)
+
+// Option changes the Config given to New, e.g. to share a base Config between services:
+//
+//	es, err := elasticsearch.New(base, elasticsearch.WithIndexPrefix("billing-"), elasticsearch.WithLogger(logger))
+//
+// Options are applied in order to a copy of the Config, which is left unchanged.
+type Option func(config *Config)
+
+// WithLogger sets Config.Logger.
+func WithLogger(logger Logger) Option {
+	return func(config *Config) {
+		config.Logger = logger
+	}
+}
+
+// WithRetry sets Config.Retry.
+func WithRetry(policy *RetryPolicy) Option {
+	return func(config *Config) {
+		config.Retry = policy
+	}
+}
+
+// WithMetrics sets Config.Metrics.
+func WithMetrics(metrics MetricsHook) Option {
+	return func(config *Config) {
+		config.Metrics = metrics
+	}
+}
+
+// WithWriteHook appends hook to Config.WriteHooks.
+func WithWriteHook(hook WriteHook) Option {
+	return func(config *Config) {
+		config.WriteHooks = append(config.WriteHooks[:len(config.WriteHooks):len(config.WriteHooks)], hook)
+	}
+}
+
+// WithValidator adds validator to Config.Validators for the indices matching pattern.
+func WithValidator(pattern string, validator Validator) Option {
+	return func(config *Config) {
+		validators := make(map[string]Validator, len(config.Validators)+1)
+		for p, v := range config.Validators {
+			validators[p] = v
+		}
+		validators[pattern] = validator
+		config.Validators = validators
+	}
+}
+
+// WithIndexPrefix sets Config.IndexPrefix. See also the WithIndexPrefix method of the client.
+func WithIndexPrefix(prefix string) Option {
+	return func(config *Config) {
+		config.IndexPrefix = prefix
+	}
+}
+
+// WithTransport sets Config.Transport.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(config *Config) {
+		config.Transport = transport
+	}
+}
+
+// apply returns a copy of config with opts.
+func (config *Config) apply(opts []Option) *Config {
+	if config == nil || len(opts) == 0 {
+		return config
+	}
+	c := *config
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return &c
+}
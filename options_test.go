@@ -0,0 +1,45 @@
+package elasticsearch
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptions(t *testing.T) {
+	server, requests := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"count": 0}`))
+	})
+	base := &Config{Address: []string{server.URL}}
+
+	var roundTrips int
+	transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		roundTrips++
+		return http.DefaultTransport.RoundTrip(req)
+	})
+	metrics := &recordingMetrics{}
+
+	es, err := New(base,
+		WithIndexPrefix("p-"),
+		WithLogger(NopLogger()),
+		WithMetrics(metrics),
+		WithRetry(&RetryPolicy{MaxAttempts: 2}),
+		WithTransport(transport),
+	)
+	assert.NoError(t, err)
+
+	_, _, err = es.Count("a", "")
+	assert.NoError(t, err)
+
+	reqs := requests()
+	assert.Equal(t, "/p-a/_count", reqs[len(reqs)-1].URL.Path)
+	assert.Positive(t, roundTrips)
+	assert.Len(t, metrics.requests, 1)
+
+	// The base config is left unchanged.
+	assert.Equal(t, &Config{Address: []string{server.URL}}, base)
+
+	_, err = New(nil, WithLogger(NopLogger()))
+	assert.Error(t, err)
+}
@@ -0,0 +1,103 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// MaxPageDepth is the default index.max_result_window: from + size of a search may not exceed it.
+const MaxPageDepth = 10000
+
+// ErrPageTooDeep is returned by SearchPage, without sending the request, for pages beyond MaxPageDepth.
+// Deeper results are read with Page.SearchAfter.
+var ErrPageTooDeep = errors.New("elasticsearch: page beyond the max result window")
+
+// Page is a page of the results of SearchPage.
+type Page struct {
+	// Number starts at 1. Default: 1.
+	Number int
+	// Size is the number of hits per page. Default: 10.
+	Size int
+	// Sort orders the hits, overriding the sort of the query. A unique tiebreaker such as an ID field
+	// should be last, for NextSearchAfter to resume exactly.
+	Sort []Sort
+	// SearchAfter is the PageInfo.NextSearchAfter of the previous page, to page beyond MaxPageDepth.
+	// Number is ignored when it is set.
+	SearchAfter []interface{}
+}
+
+// PageInfo describes a page returned by SearchPage.
+type PageInfo struct {
+	Number int
+	Size   int
+	Total  int
+	// TotalRelation is "eq" when Total is exact and "gte" when it is a lower bound.
+	TotalRelation string
+	// HasNext is true when the next page by Number exists and is within MaxPageDepth.
+	HasNext bool
+	// NextSearchAfter is the sort values of the last hit, set when the page is full and sorted.
+	NextSearchAfter []interface{}
+}
+
+// SearchPage searches page of the results of query and decodes their _source into data.
+// The from, size, sort and search_after of query are replaced by those of page.
+func (es *_elasticsearch) SearchPage(index, query string, page Page, data interface{}, opts ...SearchOption) (StatusCode, []*HitData, *PageInfo, error) {
+	if page.Number <= 0 {
+		page.Number = 1
+	}
+	if page.Size <= 0 {
+		page.Size = 10
+	}
+	info := &PageInfo{Number: page.Number, Size: page.Size}
+
+	from := (page.Number - 1) * page.Size
+	if len(page.SearchAfter) > 0 {
+		from = 0
+	}
+	if from+page.Size > MaxPageDepth {
+		return StatusInternalError, []*HitData{}, info, ErrPageTooDeep
+	}
+
+	body, err := pageBody(query, page, from)
+	if err != nil {
+		return StatusInternalError, []*HitData{}, info, err
+	}
+
+	status, result, err := es.SearchWithResult(index, body, data, opts...)
+	if err != nil {
+		return status, result.Hits, info, err
+	}
+
+	info.Total = result.Total
+	info.TotalRelation = result.TotalRelation
+	next := from + page.Size
+	info.HasNext = len(page.SearchAfter) == 0 && next < info.Total && next+page.Size <= MaxPageDepth
+	if len(result.Hits) == page.Size && (len(page.Sort) > 0 || len(page.SearchAfter) > 0) {
+		info.NextSearchAfter = result.Hits[len(result.Hits)-1].Sort
+	}
+	return status, result.Hits, info, nil
+}
+
+// pageBody returns query with the from, size, sort and search_after of page.
+func pageBody(query string, page Page, from int) (string, error) {
+	body := map[string]interface{}{}
+	if strings.TrimSpace(query) != "" {
+		if err := json.Unmarshal([]byte(query), &body); err != nil {
+			return "", err
+		}
+	}
+
+	body["from"] = from
+	body["size"] = page.Size
+	if len(page.Sort) > 0 {
+		body["sort"] = page.Sort
+	}
+	delete(body, "search_after")
+	if len(page.SearchAfter) > 0 {
+		body["search_after"] = page.SearchAfter
+	}
+
+	b, err := json.Marshal(body)
+	return string(b), err
+}
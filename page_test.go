@@ -0,0 +1,66 @@
+package elasticsearch
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchPage(t *testing.T) {
+	var body string
+	response := `{}`
+	server, requests := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		w.Write([]byte(response))
+	})
+	es, err := New(&Config{Address: []string{server.URL}})
+	assert.NoError(t, err)
+
+	t.Run("Page", func(t *testing.T) {
+		response = `{"hits": {"total": {"value": 25, "relation": "eq"}, "hits": [
+			{"_id": "1", "_source": {"id": "1"}, "sort": [3, "1"]},
+			{"_id": "2", "_source": {"id": "2"}, "sort": [2, "2"]}
+		]}}`
+		var docs []*DocBody
+		status, hits, info, err := es.SearchPage("a", SearchBody(MatchAllQuery()), Page{Number: 3, Size: 2, Sort: []Sort{FieldSort("n", "desc"), FieldSort("id", "asc")}}, &docs)
+
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+		assert.Len(t, hits, 2)
+		assert.Len(t, docs, 2)
+		assert.JSONEq(t, `{"query": {"match_all": {}}, "from": 4, "size": 2, "sort": [{"n": {"order": "desc"}}, {"id": {"order": "asc"}}]}`, body)
+		assert.Equal(t, &PageInfo{Number: 3, Size: 2, Total: 25, TotalRelation: "eq", HasNext: true, NextSearchAfter: []interface{}{float64(2), "2"}}, info)
+	})
+
+	t.Run("Last page", func(t *testing.T) {
+		response = `{"hits": {"total": {"value": 3, "relation": "eq"}, "hits": [{"_id": "3"}]}}`
+		_, _, info, err := es.SearchPage("a", "", Page{Number: 2, Size: 2}, nil)
+
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"from": 2, "size": 2}`, body)
+		assert.False(t, info.HasNext)
+		assert.Nil(t, info.NextSearchAfter)
+	})
+
+	t.Run("Search after", func(t *testing.T) {
+		response = `{"hits": {"total": {"value": 20000, "relation": "eq"}, "hits": [{"_id": "3", "sort": ["3"]}]}}`
+		_, _, info, err := es.SearchPage("a", `{"search_after": ["0"]}`, Page{Number: 5000, Size: 1, Sort: []Sort{FieldSort("id", "asc")}, SearchAfter: []interface{}{"2"}}, nil)
+
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"from": 0, "size": 1, "sort": [{"id": {"order": "asc"}}], "search_after": ["2"]}`, body)
+		assert.False(t, info.HasNext)
+		assert.Equal(t, []interface{}{"3"}, info.NextSearchAfter)
+	})
+
+	t.Run("Too deep", func(t *testing.T) {
+		n := len(requests())
+		status, _, _, err := es.SearchPage("a", "", Page{Number: 1001, Size: 10}, nil)
+
+		assert.ErrorIs(t, err, ErrPageTooDeep)
+		assert.Equal(t, StatusInternalError, status)
+		assert.Len(t, requests(), n)
+	})
+}
@@ -0,0 +1,33 @@
+package elasticsearch
+
+import (
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+)
+
+// NoPipeline is the Pipeline of the writes skipping the ingest pipelines, both WriteDefaults.Pipeline
+// and the index.default_pipeline of the index.
+const NoPipeline = "_none"
+
+// PutPipeline creates or replaces the ingest pipeline id, e.g.
+// `{"processors": [{"lowercase": {"field": "title"}}]}`.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/ingest.html
+func (es *_elasticsearch) PutPipeline(id, body string) (StatusCode, error) {
+	req := esapi.IngestPutPipelineRequest{
+		PipelineID: id,
+		Body:       strings.NewReader(body),
+	}
+
+	res, err := req.Do(es.ctx, es.client)
+	return es.handleResponse("put pipeline ID="+id, res, err, nil)
+}
+
+func (es *_elasticsearch) DeletePipeline(id string) (StatusCode, error) {
+	req := esapi.IngestDeletePipelineRequest{
+		PipelineID: id,
+	}
+
+	res, err := req.Do(es.ctx, es.client)
+	return es.handleResponse("delete pipeline ID="+id, res, err, nil)
+}
@@ -0,0 +1,24 @@
+package elasticsearch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPipeline(t *testing.T) {
+	es := newElasticsearch()
+	id := indexName + "-pipeline"
+
+	status, err := es.PutPipeline(id, `{"processors": [{"lowercase": {"field": "title"}}]}`)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSuccess, status)
+
+	status, err = es.DeletePipeline(id)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSuccess, status)
+
+	status, err = es.DeletePipeline(id)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, StatusNotFoundError, status)
+}
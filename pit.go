@@ -0,0 +1,79 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+)
+
+// OpenPIT opens a point in time of index, a view of its documents frozen at the time of the call,
+// and returns its ID to be passed to the PIT search option. The point in time is closed after
+// keepAlive without search; every search with PIT extends it.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/point-in-time-api.html
+func (es *_elasticsearch) OpenPIT(index string, keepAlive time.Duration) (StatusCode, string, error) {
+	if err := es.requireVersion("point in time", 7, 10); err != nil {
+		return StatusInternalError, "", err
+	}
+
+	req := esapi.OpenPointInTimeRequest{
+		Index:     es.indexList(index),
+		KeepAlive: keepAliveParam(keepAlive),
+	}
+	res, err := req.Do(es.ctx, es.client)
+
+	var r struct {
+		ID string `json:"id"`
+	}
+	if status, err := es.handleResponse("open point in time index="+index, res, err, &r); err != nil {
+		return status, "", err
+	}
+	return StatusSuccess, r.ID, nil
+}
+
+// ClosePIT closes the point in time id before its keep alive expires, releasing its resources.
+// Closing an expired point in time succeeds.
+func (es *_elasticsearch) ClosePIT(id string) (StatusCode, error) {
+	if err := es.requireVersion("point in time", 7, 10); err != nil {
+		return StatusInternalError, err
+	}
+
+	body, err := json.Marshal(map[string]string{"id": id})
+	if err != nil {
+		return StatusInternalError, err
+	}
+
+	req := esapi.ClosePointInTimeRequest{Body: bytes.NewReader(body)}
+	res, err := req.Do(es.ctx, es.client)
+
+	status, err := es.handleResponse("close point in time", res, err, nil)
+	if status == StatusNotFoundError {
+		return StatusSuccess, nil
+	}
+	return status, err
+}
+
+// PIT searches the point in time id opened by OpenPIT instead of the index, which must be empty,
+// and extends it by keepAlive. Several searches with the same PIT see the same documents, e.g. to
+// paginate with search_after or to run consistent queries. SearchResult.PITID is the ID to use next.
+// It is ignored by Count, as is the preference of the search, and cannot be used with Export.
+func PIT(id string, keepAlive time.Duration) SearchOption {
+	return func(o *searchOptions) {
+		o.pit = &pit{ID: id, KeepAlive: keepAliveParam(keepAlive)}
+	}
+}
+
+type pit struct {
+	ID        string `json:"id"`
+	KeepAlive string `json:"keep_alive"`
+}
+
+// keepAliveParam formats d as the time units of Elasticsearch.
+func keepAliveParam(d time.Duration) string {
+	if d%time.Second == 0 {
+		return fmt.Sprintf("%ds", d/time.Second)
+	}
+	return fmt.Sprintf("%dms", d.Milliseconds())
+}
@@ -0,0 +1,56 @@
+package elasticsearch
+
+import (
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPIT(t *testing.T) {
+	var body string
+	server, requests := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		switch r.URL.Path {
+		case "/p-a/_pit":
+			w.Write([]byte(`{"id": "pit-1"}`))
+		case "/_pit":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"succeeded": false, "num_freed": 0}`))
+		case "/_search":
+			w.Write([]byte(`{"pit_id": "pit-2", "hits": {"total": {"value": 1}, "hits": [{"_index": "p-a", "_id": "1"}]}}`))
+		default:
+			w.Write([]byte(`{}`))
+		}
+	})
+	es, err := New(&Config{Address: []string{server.URL}, IndexPrefix: "p-", Preference: PreferenceLocal})
+	assert.NoError(t, err)
+
+	lastRequest := func() *http.Request {
+		reqs := requests()
+		return reqs[len(reqs)-1]
+	}
+
+	status, id, err := es.OpenPIT("a", time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSuccess, status)
+	assert.Equal(t, "pit-1", id)
+	assert.Equal(t, "60s", lastRequest().URL.Query().Get("keep_alive"))
+
+	_, result, err := es.SearchWithResult("", SearchBody(MatchAllQuery()), nil, PIT(id, 1500*time.Millisecond))
+	assert.NoError(t, err)
+	assert.Equal(t, "/_search", lastRequest().URL.Path)
+	assert.Empty(t, lastRequest().URL.Query().Get("preference"))
+	assert.JSONEq(t, `{"query": {"match_all": {}}, "pit": {"id": "pit-1", "keep_alive": "1500ms"}}`, body)
+	assert.Equal(t, "pit-2", result.PITID)
+	assert.Equal(t, "a", result.Hits[0].Index)
+
+	status, err = es.ClosePIT(result.PITID)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSuccess, status)
+	assert.Equal(t, http.MethodDelete, lastRequest().Method)
+	assert.JSONEq(t, `{"id": "pit-2"}`, body)
+}
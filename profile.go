@@ -0,0 +1,50 @@
+package elasticsearch
+
+import (
+	"time"
+)
+
+// ProfileResult is the timing of the components of a search run with the Profile option.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/search-profile.html
+type ProfileResult struct {
+	Shards []*ShardProfile `json:"shards"`
+}
+
+// ShardProfile is the profile of a search on one shard. ID is "[nodeID][index][shard]".
+type ShardProfile struct {
+	ID           string           `json:"id"`
+	Searches     []*SearchProfile `json:"searches"`
+	Aggregations []*ProfileNode   `json:"aggregations"`
+}
+
+type SearchProfile struct {
+	Query       []*ProfileNode      `json:"query"`
+	RewriteTime int64               `json:"rewrite_time"`
+	Collector   []*CollectorProfile `json:"collector"`
+}
+
+// ProfileNode is the timing of a Lucene query or an aggregation, and of its children.
+// Breakdown holds the nanoseconds spent in each phase, such as "create_weight" or "next_doc",
+// and their call counts.
+type ProfileNode struct {
+	Type        string           `json:"type"`
+	Description string           `json:"description"`
+	TimeInNanos int64            `json:"time_in_nanos"`
+	Breakdown   map[string]int64 `json:"breakdown"`
+	Children    []*ProfileNode   `json:"children"`
+}
+
+func (n *ProfileNode) Time() time.Duration {
+	return time.Duration(n.TimeInNanos)
+}
+
+type CollectorProfile struct {
+	Name        string              `json:"name"`
+	Reason      string              `json:"reason"`
+	TimeInNanos int64               `json:"time_in_nanos"`
+	Children    []*CollectorProfile `json:"children"`
+}
+
+func (c *CollectorProfile) Time() time.Duration {
+	return time.Duration(c.TimeInNanos)
+}
@@ -0,0 +1,54 @@
+package elasticsearch
+
+import (
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfile(t *testing.T) {
+	var body string
+	server, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		w.Write([]byte(`{
+			"hits": {"total": {"value": 0}, "hits": []},
+			"profile": {"shards": [{
+				"id": "[n1][a][0]",
+				"searches": [{
+					"query": [{
+						"type": "BooleanQuery",
+						"description": "+title:elastic",
+						"time_in_nanos": 1500000,
+						"breakdown": {"create_weight": 1000, "next_doc": 2000, "next_doc_count": 3},
+						"children": [{"type": "TermQuery", "description": "title:elastic", "time_in_nanos": 700000}]
+					}],
+					"rewrite_time": 5000,
+					"collector": [{"name": "SimpleTopScoreDocCollector", "reason": "search_top_hits", "time_in_nanos": 20000}]
+				}],
+				"aggregations": []
+			}]}
+		}`))
+	})
+	es, err := New(&Config{Address: []string{server.URL}})
+	assert.NoError(t, err)
+
+	_, result, err := es.SearchWithResult("a", SearchBody(MatchQuery("title", "elastic")), nil, Profile())
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"query": {"match": {"title": "elastic"}}, "profile": true}`, body)
+	assert.Len(t, result.Profile.Shards, 1)
+	search := result.Profile.Shards[0].Searches[0]
+	assert.Equal(t, "BooleanQuery", search.Query[0].Type)
+	assert.Equal(t, 1500*time.Microsecond, search.Query[0].Time())
+	assert.Equal(t, int64(3), search.Query[0].Breakdown["next_doc_count"])
+	assert.Equal(t, "title:elastic", search.Query[0].Children[0].Description)
+	assert.Equal(t, 20*time.Microsecond, search.Collector[0].Time())
+
+	_, result, err = es.SearchWithResult("a", "", nil)
+	assert.NoError(t, err)
+	assert.Empty(t, body)
+}
@@ -0,0 +1,153 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+)
+
+// Query is a query DSL clause such as {"term": {"id": "1"}}.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/query-dsl.html
+type Query map[string]interface{}
+
+// Sort is one sort clause of a search request.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/sort-search-results.html
+type Sort map[string]interface{}
+
+// FieldSort sorts by field in order ("asc" or "desc").
+func FieldSort(field, order string) Sort {
+	return Sort{field: map[string]interface{}{"order": order}}
+}
+
+// SearchBody returns the request body for q, to be passed as the query of Search or Count.
+// Count does not accept sort.
+func SearchBody(q Query, sort ...Sort) string {
+	body := map[string]interface{}{
+		"query": q,
+	}
+	if len(sort) > 0 {
+		body["sort"] = sort
+	}
+	b, _ := json.Marshal(body)
+	return string(b)
+}
+
+func MatchAllQuery() Query {
+	return Query{"match_all": map[string]interface{}{}}
+}
+
+func TermQuery(field string, value interface{}) Query {
+	return Query{"term": map[string]interface{}{field: value}}
+}
+
+func TermsQuery(field string, values ...interface{}) Query {
+	return Query{"terms": map[string]interface{}{field: values}}
+}
+
+func MatchQuery(field string, text string) Query {
+	return Query{"match": map[string]interface{}{field: text}}
+}
+
+// ScoreFunction is one of the functions of a function_score query.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/query-dsl-function-score-query.html
+type ScoreFunction map[string]interface{}
+
+// WithWeight multiplies the score of the function by weight.
+func (f ScoreFunction) WithWeight(weight float64) ScoreFunction {
+	f["weight"] = weight
+	return f
+}
+
+// WithFilter applies the function only to documents matching filter.
+func (f ScoreFunction) WithFilter(filter Query) ScoreFunction {
+	f["filter"] = filter
+	return f
+}
+
+// WeightScore returns a function that scores every document with weight.
+func WeightScore(weight float64) ScoreFunction {
+	return ScoreFunction{"weight": weight}
+}
+
+// FieldValueFactor scores documents by the value of field.
+// modifier is one of none, log, log1p, log2p, ln, ln1p, ln2p, square, sqrt, reciprocal and may be empty.
+// Documents without the field are scored as if its value were 1.
+func FieldValueFactor(field string, factor float64, modifier string) ScoreFunction {
+	fvf := map[string]interface{}{
+		"field":   field,
+		"factor":  factor,
+		"missing": 1,
+	}
+	if modifier != "" {
+		fvf["modifier"] = modifier
+	}
+	return ScoreFunction{"field_value_factor": fvf}
+}
+
+type DecayFunction string
+
+const (
+	DecayGauss  DecayFunction = "gauss"
+	DecayLinear DecayFunction = "linear"
+	DecayExp    DecayFunction = "exp"
+)
+
+// Decay scores documents by the distance of field from origin, e.g.
+// Decay(DecayGauss, "created_at", "now", "10d", nil, 0) for recency boosting.
+// A nil offset and a zero decay use the server defaults.
+func Decay(fn DecayFunction, field string, origin, scale, offset interface{}, decay float64) ScoreFunction {
+	params := map[string]interface{}{
+		"origin": origin,
+		"scale":  scale,
+	}
+	if offset != nil {
+		params["offset"] = offset
+	}
+	if decay > 0 {
+		params["decay"] = decay
+	}
+	return ScoreFunction{string(fn): map[string]interface{}{field: params}}
+}
+
+// RandomScore scores documents randomly, differently on every request.
+func RandomScore() ScoreFunction {
+	return ScoreFunction{"random_score": map[string]interface{}{}}
+}
+
+// SeededRandomScore scores documents randomly but reproducibly for the same seed.
+// field should hold unique values per document, e.g. "_seq_no".
+func SeededRandomScore(seed int64, field string) ScoreFunction {
+	return ScoreFunction{"random_score": map[string]interface{}{
+		"seed":  seed,
+		"field": field,
+	}}
+}
+
+// FunctionScoreQuery modifies the scores of documents matching q with functions.
+// scoreMode and boostMode may be empty to use the server defaults (multiply).
+func FunctionScoreQuery(q Query, scoreMode, boostMode string, functions ...ScoreFunction) Query {
+	fs := map[string]interface{}{
+		"query":     q,
+		"functions": functions,
+	}
+	if scoreMode != "" {
+		fs["score_mode"] = scoreMode
+	}
+	if boostMode != "" {
+		fs["boost_mode"] = boostMode
+	}
+	return Query{"function_score": fs}
+}
+
+// ScriptScoreQuery scores documents matching q with a painless script.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/query-dsl-script-score-query.html
+func ScriptScoreQuery(q Query, source string, params map[string]interface{}) Query {
+	script := map[string]interface{}{
+		"source": source,
+	}
+	if len(params) > 0 {
+		script["params"] = params
+	}
+	return Query{"script_score": map[string]interface{}{
+		"query":  q,
+		"script": script,
+	}}
+}
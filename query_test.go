@@ -0,0 +1,96 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/bxcodec/faker/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func assertQueryJSON(t *testing.T, expected string, q interface{}) {
+	b, err := json.Marshal(q)
+	assert.NoError(t, err)
+	assert.JSONEq(t, expected, string(b))
+}
+
+func TestSearchBody(t *testing.T) {
+	assert.JSONEq(t, `{"query": {"term": {"id": "1"}}}`, SearchBody(TermQuery("id", "1")))
+}
+
+func TestFunctionScoreQuery(t *testing.T) {
+	t.Run("Functions", func(t *testing.T) {
+		q := FunctionScoreQuery(MatchAllQuery(), "sum", "replace",
+			FieldValueFactor("likes", 1.2, "log1p"),
+			Decay(DecayGauss, "created_at", "now", "10d", "1d", 0.5),
+			SeededRandomScore(42, "_seq_no"),
+			WeightScore(3).WithFilter(TermQuery("b", true)),
+		)
+
+		assertQueryJSON(t, `{
+			"function_score": {
+				"query": {"match_all": {}},
+				"score_mode": "sum",
+				"boost_mode": "replace",
+				"functions": [
+					{"field_value_factor": {"field": "likes", "factor": 1.2, "modifier": "log1p", "missing": 1}},
+					{"gauss": {"created_at": {"origin": "now", "scale": "10d", "offset": "1d", "decay": 0.5}}},
+					{"random_score": {"seed": 42, "field": "_seq_no"}},
+					{"weight": 3, "filter": {"term": {"b": true}}}
+				]
+			}
+		}`, q)
+	})
+
+	t.Run("Defaults", func(t *testing.T) {
+		q := FunctionScoreQuery(MatchAllQuery(), "", "", RandomScore().WithWeight(2))
+
+		assertQueryJSON(t, `{
+			"function_score": {
+				"query": {"match_all": {}},
+				"functions": [{"random_score": {}, "weight": 2}]
+			}
+		}`, q)
+	})
+}
+
+func TestScriptScoreQuery(t *testing.T) {
+	q := ScriptScoreQuery(MatchQuery("s", "foo"), "doc['i'].value * params.factor", map[string]interface{}{"factor": 2})
+
+	assertQueryJSON(t, `{
+		"script_score": {
+			"query": {"match": {"s": "foo"}},
+			"script": {"source": "doc['i'].value * params.factor", "params": {"factor": 2}}
+		}
+	}`, q)
+}
+
+func TestSearchFunctionScore(t *testing.T) {
+	es := newElasticsearch()
+
+	group := faker.UUIDDigit()
+	for i := 1; i <= 3; i++ {
+		var data DocBody
+		faker.FakeData(&data)
+		data.Id = group
+		data.I = i
+		es.CreateDocument(&Document{
+			Index: indexName,
+			ID:    fmt.Sprintf("%s-%d", group, i),
+			Body:  data,
+		})
+	}
+	es.Refresh(indexName)
+
+	var list []DocBody
+	status, hits, total, err := es.Search(indexName, SearchBody(
+		ScriptScoreQuery(TermQuery("id", group), "doc['i'].value", nil),
+	), &list)
+
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSuccess, status)
+	assert.Equal(t, 3, total)
+	assert.Equal(t, 3, list[0].I)
+	assert.Equal(t, float64(3), hits[0].Score)
+}
@@ -0,0 +1,145 @@
+package elasticsearch
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+)
+
+// Operation is the kind of a request, used to apply separate limits to e.g. searches and writes.
+type Operation string
+
+const (
+	OperationSearch Operation = "search"
+	OperationRead   Operation = "read"
+	OperationWrite  Operation = "write"
+	OperationAdmin  Operation = "admin"
+)
+
+// requestOperation classifies req from its method and path.
+func requestOperation(req *http.Request) Operation {
+	for _, segment := range strings.Split(req.URL.Path, "/") {
+		switch segment {
+		case "_search", "_msearch", "_count", "_explain", "_validate", "_termvectors", "_mtermvectors", "_terms_enum", "_field_caps", "_pit":
+			return OperationSearch
+		case "_mget":
+			return OperationRead
+		case "_bulk", "_update", "_create", "_update_by_query", "_delete_by_query", "_reindex":
+			return OperationWrite
+		case "_doc", "_source":
+			if req.Method == http.MethodGet || req.Method == http.MethodHead {
+				return OperationRead
+			}
+			return OperationWrite
+		}
+	}
+	return OperationAdmin
+}
+
+// RateLimit throttles requests with a token bucket: PerSecond requests are allowed per second
+// on average, with bursts of up to Burst requests. A throttled request waits for its turn
+// until the context of the request (see WithContext) is done.
+type RateLimit struct {
+	PerSecond float64
+	// Burst defaults to 1.
+	Burst int
+}
+
+func (l RateLimit) validate() error {
+	if l.PerSecond <= 0 {
+		return errors.New("RateLimit.PerSecond must be positive")
+	}
+	if l.Burst < 0 {
+		return errors.New("RateLimit.Burst must not be negative")
+	}
+	return nil
+}
+
+type tokenBucket struct {
+	rate  float64
+	burst float64
+	now   func() time.Time
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(l RateLimit) *tokenBucket {
+	burst := float64(l.Burst)
+	if burst == 0 {
+		burst = 1
+	}
+	return &tokenBucket{
+		rate:   l.PerSecond,
+		burst:  burst,
+		now:    time.Now,
+		tokens: burst,
+		last:   time.Now(),
+	}
+}
+
+// reserve takes a token and returns how long to wait before using it.
+func (b *tokenBucket) reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// cancel gives back a token reserved but not used.
+func (b *tokenBucket) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens++
+}
+
+func rateLimitMiddleware(global *tokenBucket, perOperation map[Operation]*tokenBucket) middleware {
+	return func(next esapi.Transport) esapi.Transport {
+		return transportFunc(func(req *http.Request) (*http.Response, error) {
+			buckets := []*tokenBucket{}
+			if global != nil {
+				buckets = append(buckets, global)
+			}
+			if b, ok := perOperation[requestOperation(req)]; ok {
+				buckets = append(buckets, b)
+			}
+
+			var wait time.Duration
+			for _, b := range buckets {
+				if d := b.reserve(); d > wait {
+					wait = d
+				}
+			}
+			if wait > 0 {
+				timer := time.NewTimer(wait)
+				select {
+				case <-req.Context().Done():
+					timer.Stop()
+					for _, b := range buckets {
+						b.cancel()
+					}
+					return nil, req.Context().Err()
+				case <-timer.C:
+				}
+			}
+
+			return next.Perform(req)
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package elasticsearch
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestOperation(t *testing.T) {
+	cases := []struct {
+		method string
+		path   string
+		want   Operation
+	}{
+		{http.MethodPost, "/index/_search", OperationSearch},
+		{http.MethodPost, "/index/_count", OperationSearch},
+		{http.MethodGet, "/index/_doc/1", OperationRead},
+		{http.MethodPut, "/index/_doc/1", OperationWrite},
+		{http.MethodDelete, "/index/_doc/1", OperationWrite},
+		{http.MethodPost, "/_bulk", OperationWrite},
+		{http.MethodPost, "/index/_mget", OperationRead},
+		{http.MethodPost, "/index/_pit", OperationSearch},
+		{http.MethodPost, "/index/_update/1", OperationWrite},
+		{http.MethodPut, "/_index_template/template", OperationAdmin},
+		{http.MethodDelete, "/index", OperationAdmin},
+	}
+	for _, c := range cases {
+		req := &http.Request{Method: c.method, URL: &url.URL{Path: c.path}}
+		assert.Equal(t, c.want, requestOperation(req), "%s %s", c.method, c.path)
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Now()
+	b := newTokenBucket(RateLimit{PerSecond: 10, Burst: 2})
+	b.now = func() time.Time { return now }
+	b.last = now
+
+	assert.Zero(t, b.reserve())
+	assert.Zero(t, b.reserve())
+	assert.Equal(t, 100*time.Millisecond, b.reserve())
+	assert.Equal(t, 200*time.Millisecond, b.reserve())
+
+	b.cancel()
+	now = now.Add(time.Second)
+	assert.Zero(t, b.reserve(), "refilled up to the burst")
+	assert.Zero(t, b.reserve())
+	assert.NotZero(t, b.reserve())
+}
+
+func TestRateLimit(t *testing.T) {
+	server, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"hits": {"total": {"value": 0, "relation": "eq"}, "hits": []}}`))
+	})
+
+	es, err := New(&Config{
+		Address: []string{server.URL},
+		OperationRateLimits: map[Operation]RateLimit{
+			OperationSearch: {PerSecond: 0.1},
+		},
+	})
+	assert.NoError(t, err)
+
+	_, _, _, err = es.Search("x", `{"query": {"match_all": {}}}`, nil)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, _, _, err = es.WithContext(ctx).Search("x", `{"query": {"match_all": {}}}`, nil)
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "throttled for 10s")
+
+	assert.NoError(t, es.Ping(), "other operations are not throttled")
+}
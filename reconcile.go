@@ -0,0 +1,226 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// SourceRecord is a record of the source of truth of an index, e.g. a row of a table.
+type SourceRecord struct {
+	ID string
+	// Version is compared with the _version of the document, when the index uses external versioning
+	// and ReconcileConfig.HashField is empty. Repairs index the document with this external version.
+	Version int64
+	// Hash is compared with the HashField of the document, e.g. a hash of the row.
+	Hash string
+	// Doc is the document indexed to repair a missing or stale document.
+	Doc interface{}
+}
+
+// SourceIterator yields the records of the source of truth.
+type SourceIterator interface {
+	// Next returns the next record, or io.EOF after the last one.
+	Next(ctx context.Context) (*SourceRecord, error)
+}
+
+type ReconcileConfig struct {
+	Index  string
+	Source SourceIterator
+	// HashField is the field of the documents compared with SourceRecord.Hash.
+	// When empty, SourceRecord.Version is compared with the _version of the documents, and a document
+	// with a greater version than its record cannot be repaired: its repair fails with a conflict.
+	HashField string
+	// Repair indexes the missing and stale documents and deletes the orphaned ones.
+	Repair bool
+	// BatchSize is the number of records checked per request. Default: 500.
+	BatchSize int
+}
+
+// ReconcileReport lists the IDs of the documents out of sync with the source of truth.
+type ReconcileReport struct {
+	// Checked is the number of records of the source.
+	Checked int
+	// Missing are the records without document.
+	Missing []string
+	// Stale are the documents whose hash or version differ from their record.
+	Stale []string
+	// Orphaned are the documents without record.
+	Orphaned []string
+	// Repaired is the number of documents indexed or deleted by Repair.
+	Repaired int
+}
+
+// Reconcile compares the records of config.Source with the documents of config.Index, and reports
+// the missing, stale and orphaned documents. With config.Repair, it also fixes them with bulk requests.
+// The IDs of the records are held in memory to find the orphaned documents.
+func Reconcile(ctx context.Context, es Elasticsearch, config *ReconcileConfig) (*ReconcileReport, error) {
+	if config.Source == nil {
+		return nil, errors.New("ReconcileConfig.Source must not be nil")
+	}
+	batchSize := config.BatchSize
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+
+	es = es.WithContext(ctx)
+	report := &ReconcileReport{Missing: []string{}, Stale: []string{}, Orphaned: []string{}}
+	seen := map[string]bool{}
+
+	for done := false; !done; {
+		batch := make([]*SourceRecord, 0, batchSize)
+		for len(batch) < batchSize {
+			record, err := config.Source.Next(ctx)
+			if err == io.EOF {
+				done = true
+				break
+			}
+			if err != nil {
+				return report, err
+			}
+			seen[record.ID] = true
+			batch = append(batch, record)
+		}
+		if len(batch) == 0 {
+			break
+		}
+		report.Checked += len(batch)
+
+		repairs, err := reconcileBatch(es, config, batch, report)
+		if err != nil {
+			return report, err
+		}
+		if err := reconcileRepair(es, config, repairs, report); err != nil {
+			return report, err
+		}
+	}
+
+	orphans := &orphanWriter{seen: seen}
+	if _, _, err := es.Export(ctx, config.Index, `{"_source": false}`, orphans, ExportPageSize(batchSize)); err != nil {
+		return report, err
+	}
+	if orphans.err != nil {
+		return report, orphans.err
+	}
+	report.Orphaned = append(report.Orphaned, orphans.ids...)
+
+	deletes := make([]*BulkItem, len(orphans.ids))
+	for i, id := range orphans.ids {
+		deletes[i] = &BulkItem{Action: BulkDelete, Index: config.Index, ID: id}
+	}
+	if err := reconcileRepair(es, config, deletes, report); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+// reconcileBatch reports the missing and stale documents of batch, and returns the items repairing them.
+func reconcileBatch(es Elasticsearch, config *ReconcileConfig, batch []*SourceRecord, report *ReconcileReport) ([]*BulkItem, error) {
+	ids := make([]interface{}, len(batch))
+	for i, record := range batch {
+		ids[i] = record.ID
+	}
+	body := map[string]interface{}{
+		"query":   Query{"ids": map[string]interface{}{"values": ids}},
+		"size":    len(batch),
+		"version": true,
+		"_source": false,
+	}
+	if config.HashField != "" {
+		body["_source"] = []string{config.HashField}
+	}
+	query, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	_, hits, _, err := es.Search(config.Index, string(query), nil)
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[string]*HitData, len(hits))
+	for _, hit := range hits {
+		found[hit.Id] = hit
+	}
+
+	repairs := []*BulkItem{}
+	for _, record := range batch {
+		hit, ok := found[record.ID]
+		switch {
+		case !ok:
+			report.Missing = append(report.Missing, record.ID)
+		case config.HashField != "" && hashOf(hit.Source, config.HashField) != record.Hash,
+			config.HashField == "" && hit.Version != record.Version:
+			report.Stale = append(report.Stale, record.ID)
+		default:
+			continue
+		}
+		if record.Doc != nil {
+			item := &BulkItem{Index: config.Index, ID: record.ID, Body: record.Doc}
+			if config.HashField == "" {
+				item.Version = record.Version
+			}
+			repairs = append(repairs, item)
+		}
+	}
+	return repairs, nil
+}
+
+// hashOf returns the string value of field of source, or "" when missing.
+func hashOf(source json.RawMessage, field string) string {
+	var fields map[string]interface{}
+	json.Unmarshal(source, &fields)
+	if v, ok := fields[field].(string); ok {
+		return v
+	}
+	return ""
+}
+
+func reconcileRepair(es Elasticsearch, config *ReconcileConfig, items []*BulkItem, report *ReconcileReport) error {
+	if !config.Repair || len(items) == 0 {
+		return nil
+	}
+
+	_, results, err := es.Bulk(items, "")
+	var bulkErr *BulkError
+	if err != nil && !errors.As(err, &bulkErr) {
+		return err
+	}
+	for _, result := range results {
+		if result.Error == nil {
+			report.Repaired++
+		}
+	}
+	return err
+}
+
+// orphanWriter collects the IDs of the NDJSON lines of Export which are not in seen.
+type orphanWriter struct {
+	seen map[string]bool
+	buf  []byte
+	ids  []string
+	err  error
+}
+
+func (w *orphanWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		var doc struct {
+			ID string `json:"_id"`
+		}
+		if err := json.Unmarshal(w.buf[:i], &doc); err != nil {
+			w.err = err
+			return 0, err
+		}
+		if !w.seen[doc.ID] {
+			w.ids = append(w.ids, doc.ID)
+		}
+		w.buf = w.buf[i+1:]
+	}
+}
@@ -0,0 +1,181 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordIterator struct {
+	records []*SourceRecord
+}
+
+func (it *recordIterator) Next(ctx context.Context) (*SourceRecord, error) {
+	if len(it.records) == 0 {
+		return nil, io.EOF
+	}
+	r := it.records[0]
+	it.records = it.records[1:]
+	return r, nil
+}
+
+func TestReconcile(t *testing.T) {
+	// The index holds 1 (up to date), 2 (stale) and 9 (orphaned). 3 is missing.
+	hashes := map[string]string{"1": "h1", "2": "old", "9": "h9"}
+	var bulk string
+	server, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		switch {
+		case r.URL.Path == "/_bulk":
+			bulk = string(b)
+			var items []string
+			for i := 0; i < strings.Count(bulk, `"_id"`); i++ {
+				items = append(items, `{"index": {"_index": "a", "status": 200}}`)
+			}
+			fmt.Fprintf(w, `{"items": [%s]}`, strings.Join(items, ","))
+		case r.URL.Path == "/_search/scroll":
+			w.Write([]byte(`{"_scroll_id": "s", "hits": {"hits": []}}`))
+		case r.URL.Query().Get("scroll") != "":
+			w.Write([]byte(`{"_scroll_id": "s", "hits": {"hits": [{"_id": "1"}, {"_id": "2"}, {"_id": "9"}]}}`))
+		case r.URL.Path == "/a/_search":
+			var body struct {
+				Query struct {
+					IDs struct {
+						Values []string `json:"values"`
+					} `json:"ids"`
+				} `json:"query"`
+			}
+			json.Unmarshal(b, &body)
+			var hits []string
+			for _, id := range body.Query.IDs.Values {
+				if hash, ok := hashes[id]; ok {
+					hits = append(hits, fmt.Sprintf(`{"_id": "%s", "_version": 1, "_source": {"hash": "%s"}}`, id, hash))
+				}
+			}
+			fmt.Fprintf(w, `{"hits": {"total": {"value": %d}, "hits": [%s]}}`, len(hits), strings.Join(hits, ","))
+		default:
+			w.Write([]byte(`{}`))
+		}
+	})
+	es, err := New(&Config{Address: []string{server.URL}})
+	assert.NoError(t, err)
+
+	records := func() SourceIterator {
+		return &recordIterator{records: []*SourceRecord{
+			{ID: "1", Hash: "h1", Doc: map[string]string{"hash": "h1"}},
+			{ID: "2", Hash: "h2", Doc: map[string]string{"hash": "h2"}},
+			{ID: "3", Hash: "h3", Doc: map[string]string{"hash": "h3"}},
+		}}
+	}
+
+	t.Run("Report", func(t *testing.T) {
+		report, err := Reconcile(context.Background(), es, &ReconcileConfig{Index: "a", Source: records(), HashField: "hash", BatchSize: 2})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, report.Checked)
+		assert.Equal(t, []string{"3"}, report.Missing)
+		assert.Equal(t, []string{"2"}, report.Stale)
+		assert.Equal(t, []string{"9"}, report.Orphaned)
+		assert.Equal(t, 0, report.Repaired)
+		assert.Empty(t, bulk)
+	})
+
+	t.Run("Repair", func(t *testing.T) {
+		report, err := Reconcile(context.Background(), es, &ReconcileConfig{Index: "a", Source: records(), HashField: "hash", Repair: true})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, report.Repaired)
+		assert.Contains(t, bulk, `{"delete":{"_id":"9","_index":"a"}}`)
+	})
+
+	t.Run("Version", func(t *testing.T) {
+		report, err := Reconcile(context.Background(), es, &ReconcileConfig{Index: "a", Source: &recordIterator{records: []*SourceRecord{
+			{ID: "1", Version: 1},
+			{ID: "2", Version: 2},
+		}}})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"2"}, report.Stale)
+		assert.Equal(t, []string{"9"}, report.Orphaned)
+	})
+}
+
+func TestReconcileVersionRepair(t *testing.T) {
+	// The index versions the documents like Elasticsearch: externally when the write has version_type=external,
+	// otherwise by incrementing the version. It holds 1 (up to date) and 2 (stale). 3 is missing.
+	var mu sync.Mutex
+	versions := map[string]int64{"1": 5, "2": 1}
+	server, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		b, _ := io.ReadAll(r.Body)
+		switch {
+		case r.URL.Path == "/_bulk":
+			lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+			var items []string
+			for i := 0; i < len(lines); i += 2 {
+				var meta struct {
+					Index struct {
+						ID          string `json:"_id"`
+						Version     int64  `json:"version"`
+						VersionType string `json:"version_type"`
+					} `json:"index"`
+				}
+				json.Unmarshal([]byte(lines[i]), &meta)
+				if meta.Index.VersionType == "external" {
+					versions[meta.Index.ID] = meta.Index.Version
+				} else {
+					versions[meta.Index.ID]++
+				}
+				items = append(items, `{"index": {"_index": "a", "status": 200}}`)
+			}
+			fmt.Fprintf(w, `{"items": [%s]}`, strings.Join(items, ","))
+		case r.URL.Path == "/_search/scroll":
+			w.Write([]byte(`{"_scroll_id": "s", "hits": {"hits": []}}`))
+		case r.URL.Query().Get("scroll") != "":
+			var hits []string
+			for id := range versions {
+				hits = append(hits, fmt.Sprintf(`{"_id": "%s"}`, id))
+			}
+			fmt.Fprintf(w, `{"_scroll_id": "s", "hits": {"hits": [%s]}}`, strings.Join(hits, ","))
+		case r.URL.Path == "/a/_search":
+			var hits []string
+			for id, version := range versions {
+				hits = append(hits, fmt.Sprintf(`{"_id": "%s", "_version": %d}`, id, version))
+			}
+			fmt.Fprintf(w, `{"hits": {"total": {"value": %d}, "hits": [%s]}}`, len(hits), strings.Join(hits, ","))
+		default:
+			w.Write([]byte(`{}`))
+		}
+	})
+	es, err := New(&Config{Address: []string{server.URL}, Logger: NopLogger()})
+	assert.NoError(t, err)
+
+	reconcile := func() *ReconcileReport {
+		report, err := Reconcile(context.Background(), es, &ReconcileConfig{Index: "a", Repair: true, Source: &recordIterator{records: []*SourceRecord{
+			{ID: "1", Version: 5, Doc: map[string]string{}},
+			{ID: "2", Version: 7, Doc: map[string]string{}},
+			{ID: "3", Version: 3, Doc: map[string]string{}},
+		}}})
+		assert.NoError(t, err)
+		return report
+	}
+
+	report := reconcile()
+	assert.Equal(t, []string{"3"}, report.Missing)
+	assert.Equal(t, []string{"2"}, report.Stale)
+	assert.Equal(t, 2, report.Repaired)
+
+	report = reconcile()
+	assert.Empty(t, report.Missing)
+	assert.Empty(t, report.Stale)
+	assert.Empty(t, report.Orphaned)
+	assert.Equal(t, 0, report.Repaired)
+}
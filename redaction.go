@@ -0,0 +1,156 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// RedactAction is how a Redaction transforms a field.
+type RedactAction string
+
+const (
+	// RedactDrop removes the field.
+	RedactDrop RedactAction = "drop"
+	// RedactHash replaces the field with the hex SHA-256 of its value, or its HMAC-SHA256 with Config.RedactionKey,
+	// so that the documents holding the same value can still be matched.
+	RedactHash RedactAction = "hash"
+	// RedactMask replaces the characters of the field with '*', except the last 4 of strings longer than 8.
+	RedactMask RedactAction = "mask"
+)
+
+// Redaction transforms a field of the documents before they are written, e.g. so that the emails of
+// the users never reach the cluster in cleartext.
+type Redaction struct {
+	// Field is the path of the field, e.g. "user.email", applied to each object of the arrays along it.
+	Field  string
+	Action RedactAction
+}
+
+// redactionHook is the WriteHook of Config.Redactions, called after the Validators and before the other hooks,
+// so that the hooks publishing the changes only see the redacted documents.
+type redactionHook struct {
+	patterns   []string
+	redactions map[string][]Redaction
+	key        []byte
+}
+
+func newRedactionHook(redactions map[string][]Redaction, key []byte) *redactionHook {
+	h := &redactionHook{redactions: redactions, key: key}
+	for pattern := range redactions {
+		h.patterns = append(h.patterns, pattern)
+	}
+	sort.Strings(h.patterns)
+	return h
+}
+
+// BeforeWrite redacts the bodies of the events. A body which is not a JSON object cancels the write.
+func (h *redactionHook) BeforeWrite(ctx context.Context, events []*WriteEvent) error {
+	for _, event := range events {
+		if event.Body == nil {
+			continue
+		}
+		var redactions []Redaction
+		for _, pattern := range h.patterns {
+			if ok, _ := path.Match(pattern, event.Index); ok {
+				redactions = append(redactions, h.redactions[pattern]...)
+			}
+		}
+		if len(redactions) == 0 {
+			continue
+		}
+
+		var doc map[string]interface{}
+		dec := json.NewDecoder(bytes.NewReader(event.Body))
+		dec.UseNumber()
+		if err := dec.Decode(&doc); err != nil {
+			return fmt.Errorf("redact document ID=%s of index %s: %w", event.ID, event.Index, err)
+		}
+		for _, r := range redactions {
+			h.redact(doc, strings.Split(r.Field, "."), r.Action)
+		}
+		body, err := json.Marshal(doc)
+		if err != nil {
+			return fmt.Errorf("redact document ID=%s of index %s: %w", event.ID, event.Index, err)
+		}
+		event.Body = body
+	}
+	return nil
+}
+
+func (h *redactionHook) AfterWrite(ctx context.Context, events []*WriteEvent) {}
+
+// redact applies action to the field at fieldPath of v.
+func (h *redactionHook) redact(v interface{}, fieldPath []string, action RedactAction) {
+	switch v := v.(type) {
+	case []interface{}:
+		for _, e := range v {
+			h.redact(e, fieldPath, action)
+		}
+	case map[string]interface{}:
+		value, ok := v[fieldPath[0]]
+		if !ok {
+			return
+		}
+		if len(fieldPath) > 1 {
+			h.redact(value, fieldPath[1:], action)
+			return
+		}
+		switch action {
+		case RedactDrop:
+			delete(v, fieldPath[0])
+		case RedactHash:
+			v[fieldPath[0]] = h.hash(value)
+		case RedactMask:
+			v[fieldPath[0]] = mask(value)
+		}
+	}
+}
+
+// hash returns the hash of a string, or of the JSON of the other values.
+func (h *redactionHook) hash(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	var b []byte
+	if s, ok := value.(string); ok {
+		b = []byte(s)
+	} else {
+		b, _ = json.Marshal(value)
+	}
+
+	if len(h.key) == 0 {
+		sum := sha256.Sum256(b)
+		return hex.EncodeToString(sum[:])
+	}
+	mac := hmac.New(sha256.New, h.key)
+	mac.Write(b)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// mask returns the masked string of value, "****" for the values which are not strings.
+func mask(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	s, ok := value.(string)
+	if !ok {
+		return "****"
+	}
+	runes := []rune(s)
+	keep := 0
+	if len(runes) > 8 {
+		keep = 4
+	}
+	for i := range runes[:len(runes)-keep] {
+		runes[i] = '*'
+	}
+	return string(runes)
+}
@@ -0,0 +1,80 @@
+package elasticsearch
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactions(t *testing.T) {
+	var body string
+	server, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		w.Write([]byte(`{}`))
+	})
+	hook := &recordingHook{}
+	es, err := New(&Config{
+		Address: []string{server.URL},
+		Redactions: map[string][]Redaction{
+			"users*": {
+				{Field: "email", Action: RedactHash},
+				{Field: "ssn", Action: RedactDrop},
+				{Field: "cards.number", Action: RedactMask},
+				{Field: "phone", Action: RedactMask},
+			},
+		},
+		WriteHooks: []WriteHook{hook},
+	})
+	assert.NoError(t, err)
+	sum := sha256.Sum256([]byte("alice@example.com"))
+	hash := hex.EncodeToString(sum[:])
+
+	t.Run("CreateDocument", func(t *testing.T) {
+		_, err := es.CreateDocument(&Document{Index: "users", ID: "1", Body: map[string]interface{}{
+			"name":  "alice",
+			"email": "alice@example.com",
+			"ssn":   "123-45-6789",
+			"cards": []map[string]interface{}{{"number": "4242424242424242"}, {"number": 42}},
+			"phone": "12345",
+			"age":   30,
+		}})
+		assert.NoError(t, err)
+
+		expected := `{"name": "alice", "email": "` + hash + `", "cards": [{"number": "************4242"}, {"number": "****"}], "phone": "*****", "age": 30}`
+		assert.JSONEq(t, expected, body)
+		assert.JSONEq(t, expected, string(hook.after[len(hook.after)-1][0].Body))
+	})
+
+	t.Run("Bulk", func(t *testing.T) {
+		_, _, err := es.Bulk([]*BulkItem{
+			{Action: BulkUpdate, Index: "users-2", ID: "1", Body: map[string]string{"email": "alice@example.com"}},
+			{Index: "logs", ID: "1", Body: map[string]string{"email": "alice@example.com"}},
+		}, RefreshFalse)
+		assert.NoError(t, err)
+
+		lines := strings.Split(strings.TrimSpace(body), "\n")
+		assert.JSONEq(t, `{"doc": {"email": "`+hash+`"}}`, lines[1])
+		assert.JSONEq(t, `{"email": "alice@example.com"}`, lines[3])
+	})
+
+	t.Run("RedactionKey", func(t *testing.T) {
+		mac := hmac.New(sha256.New, []byte("secret"))
+		mac.Write([]byte("alice@example.com"))
+
+		h := newRedactionHook(nil, []byte("secret"))
+		assert.Equal(t, hex.EncodeToString(mac.Sum(nil)), h.hash("alice@example.com"))
+		assert.NotEqual(t, hash, h.hash("alice@example.com"))
+	})
+
+	t.Run("Invalid body", func(t *testing.T) {
+		_, err := es.CreateDocument(&Document{Index: "users", ID: "1", Body: []byte(`[1]`)})
+		assert.Error(t, err)
+	})
+}
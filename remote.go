@@ -0,0 +1,75 @@
+package elasticsearch
+
+import (
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+)
+
+// RemoteIndex returns the name of index of the remote cluster for cross-cluster search, e.g.
+// Search(Indices("logs", RemoteIndex("eu", "logs")), query, &data).
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/modules-cross-cluster-search.html
+func RemoteIndex(cluster, index string) string {
+	return cluster + ":" + index
+}
+
+// PutRemoteCluster registers the remote cluster name in the persistent cluster settings,
+// connecting to it through seeds, e.g. "10.0.0.1:9300". When skipUnavailable is true,
+// searches across clusters skip name while it is unreachable instead of failing.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/remote-clusters-connect.html
+func (es *_elasticsearch) PutRemoteCluster(name string, skipUnavailable bool, seeds ...string) (StatusCode, error) {
+	return es.PutClusterSettings(&ClusterSettings{
+		Persistent: map[string]interface{}{
+			"cluster.remote." + name + ".seeds":            seeds,
+			"cluster.remote." + name + ".skip_unavailable": skipUnavailable,
+		},
+	})
+}
+
+// DeleteRemoteCluster unregisters the remote cluster name.
+func (es *_elasticsearch) DeleteRemoteCluster(name string) (StatusCode, error) {
+	return es.PutClusterSettings(&ClusterSettings{
+		Persistent: map[string]interface{}{
+			"cluster.remote." + name + ".seeds":            nil,
+			"cluster.remote." + name + ".skip_unavailable": nil,
+		},
+	})
+}
+
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/cluster-remote-info.html
+type RemoteCluster struct {
+	Connected         bool     `json:"connected"`
+	Mode              string   `json:"mode"`
+	Seeds             []string `json:"seeds"`
+	NumNodesConnected int      `json:"num_nodes_connected"`
+	SkipUnavailable   bool     `json:"skip_unavailable"`
+}
+
+// GetRemoteClusters returns the remote clusters by name.
+func (es *_elasticsearch) GetRemoteClusters() (StatusCode, map[string]*RemoteCluster, error) {
+	req := esapi.ClusterRemoteInfoRequest{}
+
+	res, err := req.Do(es.ctx, es.client)
+
+	clusters := map[string]*RemoteCluster{}
+	if status, err := es.handleResponse("get remote clusters", res, err, &clusters); err != nil {
+		return status, map[string]*RemoteCluster{}, err
+	}
+	return StatusSuccess, clusters, nil
+}
+
+// ClustersInfo reports the clusters searched by a cross-cluster search.
+// A remote cluster skipped as unavailable is counted in Skipped, and its hits are missing.
+type ClustersInfo struct {
+	Total      int `json:"total"`
+	Successful int `json:"successful"`
+	Skipped    int `json:"skipped"`
+}
+
+// splitRemoteIndex splits the cluster of a remote index returned by Elasticsearch, e.g. "eu:logs".
+func splitRemoteIndex(index string) (cluster, name string) {
+	if i := strings.Index(index, ":"); i >= 0 {
+		return index[:i+1], index[i+1:]
+	}
+	return "", index
+}
@@ -0,0 +1,52 @@
+package elasticsearch
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemoteClusterSettings(t *testing.T) {
+	var body string
+	server, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		w.Write([]byte(`{"acknowledged": true}`))
+	})
+	es, err := New(&Config{Address: []string{server.URL}})
+	assert.NoError(t, err)
+
+	status, err := es.PutRemoteCluster("eu", true, "10.0.0.1:9300", "10.0.0.2:9300")
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSuccess, status)
+	assert.JSONEq(t, `{"persistent": {"cluster.remote.eu.seeds": ["10.0.0.1:9300", "10.0.0.2:9300"], "cluster.remote.eu.skip_unavailable": true}}`, body)
+
+	status, err = es.DeleteRemoteCluster("eu")
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSuccess, status)
+	assert.JSONEq(t, `{"persistent": {"cluster.remote.eu.seeds": null, "cluster.remote.eu.skip_unavailable": null}}`, body)
+}
+
+func TestCrossClusterSearch(t *testing.T) {
+	server, requests := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"_clusters": {"total": 2, "successful": 1, "skipped": 1},
+			"_shards": {"total": 1, "successful": 1, "skipped": 0, "failed": 0},
+			"hits": {"total": {"value": 1}, "hits": [{"_index": "eu:dev-articles", "_id": "1", "_source": {"id": "1"}}]}}`))
+	})
+	es, err := New(&Config{Address: []string{server.URL}, IndexPrefix: "dev-"})
+	assert.NoError(t, err)
+
+	var docs []*DocBody
+	status, result, err := es.SearchWithResult(Indices("articles", RemoteIndex("eu", "articles")), SearchBody(MatchAllQuery()), &docs)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSuccess, status)
+	assert.Equal(t, &ClustersInfo{Total: 2, Successful: 1, Skipped: 1}, result.Clusters)
+	assert.Equal(t, "eu:articles", result.Hits[0].Index)
+	assert.Len(t, docs, 1)
+
+	reqs := requests()
+	assert.True(t, strings.HasPrefix(reqs[len(reqs)-1].URL.Path, "/dev-articles,eu:dev-articles/_search"))
+}
@@ -0,0 +1,98 @@
+package elasticsearch
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Repository stores the documents of type T in an index. The ID of a document is the field
+// of T tagged `es:"id"`, formatted with fmt.Sprint; documents without it get generated IDs.
+//
+//	type Article struct {
+//		ID    string `json:"id" es:"id"`
+//		Title string `json:"title"`
+//	}
+//
+//	articles, err := elasticsearch.NewRepository[Article](es, "articles")
+//	articles.Save(&Article{ID: "1", Title: "Elasticsearch"}, elasticsearch.RefreshWaitFor)
+//	status, found, total, err := articles.Search(elasticsearch.SearchBody(elasticsearch.MatchQuery("title", "elasticsearch")))
+type Repository[T any] struct {
+	es      Elasticsearch
+	index   string
+	idField []int
+}
+
+// NewRepository binds T, which must be a struct, to index.
+func NewRepository[T any](es Elasticsearch, index string) (*Repository[T], error) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("Repository requires a struct, not %s", t)
+	}
+
+	r := &Repository[T]{es: es, index: index}
+	for _, field := range reflect.VisibleFields(t) {
+		if field.Tag.Get("es") == "id" {
+			r.idField = field.Index
+			break
+		}
+	}
+	return r, nil
+}
+
+func (r *Repository[T]) Index() string {
+	return r.index
+}
+
+// ID returns the ID of doc, or "" when T has no ID field or it is the zero value.
+func (r *Repository[T]) ID(doc *T) string {
+	if r.idField == nil {
+		return ""
+	}
+	v := reflect.ValueOf(doc).Elem().FieldByIndex(r.idField)
+	if v.IsZero() {
+		return ""
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// Save indexes doc, replacing the document with the same ID.
+func (r *Repository[T]) Save(doc *T, refresh RefreshPolicy) (StatusCode, error) {
+	return r.es.CreateDocument(&Document{
+		Index:   r.index,
+		ID:      r.ID(doc),
+		Body:    doc,
+		Refresh: refresh,
+	})
+}
+
+// Get returns the document id, or ErrNotFound.
+func (r *Repository[T]) Get(id string) (StatusCode, *T, error) {
+	var doc T
+	status, err := r.es.GetSource(r.index, id, &doc)
+	if err != nil {
+		return status, nil, err
+	}
+	return status, &doc, nil
+}
+
+func (r *Repository[T]) Delete(id string) (StatusCode, error) {
+	if id == "" {
+		return StatusInternalError, errors.New("Required id")
+	}
+	return r.es.RemoveDocument(&Document{Index: r.index, ID: id})
+}
+
+// Search returns the documents matching query and the total number of them.
+func (r *Repository[T]) Search(query string) (StatusCode, []T, int, error) {
+	docs := []T{}
+	status, _, total, err := r.es.Search(r.index, query, &docs)
+	if err != nil {
+		return status, []T{}, 0, err
+	}
+	return status, docs, total, nil
+}
+
+func (r *Repository[T]) Count(query string) (StatusCode, int, error) {
+	return r.es.Count(r.index, query)
+}
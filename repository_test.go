@@ -0,0 +1,72 @@
+package elasticsearch
+
+import (
+	"testing"
+
+	"github.com/bxcodec/faker/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+type repositoryDoc struct {
+	ID    int    `json:"id" es:"id"`
+	Title string `json:"title"`
+}
+
+func TestNewRepository(t *testing.T) {
+	_, err := NewRepository[string](NewFake(), "x")
+	assert.Error(t, err)
+
+	r, err := NewRepository[repositoryDoc](NewFake(), "x")
+	assert.NoError(t, err)
+	assert.Equal(t, "x", r.Index())
+	assert.Equal(t, "42", r.ID(&repositoryDoc{ID: 42}))
+	assert.Empty(t, r.ID(&repositoryDoc{}))
+
+	untagged, _ := NewRepository[DocBody](NewFake(), "x")
+	assert.Empty(t, untagged.ID(&DocBody{Id: "1"}))
+}
+
+func TestRepository(t *testing.T) {
+	for name, es := range map[string]Elasticsearch{
+		"Fake":          NewFake(),
+		"Elasticsearch": newElasticsearch(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			defer es.DeleteIndeces(indexName)
+
+			r, err := NewRepository[repositoryDoc](es, indexName)
+			assert.NoError(t, err)
+
+			title := faker.Word()
+			for i := 1; i <= 3; i++ {
+				status, err := r.Save(&repositoryDoc{ID: i, Title: title}, RefreshTrue)
+				assert.NoError(t, err)
+				assert.Equal(t, StatusCreated, status)
+			}
+
+			status, doc, err := r.Get("2")
+			assert.NoError(t, err)
+			assert.Equal(t, StatusSuccess, status)
+			assert.Equal(t, title, doc.Title)
+
+			status, docs, total, err := r.Search(SearchBody(TermQuery("id", 3)))
+			assert.NoError(t, err)
+			assert.Equal(t, StatusSuccess, status)
+			assert.Equal(t, 1, total)
+			assert.Equal(t, []repositoryDoc{{ID: 3, Title: title}}, docs)
+
+			status, err = r.Delete("2")
+			assert.NoError(t, err)
+			assert.Equal(t, StatusSuccess, status)
+			es.Refresh(indexName)
+
+			status, _, err = r.Get("2")
+			assert.ErrorIs(t, err, ErrNotFound)
+			assert.Equal(t, StatusNotFoundError, status)
+
+			_, count, err := r.Count(SearchBody(MatchAllQuery()))
+			assert.NoError(t, err)
+			assert.Equal(t, 2, count)
+		})
+	}
+}
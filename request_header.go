@@ -0,0 +1,57 @@
+package elasticsearch
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+)
+
+type requestHeaderKey struct{}
+
+// WithRequestHeader returns a context sending header with the requests made with it, e.g.
+//
+//	ctx = elasticsearch.WithRequestHeader(ctx, http.Header{"X-Traffic-Tag": []string{"batch"}})
+//	es.WithContext(ctx).Search(...)
+//
+// The header is merged with the one already in ctx, replacing its values of the same keys.
+// The values of Config.Header are sent as well.
+func WithRequestHeader(ctx context.Context, header http.Header) context.Context {
+	merged := RequestHeader(ctx).Clone()
+	if merged == nil {
+		merged = http.Header{}
+	}
+	for key, values := range header {
+		merged[http.CanonicalHeaderKey(key)] = append([]string{}, values...)
+	}
+	return context.WithValue(ctx, requestHeaderKey{}, merged)
+}
+
+// RequestHeader returns the header set by WithRequestHeader.
+func RequestHeader(ctx context.Context) http.Header {
+	header, _ := ctx.Value(requestHeaderKey{}).(http.Header)
+	return header
+}
+
+// requestHeaderMiddleware sets header, then the header of fromContext and of WithRequestHeader.
+func requestHeaderMiddleware(header http.Header, fromContext func(ctx context.Context) http.Header) middleware {
+	return func(next esapi.Transport) esapi.Transport {
+		return transportFunc(func(req *http.Request) (*http.Response, error) {
+			setHeader(req, header)
+			if fromContext != nil {
+				setHeader(req, fromContext(req.Context()))
+			}
+			setHeader(req, RequestHeader(req.Context()))
+			return next.Perform(req)
+		})
+	}
+}
+
+func setHeader(req *http.Request, header http.Header) {
+	for key, values := range header {
+		req.Header.Del(key)
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+}
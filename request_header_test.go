@@ -0,0 +1,59 @@
+package elasticsearch
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type trafficTagKey struct{}
+
+func TestRequestHeader(t *testing.T) {
+	server, requests := newTestServer(t, nil)
+
+	es, err := New(&Config{
+		Address: []string{server.URL},
+		HeaderFromContext: func(ctx context.Context) http.Header {
+			tag, _ := ctx.Value(trafficTagKey{}).(string)
+			if tag == "" {
+				return nil
+			}
+			return http.Header{"X-Traffic-Tag": []string{tag}}
+		},
+	})
+	assert.NoError(t, err)
+
+	lastHeader := func() http.Header {
+		reqs := requests()
+		return reqs[len(reqs)-1].Header
+	}
+
+	t.Run("WithRequestHeader", func(t *testing.T) {
+		ctx := WithRequestHeader(context.Background(), http.Header{"x-tenant": []string{"a"}, "X-Tag": []string{"1"}})
+		ctx = WithRequestHeader(ctx, http.Header{"X-Tag": []string{"2", "3"}})
+		assert.Equal(t, http.Header{"X-Tenant": []string{"a"}, "X-Tag": []string{"2", "3"}}, RequestHeader(ctx))
+
+		assert.NoError(t, es.WithContext(ctx).Ping())
+		assert.Equal(t, "a", lastHeader().Get("X-Tenant"))
+		assert.Equal(t, []string{"2", "3"}, lastHeader().Values("X-Tag"))
+	})
+
+	t.Run("HeaderFromContext", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), trafficTagKey{}, "batch")
+
+		assert.NoError(t, es.WithContext(ctx).Ping())
+		assert.Equal(t, "batch", lastHeader().Get("X-Traffic-Tag"))
+
+		ctx = WithRequestHeader(ctx, http.Header{"X-Traffic-Tag": []string{"interactive"}})
+		assert.NoError(t, es.WithContext(ctx).Ping())
+		assert.Equal(t, []string{"interactive"}, lastHeader().Values("X-Traffic-Tag"))
+	})
+
+	t.Run("None", func(t *testing.T) {
+		assert.NoError(t, es.Ping())
+		assert.Empty(t, lastHeader().Get("X-Tenant"))
+		assert.Empty(t, lastHeader().Get("X-Traffic-Tag"))
+	})
+}
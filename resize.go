@@ -0,0 +1,122 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+)
+
+// ShrinkIndex copies source into the new index target with fewer primary shards, a factor of those of source.
+// source must be read-only (see AddIndexBlock) and have a copy of every shard on a single node.
+// When wait is positive, ShrinkIndex waits up to wait for the primary shards of target to be allocated.
+// The write block of source is not copied to target.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-shrink-index.html
+func (es *_elasticsearch) ShrinkIndex(source, target string, shards int, wait time.Duration) (StatusCode, error) {
+	body, err := resizeBody(shards)
+	if err != nil {
+		return StatusInternalError, err
+	}
+
+	req := esapi.IndicesShrinkRequest{
+		Index:  es.indexName(source),
+		Target: es.indexName(target),
+		Body:   body,
+	}
+
+	res, err := req.Do(es.ctx, es.client)
+	if status, err := es.handleResponse("shrink index "+source+" to "+target, res, err, nil); err != nil {
+		return status, err
+	}
+	return es.waitForIndex(target, wait)
+}
+
+// SplitIndex copies source into the new index target with more primary shards, a multiple of those of source.
+// source must be read-only. wait is as for ShrinkIndex.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-split-index.html
+func (es *_elasticsearch) SplitIndex(source, target string, shards int, wait time.Duration) (StatusCode, error) {
+	body, err := resizeBody(shards)
+	if err != nil {
+		return StatusInternalError, err
+	}
+
+	req := esapi.IndicesSplitRequest{
+		Index:  es.indexName(source),
+		Target: es.indexName(target),
+		Body:   body,
+	}
+
+	res, err := req.Do(es.ctx, es.client)
+	if status, err := es.handleResponse("split index "+source+" to "+target, res, err, nil); err != nil {
+		return status, err
+	}
+	return es.waitForIndex(target, wait)
+}
+
+// CloneIndex copies source into the new index target with the same shards.
+// source must be read-only. wait is as for ShrinkIndex.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-clone-index.html
+func (es *_elasticsearch) CloneIndex(source, target string, wait time.Duration) (StatusCode, error) {
+	body, err := resizeBody(0)
+	if err != nil {
+		return StatusInternalError, err
+	}
+
+	req := esapi.IndicesCloneRequest{
+		Index:  es.indexName(source),
+		Target: es.indexName(target),
+		Body:   body,
+	}
+
+	res, err := req.Do(es.ctx, es.client)
+	if status, err := es.handleResponse("clone index "+source+" to "+target, res, err, nil); err != nil {
+		return status, err
+	}
+	return es.waitForIndex(target, wait)
+}
+
+// resizeBody returns the body of a resize request to shards primary shards, or as many as the source when 0.
+func resizeBody(shards int) (io.Reader, error) {
+	settings := map[string]interface{}{
+		"index.blocks.write": nil,
+	}
+	if shards > 0 {
+		settings["index.number_of_shards"] = shards
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"settings": settings})
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(body), nil
+}
+
+// waitForIndex waits up to wait for the primary shards of index to be allocated,
+// i.e. for its health to be at least yellow. A zero wait returns at once.
+func (es *_elasticsearch) waitForIndex(index string, wait time.Duration) (StatusCode, error) {
+	if wait <= 0 {
+		return StatusSuccess, nil
+	}
+
+	req := esapi.ClusterHealthRequest{
+		Index:         []string{es.indexName(index)},
+		WaitForStatus: "yellow",
+		Timeout:       wait,
+	}
+
+	res, err := req.Do(es.ctx, es.client)
+
+	var r struct {
+		TimedOut bool `json:"timed_out"`
+	}
+	if status, err := es.handleResponse("wait for index "+index, res, err, &r); err != nil {
+		return status, err
+	}
+	if r.TimedOut {
+		return StatusError, fmt.Errorf("index %s is not allocated after %s", index, wait)
+	}
+	return StatusSuccess, nil
+}
@@ -0,0 +1,68 @@
+package elasticsearch
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bxcodec/faker/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResizeRequests(t *testing.T) {
+	var bodies []string
+	server, requests := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		if strings.HasPrefix(r.URL.Path, "/_cluster/health") {
+			w.Write([]byte(`{"status": "red", "timed_out": true}`))
+			return
+		}
+		w.Write([]byte(`{"acknowledged": true}`))
+	})
+	es, err := New(&Config{Address: []string{server.URL}})
+	assert.NoError(t, err)
+
+	status, err := es.ShrinkIndex("a", "b", 1, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSuccess, status)
+
+	reqs := requests()
+	assert.Equal(t, "/a/_shrink/b", reqs[len(reqs)-1].URL.Path)
+	assert.JSONEq(t, `{"settings": {"index.blocks.write": null, "index.number_of_shards": 1}}`, bodies[len(bodies)-1])
+
+	status, err = es.CloneIndex("a", "b", time.Second)
+	assert.Error(t, err)
+	assert.Equal(t, StatusError, status)
+
+	reqs = requests()
+	assert.Equal(t, "/a/_clone/b", reqs[len(reqs)-2].URL.Path)
+	assert.JSONEq(t, `{"settings": {"index.blocks.write": null}}`, bodies[len(bodies)-2])
+	assert.Equal(t, "/_cluster/health/b", reqs[len(reqs)-1].URL.Path)
+	assert.Equal(t, "yellow", reqs[len(reqs)-1].URL.Query().Get("wait_for_status"))
+}
+
+func TestResize(t *testing.T) {
+	es := newElasticsearch()
+	source := indexName + "-resize"
+	shrunk, split := source+"-shrunk", source+"-split"
+	defer es.DeleteIndices(Indices(source, shrunk, split))
+
+	es.CreateIndex(source, `{"settings": {"number_of_shards": 2, "number_of_replicas": 0}}`)
+	id := faker.UUIDDigit()
+	es.CreateDocument(&Document{Index: source, ID: id, Body: DocBody{Id: id}, Refresh: RefreshTrue})
+	es.AddIndexBlock(source, BlockWrite)
+
+	status, err := es.ShrinkIndex(source, shrunk, 1, 30*time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSuccess, status)
+
+	status, err = es.SplitIndex(source, split, 4, 30*time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSuccess, status)
+
+	_, count, _ := es.Count(shrunk, "")
+	assert.Equal(t, 1, count)
+}
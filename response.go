@@ -0,0 +1,48 @@
+package elasticsearch
+
+import (
+	"io"
+
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+)
+
+// handleResponse handles the response of every request in the same way:
+// a failed request is returned as a *RequestError, an error response as an *ESError
+// and a body which cannot be decoded as a *ParseError, each logged with op and mapped to a StatusCode.
+// The body of a successful response is decoded into v, unless v is nil.
+// The body is always read to the end and closed, so that the connection is reused.
+func (es *_elasticsearch) handleResponse(op string, res *esapi.Response, err error, v interface{}) (StatusCode, error) {
+	if err != nil {
+		es.logger.Errorf("Error %s: %s", op, err)
+		return StatusRequestError, &RequestError{Err: err}
+	}
+	defer func() {
+		io.Copy(io.Discard, res.Body)
+		res.Body.Close()
+	}()
+
+	if res.IsError() {
+		body, _ := io.ReadAll(res.Body)
+		es.logger.Errorf("[%s] Error %s : %s", res.Status(), op, body)
+		return errorStatus(res.StatusCode), newESError(res.StatusCode, body)
+	}
+
+	if v != nil {
+		if err := decode(es.codec, res.Body, v); err != nil {
+			es.logger.Errorf("Error parsing the response body of %s: %s", op, err)
+			return StatusParseError, &ParseError{Err: err}
+		}
+	}
+
+	return StatusSuccess, nil
+}
+
+func errorStatus(statusCode int) StatusCode {
+	switch statusCode {
+	case 400:
+		return StatusBadRequestError
+	case 404:
+		return StatusNotFoundError
+	}
+	return StatusError
+}
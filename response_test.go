@@ -0,0 +1,86 @@
+package elasticsearch
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+	"github.com/stretchr/testify/assert"
+)
+
+type testBody struct {
+	io.Reader
+	closed bool
+}
+
+func (b *testBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+func newTestResponse(statusCode int, body string) (*esapi.Response, *testBody) {
+	b := &testBody{Reader: strings.NewReader(body)}
+	return &esapi.Response{StatusCode: statusCode, Header: http.Header{}, Body: b}, b
+}
+
+func TestHandleResponse(t *testing.T) {
+	es := &_elasticsearch{logger: NopLogger(), codec: jsonCodec{}}
+
+	t.Run("Request error", func(t *testing.T) {
+		status, err := es.handleResponse("test", nil, io.ErrUnexpectedEOF, nil)
+
+		var requestErr *RequestError
+		assert.True(t, errors.As(err, &requestErr))
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+		assert.Equal(t, StatusRequestError, status)
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		res, body := newTestResponse(200, `{"count": 3} trailing`)
+
+		var r struct {
+			Count int `json:"count"`
+		}
+		status, err := es.handleResponse("test", res, nil, &r)
+
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+		assert.Equal(t, 3, r.Count)
+		assert.True(t, body.closed)
+		n, _ := body.Read(make([]byte, 1))
+		assert.Zero(t, n, "drained")
+	})
+
+	t.Run("Error responses", func(t *testing.T) {
+		for statusCode, want := range map[int]StatusCode{
+			400: StatusBadRequestError,
+			404: StatusNotFoundError,
+			409: StatusError,
+			500: StatusError,
+		} {
+			res, body := newTestResponse(statusCode, `{"error": {"type": "exception", "reason": "failure"}, "status": 500}`)
+
+			status, err := es.handleResponse("test", res, nil, nil)
+
+			var esErr *ESError
+			assert.True(t, errors.As(err, &esErr))
+			assert.Equal(t, statusCode, esErr.StatusCode)
+			assert.Equal(t, want, status)
+			assert.True(t, body.closed)
+		}
+	})
+
+	t.Run("Parse error", func(t *testing.T) {
+		res, _ := newTestResponse(200, `{`)
+
+		var r map[string]interface{}
+		status, err := es.handleResponse("test", res, nil, &r)
+
+		var parseErr *ParseError
+		assert.True(t, errors.As(err, &parseErr))
+		assert.Equal(t, StatusParseError, status)
+	})
+}
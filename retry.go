@@ -0,0 +1,137 @@
+package elasticsearch
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+)
+
+// RetryPolicy retries requests failing with a retryable status or a transient network error,
+// waiting an exponential backoff with jitter between attempts.
+// The wait is interrupted when the context of the request (see WithContext) is done.
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts including the first one. Default: 4.
+	MaxAttempts int
+	// RetryOnStatus are the retryable response statuses. Default: 429, 502, 503, 504.
+	RetryOnStatus []int
+	// RetryOnTimeout also retries requests which timed out on the network.
+	RetryOnTimeout bool
+	// InitialBackoff is the wait before the first retry, doubled on every retry. Default: 100ms.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait between attempts. Default: 10s.
+	MaxBackoff time.Duration
+	// Jitter is the fraction of every wait which is randomized, between 0 and 1. Zero disables it. Default: 0.2.
+	Jitter *float64
+}
+
+var defaultRetryOnStatus = []int{429, 502, 503, 504}
+
+func (p *RetryPolicy) validate() error {
+	if p.MaxAttempts < 0 {
+		return errors.New("Retry.MaxAttempts must not be negative")
+	}
+	if p.Jitter != nil && (*p.Jitter < 0 || *p.Jitter > 1) {
+		return errors.New("Retry.Jitter must be between 0 and 1")
+	}
+	return nil
+}
+
+func (p *RetryPolicy) maxAttempts() int {
+	if p.MaxAttempts == 0 {
+		return 4
+	}
+	return p.MaxAttempts
+}
+
+// backoff returns the wait after the failed attempt (starting at 1).
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	initial, max, jitter := p.InitialBackoff, p.MaxBackoff, 0.2
+	if initial <= 0 {
+		initial = 100 * time.Millisecond
+	}
+	if max <= 0 {
+		max = 10 * time.Second
+	}
+	if p.Jitter != nil {
+		jitter = *p.Jitter
+	}
+
+	d := math.Min(float64(initial)*math.Pow(2, float64(attempt-1)), float64(max))
+	d -= d * jitter * rand.Float64()
+	return time.Duration(d)
+}
+
+func (p *RetryPolicy) retryable(res *http.Response, err error) bool {
+	if err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return true
+		}
+		var netErr net.Error
+		if errors.As(err, &netErr) {
+			return !netErr.Timeout() || p.RetryOnTimeout
+		}
+		return false
+	}
+
+	retryOnStatus := p.RetryOnStatus
+	if len(retryOnStatus) == 0 {
+		retryOnStatus = defaultRetryOnStatus
+	}
+	for _, status := range retryOnStatus {
+		if res.StatusCode == status {
+			return true
+		}
+	}
+	return false
+}
+
+func retryMiddleware(policy *RetryPolicy, metrics MetricsHook) middleware {
+	return func(next esapi.Transport) esapi.Transport {
+		return transportFunc(func(req *http.Request) (*http.Response, error) {
+			// A body which cannot be read again is sent only once.
+			rewindable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+
+			for attempt := 1; ; attempt++ {
+				if attempt > 1 && req.GetBody != nil {
+					body, err := req.GetBody()
+					if err != nil {
+						return nil, err
+					}
+					req.Body = body
+				}
+
+				res, err := next.Perform(req)
+				if !rewindable || attempt >= policy.maxAttempts() || !policy.retryable(res, err) {
+					return res, err
+				}
+
+				if metrics != nil {
+					metrics.ObserveRetry(requestOperation(req), attempt)
+				}
+
+				timer := time.NewTimer(policy.backoff(attempt))
+				select {
+				case <-req.Context().Done():
+					timer.Stop()
+					if res != nil {
+						return res, err
+					}
+					return nil, req.Context().Err()
+				case <-timer.C:
+				}
+
+				if res != nil {
+					io.Copy(ioutil.Discard, res.Body)
+					res.Body.Close()
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package elasticsearch
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	jitter := 0.5
+	policy := &RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Jitter: &jitter}
+
+	for attempt, max := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second} {
+		d := policy.backoff(attempt + 1)
+		assert.LessOrEqual(t, int64(d), int64(max))
+		assert.GreaterOrEqual(t, int64(d), int64(max/2))
+	}
+
+	t.Run("No jitter", func(t *testing.T) {
+		jitter := 0.0
+		policy := &RetryPolicy{InitialBackoff: 100 * time.Millisecond, Jitter: &jitter}
+		for i := 0; i < 10; i++ {
+			assert.Equal(t, 200*time.Millisecond, policy.backoff(2))
+		}
+	})
+}
+
+func TestRetry(t *testing.T) {
+	searchResponse := `{"hits": {"total": {"value": 0, "relation": "eq"}, "hits": []}}`
+
+	unavailable := func(failures int32) (http.HandlerFunc, *int32) {
+		var calls int32
+		return func(w http.ResponseWriter, r *http.Request) {
+			if !strings.HasSuffix(r.URL.Path, "/_search") {
+				w.Write([]byte(`{}`))
+				return
+			}
+			if atomic.AddInt32(&calls, 1) <= failures {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				w.Write([]byte(`{"error": {"type": "unavailable_shards_exception", "reason": "unavailable"}, "status": 503}`))
+				return
+			}
+			w.Write([]byte(searchResponse))
+		}, &calls
+	}
+
+	t.Run("Success after retries", func(t *testing.T) {
+		handler, calls := unavailable(2)
+		server, _ := newTestServer(t, handler)
+
+		es, err := New(&Config{
+			Address: []string{server.URL},
+			Retry:   &RetryPolicy{InitialBackoff: time.Millisecond},
+		})
+		assert.NoError(t, err)
+
+		status, _, _, err := es.Search("x", `{"query": {"match_all": {}}}`, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+		assert.Equal(t, int32(3), atomic.LoadInt32(calls))
+	})
+
+	t.Run("Max attempts", func(t *testing.T) {
+		handler, calls := unavailable(10)
+		server, _ := newTestServer(t, handler)
+
+		es, err := New(&Config{
+			Address: []string{server.URL},
+			Retry:   &RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond},
+		})
+		assert.NoError(t, err)
+
+		status, _, _, err := es.Search("x", `{"query": {"match_all": {}}}`, nil)
+
+		assert.Error(t, err)
+		assert.Equal(t, StatusError, status)
+		assert.Equal(t, int32(2), atomic.LoadInt32(calls))
+	})
+
+	t.Run("Context cancelled during backoff", func(t *testing.T) {
+		handler, calls := unavailable(10)
+		server, _ := newTestServer(t, handler)
+
+		es, err := New(&Config{
+			Address: []string{server.URL},
+			Retry:   &RetryPolicy{InitialBackoff: time.Minute},
+		})
+		assert.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		_, _, _, err = es.WithContext(ctx).Search("x", `{"query": {"match_all": {}}}`, nil)
+
+		assert.Error(t, err)
+		assert.Less(t, int64(time.Since(start)), int64(time.Second))
+		assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+	})
+
+	t.Run("Invalid policy", func(t *testing.T) {
+		jitter := 2.0
+		_, err := New(&Config{Retry: &RetryPolicy{Jitter: &jitter}})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,267 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+)
+
+// Indices returns the index argument of Search and Count for several indices, aliases or wildcards,
+// e.g. Indices("articles", "comments-*"). HitData.Index tells the index of each hit.
+func Indices(names ...string) string {
+	return strings.Join(names, ",")
+}
+
+type searchOptions struct {
+	ignoreUnavailable *bool
+	allowNoIndices    *bool
+	terminateAfter    *int
+	preference        *string
+	slice             *[2]int
+	allowPartial      *bool
+	trackTotalHits    interface{}
+	minScore          *float64
+	timeout           *time.Duration
+	profile           bool
+	pit               *pit
+}
+
+// SearchOption changes how Search, SearchWithResult, SearchStream and Count run.
+type SearchOption func(o *searchOptions)
+
+func newSearchOptions(opts []SearchOption) *searchOptions {
+	o := &searchOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// IgnoreUnavailable skips the missing or closed indices instead of failing the request.
+func IgnoreUnavailable() SearchOption {
+	return func(o *searchOptions) {
+		t := true
+		o.ignoreUnavailable = &t
+	}
+}
+
+// AllowNoIndices sets whether a wildcard or alias matching no index is an error (false)
+// or matches nothing (true, the default).
+func AllowNoIndices(allow bool) SearchOption {
+	return func(o *searchOptions) {
+		o.allowNoIndices = &allow
+	}
+}
+
+// TerminateAfter stops collecting documents after n documents on each shard,
+// so that Count and the total of Search are a lower bound.
+func TerminateAfter(n int) SearchOption {
+	return func(o *searchOptions) {
+		o.terminateAfter = &n
+	}
+}
+
+// TrackTotalHits sets whether searches count all the matching documents (true, the default) or
+// skip counting (false), which is cheaper on large indices. Without counting, the total of the results is 0.
+// It is ignored by Count.
+func TrackTotalHits(track bool) SearchOption {
+	return func(o *searchOptions) {
+		o.trackTotalHits = track
+	}
+}
+
+// TrackTotalHitsUpTo counts the matching documents up to n only. Beyond n, the total of the results
+// is n and SearchResult.TotalRelation is "gte", e.g. to display "10,000+ results". It is ignored by Count.
+func TrackTotalHitsUpTo(n int) SearchOption {
+	return func(o *searchOptions) {
+		o.trackTotalHits = n
+	}
+}
+
+// MinScore excludes the hits scoring less than score. It is ignored by Count.
+func MinScore(score float64) SearchOption {
+	return func(o *searchOptions) {
+		o.minScore = &score
+	}
+}
+
+// Timeout bounds the time each shard spends searching. Unlike the deadline of the context, it returns
+// the hits collected until then, with SearchResult.TimedOut set. It is ignored by Count.
+func Timeout(timeout time.Duration) SearchOption {
+	return func(o *searchOptions) {
+		o.timeout = &timeout
+	}
+}
+
+// Profile times the components of the search, returned in SearchResult.Profile. Profiling slows the search
+// down, and should be used to analyze slow queries only. It is ignored by Count.
+func Profile() SearchOption {
+	return func(o *searchOptions) {
+		o.profile = true
+	}
+}
+
+// Slice searches only the slice id of max disjoint slices of the documents, so that max consumers
+// such as SearchStream or scrolls can read a large index in parallel. id starts at 0.
+// It is ignored by Count.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/paginate-search-results.html#slice-scroll
+func Slice(id, max int) SearchOption {
+	return func(o *searchOptions) {
+		o.slice = &[2]int{id, max}
+	}
+}
+
+// AllowPartialResults sets whether a search failing on some shards succeeds with the hits of the
+// other shards (true, the default) or fails with a *PartialResultsError, overriding Config.DisallowPartialResults.
+// It is ignored by Count.
+func AllowPartialResults(allow bool) SearchOption {
+	return func(o *searchOptions) {
+		o.allowPartial = &allow
+	}
+}
+
+func (o *searchOptions) allowPartialOr(allow bool) bool {
+	if o.allowPartial != nil {
+		return *o.allowPartial
+	}
+	return allow
+}
+
+// searchBody adds the slice, min_score, profile and pit of o to the search body query.
+// An invalid query is returned as is, for Elasticsearch to report it.
+func (o *searchOptions) searchBody(query string) string {
+	if o.slice == nil && o.minScore == nil && !o.profile && o.pit == nil {
+		return query
+	}
+
+	body := map[string]json.RawMessage{}
+	if strings.TrimSpace(query) != "" {
+		if err := json.Unmarshal([]byte(query), &body); err != nil {
+			return query
+		}
+	}
+	if o.slice != nil {
+		body["slice"], _ = json.Marshal(map[string]int{"id": o.slice[0], "max": o.slice[1]})
+	}
+	if o.minScore != nil {
+		body["min_score"], _ = json.Marshal(*o.minScore)
+	}
+	if o.profile {
+		body["profile"] = json.RawMessage("true")
+	}
+	if o.pit != nil {
+		body["pit"], _ = json.Marshal(o.pit)
+	}
+
+	b, _ := json.Marshal(body)
+	return string(b)
+}
+
+// Preferences of Preference, WithPreference and Config.Preference.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/search-search.html#search-preference
+const (
+	// PreferenceLocal prefers the shards of the node receiving the request.
+	PreferenceLocal = "_local"
+	// PreferenceOnlyLocal searches only the shards of the node receiving the request.
+	PreferenceOnlyLocal = "_only_local"
+)
+
+// Preference selects the shard copies searched. Any other string, such as a user or session ID,
+// sends the requests of the same preference to the same copies, so that paginating users see
+// consistent results and hit the shard request cache.
+func Preference(preference string) SearchOption {
+	return func(o *searchOptions) {
+		o.preference = &preference
+	}
+}
+
+// WithPreference returns a copy of the client whose searches and counts use preference
+// instead of Config.Preference, e.g. the session ID of a user. The Preference option overrides it.
+func (es *_elasticsearch) WithPreference(preference string) Elasticsearch {
+	c := *es
+	c.preference = preference
+	return &c
+}
+
+func (o *searchOptions) preferenceOr(preference string) string {
+	if o.preference != nil {
+		return *o.preference
+	}
+	return preference
+}
+
+// searchRequest returns the options of a search request of query on index.
+// An empty index searches all indices and an empty query matches all documents.
+func (es *_elasticsearch) searchRequest(index, query string, opts []SearchOption) []func(*esapi.SearchRequest) {
+	o := newSearchOptions(opts)
+	query = o.searchBody(query)
+
+	trackTotalHits := o.trackTotalHits
+	if trackTotalHits == nil {
+		trackTotalHits = true
+	}
+
+	s := es.client.Search
+	fs := []func(*esapi.SearchRequest){
+		s.WithContext(es.ctx),
+		s.WithTrackTotalHits(trackTotalHits),
+	}
+	// A point in time already names the indices.
+	if index := es.indexName(index); index != "" && o.pit == nil {
+		fs = append(fs, s.WithIndex(index))
+	}
+	if query != "" {
+		fs = append(fs, s.WithBody(strings.NewReader(query)))
+	}
+
+	if o.ignoreUnavailable != nil {
+		fs = append(fs, s.WithIgnoreUnavailable(*o.ignoreUnavailable))
+	}
+	if o.allowNoIndices != nil {
+		fs = append(fs, s.WithAllowNoIndices(*o.allowNoIndices))
+	}
+	if o.terminateAfter != nil {
+		fs = append(fs, s.WithTerminateAfter(*o.terminateAfter))
+	}
+	if o.timeout != nil {
+		fs = append(fs, s.WithTimeout(*o.timeout))
+	}
+	if preference := o.preferenceOr(es.preference); preference != "" && o.pit == nil {
+		fs = append(fs, s.WithPreference(preference))
+	}
+	if !o.allowPartialOr(!es.disallowPartial) {
+		fs = append(fs, s.WithAllowPartialSearchResults(false))
+	}
+	return fs
+}
+
+// countRequest returns the options of a count request of query on index, like searchRequest.
+func (es *_elasticsearch) countRequest(index, query string, opts []SearchOption) []func(*esapi.CountRequest) {
+	c := es.client.Count
+	fs := []func(*esapi.CountRequest){
+		c.WithContext(es.ctx),
+	}
+	if index := es.indexName(index); index != "" {
+		fs = append(fs, c.WithIndex(index))
+	}
+	if query != "" {
+		fs = append(fs, c.WithBody(strings.NewReader(query)))
+	}
+
+	o := newSearchOptions(opts)
+	if o.ignoreUnavailable != nil {
+		fs = append(fs, c.WithIgnoreUnavailable(*o.ignoreUnavailable))
+	}
+	if o.allowNoIndices != nil {
+		fs = append(fs, c.WithAllowNoIndices(*o.allowNoIndices))
+	}
+	if o.terminateAfter != nil {
+		fs = append(fs, c.WithTerminateAfter(*o.terminateAfter))
+	}
+	if preference := o.preferenceOr(es.preference); preference != "" {
+		fs = append(fs, c.WithPreference(preference))
+	}
+	return fs
+}
@@ -0,0 +1,193 @@
+package elasticsearch
+
+import (
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/bxcodec/faker/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchOptions(t *testing.T) {
+	server, requests := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"count": 0, "hits": {"total": {"value": 0}, "hits": []}}`))
+	})
+	es, err := New(&Config{Address: []string{server.URL}})
+	assert.NoError(t, err)
+
+	assert.Equal(t, "a,b-*", Indices("a", "b-*"))
+
+	_, _, _, err = es.Search(Indices("a", "b-*"), SearchBody(MatchAllQuery()), nil, IgnoreUnavailable(), AllowNoIndices(false))
+	assert.NoError(t, err)
+
+	reqs := requests()
+	req := reqs[len(reqs)-1]
+	assert.Equal(t, "/a,b-*/_search", req.URL.Path)
+	assert.Equal(t, "true", req.URL.Query().Get("ignore_unavailable"))
+	assert.Equal(t, "false", req.URL.Query().Get("allow_no_indices"))
+
+	_, _, err = es.Count("a", SearchBody(MatchAllQuery()), IgnoreUnavailable())
+	assert.NoError(t, err)
+
+	reqs = requests()
+	req = reqs[len(reqs)-1]
+	assert.Equal(t, "true", req.URL.Query().Get("ignore_unavailable"))
+	assert.Empty(t, req.URL.Query().Get("allow_no_indices"))
+}
+
+func TestPreference(t *testing.T) {
+	server, requests := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"count": 0, "hits": {"total": {"value": 0}, "hits": []}}`))
+	})
+	es, err := New(&Config{Address: []string{server.URL}, Preference: PreferenceLocal})
+	assert.NoError(t, err)
+
+	lastPreference := func() string {
+		reqs := requests()
+		return reqs[len(reqs)-1].URL.Query().Get("preference")
+	}
+
+	es.Search("a", "", nil)
+	assert.Equal(t, "_local", lastPreference())
+
+	session := es.WithPreference("session-1")
+	session.Search("a", "", nil)
+	assert.Equal(t, "session-1", lastPreference())
+	session.Count("a", "")
+	assert.Equal(t, "session-1", lastPreference())
+
+	session.Search("a", "", nil, Preference(""))
+	assert.Empty(t, lastPreference())
+
+	es.Count("a", "", Preference(PreferenceOnlyLocal))
+	assert.Equal(t, "_only_local", lastPreference())
+}
+
+func TestTrackTotalHits(t *testing.T) {
+	server, requests := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"hits": {"total": {"value": 10000, "relation": "gte"}, "hits": []}}`))
+	})
+	es, err := New(&Config{Address: []string{server.URL}})
+	assert.NoError(t, err)
+
+	lastTrackTotalHits := func() string {
+		reqs := requests()
+		return reqs[len(reqs)-1].URL.Query().Get("track_total_hits")
+	}
+
+	es.Search("a", "", nil)
+	assert.Equal(t, "true", lastTrackTotalHits())
+
+	es.Search("a", "", nil, TrackTotalHits(false))
+	assert.Equal(t, "false", lastTrackTotalHits())
+
+	_, result, err := es.SearchWithResult("a", "", nil, TrackTotalHitsUpTo(10000))
+	assert.NoError(t, err)
+	assert.Equal(t, "10000", lastTrackTotalHits())
+	assert.Equal(t, 10000, result.Total)
+	assert.Equal(t, "gte", result.TotalRelation)
+}
+
+func TestBoundedSearch(t *testing.T) {
+	var body string
+	server, requests := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		w.Write([]byte(`{"timed_out": true, "hits": {"total": {"value": 1}, "hits": [{"_id": "1"}]}}`))
+	})
+	es, err := New(&Config{Address: []string{server.URL}})
+	assert.NoError(t, err)
+
+	_, result, err := es.SearchWithResult("a", SearchBody(MatchAllQuery()), nil, MinScore(0.5), TerminateAfter(1000), Timeout(200*time.Millisecond))
+
+	assert.NoError(t, err)
+	assert.True(t, result.TimedOut)
+	assert.Len(t, result.Hits, 1)
+	assert.JSONEq(t, `{"query": {"match_all": {}}, "min_score": 0.5}`, body)
+	reqs := requests()
+	query := reqs[len(reqs)-1].URL.Query()
+	assert.Equal(t, "1000", query.Get("terminate_after"))
+	assert.Equal(t, "200ms", query.Get("timeout"))
+}
+
+func TestSlice(t *testing.T) {
+	var body string
+	server, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		w.Write([]byte(`{"hits": {"total": {"value": 0}, "hits": []}}`))
+	})
+	es, err := New(&Config{Address: []string{server.URL}})
+	assert.NoError(t, err)
+
+	es.Search("a", SearchBody(MatchAllQuery()), nil, Slice(1, 4))
+	assert.JSONEq(t, `{"query": {"match_all": {}}, "slice": {"id": 1, "max": 4}}`, body)
+
+	es.SearchStream("a", "", func(hit *HitData) error { return nil }, Slice(0, 2))
+	assert.JSONEq(t, `{"slice": {"id": 0, "max": 2}}`, body)
+}
+
+func TestMultiIndexSearch(t *testing.T) {
+	es := newElasticsearch()
+	other := indexName + "-other"
+	defer es.DeleteIndeces(indexName, other)
+
+	for _, index := range []string{indexName, other} {
+		id := faker.UUIDDigit()
+		es.CreateDocument(&Document{Index: index, ID: id, Body: DocBody{Id: id}, Refresh: RefreshTrue})
+	}
+
+	t.Run("Indices", func(t *testing.T) {
+		status, hits, total, err := es.Search(Indices(indexName, other), SearchBody(MatchAllQuery(), FieldSort("_index", "asc")), nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+		assert.Equal(t, 2, total)
+		assert.Equal(t, indexName, hits[0].Index)
+		assert.Equal(t, other, hits[1].Index)
+	})
+
+	t.Run("Ignore unavailable", func(t *testing.T) {
+		missing := indexName + "-missing"
+
+		status, _, _, err := es.Search(Indices(indexName, missing), SearchBody(MatchAllQuery()), nil)
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.Equal(t, StatusNotFoundError, status)
+
+		status, count, err := es.Count(Indices(indexName, missing), SearchBody(MatchAllQuery()), IgnoreUnavailable())
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+		assert.Equal(t, 1, count)
+	})
+}
+
+func TestCountAll(t *testing.T) {
+	server, requests := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"count": 3}`))
+	})
+	es, err := New(&Config{Address: []string{server.URL}})
+	assert.NoError(t, err)
+
+	status, count, err := es.Count("", "", TerminateAfter(100))
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSuccess, status)
+	assert.Equal(t, 3, count)
+
+	reqs := requests()
+	req := reqs[len(reqs)-1]
+	assert.Equal(t, "/_count", req.URL.Path)
+	assert.Equal(t, "100", req.URL.Query().Get("terminate_after"))
+	assert.Zero(t, req.ContentLength)
+
+	t.Run("Fake", func(t *testing.T) {
+		fake := NewFake()
+		fake.CreateDocument(&Document{Index: "a", ID: "1", Body: DocBody{Id: "1"}})
+		fake.CreateDocument(&Document{Index: "b", ID: "1", Body: DocBody{Id: "1"}})
+
+		_, count, err := fake.Count("", "")
+		assert.NoError(t, err)
+		assert.Equal(t, 2, count)
+	})
+}
@@ -0,0 +1,148 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// SearchStream searches like Search but decodes the hits one by one while reading the response,
+// calling fn with each hit and its raw _source in hit.Source, so that a response with many hits is never held
+// in memory as a whole. It returns the total number of matching documents.
+// Streaming always decodes with encoding/json, whatever the Codec of Config.
+// Decoding stops at the first error returned by fn, which is returned as is with StatusInternalError.
+// Like SearchWithResult, a search failing on some shards fails with a *PartialResultsError, before any hit
+// is passed to fn, unless partial results are allowed by Config.DisallowPartialResults and AllowPartialResults.
+func (es *_elasticsearch) SearchStream(index, query string, fn func(hit *HitData) error, opts ...SearchOption) (StatusCode, int, error) {
+	res, err := es.client.Search(es.searchRequest(index, query, opts)...)
+	if err != nil || res.IsError() {
+		status, err := es.handleResponse("search index="+index, res, err, nil)
+		return status, 0, err
+	}
+	defer func() {
+		io.Copy(io.Discard, res.Body)
+		res.Body.Close()
+	}()
+
+	allowPartial := newSearchOptions(opts).allowPartialOr(!es.disallowPartial)
+	var fnErr, partialErr error
+	shards, total, err := streamHits(res.Body, func(shards *ShardsInfo) error {
+		// _shards comes before the hits, which are then not passed to fn.
+		if shards != nil && shards.Failed > 0 && !allowPartial {
+			for _, failure := range shards.Failures {
+				failure.Index = es.trimIndexPrefix(failure.Index)
+			}
+			partialErr = &PartialResultsError{Shards: shards}
+			return partialErr
+		}
+		return nil
+	}, func(hit *HitData) error {
+		hit.Index = es.trimIndexPrefix(hit.Index)
+		fnErr = fn(hit)
+		return fnErr
+	})
+	if partialErr != nil {
+		return StatusError, 0, partialErr
+	}
+	if fnErr != nil {
+		return StatusInternalError, total, fnErr
+	}
+	if err != nil {
+		es.logger.Errorf("Error parsing the response body of search index=%s: %s", index, err)
+		return StatusParseError, total, &ParseError{Err: err}
+	}
+	if shards != nil && shards.Failed > 0 {
+		es.logger.Warnf("Search index=%s failed on %d of %d shards", index, shards.Failed, shards.Total)
+	}
+
+	return StatusSuccess, total, nil
+}
+
+// streamHits decodes the search response read from r token by token, calling shardsFn with _shards
+// and fn with each hit. The other fields of the response are skipped.
+func streamHits(r io.Reader, shardsFn func(shards *ShardsInfo) error, fn func(hit *HitData) error) (*ShardsInfo, int, error) {
+	dec := json.NewDecoder(r)
+
+	var (
+		shards *ShardsInfo
+		total  int
+	)
+	err := decodeObject(dec, func(key string) error {
+		switch key {
+		case "_shards":
+			if err := dec.Decode(&shards); err != nil {
+				return err
+			}
+			return shardsFn(shards)
+		case "hits":
+			return decodeObject(dec, func(key string) error {
+				switch key {
+				case "total":
+					var t struct {
+						Value int `json:"value"`
+					}
+					err := dec.Decode(&t)
+					total = t.Value
+					return err
+				case "hits":
+					return decodeArray(dec, func() error {
+						var hit HitData
+						if err := dec.Decode(&hit); err != nil {
+							return err
+						}
+						return fn(&hit)
+					})
+				}
+				return skipValue(dec)
+			})
+		}
+		return skipValue(dec)
+	})
+	return shards, total, err
+}
+
+// decodeObject reads a JSON object from dec, calling fn with each key to decode its value.
+func decodeObject(dec *json.Decoder, fn func(key string) error) error {
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if err := fn(t.(string)); err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, '}')
+}
+
+// decodeArray reads a JSON array from dec, calling fn to decode each element.
+func decodeArray(dec *json.Decoder, fn func() error) error {
+	if err := expectDelim(dec, '['); err != nil {
+		return err
+	}
+	for dec.More() {
+		if err := fn(); err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, ']')
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	t, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if t != delim {
+		return fmt.Errorf("expected %s but got %v", delim, t)
+	}
+	return nil
+}
+
+func skipValue(dec *json.Decoder) error {
+	var v json.RawMessage
+	return dec.Decode(&v)
+}
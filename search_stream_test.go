@@ -0,0 +1,137 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/bxcodec/faker/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamHits(t *testing.T) {
+	body := `{
+		"took": 1,
+		"_shards": {"total": 2, "successful": 1, "skipped": 0, "failed": 1},
+		"hits": {
+			"total": {"value": 2, "relation": "eq"},
+			"max_score": 1.0,
+			"hits": [
+				{"_index": "x", "_id": "1", "_score": 1.0, "_source": {"id": "1"}},
+				{"_index": "x", "_id": "2", "_score": 1.0, "_source": {"id": "2"}}
+			]
+		},
+		"aggregations": {"a": {"value": 1}}
+	}`
+
+	var ids []string
+	shards, total, err := streamHits(strings.NewReader(body), func(*ShardsInfo) error { return nil }, func(hit *HitData) error {
+		ids = append(ids, hit.Id)
+		assert.JSONEq(t, `{"id": "`+hit.Id+`"}`, string(hit.Source))
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, total)
+	assert.Equal(t, 1, shards.Failed)
+	assert.Equal(t, []string{"1", "2"}, ids)
+
+	t.Run("Malformed", func(t *testing.T) {
+		_, _, err := streamHits(strings.NewReader(`{"hits": {"hits": [{"_id": 1}]}}`), func(*ShardsInfo) error { return nil }, func(hit *HitData) error {
+			return nil
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestSearchStreamStop(t *testing.T) {
+	server, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"hits": {"total": {"value": 2}, "hits": [{"_index": "x", "_id": "1", "_source": {}}, {"_index": "x", "_id": "2", "_source": {}}]}}`))
+	})
+	es, err := New(&Config{Address: []string{server.URL}})
+	assert.NoError(t, err)
+
+	stop := errors.New("stop")
+	n := 0
+	status, _, err := es.SearchStream("x", SearchBody(MatchAllQuery()), func(hit *HitData) error {
+		n++
+		return stop
+	})
+
+	assert.Equal(t, stop, err)
+	assert.Equal(t, StatusInternalError, status)
+	assert.Equal(t, 1, n)
+}
+
+func TestSearchStreamPartialResults(t *testing.T) {
+	server, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"_shards": {"total": 2, "successful": 1, "skipped": 0, "failed": 1, "failures": [{"shard": 1, "index": "p-x", "reason": {"type": "exception", "reason": "boom"}}]},
+			"hits": {"total": {"value": 1}, "hits": [{"_index": "p-x", "_id": "1", "_source": {}}]}
+		}`))
+	})
+
+	for _, c := range []struct {
+		name     string
+		disallow bool
+		opts     []SearchOption
+		allowed  bool
+	}{
+		{name: "Allowed by default", allowed: true},
+		{name: "Disallowed by config", disallow: true},
+		{name: "Disallowed by option", opts: []SearchOption{AllowPartialResults(false)}},
+		{name: "Allowed by option", disallow: true, opts: []SearchOption{AllowPartialResults(true)}, allowed: true},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			es, err := New(&Config{Address: []string{server.URL}, IndexPrefix: "p-", DisallowPartialResults: c.disallow, Logger: NopLogger()})
+			assert.NoError(t, err)
+
+			var hits []*HitData
+			status, _, err := es.SearchStream("x", SearchBody(MatchAllQuery()), func(hit *HitData) error {
+				hits = append(hits, hit)
+				return nil
+			}, c.opts...)
+
+			if c.allowed {
+				assert.NoError(t, err)
+				assert.Equal(t, StatusSuccess, status)
+				assert.Len(t, hits, 1)
+				return
+			}
+			var partialErr *PartialResultsError
+			assert.ErrorAs(t, err, &partialErr)
+			assert.Equal(t, StatusError, status)
+			assert.Empty(t, hits)
+			assert.Equal(t, "x", partialErr.Shards.Failures[0].Index)
+		})
+	}
+}
+
+func TestSearchStream(t *testing.T) {
+	es := newElasticsearch()
+	defer es.DeleteIndeces(indexName)
+
+	ids := map[string]bool{}
+	for i := 0; i < 3; i++ {
+		id := faker.UUIDDigit()
+		ids[id] = true
+		es.CreateDocument(&Document{Index: indexName, ID: id, Body: DocBody{Id: id}, Refresh: RefreshTrue})
+	}
+
+	found := map[string]bool{}
+	status, total, err := es.SearchStream(indexName, `{"query": {"match_all": {}}, "size": 10}`, func(hit *HitData) error {
+		var doc DocBody
+		if err := json.Unmarshal(hit.Source, &doc); err != nil {
+			return err
+		}
+		found[doc.Id] = true
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSuccess, status)
+	assert.Equal(t, 3, total)
+	assert.Equal(t, ids, found)
+}
@@ -0,0 +1,69 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+)
+
+// PutSearchTemplate stores a mustache search template under id, e.g.
+// `{"query": {"term": {"id": "{{id}}"}}}`.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/search-template.html
+func (es *_elasticsearch) PutSearchTemplate(id, source string) (StatusCode, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"script": map[string]interface{}{
+			"lang":   "mustache",
+			"source": source,
+		},
+	})
+	if err != nil {
+		return StatusInternalError, err
+	}
+
+	req := esapi.PutScriptRequest{
+		ScriptID: id,
+		Body:     bytes.NewReader(body),
+	}
+
+	res, err := req.Do(es.ctx, es.client)
+	return es.handleResponse("put search template ID="+id, res, err, nil)
+}
+
+func (es *_elasticsearch) DeleteSearchTemplate(id string) (StatusCode, error) {
+	req := esapi.DeleteScriptRequest{
+		ScriptID: id,
+	}
+
+	res, err := req.Do(es.ctx, es.client)
+	return es.handleResponse("delete search template ID="+id, res, err, nil)
+}
+
+// SearchWithTemplate runs the stored template templateID rendered with params and decodes hits into data like Search.
+func (es *_elasticsearch) SearchWithTemplate(index, templateID string, params map[string]any, data interface{}) (StatusCode, []*HitData, int, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"id":     templateID,
+		"params": params,
+	})
+	if err != nil {
+		return StatusInternalError, []*HitData{}, 0, err
+	}
+
+	req := esapi.SearchTemplateRequest{
+		Index: []string{es.indexName(index)},
+		Body:  bytes.NewReader(body),
+	}
+
+	res, err := req.Do(es.ctx, es.client)
+
+	var r searchResponse
+	if status, err := es.handleResponse("search template ID="+templateID, res, err, &r); err != nil {
+		return status, []*HitData{}, 0, err
+	}
+
+	status, result, err := decodeSearchResult(es.codec, &r, data)
+	for _, hit := range result.Hits {
+		hit.Index = es.trimIndexPrefix(hit.Index)
+	}
+	return status, result.Hits, result.Total, err
+}
@@ -0,0 +1,53 @@
+package elasticsearch
+
+import (
+	"testing"
+
+	"github.com/bxcodec/faker/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchTemplate(t *testing.T) {
+	es := newElasticsearch()
+	templateID := "test-es-template-" + faker.UUIDDigit()
+
+	var data DocBody
+	faker.FakeData(&data)
+	data.Id = faker.UUIDDigit()
+	es.CreateDocument(&Document{
+		Index:   indexName,
+		ID:      data.Id,
+		Body:    data,
+		Refresh: RefreshTrue,
+	})
+
+	t.Run("Put", func(t *testing.T) {
+		status, err := es.PutSearchTemplate(templateID, `{"query": {"term": {"id": "{{id}}"}}}`)
+
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+	})
+
+	t.Run("Search", func(t *testing.T) {
+		var list []DocBody
+		status, hits, total, err := es.SearchWithTemplate(indexName, templateID, map[string]any{"id": data.Id}, &list)
+
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+		assert.Equal(t, 1, total)
+		assert.Equal(t, data.Id, hits[0].Id)
+		assert.Equal(t, data, list[0])
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		status, err := es.DeleteSearchTemplate(templateID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+
+		status, err = es.DeleteSearchTemplate(templateID)
+
+		assert.Error(t, err)
+		assert.Equal(t, StatusNotFoundError, status)
+	})
+}
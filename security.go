@@ -0,0 +1,348 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+)
+
+// runAsHeader makes a request run with the roles of another user.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/run-as-privilege.html
+const runAsHeader = "es-security-runas-user"
+
+// WithRunAs returns a context running the requests made with it as username, with the roles of username
+// instead of those of the authenticated user, who needs the run_as privilege. It overrides Config.RunAs.
+//
+//	es.WithContext(elasticsearch.WithRunAs(ctx, tenant.User)).Search(...)
+func WithRunAs(ctx context.Context, username string) context.Context {
+	return WithRequestHeader(ctx, http.Header{runAsHeader: []string{username}})
+}
+
+// RunAs returns the username set by WithRunAs.
+func RunAs(ctx context.Context) string {
+	return RequestHeader(ctx).Get(runAsHeader)
+}
+
+// APIKeyRequest is the request of CreateAPIKey.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/security-api-create-api-key.html
+type APIKeyRequest struct {
+	Name string `json:"name"`
+	// Expiration is a duration such as "1d". An empty Expiration never expires.
+	Expiration string `json:"expiration,omitempty"`
+	// RoleDescriptors limits the privileges of the key to a subset of those of its owner, e.g.
+	// {"reader": {"indices": [{"names": ["logs-*"], "privileges": ["read"]}]}}.
+	// A nil RoleDescriptors grants every privilege of the owner.
+	RoleDescriptors map[string]interface{} `json:"role_descriptors,omitempty"`
+	Metadata        map[string]interface{} `json:"metadata,omitempty"`
+}
+
+type APIKey struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	APIKey string `json:"api_key"`
+	// Expiration is in milliseconds since the epoch, and zero when the key never expires.
+	Expiration int64 `json:"expiration"`
+	// Encoded is the credential to be set to Config.APIKey.
+	Encoded string `json:"encoded"`
+}
+
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/security-api-get-api-key.html
+type APIKeyInfo struct {
+	ID          string                 `json:"id"`
+	Name        string                 `json:"name"`
+	Creation    int64                  `json:"creation"`
+	Expiration  int64                  `json:"expiration"`
+	Invalidated bool                   `json:"invalidated"`
+	Username    string                 `json:"username"`
+	Realm       string                 `json:"realm"`
+	Metadata    map[string]interface{} `json:"metadata"`
+}
+
+// CreateAPIKey creates an API key owned by the user of the client.
+func (es *_elasticsearch) CreateAPIKey(key *APIKeyRequest) (StatusCode, *APIKey, error) {
+	body, err := json.Marshal(key)
+	if err != nil {
+		return StatusInternalError, &APIKey{}, err
+	}
+
+	req := esapi.SecurityCreateAPIKeyRequest{
+		Body: bytes.NewReader(body),
+	}
+
+	res, err := req.Do(es.ctx, es.client)
+
+	apiKey := &APIKey{}
+	if status, err := es.handleResponse("create api key name="+key.Name, res, err, apiKey); err != nil {
+		return status, &APIKey{}, err
+	}
+	// encoded is returned only by 7.16 and later.
+	if apiKey.Encoded == "" {
+		apiKey.Encoded = base64.StdEncoding.EncodeToString([]byte(apiKey.ID + ":" + apiKey.APIKey))
+	}
+	return StatusSuccess, apiKey, nil
+}
+
+// InvalidateAPIKey invalidates the API keys ids and returns the number of keys invalidated,
+// which excludes the keys already invalidated.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/security-api-invalidate-api-key.html
+func (es *_elasticsearch) InvalidateAPIKey(ids ...string) (StatusCode, int, error) {
+	body, err := json.Marshal(map[string]interface{}{"ids": ids})
+	if err != nil {
+		return StatusInternalError, 0, err
+	}
+
+	req := esapi.SecurityInvalidateAPIKeyRequest{
+		Body: bytes.NewReader(body),
+	}
+
+	res, err := req.Do(es.ctx, es.client)
+
+	var r struct {
+		InvalidatedAPIKeys []string `json:"invalidated_api_keys"`
+		ErrorCount         int      `json:"error_count"`
+		ErrorDetails       []struct {
+			Reason string `json:"reason"`
+		} `json:"error_details"`
+	}
+	if status, err := es.handleResponse("invalidate api key", res, err, &r); err != nil {
+		return status, 0, err
+	}
+	if r.ErrorCount > 0 {
+		reason := ""
+		if len(r.ErrorDetails) > 0 {
+			reason = r.ErrorDetails[0].Reason
+		}
+		es.logger.Errorf("Error invalidate api key: %d errors: %s", r.ErrorCount, reason)
+		return StatusError, len(r.InvalidatedAPIKeys), fmt.Errorf("failed to invalidate %d api keys: %s", r.ErrorCount, reason)
+	}
+	return StatusSuccess, len(r.InvalidatedAPIKeys), nil
+}
+
+// GetAPIKeys returns the API keys owned by the user of the client, including invalidated ones.
+// name may contain wildcards, and an empty name returns all of them.
+func (es *_elasticsearch) GetAPIKeys(name string) (StatusCode, []*APIKeyInfo, error) {
+	owner := true
+	req := esapi.SecurityGetAPIKeyRequest{
+		Name:  name,
+		Owner: &owner,
+	}
+
+	res, err := req.Do(es.ctx, es.client)
+
+	var r struct {
+		APIKeys []*APIKeyInfo `json:"api_keys"`
+	}
+	status, err := es.handleResponse("get api keys name="+name, res, err, &r)
+	if status == StatusNotFoundError {
+		// 7.x answers 404 when no key matches.
+		return StatusSuccess, []*APIKeyInfo{}, nil
+	}
+	if err != nil {
+		return status, []*APIKeyInfo{}, err
+	}
+	if r.APIKeys == nil {
+		r.APIKeys = []*APIKeyInfo{}
+	}
+	return StatusSuccess, r.APIKeys, nil
+}
+
+// Role is a set of privileges granted to users through PutUser or PutRoleMapping.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/security-api-put-role.html
+type Role struct {
+	Cluster  []string               `json:"cluster,omitempty"`
+	Indices  []*IndexPrivileges     `json:"indices,omitempty"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+type IndexPrivileges struct {
+	// Names are index names or patterns, prefixed with the index prefix of the client.
+	Names      []string `json:"names"`
+	Privileges []string `json:"privileges"`
+	// FieldSecurity restricts the fields readable in the indices.
+	FieldSecurity *FieldSecurity `json:"field_security,omitempty"`
+	// Query restricts the documents readable in the indices, e.g. `{"term": {"tenant": "a"}}`.
+	Query string `json:"query,omitempty"`
+}
+
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/field-level-security.html
+type FieldSecurity struct {
+	Grant  []string `json:"grant,omitempty"`
+	Except []string `json:"except,omitempty"`
+}
+
+// ReadOnlyRole returns a role to search and read the mappings of the indices matching patterns.
+func ReadOnlyRole(patterns ...string) *Role {
+	return &Role{
+		Indices: []*IndexPrivileges{{
+			Names:      patterns,
+			Privileges: []string{"read", "view_index_metadata"},
+		}},
+	}
+}
+
+func (es *_elasticsearch) PutRole(name string, role *Role) (StatusCode, error) {
+	prefixed := *role
+	prefixed.Indices = make([]*IndexPrivileges, len(role.Indices))
+	for i, privileges := range role.Indices {
+		p := *privileges
+		p.Names = es.indexNames(privileges.Names)
+		prefixed.Indices[i] = &p
+	}
+
+	body, err := json.Marshal(prefixed)
+	if err != nil {
+		return StatusInternalError, err
+	}
+
+	req := esapi.SecurityPutRoleRequest{
+		Name: name,
+		Body: bytes.NewReader(body),
+	}
+
+	res, err := req.Do(es.ctx, es.client)
+	return es.handleResponse("put role name="+name, res, err, nil)
+}
+
+// GetRoles returns the roles names by name. Empty names return all roles.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/security-api-get-role.html
+func (es *_elasticsearch) GetRoles(names ...string) (StatusCode, map[string]*Role, error) {
+	req := esapi.SecurityGetRoleRequest{
+		Name: names,
+	}
+
+	res, err := req.Do(es.ctx, es.client)
+
+	roles := map[string]*Role{}
+	if status, err := es.handleResponse("get roles name="+strings.Join(names, ","), res, err, &roles); err != nil {
+		return status, map[string]*Role{}, err
+	}
+	for _, role := range roles {
+		for _, privileges := range role.Indices {
+			for i, name := range privileges.Names {
+				privileges.Names[i] = es.trimIndexPrefix(name)
+			}
+		}
+	}
+	return StatusSuccess, roles, nil
+}
+
+func (es *_elasticsearch) DeleteRole(name string) (StatusCode, error) {
+	req := esapi.SecurityDeleteRoleRequest{
+		Name: name,
+	}
+
+	res, err := req.Do(es.ctx, es.client)
+	return es.handleResponse("delete role name="+name, res, err, nil)
+}
+
+// User is a user of the native realm.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/security-api-put-user.html
+type User struct {
+	Username string `json:"username,omitempty"`
+	// Password is required to create a user, and is never returned.
+	Password string                 `json:"password,omitempty"`
+	Roles    []string               `json:"roles"`
+	FullName string                 `json:"full_name,omitempty"`
+	Email    string                 `json:"email,omitempty"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+	// Enabled defaults to true when nil.
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
+// PutUser creates or updates the user username. An empty Password keeps the current one of an existing user.
+func (es *_elasticsearch) PutUser(username string, user *User) (StatusCode, error) {
+	u := *user
+	u.Username = ""
+	body, err := json.Marshal(u)
+	if err != nil {
+		return StatusInternalError, err
+	}
+
+	req := esapi.SecurityPutUserRequest{
+		Username: username,
+		Body:     bytes.NewReader(body),
+	}
+
+	res, err := req.Do(es.ctx, es.client)
+	return es.handleResponse("put user username="+username, res, err, nil)
+}
+
+// GetUsers returns the users usernames by username. Empty usernames return all users.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/security-api-get-user.html
+func (es *_elasticsearch) GetUsers(usernames ...string) (StatusCode, map[string]*User, error) {
+	req := esapi.SecurityGetUserRequest{
+		Username: usernames,
+	}
+
+	res, err := req.Do(es.ctx, es.client)
+
+	users := map[string]*User{}
+	if status, err := es.handleResponse("get users username="+strings.Join(usernames, ","), res, err, &users); err != nil {
+		return status, map[string]*User{}, err
+	}
+	return StatusSuccess, users, nil
+}
+
+func (es *_elasticsearch) DeleteUser(username string) (StatusCode, error) {
+	req := esapi.SecurityDeleteUserRequest{
+		Username: username,
+	}
+
+	res, err := req.Do(es.ctx, es.client)
+	return es.handleResponse("delete user username="+username, res, err, nil)
+}
+
+// RoleMapping grants roles to the users of external realms, such as LDAP or SAML, matching Rules, e.g.
+// {"field": {"groups": "cn=tenant-a,dc=example,dc=com"}}.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/security-api-put-role-mapping.html
+type RoleMapping struct {
+	Roles    []string               `json:"roles"`
+	Enabled  bool                   `json:"enabled"`
+	Rules    map[string]interface{} `json:"rules"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+func (es *_elasticsearch) PutRoleMapping(name string, mapping *RoleMapping) (StatusCode, error) {
+	body, err := json.Marshal(mapping)
+	if err != nil {
+		return StatusInternalError, err
+	}
+
+	req := esapi.SecurityPutRoleMappingRequest{
+		Name: name,
+		Body: bytes.NewReader(body),
+	}
+
+	res, err := req.Do(es.ctx, es.client)
+	return es.handleResponse("put role mapping name="+name, res, err, nil)
+}
+
+// GetRoleMappings returns the role mappings names by name. Empty names return all role mappings.
+func (es *_elasticsearch) GetRoleMappings(names ...string) (StatusCode, map[string]*RoleMapping, error) {
+	req := esapi.SecurityGetRoleMappingRequest{
+		Name: names,
+	}
+
+	res, err := req.Do(es.ctx, es.client)
+
+	mappings := map[string]*RoleMapping{}
+	if status, err := es.handleResponse("get role mappings name="+strings.Join(names, ","), res, err, &mappings); err != nil {
+		return status, map[string]*RoleMapping{}, err
+	}
+	return StatusSuccess, mappings, nil
+}
+
+func (es *_elasticsearch) DeleteRoleMapping(name string) (StatusCode, error) {
+	req := esapi.SecurityDeleteRoleMappingRequest{
+		Name: name,
+	}
+
+	res, err := req.Do(es.ctx, es.client)
+	return es.handleResponse("delete role mapping name="+name, res, err, nil)
+}
@@ -0,0 +1,205 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKeyRequests(t *testing.T) {
+	var body string
+	server, requests := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		switch {
+		case r.URL.Path == "/":
+			w.Write([]byte(`{}`))
+		case r.Method == http.MethodPost, r.Method == http.MethodPut:
+			w.Write([]byte(`{"id": "key-id", "name": "reader", "api_key": "secret", "expiration": 1700000000000}`))
+		case r.Method == http.MethodDelete:
+			w.Write([]byte(`{"invalidated_api_keys": ["key-id"], "previously_invalidated_api_keys": [], "error_count": 1,
+				"error_details": [{"type": "exception", "reason": "unknown key"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": {"type": "resource_not_found_exception", "reason": "no api key"}, "status": 404}`))
+		}
+	})
+	es, err := New(&Config{Address: []string{server.URL}})
+	assert.NoError(t, err)
+
+	t.Run("Create", func(t *testing.T) {
+		status, key, err := es.CreateAPIKey(&APIKeyRequest{Name: "reader", Expiration: "1d"})
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+		assert.Equal(t, "key-id", key.ID)
+		assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("key-id:secret")), key.Encoded)
+		assert.JSONEq(t, `{"name": "reader", "expiration": "1d"}`, body)
+	})
+
+	t.Run("Invalidate", func(t *testing.T) {
+		status, count, err := es.InvalidateAPIKey("key-id", "unknown")
+		assert.Error(t, err)
+		assert.Equal(t, StatusError, status)
+		assert.Equal(t, 1, count)
+		assert.JSONEq(t, `{"ids": ["key-id", "unknown"]}`, body)
+	})
+
+	t.Run("Get None", func(t *testing.T) {
+		status, keys, err := es.GetAPIKeys("reader-*")
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+		assert.Empty(t, keys)
+
+		reqs := requests()
+		req := reqs[len(reqs)-1]
+		assert.Equal(t, "/_security/api_key", req.URL.Path)
+		assert.Equal(t, "reader-*", req.URL.Query().Get("name"))
+		assert.Equal(t, "true", req.URL.Query().Get("owner"))
+	})
+}
+
+func TestAPIKey(t *testing.T) {
+	es := newElasticsearch()
+	name := indexName + "-api-key"
+
+	status, key, err := es.CreateAPIKey(&APIKeyRequest{
+		Name:       name,
+		Expiration: "1h",
+		RoleDescriptors: map[string]interface{}{
+			"reader": map[string]interface{}{
+				"indices": []map[string]interface{}{{"names": []string{indexName}, "privileges": []string{"read"}}},
+			},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSuccess, status)
+	assert.NotEmpty(t, key.Encoded)
+
+	status, keys, err := es.GetAPIKeys(name)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSuccess, status)
+	assert.Len(t, keys, 1)
+	assert.False(t, keys[0].Invalidated)
+
+	status, count, err := es.InvalidateAPIKey(key.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSuccess, status)
+	assert.Equal(t, 1, count)
+
+	_, keys, _ = es.GetAPIKeys(name)
+	assert.True(t, keys[0].Invalidated)
+}
+
+func TestRoleRequests(t *testing.T) {
+	var body string
+	server, requests := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		w.Write([]byte(`{"tenant-a": {"cluster": [], "indices": [{"names": ["dev-logs-a-*"], "privileges": ["read"]}]}}`))
+	})
+	es, err := New(&Config{Address: []string{server.URL}, IndexPrefix: "dev-"})
+	assert.NoError(t, err)
+
+	role := ReadOnlyRole("logs-a-*")
+	role.Indices[0].FieldSecurity = &FieldSecurity{Except: []string{"email"}}
+	status, err := es.PutRole("tenant-a", role)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSuccess, status)
+	assert.Equal(t, []string{"logs-a-*"}, role.Indices[0].Names)
+	assert.JSONEq(t, `{"indices": [{"names": ["dev-logs-a-*"], "privileges": ["read", "view_index_metadata"], "field_security": {"except": ["email"]}}]}`, body)
+
+	reqs := requests()
+	assert.Equal(t, "/_security/role/tenant-a", reqs[len(reqs)-1].URL.Path)
+
+	status, roles, err := es.GetRoles("tenant-a")
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSuccess, status)
+	assert.Equal(t, []string{"logs-a-*"}, roles["tenant-a"].Indices[0].Names)
+}
+
+func TestRoleAndUser(t *testing.T) {
+	es := newElasticsearch()
+	name := indexName + "-reader"
+
+	t.Run("Role", func(t *testing.T) {
+		status, err := es.PutRole(name, ReadOnlyRole(indexName))
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+
+		status, roles, err := es.GetRoles(name)
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+		assert.Equal(t, []string{indexName}, roles[name].Indices[0].Names)
+	})
+
+	t.Run("User", func(t *testing.T) {
+		status, err := es.PutUser(name, &User{Password: "changeme", Roles: []string{name}})
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+
+		status, users, err := es.GetUsers(name)
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+		assert.Equal(t, []string{name}, users[name].Roles)
+		assert.True(t, *users[name].Enabled)
+
+		status, err = es.DeleteUser(name)
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+	})
+
+	t.Run("Role Mapping", func(t *testing.T) {
+		status, err := es.PutRoleMapping(name, &RoleMapping{
+			Roles:   []string{name},
+			Enabled: true,
+			Rules:   map[string]interface{}{"field": map[string]interface{}{"groups": name}},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+
+		status, mappings, err := es.GetRoleMappings(name)
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+		assert.Equal(t, []string{name}, mappings[name].Roles)
+
+		status, err = es.DeleteRoleMapping(name)
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+	})
+
+	status, err := es.DeleteRole(name)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSuccess, status)
+
+	status, err = es.DeleteRole(name)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, StatusNotFoundError, status)
+}
+
+func TestRunAs(t *testing.T) {
+	server, requests := newTestServer(t, nil)
+	es, err := New(&Config{Address: []string{server.URL}, RunAs: "service"})
+	assert.NoError(t, err)
+
+	lastRunAs := func() []string {
+		reqs := requests()
+		return reqs[len(reqs)-1].Header.Values("es-security-runas-user")
+	}
+
+	assert.NoError(t, es.Ping())
+	assert.Equal(t, []string{"service"}, lastRunAs())
+
+	ctx := WithRunAs(context.Background(), "tenant-1")
+	assert.Equal(t, "tenant-1", RunAs(ctx))
+	assert.NoError(t, es.WithContext(ctx).Ping())
+	assert.Equal(t, []string{"tenant-1"}, lastRunAs())
+
+	es, err = New(&Config{Address: []string{server.URL}})
+	assert.NoError(t, err)
+	assert.NoError(t, es.Ping())
+	assert.Empty(t, lastRunAs())
+}
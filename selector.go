@@ -0,0 +1,62 @@
+package elasticsearch
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/elastic/go-elasticsearch/v7/estransport"
+)
+
+// The selectors choose among the live nodes only: a node failing a request is marked dead
+// and skipped until the transport resurrects it after a backoff.
+
+// RoundRobinSelector sends requests to the live nodes in turn.
+func RoundRobinSelector() estransport.Selector {
+	return &roundRobinSelector{}
+}
+
+type roundRobinSelector struct {
+	mu   sync.Mutex
+	next int
+}
+
+func (s *roundRobinSelector) Select(conns []*estransport.Connection) (*estransport.Connection, error) {
+	if len(conns) == 0 {
+		return nil, errors.New("no connection available")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	conn := conns[s.next%len(conns)]
+	s.next = (s.next + 1) % len(conns)
+	return conn, nil
+}
+
+// ZoneAwareSelector sends requests in turn to the live nodes whose attribute equals zone,
+// e.g. ZoneAwareSelector("zone", "ap-northeast-1a") for nodes started with node.attr.zone,
+// and to the other nodes only when none of them is live.
+// Node attributes are known from node discovery (Config.DiscoverNodesOnStart).
+func ZoneAwareSelector(attribute, zone string) estransport.Selector {
+	return &zoneAwareSelector{attribute: attribute, zone: zone}
+}
+
+type zoneAwareSelector struct {
+	attribute string
+	zone      string
+	local     roundRobinSelector
+	remote    roundRobinSelector
+}
+
+func (s *zoneAwareSelector) Select(conns []*estransport.Connection) (*estransport.Connection, error) {
+	local := []*estransport.Connection{}
+	for _, conn := range conns {
+		if fmt.Sprint(conn.Attributes[s.attribute]) == s.zone {
+			local = append(local, conn)
+		}
+	}
+	if len(local) > 0 {
+		return s.local.Select(local)
+	}
+	return s.remote.Select(conns)
+}
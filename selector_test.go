@@ -0,0 +1,78 @@
+package elasticsearch
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/elastic/go-elasticsearch/v7/estransport"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestConnection(host, zone string) *estransport.Connection {
+	return &estransport.Connection{
+		URL:        &url.URL{Scheme: "http", Host: host},
+		Attributes: map[string]interface{}{"zone": zone},
+	}
+}
+
+func TestRoundRobinSelector(t *testing.T) {
+	conns := []*estransport.Connection{
+		newTestConnection("es1:9200", "a"),
+		newTestConnection("es2:9200", "b"),
+	}
+	s := RoundRobinSelector()
+
+	hosts := []string{}
+	for i := 0; i < 4; i++ {
+		conn, err := s.Select(conns)
+		assert.NoError(t, err)
+		hosts = append(hosts, conn.URL.Host)
+	}
+	assert.Equal(t, []string{"es1:9200", "es2:9200", "es1:9200", "es2:9200"}, hosts)
+
+	_, err := s.Select(nil)
+	assert.Error(t, err)
+}
+
+func TestZoneAwareSelector(t *testing.T) {
+	s := ZoneAwareSelector("zone", "a")
+
+	t.Run("Local nodes", func(t *testing.T) {
+		conns := []*estransport.Connection{
+			newTestConnection("es1:9200", "a"),
+			newTestConnection("es2:9200", "b"),
+			newTestConnection("es3:9200", "a"),
+		}
+		for i := 0; i < 4; i++ {
+			conn, err := s.Select(conns)
+			assert.NoError(t, err)
+			assert.Equal(t, "a", conn.Attributes["zone"])
+		}
+	})
+
+	t.Run("No live local node", func(t *testing.T) {
+		conns := []*estransport.Connection{
+			newTestConnection("es2:9200", "b"),
+		}
+		conn, err := s.Select(conns)
+		assert.NoError(t, err)
+		assert.Equal(t, "es2:9200", conn.URL.Host)
+	})
+}
+
+func TestNodeSelector(t *testing.T) {
+	server1, requests1 := newTestServer(t, nil)
+	server2, requests2 := newTestServer(t, nil)
+
+	es, err := New(&Config{
+		Address:      []string{server1.URL, server2.URL},
+		NodeSelector: RoundRobinSelector(),
+	})
+	assert.NoError(t, err)
+
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, es.Ping())
+	}
+	assert.NotEmpty(t, requests1())
+	assert.NotEmpty(t, requests2())
+}
@@ -0,0 +1,41 @@
+package elasticsearch
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+)
+
+const defaultSlowLogQueryLimit = 1024
+
+// requestIndex returns the index (or indices, comma separated) targeted by req,
+// or "" for the APIs of the cluster.
+func requestIndex(req *http.Request) string {
+	segment := strings.SplitN(strings.TrimPrefix(req.URL.Path, "/"), "/", 2)[0]
+	if strings.HasPrefix(segment, "_") {
+		return ""
+	}
+	return segment
+}
+
+// slowLogMiddleware warns about the requests taking more than threshold, retries included.
+func slowLogMiddleware(logger Logger, threshold time.Duration, limit int) middleware {
+	if limit <= 0 {
+		limit = defaultSlowLogQueryLimit
+	}
+
+	return func(next esapi.Transport) esapi.Transport {
+		return transportFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			res, err := next.Perform(req)
+
+			if duration := time.Since(start); duration >= threshold {
+				logger.Warnf("Slow %s %s index=%s took %s: %s",
+					requestOperation(req), req.URL.Path, requestIndex(req), duration, truncate(redact(peekBody(req)), limit))
+			}
+			return res, err
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package elasticsearch
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestIndex(t *testing.T) {
+	for path, want := range map[string]string{
+		"/x/_search":        "x",
+		"/x,y/_doc/1":       "x,y",
+		"/_cluster/health":  "",
+		"/_index_template/": "",
+		"/":                 "",
+	} {
+		assert.Equal(t, want, requestIndex(&http.Request{URL: &url.URL{Path: path}}), path)
+	}
+}
+
+func TestSlowLog(t *testing.T) {
+	server, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/_search") {
+			time.Sleep(20 * time.Millisecond)
+		}
+		w.Write([]byte(`{"hits": {"total": {"value": 0, "relation": "eq"}, "hits": []}}`))
+	})
+
+	logger := &recordingLogger{}
+	es, err := New(&Config{
+		Address:        []string{server.URL},
+		Logger:         logger,
+		SlowThreshold:  10 * time.Millisecond,
+		SlowQueryLimit: 16,
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, es.Ping())
+	assert.Empty(t, logger.logs)
+
+	_, _, _, err = es.Search("x", `{"query": {"match_all": {}}}`, nil)
+	assert.NoError(t, err)
+
+	assert.Len(t, logger.logs, 1)
+	assert.Contains(t, logger.logs[0], `WARN Slow search /x/_search index=x took `)
+	assert.Contains(t, logger.logs[0], `{"query": {"matc... (12 bytes truncated)`)
+}
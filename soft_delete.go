@@ -0,0 +1,271 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+)
+
+// SoftDeleteConfig configures NewSoftDelete.
+type SoftDeleteConfig struct {
+	// Field holds the time a document was deleted at. Default: "deleted_at".
+	Field string
+	// Codec decodes the sources of GetSource into the results of the callers.
+	// It should be the Config.Codec of the wrapped client. Default: encoding/json.
+	Codec Codec
+}
+
+// SoftDelete marks the documents deleted instead of deleting them, so that they can be restored or audited:
+// RemoveDocument, and the BulkDelete items of Bulk, set Field to the current time with an update.
+// Search, SearchWithResult, SearchPage, SearchStream, Count, Export, ScanCompositeAggregation, MoreLikeThis and
+// Explain exclude the documents holding Field, Autocomplete drops their completions, so it may return fewer than size,
+// GetSource returns 404 for them and GetDocument ErrNotFound. PurgeDeleted deletes them for good.
+// A document is restored by updating Field to null.
+// The other methods are those of the wrapped client, so SearchWithTemplate, whose query is rendered by the server,
+// Suggest, TermsEnum, TermVectors, MTermVectors, DeleteByQuery and UpdateByQuery also see the deleted documents.
+type SoftDelete struct {
+	Elasticsearch
+
+	field string
+	codec Codec
+	now   func() time.Time
+}
+
+func NewSoftDelete(es Elasticsearch, config *SoftDeleteConfig) *SoftDelete {
+	if config == nil {
+		config = &SoftDeleteConfig{}
+	}
+	sd := &SoftDelete{Elasticsearch: es, field: config.Field, codec: config.Codec, now: time.Now}
+	if sd.field == "" {
+		sd.field = "deleted_at"
+	}
+	if sd.codec == nil {
+		sd.codec = jsonCodec{}
+	}
+	return sd
+}
+
+func (sd *SoftDelete) WithContext(ctx context.Context) Elasticsearch {
+	c := *sd
+	c.Elasticsearch = sd.Elasticsearch.WithContext(ctx)
+	return &c
+}
+
+func (sd *SoftDelete) WithIndexPrefix(prefix string) Elasticsearch {
+	c := *sd
+	c.Elasticsearch = sd.Elasticsearch.WithIndexPrefix(prefix)
+	return &c
+}
+
+func (sd *SoftDelete) WithPreference(preference string) Elasticsearch {
+	c := *sd
+	c.Elasticsearch = sd.Elasticsearch.WithPreference(preference)
+	return &c
+}
+
+func (sd *SoftDelete) unwrap() Elasticsearch {
+	return sd.Elasticsearch
+}
+
+// deletedBody returns the body of an update marking a document deleted.
+func (sd *SoftDelete) deletedBody() map[string]string {
+	return map[string]string{sd.field: sd.now().UTC().Format(time.RFC3339)}
+}
+
+// excludeDeleted returns query with its query clause, if any, excluding the deleted documents.
+func (sd *SoftDelete) excludeDeleted(query string) (string, error) {
+	body := map[string]json.RawMessage{}
+	if strings.TrimSpace(query) != "" {
+		if err := json.Unmarshal([]byte(query), &body); err != nil {
+			return "", err
+		}
+	}
+
+	b := map[string]interface{}{
+		"must_not": []Query{{"exists": map[string]string{"field": sd.field}}},
+	}
+	if q, ok := body["query"]; ok {
+		b["must"] = []json.RawMessage{q}
+	}
+	body["query"], _ = json.Marshal(Query{"bool": b})
+
+	s, err := json.Marshal(body)
+	return string(s), err
+}
+
+// deleted reports whether the source of a document holds Field.
+func (sd *SoftDelete) deleted(source json.RawMessage) (bool, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(source, &fields); err != nil {
+		return false, err
+	}
+	deletedAt, ok := fields[sd.field]
+	return ok && string(deletedAt) != "null", nil
+}
+
+// GetSource returns ErrNotFound for the deleted documents.
+func (sd *SoftDelete) GetSource(index string, id string, result any, opts ...GetOption) (StatusCode, error) {
+	var source json.RawMessage
+	status, err := sd.Elasticsearch.GetSource(index, id, &source, append(opts, withSourceField(sd.field))...)
+	if err != nil || len(source) == 0 {
+		return status, err
+	}
+
+	deleted, err := sd.deleted(source)
+	if err != nil {
+		return StatusParseError, &ParseError{Err: err}
+	}
+	if deleted {
+		return StatusNotFoundError, ErrNotFound
+	}
+	if err := sd.codec.Unmarshal(source, result); err != nil {
+		return StatusParseError, &ParseError{Err: err}
+	}
+	return status, nil
+}
+
+// GetDocument returns ErrNotFound for the deleted documents.
+func (sd *SoftDelete) GetDocument(index, id string, result any, opts ...GetOption) (StatusCode, *DocumentMeta, error) {
+	var source json.RawMessage
+	status, meta, err := sd.Elasticsearch.GetDocument(index, id, &source, append(opts, withSourceField(sd.field))...)
+	if err != nil {
+		return status, meta, err
+	}
+
+	deleted, err := sd.deleted(source)
+	if err != nil {
+		return StatusParseError, meta, &ParseError{Err: err}
+	}
+	if deleted {
+		return StatusNotFoundError, nil, ErrNotFound
+	}
+	if result != nil {
+		if err := sd.codec.Unmarshal(source, result); err != nil {
+			return StatusParseError, meta, &ParseError{Err: err}
+		}
+	}
+	return status, meta, nil
+}
+
+func (sd *SoftDelete) Search(index string, query string, data interface{}, opts ...SearchOption) (StatusCode, []*HitData, int, error) {
+	query, err := sd.excludeDeleted(query)
+	if err != nil {
+		return StatusInternalError, []*HitData{}, 0, err
+	}
+	return sd.Elasticsearch.Search(index, query, data, opts...)
+}
+
+func (sd *SoftDelete) SearchWithResult(index string, query string, data interface{}, opts ...SearchOption) (StatusCode, *SearchResult, error) {
+	query, err := sd.excludeDeleted(query)
+	if err != nil {
+		return StatusInternalError, &SearchResult{Hits: []*HitData{}}, err
+	}
+	return sd.Elasticsearch.SearchWithResult(index, query, data, opts...)
+}
+
+func (sd *SoftDelete) SearchPage(index, query string, page Page, data interface{}, opts ...SearchOption) (StatusCode, []*HitData, *PageInfo, error) {
+	query, err := sd.excludeDeleted(query)
+	if err != nil {
+		return StatusInternalError, []*HitData{}, &PageInfo{Number: page.Number, Size: page.Size}, err
+	}
+	return sd.Elasticsearch.SearchPage(index, query, page, data, opts...)
+}
+
+func (sd *SoftDelete) SearchStream(index, query string, fn func(hit *HitData) error, opts ...SearchOption) (StatusCode, int, error) {
+	query, err := sd.excludeDeleted(query)
+	if err != nil {
+		return StatusInternalError, 0, err
+	}
+	return sd.Elasticsearch.SearchStream(index, query, fn, opts...)
+}
+
+func (sd *SoftDelete) Count(index string, query string, opts ...SearchOption) (StatusCode, int, error) {
+	query, err := sd.excludeDeleted(query)
+	if err != nil {
+		return StatusInternalError, 0, err
+	}
+	return sd.Elasticsearch.Count(index, query, opts...)
+}
+
+func (sd *SoftDelete) Export(ctx context.Context, index, query string, w io.Writer, opts ...ExportOption) (StatusCode, int, error) {
+	query, err := sd.excludeDeleted(query)
+	if err != nil {
+		return StatusInternalError, 0, err
+	}
+	return sd.Elasticsearch.Export(ctx, index, query, w, opts...)
+}
+
+func (sd *SoftDelete) ScanCompositeAggregation(index, query string, agg *CompositeAggregation, fn func(bucket *CompositeBucket) error) (StatusCode, error) {
+	query, err := sd.excludeDeleted(query)
+	if err != nil {
+		return StatusInternalError, err
+	}
+	return sd.Elasticsearch.ScanCompositeAggregation(index, query, agg, fn)
+}
+
+func (sd *SoftDelete) MoreLikeThis(index, id string, fields []string, data interface{}, opts ...MoreLikeThisOption) (StatusCode, []*HitData, int, error) {
+	notDeleted := Query{"bool": map[string]interface{}{
+		"must_not": []Query{{"exists": map[string]string{"field": sd.field}}},
+	}}
+	return sd.Elasticsearch.MoreLikeThis(index, id, fields, data, append(opts, MLTFilter(notDeleted))...)
+}
+
+// Explain reports the deleted documents as not matching.
+func (sd *SoftDelete) Explain(index, id, query string) (StatusCode, bool, *Explanation, error) {
+	query, err := sd.excludeDeleted(query)
+	if err != nil {
+		return StatusInternalError, false, nil, err
+	}
+	return sd.Elasticsearch.Explain(index, id, query)
+}
+
+// Autocomplete drops the completions of the deleted documents.
+func (sd *SoftDelete) Autocomplete(index, field, prefix string, size int) (StatusCode, []*Completion, error) {
+	status, completions, err := sd.Elasticsearch.Autocomplete(index, field, prefix, size)
+	if err != nil {
+		return status, completions, err
+	}
+
+	kept := []*Completion{}
+	for _, c := range completions {
+		deleted, err := sd.deleted(c.Source)
+		if err != nil {
+			return StatusParseError, []*Completion{}, &ParseError{Err: err}
+		}
+		if !deleted {
+			kept = append(kept, c)
+		}
+	}
+	return status, kept, nil
+}
+
+// RemoveDocument marks the document deleted.
+func (sd *SoftDelete) RemoveDocument(doc *Document) (StatusCode, error) {
+	d := *doc
+	d.Body = sd.deletedBody()
+	return sd.Elasticsearch.UpdateDocument(&d)
+}
+
+// Bulk marks the documents of the BulkDelete items deleted.
+func (sd *SoftDelete) Bulk(items []*BulkItem, refresh RefreshPolicy) (StatusCode, []*BulkItemResult, error) {
+	marked := make([]*BulkItem, len(items))
+	for i, item := range items {
+		marked[i] = item
+		if item.Action == BulkDelete {
+			it := *item
+			it.Action = BulkUpdate
+			it.Body = sd.deletedBody()
+			marked[i] = &it
+		}
+	}
+	return sd.Elasticsearch.Bulk(marked, refresh)
+}
+
+// PurgeDeleted deletes the documents of index marked deleted more than olderThan ago, returning their number.
+func (sd *SoftDelete) PurgeDeleted(index string, olderThan time.Duration, opts ...ByQueryOption) (StatusCode, int, error) {
+	before := sd.now().Add(-olderThan).UTC().Format(time.RFC3339)
+	query := SearchBody(Query{"range": map[string]interface{}{sd.field: map[string]string{"lt": before}}})
+	return sd.Elasticsearch.DeleteByQuery(index, query, opts...)
+}
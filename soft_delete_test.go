@@ -0,0 +1,128 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSoftDelete(t *testing.T) {
+	var body string
+	server, requests := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		switch r.URL.Path {
+		case "/a/_doc/1/_source":
+			w.Write([]byte(`{"id": "1", "deleted_at": "2024-01-01T00:00:00Z"}`))
+		case "/a/_doc/2/_source":
+			w.Write([]byte(`{"id": "2", "deleted_at": null}`))
+		case "/a/_delete_by_query":
+			w.Write([]byte(`{"deleted": 3}`))
+		case "/c/_search":
+			w.Write([]byte(`{"suggest": {"autocomplete": [{"options": [
+				{"text": "tokyo", "_id": "1", "_source": {"deleted_at": "2024-01-01T00:00:00Z"}},
+				{"text": "toyama", "_id": "2", "_source": {"deleted_at": null}}
+			]}]}}`))
+		default:
+			w.Write([]byte(`{}`))
+		}
+	})
+	client, err := New(&Config{Address: []string{server.URL}})
+	assert.NoError(t, err)
+
+	es := NewSoftDelete(client, nil)
+	now := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	es.now = func() time.Time { return now }
+
+	lastRequest := func() *http.Request {
+		reqs := requests()
+		return reqs[len(reqs)-1]
+	}
+
+	t.Run("RemoveDocument", func(t *testing.T) {
+		status, err := es.RemoveDocument(&Document{Index: "a", ID: "1"})
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+		assert.Equal(t, "/a/_doc/1/_update", lastRequest().URL.Path)
+		assert.JSONEq(t, `{"doc": {"deleted_at": "2024-02-01T00:00:00Z"}}`, body)
+	})
+
+	t.Run("Bulk", func(t *testing.T) {
+		items := []*BulkItem{{Action: BulkDelete, Index: "a", ID: "1"}}
+		es.Bulk(items, RefreshFalse)
+
+		assert.Contains(t, body, `{"update":`)
+		assert.Contains(t, body, `"deleted_at":"2024-02-01T00:00:00Z"`)
+		assert.Equal(t, BulkDelete, items[0].Action)
+	})
+
+	t.Run("Search", func(t *testing.T) {
+		_, _, _, err := es.Search("a", SearchBody(TermQuery("id", "1")), nil)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"query": {"bool": {"must": [{"term": {"id": "1"}}], "must_not": [{"exists": {"field": "deleted_at"}}]}}}`, body)
+
+		_, _, err = es.Count("a", "")
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"query": {"bool": {"must_not": [{"exists": {"field": "deleted_at"}}]}}}`, body)
+
+		status, _, _, err := es.Search("a", "{", nil)
+		assert.Error(t, err)
+		assert.Equal(t, StatusInternalError, status)
+	})
+
+	t.Run("Query methods", func(t *testing.T) {
+		notDeleted := `{"bool": {"must_not": [{"exists": {"field": "deleted_at"}}]}}`
+		var sent struct {
+			Query struct {
+				Bool struct {
+					Filter json.RawMessage `json:"filter"`
+				} `json:"bool"`
+			} `json:"query"`
+		}
+
+		agg := &CompositeAggregation{Sources: []map[string]interface{}{{"id": Query{"terms": map[string]string{"field": "id"}}}}}
+		_, err := es.ScanCompositeAggregation("a", "", agg, func(bucket *CompositeBucket) error { return nil })
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"query": `+notDeleted+`, "size": 0, "aggs": {"composite": {"composite": {"sources": [{"id": {"terms": {"field": "id"}}}]}}}}`, body)
+
+		es.MoreLikeThis("a", "1", []string{"title"}, nil)
+		assert.NoError(t, json.Unmarshal([]byte(body), &sent))
+		assert.JSONEq(t, notDeleted, string(sent.Query.Bool.Filter))
+
+		es.Explain("a", "1", SearchBody(TermQuery("id", "1")))
+		assert.JSONEq(t, `{"query": {"bool": {"must": [{"term": {"id": "1"}}], "must_not": [{"exists": {"field": "deleted_at"}}]}}}`, body)
+	})
+
+	t.Run("Autocomplete", func(t *testing.T) {
+		status, completions, err := es.Autocomplete("c", "name", "to", 10)
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+		assert.Len(t, completions, 1)
+		assert.Equal(t, "toyama", completions[0].Text)
+	})
+
+	t.Run("GetSource", func(t *testing.T) {
+		var doc map[string]interface{}
+		status, err := es.GetSource("a", "1", &doc)
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.Equal(t, StatusNotFoundError, status)
+		assert.Nil(t, doc)
+
+		status, err = es.GetSource("a", "2", &doc)
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+		assert.Equal(t, "2", doc["id"])
+	})
+
+	t.Run("PurgeDeleted", func(t *testing.T) {
+		status, n, err := es.PurgeDeleted("a", 24*time.Hour)
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+		assert.Equal(t, 3, n)
+		assert.JSONEq(t, `{"query": {"range": {"deleted_at": {"lt": "2024-01-31T00:00:00Z"}}}}`, body)
+	})
+}
@@ -0,0 +1,53 @@
+package elasticsearch
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// ClientStats are the statistics of the requests and the connection pool of a client,
+// e.g. to be exported as gauges.
+type ClientStats struct {
+	// Requests counts the requests sent to the nodes, including retries, and Failures those failing
+	// without response.
+	Requests int
+	Failures int
+	// Responses counts the responses by status code.
+	Responses map[int]int
+	// InFlight is the number of requests waiting for their response.
+	InFlight int
+
+	LiveNodes int
+	DeadNodes int
+	Nodes     []*NodeStats
+}
+
+// NodeStats is the state of the connection to a node. A dead node is not sent requests until it is resurrected.
+type NodeStats struct {
+	URL       string
+	ID        string
+	Name      string
+	Dead      bool
+	Failures  int
+	DeadSince time.Time
+}
+
+func (es *_elasticsearch) Stats() *ClientStats {
+	stats := &ClientStats{
+		Responses: map[int]int{},
+		InFlight:  int(atomic.LoadInt64(&es.client.inFlight)),
+		Nodes:     []*NodeStats{},
+	}
+
+	if err := es.client.es.metrics(stats); err != nil {
+		return stats
+	}
+	for _, node := range stats.Nodes {
+		if node.Dead {
+			stats.DeadNodes++
+		} else {
+			stats.LiveNodes++
+		}
+	}
+	return stats
+}
@@ -0,0 +1,40 @@
+package elasticsearch
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStats(t *testing.T) {
+	release := make(chan struct{})
+	server, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/a/_count" {
+			<-release
+		}
+		w.Write([]byte(`{"count": 1}`))
+	})
+	es, err := New(&Config{Address: []string{server.URL}})
+	assert.NoError(t, err)
+	es.Ping()
+
+	done := make(chan struct{})
+	go func() {
+		es.Count("a", "")
+		close(done)
+	}()
+	assert.Eventually(t, func() bool { return es.Stats().InFlight == 1 }, time.Second, 10*time.Millisecond)
+	close(release)
+	<-done
+
+	stats := es.Stats()
+	assert.Equal(t, 0, stats.InFlight)
+	assert.Equal(t, 1, stats.LiveNodes)
+	assert.Equal(t, 0, stats.DeadNodes)
+	assert.Len(t, stats.Nodes, 1)
+	assert.Equal(t, server.URL, stats.Nodes[0].URL)
+	assert.GreaterOrEqual(t, stats.Requests, 2)
+	assert.GreaterOrEqual(t, stats.Responses[200], 2)
+}
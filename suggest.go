@@ -0,0 +1,145 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/search-suggesters.html#completion-suggester
+type Completion struct {
+	Text   string          `json:"text"`
+	Index  string          `json:"_index"`
+	Id     string          `json:"_id"`
+	Score  float64         `json:"_score"`
+	Source json.RawMessage `json:"_source"`
+}
+
+// CompletionInput is the value indexed into a completion field.
+type CompletionInput struct {
+	Input  []string `json:"input"`
+	Weight int      `json:"weight,omitempty"`
+}
+
+// CompletionField returns the mapping of a completion field to be placed under
+// "properties" of an index template. An empty analyzer uses the default "simple".
+func CompletionField(analyzer string) map[string]interface{} {
+	field := map[string]interface{}{
+		"type": "completion",
+	}
+	if analyzer != "" {
+		field["analyzer"] = analyzer
+	}
+	return field
+}
+
+func (es *_elasticsearch) Autocomplete(index, field, prefix string, size int) (StatusCode, []*Completion, error) {
+	completion := map[string]interface{}{
+		"field":           field,
+		"skip_duplicates": true,
+	}
+	if size > 0 {
+		completion["size"] = size
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"suggest": map[string]interface{}{
+			"autocomplete": map[string]interface{}{
+				"prefix":     prefix,
+				"completion": completion,
+			},
+		},
+	})
+	if err != nil {
+		return StatusInternalError, []*Completion{}, err
+	}
+
+	res, err := es.client.Search(
+		es.client.Search.WithContext(es.ctx),
+		es.client.Search.WithIndex(es.indexName(index)),
+		es.client.Search.WithBody(bytes.NewReader(body)),
+	)
+
+	var r struct {
+		Suggest map[string][]struct {
+			Options []*Completion `json:"options"`
+		} `json:"suggest"`
+	}
+	if status, err := es.handleResponse("autocomplete field="+field, res, err, &r); err != nil {
+		return status, []*Completion{}, err
+	}
+
+	completions := []*Completion{}
+	for _, entry := range r.Suggest["autocomplete"] {
+		completions = append(completions, entry.Options...)
+	}
+	for _, c := range completions {
+		c.Index = es.trimIndexPrefix(c.Index)
+	}
+
+	return StatusSuccess, completions, nil
+}
+
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/search-suggesters.html
+type SuggestOption struct {
+	Text        string  `json:"text"`
+	Highlighted string  `json:"highlighted,omitempty"`
+	Score       float64 `json:"score"`
+	Freq        int     `json:"freq,omitempty"`
+}
+
+type SuggestEntry struct {
+	Text    string           `json:"text"`
+	Offset  int              `json:"offset"`
+	Length  int              `json:"length"`
+	Options []*SuggestOption `json:"options"`
+}
+
+// Suggestions holds the corrections returned by Suggest.
+// Terms has one entry per token of the input text; Phrases are whole-text corrections
+// ordered by score, so Phrases[0] is the best "did you mean" candidate.
+type Suggestions struct {
+	Terms   []*SuggestEntry
+	Phrases []*SuggestOption
+}
+
+func (es *_elasticsearch) Suggest(index, field, text string) (StatusCode, *Suggestions, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"size": 0,
+		"suggest": map[string]interface{}{
+			"text": text,
+			"term": map[string]interface{}{
+				"term": map[string]interface{}{
+					"field": field,
+				},
+			},
+			"phrase": map[string]interface{}{
+				"phrase": map[string]interface{}{
+					"field": field,
+					"size":  3,
+					"highlight": map[string]interface{}{
+						"pre_tag":  "<em>",
+						"post_tag": "</em>",
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return StatusInternalError, &Suggestions{}, err
+	}
+
+	status, result, err := es.SearchWithResult(index, string(body), nil)
+	if err != nil || status != StatusSuccess {
+		return status, &Suggestions{}, err
+	}
+
+	suggestions := &Suggestions{
+		Terms:   result.Suggest["term"],
+		Phrases: []*SuggestOption{},
+	}
+	for _, entry := range result.Suggest["phrase"] {
+		suggestions.Phrases = append(suggestions.Phrases, entry.Options...)
+	}
+
+	return StatusSuccess, suggestions, nil
+}
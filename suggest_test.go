@@ -0,0 +1,114 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/bxcodec/faker/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+const suggestIndexName = "test-es-suggest"
+
+type SuggestDocBody struct {
+	Id      string          `json:"id"`
+	Title   string          `json:"title"`
+	Suggest CompletionInput `json:"suggest"`
+}
+
+func createSuggestIndex(t *testing.T, es Elasticsearch) {
+	templates, _ := json.Marshal(map[string]interface{}{
+		"index_patterns": []string{suggestIndexName},
+		"template": map[string]interface{}{
+			"mappings": map[string]interface{}{
+				"properties": map[string]interface{}{
+					"title":   map[string]interface{}{"type": "text"},
+					"suggest": CompletionField(""),
+				},
+			},
+		},
+	})
+	if _, err := es.CreateIndexTemplate(suggestIndexName, string(templates)); err != nil {
+		t.FailNow()
+	}
+}
+
+func TestAutocomplete(t *testing.T) {
+	es := newElasticsearch()
+	createSuggestIndex(t, es)
+	defer es.DeleteIndeces(suggestIndexName)
+
+	titles := []string{"elasticsearch", "elastic cloud", "kibana"}
+	for _, title := range titles {
+		id := faker.UUIDDigit()
+		es.CreateDocument(&Document{
+			Index: suggestIndexName,
+			ID:    id,
+			Body: SuggestDocBody{
+				Id:      id,
+				Title:   title,
+				Suggest: CompletionInput{Input: []string{title}},
+			},
+		})
+	}
+	es.Refresh(suggestIndexName)
+
+	t.Run("Found", func(t *testing.T) {
+		status, completions, err := es.Autocomplete(suggestIndexName, "suggest", "ela", 10)
+
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+		assert.Len(t, completions, 2)
+		for _, c := range completions {
+			assert.Equal(t, suggestIndexName, c.Index)
+			assert.Contains(t, []string{"elasticsearch", "elastic cloud"}, c.Text)
+		}
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		status, completions, err := es.Autocomplete(suggestIndexName, "suggest", "zzz", 10)
+
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+		assert.Empty(t, completions)
+	})
+}
+
+func TestSuggest(t *testing.T) {
+	es := newElasticsearch()
+	createSuggestIndex(t, es)
+	defer es.DeleteIndeces(suggestIndexName)
+
+	for i := 0; i < 3; i++ {
+		id := faker.UUIDDigit()
+		es.CreateDocument(&Document{
+			Index: suggestIndexName,
+			ID:    id,
+			Body: SuggestDocBody{
+				Id:      id,
+				Title:   "elasticsearch client",
+				Suggest: CompletionInput{Input: []string{"elasticsearch"}},
+			},
+		})
+	}
+	es.Refresh(suggestIndexName)
+
+	t.Run("Misspelled", func(t *testing.T) {
+		status, suggestions, err := es.Suggest(suggestIndexName, "title", "elasticsaerch clinet")
+
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+		assert.Len(t, suggestions.Terms, 2)
+		assert.Equal(t, "elasticsearch", suggestions.Terms[0].Options[0].Text)
+		assert.NotEmpty(t, suggestions.Phrases)
+		assert.Equal(t, "elasticsearch client", suggestions.Phrases[0].Text)
+	})
+
+	t.Run("Correct", func(t *testing.T) {
+		status, suggestions, err := es.Suggest(suggestIndexName, "title", "elasticsearch")
+
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+		assert.Empty(t, suggestions.Terms[0].Options)
+	})
+}
@@ -0,0 +1,409 @@
+package elasticsearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ErrNoTenant is returned by TenantIndex, without sending the request, for the requests on its index
+// made without a tenant in the context.
+var ErrNoTenant = errors.New("elasticsearch: no tenant in the context")
+
+// ErrInvalidTenant is returned by TenantIndex for a tenant ID with characters that are not valid in index names,
+// such as ",", "*", whitespace or uppercase letters.
+var ErrInvalidTenant = errors.New("elasticsearch: invalid tenant")
+
+// ErrTenantPattern is returned by TenantIndex, without sending the request, for the requests whose target
+// matches its index without naming it, i.e. an empty target, _all, or a wildcard pattern.
+var ErrTenantPattern = errors.New("elasticsearch: target matches the tenant index")
+
+type tenantKey struct{}
+
+// WithTenant returns a context whose requests through a TenantIndex target the alias of tenant.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// Tenant returns the tenant set by WithTenant.
+func Tenant(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	return tenant
+}
+
+// TenantIndex isolates the tenants sharing a physical index with a filtered alias per tenant,
+// matching the documents whose field holds the tenant ID and routing them by the tenant ID:
+//
+//	articles := elasticsearch.NewTenantIndex(es, "articles", "tenant_id")
+//	articles.AddTenant("acme")
+//	articles.WithContext(elasticsearch.WithTenant(ctx, "acme")).Search("articles", query, &docs)
+//
+// The reads and writes on the index through a TenantIndex bound to a context with a tenant target
+// the alias of the tenant instead: every method of DocumentReader and DocumentWriter taking an index.
+// They fail with ErrNoTenant without a tenant, and with ErrTenantPattern for a target matching the index
+// without naming it, such as "_all" or "art*". The other indices and methods are left unchanged.
+//
+// Written documents must hold the tenant ID in the field, since the filter of an alias only applies to searches.
+// For the same reason, GetSource returns a document of another tenant given its ID, but only if it has
+// the same routing.
+type TenantIndex struct {
+	Elasticsearch
+
+	index  string
+	field  string
+	tenant string
+}
+
+func NewTenantIndex(es Elasticsearch, index, field string) *TenantIndex {
+	return &TenantIndex{Elasticsearch: es, index: index, field: field}
+}
+
+func (ti *TenantIndex) unwrap() Elasticsearch {
+	return ti.Elasticsearch
+}
+
+// Alias returns the alias of tenant, "<index>-<tenant>".
+func (ti *TenantIndex) Alias(tenant string) string {
+	return ti.index + "-" + tenant
+}
+
+// AddTenant creates the alias of tenant.
+func (ti *TenantIndex) AddTenant(tenant string) (StatusCode, error) {
+	if err := validateTenant(tenant); err != nil {
+		return StatusInternalError, err
+	}
+	return ti.Elasticsearch.PutAlias(ti.index, ti.Alias(tenant), &Alias{
+		Filter:  TermQuery(ti.field, tenant),
+		Routing: tenant,
+	})
+}
+
+// RemoveTenant deletes the alias of tenant, leaving its documents in the index.
+func (ti *TenantIndex) RemoveTenant(tenant string) (StatusCode, error) {
+	if err := validateTenant(tenant); err != nil {
+		return StatusInternalError, err
+	}
+	return ti.Elasticsearch.DeleteAlias(ti.index, ti.Alias(tenant))
+}
+
+// WithContext returns a TenantIndex bound to ctx, targeting the alias of Tenant(ctx).
+func (ti *TenantIndex) WithContext(ctx context.Context) Elasticsearch {
+	return &TenantIndex{Elasticsearch: ti.Elasticsearch.WithContext(ctx), index: ti.index, field: ti.field, tenant: Tenant(ctx)}
+}
+
+func (ti *TenantIndex) WithIndexPrefix(prefix string) Elasticsearch {
+	c := *ti
+	c.Elasticsearch = ti.Elasticsearch.WithIndexPrefix(prefix)
+	return &c
+}
+
+func (ti *TenantIndex) WithPreference(preference string) Elasticsearch {
+	c := *ti
+	c.Elasticsearch = ti.Elasticsearch.WithPreference(preference)
+	return &c
+}
+
+// target returns the target with the entries naming the index replaced by the alias of the tenant, and the other
+// entries unchanged. It fails with ErrTenantPattern for a target that matches the index otherwise,
+// i.e. an empty target, _all, or a wildcard pattern that does not exclude it.
+func (ti *TenantIndex) target(index string) (string, error) {
+	names := strings.Split(index, ",")
+	excluded := false
+	for _, name := range names {
+		if name == "-"+ti.index {
+			excluded = true
+		}
+	}
+	for i, name := range names {
+		switch {
+		case name == ti.index:
+			alias, err := ti.tenantAlias()
+			if err != nil {
+				return "", err
+			}
+			names[i] = alias
+		case excluded || strings.HasPrefix(name, "-"):
+		case name == "" || name == "_all":
+			return "", ErrTenantPattern
+		case strings.Contains(name, "*"):
+			if ok, _ := path.Match(name, ti.index); ok {
+				return "", ErrTenantPattern
+			}
+		}
+	}
+	return strings.Join(names, ","), nil
+}
+
+// tenantAlias returns the alias of the tenant of the context.
+func (ti *TenantIndex) tenantAlias() (string, error) {
+	if ti.tenant == "" {
+		return "", ErrNoTenant
+	}
+	if err := validateTenant(ti.tenant); err != nil {
+		return "", err
+	}
+	return ti.Alias(ti.tenant), nil
+}
+
+// validateTenant rejects the tenant IDs that are not valid in index names, and could make the alias
+// of a tenant name several indices or a pattern.
+func validateTenant(tenant string) error {
+	if tenant == "" {
+		return fmt.Errorf("%w: empty", ErrInvalidTenant)
+	}
+	for _, r := range tenant {
+		if unicode.IsSpace(r) || unicode.IsUpper(r) || strings.ContainsRune(`,*?"<>|\/#:`, r) {
+			return fmt.Errorf("%w: %q", ErrInvalidTenant, tenant)
+		}
+	}
+	return nil
+}
+
+// targetDocument returns doc targeting the alias of the tenant, or doc itself.
+func (ti *TenantIndex) targetDocument(doc *Document) (*Document, error) {
+	index, err := ti.target(doc.Index)
+	if err != nil || index == doc.Index {
+		return doc, err
+	}
+	d := *doc
+	d.Index = index
+	return &d, nil
+}
+
+func (ti *TenantIndex) GetSource(index string, id string, result any, opts ...GetOption) (StatusCode, error) {
+	index, err := ti.target(index)
+	if err != nil {
+		return StatusInternalError, err
+	}
+	return ti.Elasticsearch.GetSource(index, id, result, opts...)
+}
+
+func (ti *TenantIndex) GetDocument(index, id string, result any, opts ...GetOption) (StatusCode, *DocumentMeta, error) {
+	index, err := ti.target(index)
+	if err != nil {
+		return StatusInternalError, nil, err
+	}
+	return ti.Elasticsearch.GetDocument(index, id, result, opts...)
+}
+
+func (ti *TenantIndex) Search(index string, query string, data interface{}, opts ...SearchOption) (StatusCode, []*HitData, int, error) {
+	index, err := ti.target(index)
+	if err != nil {
+		return StatusInternalError, []*HitData{}, 0, err
+	}
+	return ti.Elasticsearch.Search(index, query, data, opts...)
+}
+
+func (ti *TenantIndex) SearchWithResult(index string, query string, data interface{}, opts ...SearchOption) (StatusCode, *SearchResult, error) {
+	index, err := ti.target(index)
+	if err != nil {
+		return StatusInternalError, &SearchResult{Hits: []*HitData{}}, err
+	}
+	return ti.Elasticsearch.SearchWithResult(index, query, data, opts...)
+}
+
+func (ti *TenantIndex) SearchPage(index, query string, page Page, data interface{}, opts ...SearchOption) (StatusCode, []*HitData, *PageInfo, error) {
+	index, err := ti.target(index)
+	if err != nil {
+		return StatusInternalError, []*HitData{}, &PageInfo{Number: page.Number, Size: page.Size}, err
+	}
+	return ti.Elasticsearch.SearchPage(index, query, page, data, opts...)
+}
+
+func (ti *TenantIndex) SearchStream(index, query string, fn func(hit *HitData) error, opts ...SearchOption) (StatusCode, int, error) {
+	index, err := ti.target(index)
+	if err != nil {
+		return StatusInternalError, 0, err
+	}
+	return ti.Elasticsearch.SearchStream(index, query, fn, opts...)
+}
+
+func (ti *TenantIndex) Count(index string, query string, opts ...SearchOption) (StatusCode, int, error) {
+	index, err := ti.target(index)
+	if err != nil {
+		return StatusInternalError, 0, err
+	}
+	return ti.Elasticsearch.Count(index, query, opts...)
+}
+
+func (ti *TenantIndex) CreateDocument(doc *Document) (StatusCode, error) {
+	doc, err := ti.targetDocument(doc)
+	if err != nil {
+		return StatusInternalError, err
+	}
+	return ti.Elasticsearch.CreateDocument(doc)
+}
+
+func (ti *TenantIndex) UpdateDocument(doc *Document) (StatusCode, error) {
+	doc, err := ti.targetDocument(doc)
+	if err != nil {
+		return StatusInternalError, err
+	}
+	return ti.Elasticsearch.UpdateDocument(doc)
+}
+
+func (ti *TenantIndex) RemoveDocument(doc *Document) (StatusCode, error) {
+	doc, err := ti.targetDocument(doc)
+	if err != nil {
+		return StatusInternalError, err
+	}
+	return ti.Elasticsearch.RemoveDocument(doc)
+}
+
+func (ti *TenantIndex) Bulk(items []*BulkItem, refresh RefreshPolicy) (StatusCode, []*BulkItemResult, error) {
+	targeted := make([]*BulkItem, len(items))
+	for i, item := range items {
+		index, err := ti.target(item.Index)
+		if err != nil {
+			return StatusInternalError, []*BulkItemResult{}, err
+		}
+		targeted[i] = item
+		if index != item.Index {
+			it := *item
+			it.Index = index
+			targeted[i] = &it
+		}
+	}
+	return ti.Elasticsearch.Bulk(targeted, refresh)
+}
+
+func (ti *TenantIndex) DeleteByQuery(index, query string, opts ...ByQueryOption) (StatusCode, int, error) {
+	index, err := ti.target(index)
+	if err != nil {
+		return StatusInternalError, 0, err
+	}
+	return ti.Elasticsearch.DeleteByQuery(index, query, opts...)
+}
+
+func (ti *TenantIndex) UpdateByQuery(index, query string, opts ...ByQueryOption) (StatusCode, int, error) {
+	index, err := ti.target(index)
+	if err != nil {
+		return StatusInternalError, 0, err
+	}
+	return ti.Elasticsearch.UpdateByQuery(index, query, opts...)
+}
+
+func (ti *TenantIndex) LoadFixtures(index string, r io.Reader) (StatusCode, error) {
+	index, err := ti.target(index)
+	if err != nil {
+		return StatusInternalError, err
+	}
+	return ti.Elasticsearch.LoadFixtures(index, r)
+}
+
+func (ti *TenantIndex) BulkFromNDJSON(ctx context.Context, index string, r io.Reader, opts ...BulkStreamOption) (StatusCode, int, error) {
+	index, err := ti.target(index)
+	if err != nil {
+		return StatusInternalError, 0, err
+	}
+	return ti.Elasticsearch.BulkFromNDJSON(ctx, index, r, opts...)
+}
+
+func (ti *TenantIndex) BulkFromCSV(ctx context.Context, index string, r io.Reader, mapping *CSVMapping, opts ...BulkStreamOption) (StatusCode, int, error) {
+	index, err := ti.target(index)
+	if err != nil {
+		return StatusInternalError, 0, err
+	}
+	return ti.Elasticsearch.BulkFromCSV(ctx, index, r, mapping, opts...)
+}
+
+func (ti *TenantIndex) Export(ctx context.Context, index, query string, w io.Writer, opts ...ExportOption) (StatusCode, int, error) {
+	index, err := ti.target(index)
+	if err != nil {
+		return StatusInternalError, 0, err
+	}
+	return ti.Elasticsearch.Export(ctx, index, query, w, opts...)
+}
+
+func (ti *TenantIndex) OpenPIT(index string, keepAlive time.Duration) (StatusCode, string, error) {
+	index, err := ti.target(index)
+	if err != nil {
+		return StatusInternalError, "", err
+	}
+	return ti.Elasticsearch.OpenPIT(index, keepAlive)
+}
+
+func (ti *TenantIndex) Autocomplete(index, field, prefix string, size int) (StatusCode, []*Completion, error) {
+	index, err := ti.target(index)
+	if err != nil {
+		return StatusInternalError, []*Completion{}, err
+	}
+	return ti.Elasticsearch.Autocomplete(index, field, prefix, size)
+}
+
+func (ti *TenantIndex) Suggest(index, field, text string) (StatusCode, *Suggestions, error) {
+	index, err := ti.target(index)
+	if err != nil {
+		return StatusInternalError, &Suggestions{}, err
+	}
+	return ti.Elasticsearch.Suggest(index, field, text)
+}
+
+func (ti *TenantIndex) MoreLikeThis(index, id string, fields []string, data interface{}, opts ...MoreLikeThisOption) (StatusCode, []*HitData, int, error) {
+	index, err := ti.target(index)
+	if err != nil {
+		return StatusInternalError, []*HitData{}, 0, err
+	}
+	return ti.Elasticsearch.MoreLikeThis(index, id, fields, data, opts...)
+}
+
+func (ti *TenantIndex) Explain(index, id, query string) (StatusCode, bool, *Explanation, error) {
+	index, err := ti.target(index)
+	if err != nil {
+		return StatusInternalError, false, nil, err
+	}
+	return ti.Elasticsearch.Explain(index, id, query)
+}
+
+func (ti *TenantIndex) ValidateQuery(index, query string) (StatusCode, *QueryValidation, error) {
+	index, err := ti.target(index)
+	if err != nil {
+		return StatusInternalError, &QueryValidation{}, err
+	}
+	return ti.Elasticsearch.ValidateQuery(index, query)
+}
+
+func (ti *TenantIndex) TermVectors(index, id string, fields []string) (StatusCode, map[string]*TermVector, error) {
+	index, err := ti.target(index)
+	if err != nil {
+		return StatusInternalError, map[string]*TermVector{}, err
+	}
+	return ti.Elasticsearch.TermVectors(index, id, fields)
+}
+
+func (ti *TenantIndex) MTermVectors(index string, ids []string, fields []string) (StatusCode, map[string]map[string]*TermVector, error) {
+	index, err := ti.target(index)
+	if err != nil {
+		return StatusInternalError, map[string]map[string]*TermVector{}, err
+	}
+	return ti.Elasticsearch.MTermVectors(index, ids, fields)
+}
+
+func (ti *TenantIndex) TermsEnum(index, field, prefix string, size int) (StatusCode, []string, bool, error) {
+	index, err := ti.target(index)
+	if err != nil {
+		return StatusInternalError, []string{}, false, err
+	}
+	return ti.Elasticsearch.TermsEnum(index, field, prefix, size)
+}
+
+func (ti *TenantIndex) SearchWithTemplate(index, templateID string, params map[string]any, data interface{}) (StatusCode, []*HitData, int, error) {
+	index, err := ti.target(index)
+	if err != nil {
+		return StatusInternalError, []*HitData{}, 0, err
+	}
+	return ti.Elasticsearch.SearchWithTemplate(index, templateID, params, data)
+}
+
+func (ti *TenantIndex) ScanCompositeAggregation(index, query string, agg *CompositeAggregation, fn func(bucket *CompositeBucket) error) (StatusCode, error) {
+	index, err := ti.target(index)
+	if err != nil {
+		return StatusInternalError, err
+	}
+	return ti.Elasticsearch.ScanCompositeAggregation(index, query, agg, fn)
+}
@@ -0,0 +1,209 @@
+package elasticsearch
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// tenantFake records the indices and aliases reaching the Fake behind a TenantIndex.
+type tenantFake struct {
+	*Fake
+	indices []string
+	aliases map[string]*Alias
+}
+
+func (f *tenantFake) WithContext(ctx context.Context) Elasticsearch {
+	return f
+}
+
+func (f *tenantFake) PutAlias(index, alias string, options *Alias) (StatusCode, error) {
+	f.aliases[alias] = options
+	return StatusSuccess, nil
+}
+
+func (f *tenantFake) DeleteAlias(index, alias string) (StatusCode, error) {
+	delete(f.aliases, alias)
+	return StatusSuccess, nil
+}
+
+func (f *tenantFake) Search(index string, query string, data interface{}, opts ...SearchOption) (StatusCode, []*HitData, int, error) {
+	f.indices = append(f.indices, index)
+	return f.Fake.Search(index, query, data, opts...)
+}
+
+func (f *tenantFake) Count(index string, query string, opts ...SearchOption) (StatusCode, int, error) {
+	f.indices = append(f.indices, index)
+	return f.Fake.Count(index, query, opts...)
+}
+
+func (f *tenantFake) CreateDocument(doc *Document) (StatusCode, error) {
+	f.indices = append(f.indices, doc.Index)
+	return f.Fake.CreateDocument(doc)
+}
+
+func (f *tenantFake) Bulk(items []*BulkItem, refresh RefreshPolicy) (StatusCode, []*BulkItemResult, error) {
+	for _, item := range items {
+		f.indices = append(f.indices, item.Index)
+	}
+	return f.Fake.Bulk(items, refresh)
+}
+
+func TestTenantIndex(t *testing.T) {
+	setup := func() (*tenantFake, *TenantIndex) {
+		fake := &tenantFake{Fake: NewFake(), aliases: map[string]*Alias{}}
+		return fake, NewTenantIndex(fake, "articles", "tenant_id")
+	}
+
+	t.Run("AddTenant", func(t *testing.T) {
+		fake, ti := setup()
+
+		status, err := ti.AddTenant("acme")
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+		assert.Equal(t, &Alias{Filter: TermQuery("tenant_id", "acme"), Routing: "acme"}, fake.aliases["articles-acme"])
+
+		ti.RemoveTenant("acme")
+		assert.Empty(t, fake.aliases)
+	})
+
+	t.Run("With tenant", func(t *testing.T) {
+		fake, ti := setup()
+		es := ti.WithContext(WithTenant(context.Background(), "acme"))
+
+		es.Search("articles", "", nil)
+		es.CreateDocument(&Document{Index: "articles", ID: "1", Body: map[string]string{"tenant_id": "acme"}})
+		items := []*BulkItem{{Action: BulkIndex, Index: "articles", ID: "2", Body: map[string]string{}}}
+		es.Bulk(items, RefreshFalse)
+
+		assert.Equal(t, []string{"articles-acme", "articles-acme", "articles-acme"}, fake.indices)
+		assert.Equal(t, "articles", items[0].Index)
+	})
+
+	t.Run("Without tenant", func(t *testing.T) {
+		fake, ti := setup()
+		es := ti.WithContext(context.Background())
+
+		status, _, _, err := es.Search("articles", "", nil)
+		assert.ErrorIs(t, err, ErrNoTenant)
+		assert.Equal(t, StatusInternalError, status)
+
+		_, err = es.CreateDocument(&Document{Index: "articles", ID: "1"})
+		assert.ErrorIs(t, err, ErrNoTenant)
+		assert.Empty(t, fake.indices)
+	})
+
+	t.Run("Other indices", func(t *testing.T) {
+		fake, ti := setup()
+
+		_, _, _, err := ti.Search("users", "", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"users"}, fake.indices)
+	})
+
+	t.Run("Lists", func(t *testing.T) {
+		fake, ti := setup()
+		es := ti.WithContext(WithTenant(context.Background(), "acme"))
+
+		_, _, _, err := es.Search("users,articles", "", nil)
+		assert.NoError(t, err)
+		_, _, err = es.Count("articles,users,articles", "")
+		assert.NoError(t, err)
+
+		assert.Equal(t, []string{"users,articles-acme", "articles-acme,users,articles-acme"}, fake.indices)
+	})
+
+	t.Run("Patterns", func(t *testing.T) {
+		for _, index := range []string{"", "_all", "*", "art*", "users,*cles", "users,"} {
+			fake, ti := setup()
+			es := ti.WithContext(WithTenant(context.Background(), "acme"))
+
+			status, _, _, err := es.Search(index, "", nil)
+			assert.ErrorIs(t, err, ErrTenantPattern, index)
+			assert.Equal(t, StatusInternalError, status)
+			_, _, err = es.Count(index, "")
+			assert.ErrorIs(t, err, ErrTenantPattern, index)
+			assert.Empty(t, fake.indices)
+		}
+
+		fake, ti := setup()
+		es := ti.WithContext(WithTenant(context.Background(), "acme"))
+		_, _, _, err := es.Search("user*", "", nil)
+		assert.NoError(t, err)
+		_, _, _, err = es.Search("*,-articles", "", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"user*", "*,-articles"}, fake.indices)
+	})
+
+	t.Run("Invalid tenant", func(t *testing.T) {
+		for _, tenant := range []string{"a,b", "a*", "a b", "a\tb", "Acme", "a/b", "a#b"} {
+			fake, ti := setup()
+			es := ti.WithContext(WithTenant(context.Background(), tenant))
+
+			_, _, _, err := es.Search("articles", "", nil)
+			assert.ErrorIs(t, err, ErrInvalidTenant, tenant)
+			_, err = es.CreateDocument(&Document{Index: "articles", ID: "1"})
+			assert.ErrorIs(t, err, ErrInvalidTenant, tenant)
+			assert.Empty(t, fake.indices)
+
+			status, err := ti.AddTenant(tenant)
+			assert.ErrorIs(t, err, ErrInvalidTenant, tenant)
+			assert.Equal(t, StatusInternalError, status)
+			assert.Empty(t, fake.aliases)
+		}
+	})
+}
+
+// TestTenantIndexCoverage calls every method of DocumentReader and DocumentWriter with the tenant index
+// and no tenant: a method not overridden by TenantIndex reaches the nil client and panics.
+func TestTenantIndexCoverage(t *testing.T) {
+	ti := &TenantIndex{index: "articles", field: "tenant_id"}
+	// The methods without an index.
+	skipped := map[string]bool{"ClosePIT": true}
+
+	for _, iface := range []reflect.Type{
+		reflect.TypeOf((*DocumentReader)(nil)).Elem(),
+		reflect.TypeOf((*DocumentWriter)(nil)).Elem(),
+	} {
+		for i := 0; i < iface.NumMethod(); i++ {
+			name := iface.Method(i).Name
+			if skipped[name] {
+				continue
+			}
+			t.Run(name, func(t *testing.T) {
+				method := reflect.ValueOf(ti).MethodByName(name)
+				typ := method.Type()
+				args := []reflect.Value{}
+				indexSet := false
+				for j := 0; j < typ.NumIn(); j++ {
+					in := typ.In(j)
+					if typ.IsVariadic() && j == typ.NumIn()-1 {
+						break
+					}
+					switch {
+					case in.Kind() == reflect.String && !indexSet:
+						args = append(args, reflect.ValueOf("articles").Convert(in))
+						indexSet = true
+					case in == reflect.TypeOf((*context.Context)(nil)).Elem():
+						args = append(args, reflect.ValueOf(context.Background()))
+					case in == reflect.TypeOf(&Document{}):
+						args = append(args, reflect.ValueOf(&Document{Index: "articles", ID: "1"}))
+					case in == reflect.TypeOf([]*BulkItem{}):
+						args = append(args, reflect.ValueOf([]*BulkItem{{Index: "articles", ID: "1"}}))
+					default:
+						args = append(args, reflect.Zero(in))
+					}
+				}
+
+				var out []reflect.Value
+				assert.NotPanics(t, func() { out = method.Call(args) }, "%s is not rewritten to the tenant alias", name)
+				if len(out) > 0 {
+					err, _ := out[len(out)-1].Interface().(error)
+					assert.ErrorIs(t, err, ErrNoTenant)
+				}
+			})
+		}
+	}
+}
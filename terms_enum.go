@@ -0,0 +1,46 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+)
+
+// TermsEnum returns up to size indexed terms of field starting with prefix, in sorted order.
+// complete is false when the lookup timed out or was truncated, so more terms may exist.
+// A zero size uses the server default (10). The terms enum API requires Elasticsearch 7.14 or later.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/search-terms-enum.html
+func (es *_elasticsearch) TermsEnum(index, field, prefix string, size int) (StatusCode, []string, bool, error) {
+	params := map[string]interface{}{
+		"field":  field,
+		"string": prefix,
+	}
+	if size > 0 {
+		params["size"] = size
+	}
+	body, err := json.Marshal(params)
+	if err != nil {
+		return StatusInternalError, []string{}, false, err
+	}
+
+	req := esapi.TermsEnumRequest{
+		Index: []string{es.indexName(index)},
+		Body:  bytes.NewReader(body),
+	}
+
+	res, err := req.Do(es.ctx, es.client)
+
+	var r struct {
+		Terms    []string `json:"terms"`
+		Complete bool     `json:"complete"`
+	}
+	if status, err := es.handleResponse("terms enum field="+field, res, err, &r); err != nil {
+		return status, []string{}, false, err
+	}
+	if r.Terms == nil {
+		r.Terms = []string{}
+	}
+
+	return StatusSuccess, r.Terms, r.Complete, nil
+}
@@ -0,0 +1,36 @@
+package elasticsearch
+
+import (
+	"testing"
+
+	"github.com/bxcodec/faker/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTermsEnum(t *testing.T) {
+	es := newElasticsearch()
+
+	prefix := faker.UUIDDigit()
+	for _, suffix := range []string{"a", "b", "c"} {
+		var data DocBody
+		faker.FakeData(&data)
+		data.Id = faker.UUIDDigit()
+		data.S = prefix + suffix
+		es.CreateDocument(&Document{
+			Index: indexName,
+			ID:    data.Id,
+			Body:  data,
+		})
+	}
+	es.Refresh(indexName)
+
+	status, terms, complete, err := es.TermsEnum(indexName, "s.keyword", prefix, 2)
+	if status == StatusBadRequestError || status == StatusNotFoundError {
+		t.Skip("terms enum API requires Elasticsearch 7.14 or later")
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSuccess, status)
+	assert.Equal(t, []string{prefix + "a", prefix + "b"}, terms)
+	assert.True(t, complete)
+}
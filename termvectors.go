@@ -0,0 +1,97 @@
+package elasticsearch
+
+import (
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+)
+
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/docs-termvectors.html
+type TermVector struct {
+	FieldStatistics *FieldStatistics           `json:"field_statistics"`
+	Terms           map[string]*TermStatistics `json:"terms"`
+}
+
+type FieldStatistics struct {
+	SumDocFreq int64 `json:"sum_doc_freq"`
+	DocCount   int64 `json:"doc_count"`
+	SumTTF     int64 `json:"sum_ttf"`
+}
+
+type TermStatistics struct {
+	// TermFreq is the frequency of the term in the document.
+	TermFreq int `json:"term_freq"`
+	// DocFreq is the number of documents of the shard containing the term.
+	DocFreq int `json:"doc_freq"`
+	// TTF is the total frequency of the term in the shard.
+	TTF    int          `json:"ttf"`
+	Tokens []*TermToken `json:"tokens"`
+}
+
+type TermToken struct {
+	Position    int `json:"position"`
+	StartOffset int `json:"start_offset"`
+	EndOffset   int `json:"end_offset"`
+}
+
+type termVectorsResponse struct {
+	Id          string                 `json:"_id"`
+	Found       bool                   `json:"found"`
+	TermVectors map[string]*TermVector `json:"term_vectors"`
+}
+
+// TermVectors returns the term vectors with term and field statistics of the document id, keyed by field.
+// Empty fields returns every field that has term vectors stored or can be analyzed.
+func (es *_elasticsearch) TermVectors(index, id string, fields []string) (StatusCode, map[string]*TermVector, error) {
+	req := esapi.TermvectorsRequest{
+		Index:           es.indexName(index),
+		DocumentID:      id,
+		Fields:          fields,
+		TermStatistics:  esapi.BoolPtr(true),
+		FieldStatistics: esapi.BoolPtr(true),
+	}
+
+	res, err := req.Do(es.ctx, es.client)
+
+	var r termVectorsResponse
+	if status, err := es.handleResponse("term vectors doc ID="+id, res, err, &r); err != nil {
+		return status, map[string]*TermVector{}, err
+	}
+
+	if !r.Found {
+		return StatusNotFoundError, map[string]*TermVector{}, ErrNotFound
+	}
+	if r.TermVectors == nil {
+		r.TermVectors = map[string]*TermVector{}
+	}
+
+	return StatusSuccess, r.TermVectors, nil
+}
+
+// MTermVectors returns the term vectors of several documents keyed by document ID and then by field.
+// Documents which do not exist are omitted.
+func (es *_elasticsearch) MTermVectors(index string, ids []string, fields []string) (StatusCode, map[string]map[string]*TermVector, error) {
+	req := esapi.MtermvectorsRequest{
+		Index:           es.indexName(index),
+		Ids:             ids,
+		Fields:          fields,
+		TermStatistics:  esapi.BoolPtr(true),
+		FieldStatistics: esapi.BoolPtr(true),
+	}
+
+	res, err := req.Do(es.ctx, es.client)
+
+	var r struct {
+		Docs []*termVectorsResponse `json:"docs"`
+	}
+	if status, err := es.handleResponse("multi term vectors", res, err, &r); err != nil {
+		return status, map[string]map[string]*TermVector{}, err
+	}
+
+	vectors := make(map[string]map[string]*TermVector, len(r.Docs))
+	for _, doc := range r.Docs {
+		if doc.Found {
+			vectors[doc.Id] = doc.TermVectors
+		}
+	}
+
+	return StatusSuccess, vectors, nil
+}
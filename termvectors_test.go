@@ -0,0 +1,59 @@
+package elasticsearch
+
+import (
+	"testing"
+
+	"github.com/bxcodec/faker/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTermVectors(t *testing.T) {
+	es := newElasticsearch()
+
+	ids := make([]string, 2)
+	for i := range ids {
+		var data DocBody
+		faker.FakeData(&data)
+		data.Id = faker.UUIDDigit()
+		data.S = "quick brown fox quick"
+		es.CreateDocument(&Document{
+			Index: indexName,
+			ID:    data.Id,
+			Body:  data,
+		})
+		ids[i] = data.Id
+	}
+	es.Refresh(indexName)
+
+	t.Run("Found", func(t *testing.T) {
+		status, vectors, err := es.TermVectors(indexName, ids[0], []string{"s"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+		assert.Equal(t, 2, vectors["s"].Terms["quick"].TermFreq)
+		assert.Equal(t, 1, vectors["s"].Terms["fox"].TermFreq)
+		assert.GreaterOrEqual(t, vectors["s"].Terms["fox"].DocFreq, 1)
+		assert.Greater(t, vectors["s"].FieldStatistics.DocCount, int64(0))
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		status, vectors, err := es.TermVectors(indexName, faker.UUIDDigit(), []string{"s"})
+
+		assert.Error(t, err)
+		assert.Equal(t, StatusNotFoundError, status)
+		assert.Empty(t, vectors)
+	})
+
+	t.Run("Multi", func(t *testing.T) {
+		missing := faker.UUIDDigit()
+		status, vectors, err := es.MTermVectors(indexName, append(ids, missing), []string{"s"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+		assert.Len(t, vectors, 2)
+		for _, id := range ids {
+			assert.Equal(t, 2, vectors[id]["s"].Terms["quick"].TermFreq)
+		}
+		assert.NotContains(t, vectors, missing)
+	})
+}
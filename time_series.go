@@ -0,0 +1,138 @@
+package elasticsearch
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TimeSeriesInterval is the period covered by each index of a TimeSeriesWriter.
+type TimeSeriesInterval int
+
+const (
+	Daily TimeSeriesInterval = iota
+	// Weekly indices are named after the Monday starting their week.
+	Weekly
+	// Monthly indices are named after the first day of their month.
+	Monthly
+)
+
+// TimeSeriesWriter writes documents into the index of their timestamp, e.g. logs-2024.06.01,
+// for daily, weekly or monthly indices.
+//
+//	w, _ := elasticsearch.NewTimeSeriesWriter(es, "logs-{2006.01.02}", elasticsearch.Daily)
+//	w.Write(entry.Time, &elasticsearch.Document{Body: entry})
+//	es.Search(w.SearchIndex(), query, &entries)
+type TimeSeriesWriter struct {
+	// IndexBody is the settings and mappings each index is created with before its first write.
+	// When empty, indices are created by Elasticsearch on write, e.g. from an index template.
+	IndexBody string
+	// Location is the time zone of the index names. Default: UTC.
+	Location *time.Location
+
+	es       Elasticsearch
+	prefix   string
+	layout   string
+	suffix   string
+	interval TimeSeriesInterval
+
+	mu      sync.Mutex
+	created map[string]bool
+}
+
+// NewTimeSeriesWriter returns a writer of the indices named after pattern, whose part in braces
+// is the time layout of the index timestamp, e.g. "logs-{2006.01.02}" or "metrics-{2006.01}".
+func NewTimeSeriesWriter(es Elasticsearch, pattern string, interval TimeSeriesInterval) (*TimeSeriesWriter, error) {
+	start, end := strings.Index(pattern, "{"), strings.LastIndex(pattern, "}")
+	if start < 0 || end < start+2 {
+		return nil, fmt.Errorf("invalid time series pattern %q: the time layout must be in braces", pattern)
+	}
+	if interval < Daily || interval > Monthly {
+		return nil, fmt.Errorf("invalid time series interval: %d", interval)
+	}
+
+	return &TimeSeriesWriter{
+		es:       es,
+		prefix:   pattern[:start],
+		layout:   pattern[start+1 : end],
+		suffix:   pattern[end+1:],
+		interval: interval,
+		created:  map[string]bool{},
+	}, nil
+}
+
+// IndexName returns the name of the index holding the documents of t.
+func (w *TimeSeriesWriter) IndexName(t time.Time) string {
+	loc := w.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	t = t.In(loc)
+	y, m, d := t.Date()
+	switch w.interval {
+	case Weekly:
+		d -= (int(t.Weekday()) + 6) % 7
+	case Monthly:
+		d = 1
+	}
+	start := time.Date(y, m, d, 0, 0, 0, 0, loc)
+
+	return w.prefix + start.Format(w.layout) + w.suffix
+}
+
+// SearchIndex returns the wildcard matching every index of the writer, to search across them.
+func (w *TimeSeriesWriter) SearchIndex() string {
+	return w.prefix + "*" + w.suffix
+}
+
+// Write indexes doc into the index of t, creating it with IndexBody first.
+// doc.Index is overwritten.
+func (w *TimeSeriesWriter) Write(t time.Time, doc *Document) (StatusCode, error) {
+	doc.Index = w.IndexName(t)
+	if status, err := w.createIndex(doc.Index); err != nil {
+		return status, err
+	}
+	return w.es.CreateDocument(doc)
+}
+
+// Bulk writes each item into the index of the timestamp of the same position, like Write.
+func (w *TimeSeriesWriter) Bulk(times []time.Time, items []*BulkItem, refresh RefreshPolicy) (StatusCode, []*BulkItemResult, error) {
+	if len(times) != len(items) {
+		return StatusInternalError, []*BulkItemResult{}, errors.New("the numbers of times and items differ")
+	}
+
+	for i, item := range items {
+		item.Index = w.IndexName(times[i])
+		if status, err := w.createIndex(item.Index); err != nil {
+			return status, []*BulkItemResult{}, err
+		}
+	}
+	return w.es.Bulk(items, refresh)
+}
+
+func (w *TimeSeriesWriter) createIndex(index string) (StatusCode, error) {
+	if w.IndexBody == "" {
+		return StatusSuccess, nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.created[index] {
+		return StatusSuccess, nil
+	}
+
+	status, err := w.es.CreateIndex(index, w.IndexBody)
+	var esErr *ESError
+	if errors.As(err, &esErr) && esErr.Type == "resource_already_exists_exception" {
+		status, err = StatusSuccess, nil
+	}
+	if err != nil {
+		return status, err
+	}
+
+	w.created[index] = true
+	return StatusSuccess, nil
+}
@@ -0,0 +1,65 @@
+package elasticsearch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeSeriesIndexName(t *testing.T) {
+	_, err := NewTimeSeriesWriter(NewFake(), "logs-2006.01.02", Daily)
+	assert.Error(t, err)
+
+	ts := time.Date(2024, 6, 1, 23, 0, 0, 0, time.UTC) // Saturday
+
+	t.Run("Daily", func(t *testing.T) {
+		w, err := NewTimeSeriesWriter(NewFake(), "logs-{2006.01.02}", Daily)
+		assert.NoError(t, err)
+		assert.Equal(t, "logs-2024.06.01", w.IndexName(ts))
+		assert.Equal(t, "logs-*", w.SearchIndex())
+
+		w.Location = time.FixedZone("JST", 9*60*60)
+		assert.Equal(t, "logs-2024.06.02", w.IndexName(ts))
+	})
+
+	t.Run("Weekly", func(t *testing.T) {
+		w, _ := NewTimeSeriesWriter(NewFake(), "logs-{2006.01.02}-v1", Weekly)
+		assert.Equal(t, "logs-2024.05.27-v1", w.IndexName(ts))
+		assert.Equal(t, "logs-2024.06.03-v1", w.IndexName(ts.AddDate(0, 0, 2)))
+		assert.Equal(t, "logs-*-v1", w.SearchIndex())
+	})
+
+	t.Run("Monthly", func(t *testing.T) {
+		w, _ := NewTimeSeriesWriter(NewFake(), "metrics-{2006.01}", Monthly)
+		assert.Equal(t, "metrics-2024.06", w.IndexName(ts))
+	})
+}
+
+func TestTimeSeriesWriter(t *testing.T) {
+	es := NewFake()
+	w, _ := NewTimeSeriesWriter(es, "logs-{2006.01.02}", Daily)
+	w.IndexBody = `{"settings": {"number_of_shards": 1}}`
+
+	ts := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	es.CreateIndex("logs-2024.06.02", "")
+
+	status, err := w.Write(ts, &Document{ID: "1", Body: DocBody{Id: "1"}})
+	assert.NoError(t, err)
+	assert.Equal(t, StatusCreated, status)
+
+	_, results, err := w.Bulk(
+		[]time.Time{ts, ts.AddDate(0, 0, 1)},
+		[]*BulkItem{{ID: "2", Body: DocBody{Id: "2"}}, {ID: "3", Body: DocBody{Id: "3"}}},
+		"",
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, "logs-2024.06.01", results[0].Index)
+	assert.Equal(t, "logs-2024.06.02", results[1].Index)
+
+	_, count, _ := es.Count(w.SearchIndex(), SearchBody(MatchAllQuery()))
+	assert.Equal(t, 3, count)
+
+	_, _, err = w.Bulk([]time.Time{ts}, []*BulkItem{}, "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,66 @@
+package elasticsearch
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// tlsConfig returns the TLS configuration of the transport, or nil when no TLS option is set.
+func (config *Config) tlsConfig() (*tls.Config, error) {
+	if config.CACert == nil && config.ClientCert == nil && config.ClientKey == nil &&
+		config.CertificateFingerprint == "" && !config.InsecureSkipVerify {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: config.InsecureSkipVerify,
+	}
+
+	if config.CACert != nil {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(config.CACert) {
+			return nil, errors.New("unable to add CA certificate")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if config.ClientCert != nil || config.ClientKey != nil {
+		cert, err := tls.X509KeyPair(config.ClientCert, config.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if config.CertificateFingerprint != "" {
+		fingerprint := normalizeFingerprint(config.CertificateFingerprint)
+		if _, err := hex.DecodeString(fingerprint); err != nil || len(fingerprint) != sha256.Size*2 {
+			return nil, fmt.Errorf("invalid CertificateFingerprint %q: must be a hex SHA-256 fingerprint", config.CertificateFingerprint)
+		}
+
+		// The fingerprint pins the CA (or the server certificate) instead of verifying the chain,
+		// as the certificates generated by Elasticsearch 8 are self-signed.
+		tlsConfig.InsecureSkipVerify = true
+		tlsConfig.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			for _, raw := range rawCerts {
+				sum := sha256.Sum256(raw)
+				if hex.EncodeToString(sum[:]) == fingerprint {
+					return nil
+				}
+			}
+			return errors.New("no certificate of the server matches CertificateFingerprint")
+		}
+	}
+
+	return tlsConfig, nil
+}
+
+// normalizeFingerprint accepts both "AB:CD:..." and "abcd..." forms.
+func normalizeFingerprint(fingerprint string) string {
+	return strings.ToLower(strings.ReplaceAll(fingerprint, ":", ""))
+}
@@ -0,0 +1,82 @@
+package elasticsearch
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTLSTestServer(t *testing.T) *httptest.Server {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+		w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestTLS(t *testing.T) {
+	server := newTLSTestServer(t)
+	caCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	sum := sha256.Sum256(server.Certificate().Raw)
+	fingerprint := hex.EncodeToString(sum[:])
+
+	ping := func(config *Config) error {
+		config.Address = []string{server.URL}
+		es, err := New(config)
+		if err != nil {
+			return err
+		}
+		return es.Ping()
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		t.Run("CACert", func(t *testing.T) {
+			assert.NoError(t, ping(&Config{CACert: caCert}))
+		})
+
+		t.Run("CertificateFingerprint", func(t *testing.T) {
+			assert.NoError(t, ping(&Config{CertificateFingerprint: fingerprint}))
+		})
+
+		t.Run("CertificateFingerprint with colons", func(t *testing.T) {
+			var pairs []string
+			for i := 0; i < len(fingerprint); i += 2 {
+				pairs = append(pairs, strings.ToUpper(fingerprint[i:i+2]))
+			}
+			assert.NoError(t, ping(&Config{CertificateFingerprint: strings.Join(pairs, ":")}))
+		})
+
+		t.Run("InsecureSkipVerify", func(t *testing.T) {
+			assert.NoError(t, ping(&Config{InsecureSkipVerify: true}))
+		})
+	})
+
+	t.Run("Failure", func(t *testing.T) {
+		t.Run("Unknown authority", func(t *testing.T) {
+			assert.Error(t, ping(&Config{}))
+		})
+
+		t.Run("Fingerprint mismatch", func(t *testing.T) {
+			assert.Error(t, ping(&Config{CertificateFingerprint: strings.Repeat("0", 64)}))
+		})
+
+		t.Run("Invalid fingerprint", func(t *testing.T) {
+			assert.Error(t, ping(&Config{CertificateFingerprint: "xyz"}))
+		})
+
+		t.Run("Invalid CACert", func(t *testing.T) {
+			assert.Error(t, ping(&Config{CACert: []byte("not a certificate")}))
+		})
+
+		t.Run("ClientCert without ClientKey", func(t *testing.T) {
+			assert.Error(t, ping(&Config{ClientCert: caCert}))
+		})
+	})
+}
@@ -0,0 +1,212 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+)
+
+// apiClient binds the esapi functions to the go-elasticsearch client wrapped with middlewares,
+// so every request of the package goes through them.
+// With skipProductCheck, the requests are sent to the transport of the client directly, bypassing the
+// check of go-elasticsearch that the server is Elasticsearch, which rejects OpenSearch.
+type apiClient struct {
+	*esapi.API
+	es        driver
+	driver    Driver
+	transport esapi.Transport
+	// inFlight is the number of requests waiting for their response, for Stats and Close.
+	inFlight  int64
+	lifecycle *lifecycle
+	version   *versionCache
+}
+
+func newAPIClient(es driver, skipProductCheck bool, middlewares ...middleware) *apiClient {
+	var transport esapi.Transport = es
+	if skipProductCheck {
+		transport = es.transport()
+	}
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		transport = middlewares[i](transport)
+	}
+
+	c := &apiClient{es: es, transport: transport, lifecycle: newLifecycle(), version: &versionCache{}}
+	c.API = esapi.New(c)
+	return c
+}
+
+func (c *apiClient) Perform(req *http.Request) (*http.Response, error) {
+	atomic.AddInt64(&c.inFlight, 1)
+	defer atomic.AddInt64(&c.inFlight, -1)
+	if atomic.LoadInt32(&c.lifecycle.closed) == 1 {
+		return nil, ErrClientClosed
+	}
+	return c.transport.Perform(req)
+}
+
+type transportFunc func(req *http.Request) (*http.Response, error)
+
+func (f transportFunc) Perform(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// middleware wraps the transport performing a request, once per logical request
+// (the go-elasticsearch transport below still selects a node per attempt).
+type middleware func(next esapi.Transport) esapi.Transport
+
+const (
+	compatibleMediaType       = "application/vnd.elasticsearch+json;compatible-with=7"
+	compatibleNDJSONMediaType = "application/vnd.elasticsearch+x-ndjson;compatible-with=7"
+)
+
+// compatibilityMiddleware sends the requests with the media types of Elasticsearch 7,
+// except those already asking for a version, such as the requests of the typed API.
+func compatibilityMiddleware(next esapi.Transport) esapi.Transport {
+	return transportFunc(func(req *http.Request) (*http.Response, error) {
+		if strings.Contains(req.Header.Get("Accept"), "compatible-with=") {
+			return next.Perform(req)
+		}
+		if req.Body != nil && req.Body != http.NoBody {
+			if ndjsonRequest(req) {
+				req.Header.Set("Content-Type", compatibleNDJSONMediaType)
+			} else {
+				req.Header.Set("Content-Type", compatibleMediaType)
+			}
+		}
+		req.Header.Set("Accept", compatibleMediaType)
+		return next.Perform(req)
+	})
+}
+
+// ndjsonRequest reports whether the body of req is newline-delimited JSON. The 7.x client sends
+// the bodies of _bulk and _msearch as application/json, which Elasticsearch 7 accepts.
+func ndjsonRequest(req *http.Request) bool {
+	path := strings.TrimSuffix(req.URL.Path, "/template")
+	return strings.HasSuffix(path, "/_bulk") || strings.HasSuffix(path, "/_msearch") ||
+		strings.Contains(req.Header.Get("Content-Type"), "ndjson")
+}
+
+// middlewares returns the middlewares enabled by config, outermost first.
+func (config *Config) middlewares() []middleware {
+	var middlewares []middleware
+	if config.Metrics != nil {
+		middlewares = append(middlewares, metricsMiddleware(config.Metrics))
+	}
+	if config.SlowThreshold > 0 {
+		middlewares = append(middlewares, slowLogMiddleware(config.logger(), config.SlowThreshold, config.SlowQueryLimit))
+	}
+	middlewares = append(middlewares, opaqueIDMiddleware(config.OpaqueIDFromContext))
+	var header http.Header
+	if config.RunAs != "" {
+		header = http.Header{runAsHeader: []string{config.RunAs}}
+	}
+	middlewares = append(middlewares, requestHeaderMiddleware(header, config.HeaderFromContext))
+	if config.CompatibilityMode || config.Driver == DriverV8 {
+		middlewares = append(middlewares, compatibilityMiddleware)
+	}
+	if config.DryRun {
+		middlewares = append(middlewares, dryRunMiddleware(config.logger()))
+	}
+	// The circuit breaker counts a retried request as a single failure.
+	if config.CircuitBreaker != nil {
+		middlewares = append(middlewares, circuitBreakerMiddleware(newCircuit(config.CircuitBreaker, config.logger(), config.Metrics)))
+	}
+	if config.Retry != nil && !config.DisableRetry {
+		middlewares = append(middlewares, retryMiddleware(config.Retry, config.Metrics))
+	}
+	// Every attempt of a retried request is throttled.
+	if config.RateLimit != nil || len(config.OperationRateLimits) > 0 {
+		var global *tokenBucket
+		if config.RateLimit != nil {
+			global = newTokenBucket(*config.RateLimit)
+		}
+		perOperation := map[Operation]*tokenBucket{}
+		for op, l := range config.OperationRateLimits {
+			perOperation[op] = newTokenBucket(l)
+		}
+		middlewares = append(middlewares, rateLimitMiddleware(global, perOperation))
+	}
+	if config.Debug {
+		middlewares = append(middlewares, debugMiddleware(config.logger(), config.DebugBodyLimit))
+	}
+	// Innermost, for the other middlewares to see the bodies uncompressed.
+	if config.CompressRequestBody {
+		middlewares = append(middlewares, compressionMiddleware(config.CompressionMinSize))
+	}
+	return middlewares
+}
+
+// transport returns the HTTP transport of the client, or nil to use http.DefaultTransport.
+// The TLS, proxy and connection options are applied to a clone of Config.Transport when it is
+// an *http.Transport, and of http.DefaultTransport when it is nil.
+func (config *Config) transport() (http.RoundTripper, error) {
+	tlsConfig, err := config.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if tlsConfig == nil && config.ProxyURL == "" && config.DialTimeout == 0 && config.KeepAlive == 0 &&
+		config.MaxIdleConns == 0 && config.MaxIdleConnsPerHost == 0 && config.IdleConnTimeout == 0 &&
+		config.ResponseHeaderTimeout == 0 && !config.DisableKeepAlives {
+		return config.Transport, nil
+	}
+
+	var transport *http.Transport
+	switch t := config.Transport.(type) {
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		transport = t.Clone()
+	default:
+		return nil, fmt.Errorf("TLS, proxy and connection options cannot be applied to a transport of type %T", t)
+	}
+
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	if config.ProxyURL != "" {
+		proxy, err := url.Parse(config.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ProxyURL %q: %w", config.ProxyURL, err)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	if config.DialTimeout != 0 || config.KeepAlive != 0 {
+		// Same defaults as http.DefaultTransport.
+		dialer := &net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}
+		if config.DialTimeout != 0 {
+			dialer.Timeout = config.DialTimeout
+		}
+		if config.KeepAlive != 0 {
+			dialer.KeepAlive = config.KeepAlive
+		}
+		transport.DialContext = dialer.DialContext
+	}
+
+	if config.MaxIdleConns != 0 {
+		transport.MaxIdleConns = config.MaxIdleConns
+	}
+	if config.MaxIdleConnsPerHost != 0 {
+		transport.MaxIdleConnsPerHost = config.MaxIdleConnsPerHost
+	}
+	if config.IdleConnTimeout != 0 {
+		transport.IdleConnTimeout = config.IdleConnTimeout
+	}
+	if config.ResponseHeaderTimeout != 0 {
+		transport.ResponseHeaderTimeout = config.ResponseHeaderTimeout
+	}
+	transport.DisableKeepAlives = transport.DisableKeepAlives || config.DisableKeepAlives
+
+	return transport, nil
+}
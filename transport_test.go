@@ -0,0 +1,112 @@
+package elasticsearch
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestTransport(t *testing.T) {
+	t.Run("Custom RoundTripper", func(t *testing.T) {
+		server, _ := newTestServer(t, nil)
+
+		var calls int32
+		es, err := New(&Config{
+			Address: []string{server.URL},
+			Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				atomic.AddInt32(&calls, 1)
+				return http.DefaultTransport.RoundTrip(req)
+			}),
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, es.Ping())
+		assert.NotZero(t, atomic.LoadInt32(&calls))
+	})
+
+	t.Run("Proxy", func(t *testing.T) {
+		proxy, requests := newTestServer(t, nil)
+
+		es, err := New(&Config{
+			Address:  []string{"http://elasticsearch.invalid:9200"},
+			ProxyURL: proxy.URL,
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, es.Ping())
+
+		reqs := requests()
+		assert.Equal(t, "elasticsearch.invalid:9200", reqs[len(reqs)-1].Host)
+	})
+
+	t.Run("Connection options", func(t *testing.T) {
+		config := &Config{
+			DialTimeout:           time.Second,
+			KeepAlive:             -1,
+			MaxIdleConns:          64,
+			MaxIdleConnsPerHost:   32,
+			IdleConnTimeout:       time.Minute,
+			ResponseHeaderTimeout: 10 * time.Second,
+		}
+		transport, err := config.transport()
+
+		assert.NoError(t, err)
+		assert.Equal(t, 64, transport.(*http.Transport).MaxIdleConns)
+		assert.Equal(t, 32, transport.(*http.Transport).MaxIdleConnsPerHost)
+		assert.Equal(t, time.Minute, transport.(*http.Transport).IdleConnTimeout)
+		assert.Equal(t, 10*time.Second, transport.(*http.Transport).ResponseHeaderTimeout)
+		assert.NotNil(t, transport.(*http.Transport).DialContext)
+	})
+
+	t.Run("Options on a custom RoundTripper", func(t *testing.T) {
+		_, err := New(&Config{
+			Transport: roundTripperFunc(http.DefaultTransport.RoundTrip),
+			ProxyURL:  "http://proxy:3128",
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("Compatibility mode", func(t *testing.T) {
+		server, requests := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			// Elasticsearch 8 does not return _type.
+			w.Write([]byte(`{"hits": {"total": {"value": 1, "relation": "eq"}, "hits": [{"_index": "x", "_id": "1", "_score": 1.0, "_source": {}}]}}`))
+		})
+
+		es, err := New(&Config{
+			Address:           []string{server.URL},
+			CompatibilityMode: true,
+		})
+		assert.NoError(t, err)
+
+		status, hits, total, err := es.Search("x", `{"query": {"match_all": {}}}`, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+		assert.Equal(t, 1, total)
+		assert.Equal(t, "1", hits[0].Id)
+		assert.Empty(t, hits[0].Type)
+
+		reqs := requests()
+		req := reqs[len(reqs)-1]
+		assert.Equal(t, "application/vnd.elasticsearch+json;compatible-with=7", req.Header.Get("Accept"))
+		assert.Equal(t, "application/vnd.elasticsearch+json;compatible-with=7", req.Header.Get("Content-Type"))
+
+		t.Run("NDJSON", func(t *testing.T) {
+			_, _, err := es.Bulk([]*BulkItem{{Index: "x", ID: "1", Body: map[string]interface{}{"a": 1}}}, RefreshFalse)
+			assert.NoError(t, err)
+
+			reqs := requests()
+			req := reqs[len(reqs)-1]
+			assert.Equal(t, "/_bulk", req.URL.Path)
+			assert.Equal(t, "application/vnd.elasticsearch+json;compatible-with=7", req.Header.Get("Accept"))
+			assert.Equal(t, "application/vnd.elasticsearch+x-ndjson;compatible-with=7", req.Header.Get("Content-Type"))
+		})
+	})
+}
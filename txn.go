@@ -0,0 +1,142 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// TxnWriter stages writes of several documents and commits them together, best effort:
+//
+//	txn := elasticsearch.NewTxnWriter(es)
+//	txn.Update("accounts", from, map[string]int{"balance": fromBalance - amount})
+//	txn.Update("accounts", to, map[string]int{"balance": toBalance + amount})
+//	status, err := txn.Commit(elasticsearch.RefreshWaitFor)
+//
+// Commit reads the current version of the documents, writes them in a single bulk request, and when some
+// writes fail restores the previous version of the documents written, or deletes those that did not exist.
+// It is not isolated: other clients may read the writes before they are restored, and the writes of other
+// clients in between are overwritten by the restoration.
+type TxnWriter struct {
+	es    Elasticsearch
+	items []*BulkItem
+}
+
+func NewTxnWriter(es Elasticsearch) *TxnWriter {
+	return &TxnWriter{es: es}
+}
+
+// Index stages the indexing of body as the document id.
+func (txn *TxnWriter) Index(index, id string, body interface{}) {
+	txn.items = append(txn.items, &BulkItem{Action: BulkIndex, Index: index, ID: id, Body: body})
+}
+
+// Update stages the update of the fields of the document id.
+func (txn *TxnWriter) Update(index, id string, fields interface{}) {
+	txn.items = append(txn.items, &BulkItem{Action: BulkUpdate, Index: index, ID: id, Body: fields})
+}
+
+// Delete stages the deletion of the document id.
+func (txn *TxnWriter) Delete(index, id string) {
+	txn.items = append(txn.items, &BulkItem{Action: BulkDelete, Index: index, ID: id})
+}
+
+// TxnError is returned by Commit when some writes failed.
+type TxnError struct {
+	// Err is the error of the writes: a *BulkError listing the failed writes, or the error of the bulk request,
+	// in which case all the writes were restored since any of them may have been applied.
+	Err error
+	// Inconsistent are the writes which could not be restored, and remain applied.
+	Inconsistent []*TxnInconsistency
+}
+
+// TxnInconsistency is a write of a TxnWriter which could not be restored.
+type TxnInconsistency struct {
+	Item *BulkItem
+	// Err is the error of the restoration.
+	Err error
+}
+
+func (e *TxnError) Error() string {
+	msg := fmt.Sprintf("elasticsearch: transaction failed: %s", e.Err)
+	if len(e.Inconsistent) > 0 {
+		first := e.Inconsistent[0]
+		msg += fmt.Sprintf("; %d writes remain inconsistent, first %s ID=%s: %s", len(e.Inconsistent), first.Item.Action, first.Item.ID, first.Err)
+	}
+	return msg
+}
+
+func (e *TxnError) Unwrap() error {
+	return e.Err
+}
+
+// Commit writes the staged writes, which are then cleared. When some of them failed, the error is a *TxnError.
+// Every staged write must have an ID.
+func (txn *TxnWriter) Commit(refresh RefreshPolicy) (StatusCode, error) {
+	items := txn.items
+	txn.items = nil
+	if len(items) == 0 {
+		return StatusSuccess, nil
+	}
+
+	// The restorations are captured before writing.
+	restorations := make([]*BulkItem, len(items))
+	for i, item := range items {
+		if item.ID == "" {
+			return StatusInternalError, fmt.Errorf("transaction write %d: Required id", i)
+		}
+		var source json.RawMessage
+		status, _, err := txn.es.GetDocument(item.Index, item.ID, &source)
+		switch {
+		case errors.Is(err, ErrNotFound):
+			restorations[i] = &BulkItem{Action: BulkDelete, Index: item.Index, ID: item.ID}
+		case err != nil:
+			return status, err
+		default:
+			// The previous source was already processed by the ingest pipelines.
+			restorations[i] = &BulkItem{Action: BulkIndex, Index: item.Index, ID: item.ID, Pipeline: NoPipeline, Body: source}
+		}
+	}
+
+	status, results, err := txn.es.Bulk(items, refresh)
+	if err == nil {
+		return status, nil
+	}
+
+	// Without the results of the items, all of them are restored.
+	var bulkErr *BulkError
+	partial := errors.As(err, &bulkErr) && len(results) == len(items)
+	applied := make([]int, 0, len(items))
+	for i := range items {
+		if !partial || results[i].Error == nil {
+			applied = append(applied, i)
+		}
+	}
+	txnErr := &TxnError{Err: err}
+	if len(applied) == 0 {
+		return status, txnErr
+	}
+
+	restore := make([]*BulkItem, len(applied))
+	for j, i := range applied {
+		restore[j] = restorations[i]
+	}
+	_, restored, restoreErr := txn.es.Bulk(restore, refresh)
+	if restoreErr == nil {
+		return status, txnErr
+	}
+
+	partial = errors.As(restoreErr, &bulkErr) && len(restored) == len(restore)
+	for j, i := range applied {
+		err := restoreErr
+		if partial {
+			// Restoring the absence of a document which the failed write did not create is not an inconsistency.
+			if restored[j].Error == nil || (restore[j].Action == BulkDelete && restored[j].Status == 404) {
+				continue
+			}
+			err = restored[j].esError()
+		}
+		txnErr.Inconsistent = append(txnErr.Inconsistent, &TxnInconsistency{Item: items[i], Err: err})
+	}
+	return status, txnErr
+}
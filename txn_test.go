@@ -0,0 +1,103 @@
+package elasticsearch
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// failingRestoreFake fails the bulk requests after the first one.
+type failingRestoreFake struct {
+	*Fake
+	bulks int
+}
+
+func (f *failingRestoreFake) Bulk(items []*BulkItem, refresh RefreshPolicy) (StatusCode, []*BulkItemResult, error) {
+	f.bulks++
+	if f.bulks > 1 {
+		return StatusRequestError, []*BulkItemResult{}, &RequestError{Err: errors.New("connection reset")}
+	}
+	return f.Fake.Bulk(items, refresh)
+}
+
+func TestTxnWriter(t *testing.T) {
+	type account struct {
+		Balance int `json:"balance"`
+	}
+	setup := func() *Fake {
+		fake := NewFake()
+		fake.CreateDocument(&Document{Index: "accounts", ID: "1", Body: account{Balance: 10}})
+		return fake
+	}
+	balance := func(fake *Fake, id string) (int, bool) {
+		var a account
+		status, _ := fake.GetSource("accounts", id, &a)
+		return a.Balance, status == 200
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		fake := setup()
+		txn := NewTxnWriter(fake)
+		txn.Update("accounts", "1", account{Balance: 5})
+		txn.Index("accounts", "2", account{Balance: 5})
+
+		status, err := txn.Commit(RefreshFalse)
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+		b, _ := balance(fake, "1")
+		assert.Equal(t, 5, b)
+		b, _ = balance(fake, "2")
+		assert.Equal(t, 5, b)
+
+		status, err = txn.Commit(RefreshFalse)
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+	})
+
+	t.Run("Restored", func(t *testing.T) {
+		fake := setup()
+		txn := NewTxnWriter(fake)
+		txn.Update("accounts", "1", account{Balance: 5})
+		txn.Index("accounts", "2", account{Balance: 5})
+		txn.Update("accounts", "missing", account{Balance: 5})
+
+		_, err := txn.Commit(RefreshFalse)
+		var txnErr *TxnError
+		assert.ErrorAs(t, err, &txnErr)
+		var bulkErr *BulkError
+		assert.ErrorAs(t, err, &bulkErr)
+		assert.Equal(t, "missing", bulkErr.Failed[0].ID)
+		assert.Empty(t, txnErr.Inconsistent)
+
+		b, _ := balance(fake, "1")
+		assert.Equal(t, 10, b)
+		_, found := balance(fake, "2")
+		assert.False(t, found)
+	})
+
+	t.Run("Inconsistent", func(t *testing.T) {
+		fake := &failingRestoreFake{Fake: setup()}
+		txn := NewTxnWriter(fake)
+		txn.Update("accounts", "1", account{Balance: 5})
+		txn.Update("accounts", "missing", account{Balance: 5})
+
+		_, err := txn.Commit(RefreshFalse)
+		var txnErr *TxnError
+		assert.ErrorAs(t, err, &txnErr)
+		assert.Len(t, txnErr.Inconsistent, 1)
+		assert.Equal(t, "1", txnErr.Inconsistent[0].Item.ID)
+		assert.EqualError(t, txnErr.Inconsistent[0].Err, "elasticsearch: request failed: connection reset")
+		b, _ := balance(fake.Fake, "1")
+		assert.Equal(t, 5, b)
+	})
+
+	t.Run("Required ID", func(t *testing.T) {
+		txn := NewTxnWriter(setup())
+		txn.Index("accounts", "", account{})
+
+		status, err := txn.Commit(RefreshFalse)
+		assert.Error(t, err)
+		assert.Equal(t, StatusInternalError, status)
+	})
+}
@@ -0,0 +1,58 @@
+package elasticsearch
+
+import (
+	"context"
+	"errors"
+)
+
+// UpdateWithRetry reads the document id of index, changes it with fn and writes it back unless it was
+// written in between, by GetDocument and CreateDocument with IfSeqNo and IfPrimaryTerm. On a conflict,
+// it reads the document and calls fn again, up to maxRetries times, then returns ErrConflict.
+//
+//	status, article, err := elasticsearch.UpdateWithRetry(ctx, es, "articles", id, func(a Article) (Article, error) {
+//		a.Views++
+//		return a, nil
+//	}, 5)
+//
+// A missing document returns ErrNotFound without calling fn. An error of fn stops the update and is
+// returned with StatusInternalError. It returns the written document.
+func UpdateWithRetry[T any](ctx context.Context, es Elasticsearch, index, id string, fn func(current T) (T, error), maxRetries int) (StatusCode, T, error) {
+	es = es.WithContext(ctx)
+
+	var zero T
+	for attempt := 0; ; attempt++ {
+		var current T
+		status, meta, err := es.GetDocument(index, id, &current)
+		if err != nil {
+			return status, zero, err
+		}
+
+		updated, err := fn(current)
+		if err != nil {
+			return StatusInternalError, zero, err
+		}
+
+		status, err = es.CreateDocument(&Document{
+			Index:         index,
+			ID:            id,
+			Body:          updated,
+			Routing:       meta.Routing,
+			IfSeqNo:       &meta.SeqNo,
+			IfPrimaryTerm: &meta.PrimaryTerm,
+		})
+		if err == nil {
+			return status, updated, nil
+		}
+		if !errors.Is(err, ErrConflict) || attempt >= maxRetries {
+			return status, zero, err
+		}
+		if err := ctx.Err(); err != nil {
+			return StatusRequestError, zero, &RequestError{Err: err}
+		}
+	}
+}
+
+// UpdateWithRetry changes the document id with fn, retrying on conflicts. See the UpdateWithRetry function.
+func (r *Repository[T]) UpdateWithRetry(ctx context.Context, id string, fn func(current T) (T, error), maxRetries int) (StatusCode, T, error) {
+	return UpdateWithRetry(ctx, r.es, r.index, id, fn, maxRetries)
+}
@@ -0,0 +1,88 @@
+package elasticsearch
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateWithRetry(t *testing.T) {
+	type counter struct {
+		ID    string `json:"id" es:"id"`
+		Count int    `json:"count"`
+	}
+	ctx := context.Background()
+	setup := func() *Fake {
+		fake := NewFake()
+		fake.CreateDocument(&Document{Index: "counters", ID: "1", Body: counter{ID: "1"}})
+		return fake
+	}
+
+	t.Run("Conflict", func(t *testing.T) {
+		fake := setup()
+		calls := 0
+		status, c, err := UpdateWithRetry(ctx, fake, "counters", "1", func(c counter) (counter, error) {
+			calls++
+			if calls == 1 {
+				// Another writer increments the counter in between.
+				fake.UpdateDocument(&Document{Index: "counters", ID: "1", Body: map[string]int{"count": 10}})
+			}
+			c.Count++
+			return c, nil
+		}, 3)
+
+		assert.NoError(t, err)
+		assert.Equal(t, StatusCreated, status)
+		assert.Equal(t, 2, calls)
+		assert.Equal(t, 11, c.Count)
+
+		var stored counter
+		fake.GetSource("counters", "1", &stored)
+		assert.Equal(t, 11, stored.Count)
+	})
+
+	t.Run("Retries exhausted", func(t *testing.T) {
+		fake := setup()
+		calls := 0
+		_, _, err := UpdateWithRetry(ctx, fake, "counters", "1", func(c counter) (counter, error) {
+			calls++
+			fake.UpdateDocument(&Document{Index: "counters", ID: "1", Body: map[string]int{"count": calls}})
+			return c, nil
+		}, 2)
+
+		assert.ErrorIs(t, err, ErrConflict)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		fake := setup()
+		refused := errors.New("refused")
+
+		status, _, err := UpdateWithRetry(ctx, fake, "counters", "1", func(c counter) (counter, error) {
+			return c, refused
+		}, 3)
+		assert.ErrorIs(t, err, refused)
+		assert.Equal(t, StatusInternalError, status)
+
+		status, _, err = UpdateWithRetry(ctx, fake, "counters", "missing", func(c counter) (counter, error) {
+			t.Fatal("fn called for a missing document")
+			return c, nil
+		}, 3)
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.Equal(t, StatusNotFoundError, status)
+	})
+
+	t.Run("Repository", func(t *testing.T) {
+		fake := setup()
+		r, _ := NewRepository[counter](fake, "counters")
+
+		_, c, err := r.UpdateWithRetry(ctx, "1", func(c counter) (counter, error) {
+			c.Count = 5
+			return c, nil
+		}, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, counter{ID: "1", Count: 5}, c)
+	})
+}
@@ -0,0 +1,73 @@
+package elasticsearch
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+)
+
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/search-validate.html
+type QueryValidation struct {
+	Valid bool
+	// Error is a readable reason of the first failure, empty when Valid.
+	Error        string
+	Explanations []*QueryExplanation
+}
+
+type QueryExplanation struct {
+	Index       string `json:"index"`
+	Valid       bool   `json:"valid"`
+	Explanation string `json:"explanation"`
+	Error       string `json:"error"`
+}
+
+// Java exception class names prefixed to the reasons, e.g.
+// "org.elasticsearch.common.ParsingException: [match] unknown token".
+var exceptionPrefix = regexp.MustCompile(`^([a-zA-Z_$][\w$]*\.)+[\w$]*(Exception|Error): `)
+
+func friendlyValidationError(reason string) string {
+	for exceptionPrefix.MatchString(reason) {
+		reason = exceptionPrefix.ReplaceAllString(reason, "")
+	}
+	return strings.TrimSpace(reason)
+}
+
+// ValidateQuery checks query against index without executing it.
+// A malformed query body is reported with StatusBadRequestError and a validation holding its reason.
+func (es *_elasticsearch) ValidateQuery(index, query string) (StatusCode, *QueryValidation, error) {
+	req := esapi.IndicesValidateQueryRequest{
+		Index:   []string{es.indexName(index)},
+		Body:    strings.NewReader(query),
+		Explain: esapi.BoolPtr(true),
+	}
+
+	res, err := req.Do(es.ctx, es.client)
+
+	var r struct {
+		Valid        bool                `json:"valid"`
+		Error        string              `json:"error"`
+		Explanations []*QueryExplanation `json:"explanations"`
+	}
+	if status, err := es.handleResponse("validate query", res, err, &r); err != nil {
+		var esErr *ESError
+		if status == StatusBadRequestError && errors.As(err, &esErr) {
+			return status, &QueryValidation{Error: friendlyValidationError(esErr.Reason)}, err
+		}
+		return status, &QueryValidation{}, err
+	}
+
+	validation := &QueryValidation{
+		Valid:        r.Valid,
+		Error:        friendlyValidationError(r.Error),
+		Explanations: r.Explanations,
+	}
+	for _, e := range r.Explanations {
+		if !e.Valid && validation.Error == "" {
+			validation.Error = friendlyValidationError(e.Error)
+		}
+	}
+
+	return StatusSuccess, validation, nil
+}
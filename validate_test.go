@@ -0,0 +1,53 @@
+package elasticsearch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFriendlyValidationError(t *testing.T) {
+	assert.Equal(t,
+		"[match] unknown token [START_ARRAY] after [s]",
+		friendlyValidationError("org.elasticsearch.common.ParsingException: [match] unknown token [START_ARRAY] after [s]"),
+	)
+	assert.Equal(t,
+		"failed to create query: For input string: \"abc\"",
+		friendlyValidationError("org.elasticsearch.index.query.QueryShardException: failed to create query: For input string: \"abc\""),
+	)
+	assert.Equal(t, "no mapping", friendlyValidationError(" no mapping "))
+}
+
+func TestValidateQuery(t *testing.T) {
+	es := newElasticsearch()
+	es.Refresh(indexName)
+
+	t.Run("Valid", func(t *testing.T) {
+		status, validation, err := es.ValidateQuery(indexName, SearchBody(TermQuery("id", "1")))
+
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+		assert.True(t, validation.Valid)
+		assert.Empty(t, validation.Error)
+		assert.NotEmpty(t, validation.Explanations)
+	})
+
+	t.Run("Invalid value", func(t *testing.T) {
+		status, validation, err := es.ValidateQuery(indexName, SearchBody(TermQuery("i", "not-a-number")))
+
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+		assert.False(t, validation.Valid)
+		assert.NotEmpty(t, validation.Error)
+		assert.NotContains(t, validation.Error, "Exception:")
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		status, validation, err := es.ValidateQuery(indexName, `{"query": {"unknown": {}}}`)
+
+		assert.Error(t, err)
+		assert.Equal(t, StatusBadRequestError, status)
+		assert.False(t, validation.Valid)
+		assert.NotEmpty(t, validation.Error)
+	})
+}
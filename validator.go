@@ -0,0 +1,91 @@
+package elasticsearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+)
+
+// ErrValidation matches with errors.Is the *ValidationError of the writes rejected by the Config.Validators.
+var ErrValidation = errors.New("elasticsearch: document validation failed")
+
+// ValidationError is returned by the writes of documents rejected by a Validator, without sending the request.
+type ValidationError struct {
+	Index string
+	ID    string
+	// Item is the position of the rejected item of a Bulk, 0 for the other writes.
+	Item int
+	Err  error
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("elasticsearch: invalid document ID=%s of index %s: %s", e.ID, e.Index, e.Err)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// Is matches ErrValidation.
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// Validator checks the documents before they are written, e.g. against a JSON Schema, so that malformed
+// documents are rejected instead of adding fields to the mapping. Validate is given the WriteEvent of
+// each document indexed or updated, whose Body only holds the updated fields of a BulkUpdate.
+type Validator interface {
+	Validate(ctx context.Context, event *WriteEvent) error
+}
+
+// ValidatorFunc is a Validator function:
+//
+//	elasticsearch.ValidatorFunc(func(ctx context.Context, e *elasticsearch.WriteEvent) error {
+//		var a Article
+//		if err := json.Unmarshal(e.Body, &a); err != nil {
+//			return err
+//		}
+//		return a.Validate()
+//	})
+type ValidatorFunc func(ctx context.Context, event *WriteEvent) error
+
+func (f ValidatorFunc) Validate(ctx context.Context, event *WriteEvent) error {
+	return f(ctx, event)
+}
+
+// validationHook is the WriteHook of Config.Validators, called before the other hooks.
+type validationHook struct {
+	patterns   []string
+	validators map[string]Validator
+}
+
+func newValidationHook(validators map[string]Validator) *validationHook {
+	h := &validationHook{validators: validators}
+	for pattern := range validators {
+		h.patterns = append(h.patterns, pattern)
+	}
+	sort.Strings(h.patterns)
+	return h
+}
+
+// BeforeWrite rejects the whole write at the first invalid document.
+func (h *validationHook) BeforeWrite(ctx context.Context, events []*WriteEvent) error {
+	for i, event := range events {
+		if event.Action == BulkDelete {
+			continue
+		}
+		for _, pattern := range h.patterns {
+			if ok, _ := path.Match(pattern, event.Index); !ok {
+				continue
+			}
+			if err := h.validators[pattern].Validate(ctx, event); err != nil {
+				return &ValidationError{Index: event.Index, ID: event.ID, Item: i, Err: err}
+			}
+		}
+	}
+	return nil
+}
+
+func (h *validationHook) AfterWrite(ctx context.Context, events []*WriteEvent) {}
@@ -0,0 +1,58 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidators(t *testing.T) {
+	server, requests := newTestServer(t, nil)
+	requireTitle := ValidatorFunc(func(ctx context.Context, e *WriteEvent) error {
+		var doc struct {
+			Title string `json:"title"`
+		}
+		if err := json.Unmarshal(e.Body, &doc); err != nil {
+			return err
+		}
+		if doc.Title == "" && e.Action != BulkUpdate {
+			return errors.New("title is required")
+		}
+		return nil
+	})
+	es, err := New(&Config{Address: []string{server.URL}}, WithValidator("articles-*", requireTitle))
+	assert.NoError(t, err)
+	sent := len(requests())
+
+	t.Run("Invalid", func(t *testing.T) {
+		status, err := es.CreateDocument(&Document{Index: "articles-1", ID: "1", Body: map[string]string{}})
+		assert.ErrorIs(t, err, ErrValidation)
+		assert.EqualError(t, err, "elasticsearch: invalid document ID=1 of index articles-1: title is required")
+		assert.Equal(t, StatusInternalError, status)
+
+		_, _, err = es.Bulk([]*BulkItem{
+			{Index: "articles-1", ID: "1", Body: map[string]string{"title": "a"}},
+			{Index: "articles-1", ID: "2", Body: map[string]string{}},
+		}, RefreshFalse)
+		var validationErr *ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, 1, validationErr.Item)
+		assert.Equal(t, "2", validationErr.ID)
+
+		assert.Len(t, requests(), sent)
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		_, err := es.CreateDocument(&Document{Index: "articles-1", ID: "1", Body: map[string]string{"title": "a"}})
+		assert.NoError(t, err)
+		_, err = es.UpdateDocument(&Document{Index: "articles-1", ID: "1", Body: map[string]string{"body": "b"}})
+		assert.NoError(t, err)
+		_, err = es.RemoveDocument(&Document{Index: "articles-1", ID: "1"})
+		assert.NoError(t, err)
+		_, err = es.CreateDocument(&Document{Index: "users", ID: "1", Body: map[string]string{}})
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,150 @@
+package elasticsearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrUnsupportedByServer is returned, without sending the request, by the methods using a feature
+// the version of the cluster does not have: point in times before 7.10 and composable index templates before 7.8.
+var ErrUnsupportedByServer = errors.New("elasticsearch: unsupported by the server version")
+
+// ServerVersion is the version of the cluster returned by Version.
+type ServerVersion struct {
+	// Number is the version number, e.g. "7.17.9".
+	Number string
+	Major  int
+	Minor  int
+	Patch  int
+	// Distribution is "opensearch" on OpenSearch, and empty on Elasticsearch.
+	Distribution string
+}
+
+// AtLeast reports whether the version is major.minor or later.
+func (v *ServerVersion) AtLeast(major, minor int) bool {
+	return v.Major > major || (v.Major == major && v.Minor >= minor)
+}
+
+func (v *ServerVersion) String() string {
+	if v.Distribution != "" {
+		return v.Distribution + " " + v.Number
+	}
+	return v.Number
+}
+
+// parseServerVersion parses the version of Info, e.g. "7.17.9" or "8.0.0-SNAPSHOT".
+func parseServerVersion(version ClusterVersion) (*ServerVersion, error) {
+	number := strings.SplitN(version.Number, "-", 2)[0]
+	parts := strings.Split(number, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid version number %q", version.Number)
+	}
+	v := &ServerVersion{Number: version.Number, Distribution: version.Distribution}
+	for i, n := range []*int{&v.Major, &v.Minor, &v.Patch} {
+		var err error
+		if *n, err = strconv.Atoi(parts[i]); err != nil {
+			return nil, fmt.Errorf("invalid version number %q", version.Number)
+		}
+	}
+	return v, nil
+}
+
+// versionRetryInterval is how long a failure of Version is returned before Info is requested again.
+const versionRetryInterval = 30 * time.Second
+
+// versionCache holds the version of the cluster, shared by the copies of a client.
+// Concurrent callers wait for a single Info request, made without holding mu.
+type versionCache struct {
+	mu      sync.Mutex
+	version *ServerVersion
+	call    *versionCall
+	// failed is the last failure, returned until retryAt.
+	failed  *versionCall
+	retryAt time.Time
+}
+
+// versionCall is a request of the version, done once for its callers.
+type versionCall struct {
+	done    chan struct{}
+	status  StatusCode
+	version *ServerVersion
+	err     error
+}
+
+// Version returns the version of the cluster. It is requested with Info once, then cached for the life
+// of the client: a rolling upgrade is only seen by the clients created after it.
+// A failure is returned for 30 seconds before Info is requested again.
+func (es *_elasticsearch) Version() (StatusCode, *ServerVersion, error) {
+	cache := es.client.version
+	cache.mu.Lock()
+	if cache.version != nil {
+		cache.mu.Unlock()
+		return StatusSuccess, cache.version, nil
+	}
+	if cache.failed != nil && time.Now().Before(cache.retryAt) {
+		failed := cache.failed
+		cache.mu.Unlock()
+		return failed.status, &ServerVersion{}, failed.err
+	}
+	if call := cache.call; call != nil {
+		cache.mu.Unlock()
+		select {
+		case <-call.done:
+			return call.status, call.version, call.err
+		case <-es.ctx.Done():
+			return StatusInternalError, &ServerVersion{}, &RequestError{Err: es.ctx.Err()}
+		}
+	}
+	call := &versionCall{done: make(chan struct{})}
+	cache.call = call
+	cache.mu.Unlock()
+
+	call.status, call.version, call.err = es.requestVersion()
+
+	cache.mu.Lock()
+	cache.call = nil
+	switch {
+	case call.err == nil:
+		cache.version = call.version
+	// The context of this caller is not a failure of the cluster.
+	case !errors.Is(call.err, context.Canceled) && !errors.Is(call.err, context.DeadlineExceeded):
+		cache.failed = call
+		cache.retryAt = time.Now().Add(versionRetryInterval)
+	}
+	cache.mu.Unlock()
+	close(call.done)
+
+	return call.status, call.version, call.err
+}
+
+// requestVersion requests the version of the cluster with Info.
+func (es *_elasticsearch) requestVersion() (StatusCode, *ServerVersion, error) {
+	status, info, err := es.Info()
+	if err != nil {
+		return status, &ServerVersion{}, err
+	}
+	v, err := parseServerVersion(info.Version)
+	if err != nil {
+		es.logger.Errorf("Error parsing the version of the cluster: %s", err)
+		return StatusParseError, &ServerVersion{}, &ParseError{Err: err}
+	}
+	return StatusSuccess, v, nil
+}
+
+// requireVersion returns an error matching ErrUnsupportedByServer when the cluster runs an Elasticsearch
+// older than major.minor. The feature is allowed when the version is unknown, e.g. when Info failed,
+// and on OpenSearch, whose version numbers differ.
+func (es *_elasticsearch) requireVersion(feature string, major, minor int) error {
+	_, v, err := es.Version()
+	if err != nil || v.Distribution != "" || v.AtLeast(major, minor) {
+		return nil
+	}
+	err = fmt.Errorf("%w: %s requires Elasticsearch %d.%d or later, the cluster runs %s", ErrUnsupportedByServer, feature, major, minor, v)
+	es.logger.Errorf("Error %s", err)
+	return err
+}
@@ -0,0 +1,151 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseServerVersion(t *testing.T) {
+	for number, expected := range map[string]*ServerVersion{
+		"7.17.9":         {Number: "7.17.9", Major: 7, Minor: 17, Patch: 9},
+		"8.0.0-SNAPSHOT": {Number: "8.0.0-SNAPSHOT", Major: 8},
+		"":               nil,
+		"7.x":            nil,
+	} {
+		v, err := parseServerVersion(ClusterVersion{Number: number})
+		if expected == nil {
+			assert.Error(t, err, number)
+			continue
+		}
+		assert.NoError(t, err, number)
+		assert.Equal(t, expected, v)
+	}
+
+	v := &ServerVersion{Major: 7, Minor: 10}
+	assert.True(t, v.AtLeast(7, 10))
+	assert.True(t, v.AtLeast(6, 20))
+	assert.False(t, v.AtLeast(7, 11))
+	assert.False(t, v.AtLeast(8, 0))
+}
+
+func TestVersion(t *testing.T) {
+	newServer := func(t *testing.T, version string) (Elasticsearch, func() []*http.Request) {
+		server, requests := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/":
+				w.Write([]byte(fmt.Sprintf(`{"cluster_name": "c", "version": %s}`, version)))
+			case "/a/_pit":
+				w.Write([]byte(`{"id": "pit-1"}`))
+			default:
+				w.Write([]byte(`{}`))
+			}
+		})
+		es, err := New(&Config{Address: []string{server.URL}, Logger: NopLogger()})
+		assert.NoError(t, err)
+		return es, requests
+	}
+	countRoot := func(reqs []*http.Request) int {
+		n := 0
+		for _, r := range reqs {
+			if r.URL.Path == "/" {
+				n++
+			}
+		}
+		return n
+	}
+
+	t.Run("Cached", func(t *testing.T) {
+		es, requests := newServer(t, `{"number": "7.17.9"}`)
+
+		status, v, err := es.Version()
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+		assert.Equal(t, &ServerVersion{Number: "7.17.9", Major: 7, Minor: 17, Patch: 9}, v)
+		before := countRoot(requests())
+
+		_, v, _ = es.WithIndexPrefix("p-").Version()
+		assert.Equal(t, 17, v.Minor)
+		assert.Equal(t, before, countRoot(requests()))
+
+		_, _, err = es.OpenPIT("a", time.Minute)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		es, requests := newServer(t, `{"number": "7.7.1"}`)
+
+		status, _, err := es.OpenPIT("a", time.Minute)
+		assert.ErrorIs(t, err, ErrUnsupportedByServer)
+		assert.Equal(t, StatusInternalError, status)
+		assert.Equal(t, "elasticsearch: unsupported by the server version: point in time requires Elasticsearch 7.10 or later, the cluster runs 7.7.1", err.Error())
+
+		_, err = es.CreateIndexTemplate("t", `{"index_patterns": ["t-*"]}`)
+		assert.ErrorIs(t, err, ErrUnsupportedByServer)
+
+		for _, r := range requests() {
+			assert.Equal(t, "/", r.URL.Path)
+		}
+	})
+
+	t.Run("OpenSearch", func(t *testing.T) {
+		es, _ := newServer(t, `{"number": "1.3.0", "distribution": "opensearch"}`)
+
+		_, v, err := es.Version()
+		assert.NoError(t, err)
+		assert.Equal(t, "opensearch 1.3.0", v.String())
+		_, _, err = es.OpenPIT("a", time.Minute)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Unknown", func(t *testing.T) {
+		es, requests := newServer(t, `{}`)
+
+		status, _, err := es.Version()
+		assert.Equal(t, StatusParseError, status)
+		assert.Error(t, err)
+		before := countRoot(requests())
+
+		_, _, err = es.OpenPIT("a", time.Minute)
+		assert.NoError(t, err)
+		status, _, err = es.Version()
+		assert.Equal(t, StatusParseError, status)
+		assert.Error(t, err)
+		assert.Equal(t, before, countRoot(requests()))
+
+		es.(*_elasticsearch).client.version.retryAt = time.Time{}
+		_, _, err = es.Version()
+		assert.Error(t, err)
+		assert.Equal(t, before+1, countRoot(requests()))
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		server, requests := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/" {
+				time.Sleep(50 * time.Millisecond)
+			}
+			w.Write([]byte(`{"cluster_name": "c", "version": {"number": "7.17.9"}}`))
+		})
+		es, err := New(&Config{Address: []string{server.URL}, Logger: NopLogger()})
+		assert.NoError(t, err)
+		assert.NoError(t, es.Ping())
+		before := countRoot(requests())
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, v, err := es.Version()
+				assert.NoError(t, err)
+				assert.Equal(t, 17, v.Minor)
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, before+1, countRoot(requests()))
+	})
+}
@@ -0,0 +1,111 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+)
+
+// PutWatch creates or replaces the watch id, e.g.
+// `{"trigger": {"schedule": {"interval": "5m"}}, "input": {...}, "condition": {...}, "actions": {...}}`.
+// The index names in body are not prefixed with the index prefix of the client.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/watcher-api-put-watch.html
+func (es *_elasticsearch) PutWatch(id, body string, active bool) (StatusCode, error) {
+	req := esapi.WatcherPutWatchRequest{
+		WatchID: id,
+		Body:    strings.NewReader(body),
+		Active:  &active,
+	}
+
+	res, err := req.Do(es.ctx, es.client)
+	return es.handleResponse("put watch ID="+id, res, err, nil)
+}
+
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/watcher-api-get-watch.html
+type Watch struct {
+	ID      string `json:"_id"`
+	Version int    `json:"_version"`
+	Status  struct {
+		State struct {
+			Active    bool   `json:"active"`
+			Timestamp string `json:"timestamp"`
+		} `json:"state"`
+		LastChecked      string `json:"last_checked"`
+		LastMetCondition string `json:"last_met_condition"`
+	} `json:"status"`
+	// Watch is the definition given to PutWatch.
+	Watch json.RawMessage `json:"watch"`
+}
+
+func (es *_elasticsearch) GetWatch(id string) (StatusCode, *Watch, error) {
+	req := esapi.WatcherGetWatchRequest{
+		WatchID: id,
+	}
+
+	res, err := req.Do(es.ctx, es.client)
+
+	watch := &Watch{}
+	if status, err := es.handleResponse("get watch ID="+id, res, err, watch); err != nil {
+		return status, &Watch{}, err
+	}
+	return StatusSuccess, watch, nil
+}
+
+func (es *_elasticsearch) DeleteWatch(id string) (StatusCode, error) {
+	req := esapi.WatcherDeleteWatchRequest{
+		WatchID: id,
+	}
+
+	res, err := req.Do(es.ctx, es.client)
+	return es.handleResponse("delete watch ID="+id, res, err, nil)
+}
+
+// WatchRecord is the result of ExecuteWatch.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/watcher-api-execute-watch.html
+type WatchRecord struct {
+	WatchID string `json:"watch_id"`
+	// State is e.g. "executed" or "execution_not_needed" when the condition is not met.
+	State  string `json:"state"`
+	Result struct {
+		Condition struct {
+			Met bool `json:"met"`
+		} `json:"condition"`
+		Actions []struct {
+			ID     string `json:"id"`
+			Type   string `json:"type"`
+			Status string `json:"status"`
+		} `json:"actions"`
+	} `json:"result"`
+}
+
+// ExecuteWatch runs the watch id now to check its definition, without recording the execution.
+// ignoreCondition runs the actions even when the condition is not met.
+func (es *_elasticsearch) ExecuteWatch(id string, ignoreCondition bool) (StatusCode, *WatchRecord, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"ignore_condition": ignoreCondition,
+		"record_execution": false,
+	})
+	if err != nil {
+		return StatusInternalError, &WatchRecord{}, err
+	}
+
+	req := esapi.WatcherExecuteWatchRequest{
+		WatchID: id,
+		Body:    bytes.NewReader(body),
+	}
+
+	res, err := req.Do(es.ctx, es.client)
+
+	var r struct {
+		WatchRecord *WatchRecord `json:"watch_record"`
+	}
+	if status, err := es.handleResponse("execute watch ID="+id, res, err, &r); err != nil {
+		return status, &WatchRecord{}, err
+	}
+	if r.WatchRecord == nil {
+		r.WatchRecord = &WatchRecord{}
+	}
+	return StatusSuccess, r.WatchRecord, nil
+}
@@ -0,0 +1,50 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatch(t *testing.T) {
+	es := newElasticsearch()
+	id := indexName + "-watch"
+
+	watch, _ := json.Marshal(map[string]interface{}{
+		"trigger": map[string]interface{}{"schedule": map[string]interface{}{"interval": "1h"}},
+		"input": map[string]interface{}{"search": map[string]interface{}{
+			"request": map[string]interface{}{"indices": []string{indexName}, "body": map[string]interface{}{"query": MatchAllQuery()}},
+		}},
+		"condition": map[string]interface{}{"compare": map[string]interface{}{"ctx.payload.hits.total": map[string]interface{}{"gt": 1000}}},
+		"actions":   map[string]interface{}{"log": map[string]interface{}{"logging": map[string]interface{}{"text": "too many documents"}}},
+	})
+
+	status, err := es.PutWatch(id, string(watch), false)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSuccess, status)
+
+	t.Run("Get", func(t *testing.T) {
+		status, w, err := es.GetWatch(id)
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+		assert.Equal(t, id, w.ID)
+		assert.False(t, w.Status.State.Active)
+	})
+
+	t.Run("Execute", func(t *testing.T) {
+		status, record, err := es.ExecuteWatch(id, true)
+		assert.NoError(t, err)
+		assert.Equal(t, StatusSuccess, status)
+		assert.Equal(t, "executed", record.State)
+		assert.Len(t, record.Result.Actions, 1)
+	})
+
+	status, err = es.DeleteWatch(id)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSuccess, status)
+
+	status, _, err = es.GetWatch(id)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, StatusNotFoundError, status)
+}
@@ -0,0 +1,378 @@
+package elasticsearch
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+var errWriteAheadLogClosed = errors.New("write-ahead log is closed")
+
+type WriteAheadLogConfig struct {
+	// Path is the file the writes are appended to while the cluster is unreachable.
+	Path string
+	// Sync flushes the file to disk after every append, so that no write is lost on a crash of the host.
+	Sync bool
+	// BatchSize is the maximum number of items of a bulk request of Replay. Default: 500.
+	BatchSize int
+	// ReplayInterval is the interval of the replays of Run. Default: 5s.
+	ReplayInterval time.Duration
+	// Refresh is the refresh policy of the writes.
+	Refresh RefreshPolicy
+
+	// OnDropped is called for the items rejected by Elasticsearch on replay, e.g. on a mapping error.
+	// They are not replayed again.
+	OnDropped func(item *BulkItem, err error)
+	// Logger receives the logs of the log. Default: all levels to the standard log package.
+	Logger Logger
+}
+
+// WriteAheadLog sends writes to Elasticsearch, or appends them to a local file while the cluster
+// is unreachable and replays them in order when it is back. Writes are buffered instead of failing
+// on request errors, such as network errors and ErrCircuitOpen, and on 429 and 5xx responses.
+//
+// While writes are buffered, the following ones are buffered too so that the order is kept.
+// Replayed items may be sent more than once, so prefer items with an ID and BulkIndex.
+type WriteAheadLog struct {
+	es     Elasticsearch
+	config WriteAheadLogConfig
+
+	mu      sync.Mutex
+	file    *os.File
+	pending int
+	closed  bool
+	// replayMu makes the replays run one at a time.
+	replayMu sync.Mutex
+	// rename is os.Rename, replaced by tests.
+	rename func(oldpath, newpath string) error
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	// deregister removes drain from the closers of the client.
+	deregister func()
+}
+
+type walEntry struct {
+	Action          BulkAction      `json:"action,omitempty"`
+	Index           string          `json:"index"`
+	ID              string          `json:"id,omitempty"`
+	Routing         string          `json:"routing,omitempty"`
+	Pipeline        string          `json:"pipeline,omitempty"`
+	RetryOnConflict int             `json:"retry_on_conflict,omitempty"`
+	Version         int64           `json:"version,omitempty"`
+	Body            json.RawMessage `json:"body,omitempty"`
+}
+
+// OpenWriteAheadLog opens the log of config.Path, creating it if needed.
+// The writes left in it by a previous process are replayed by the next Replay.
+func OpenWriteAheadLog(es Elasticsearch, config *WriteAheadLogConfig) (*WriteAheadLog, error) {
+	if config.Path == "" {
+		return nil, errors.New("WriteAheadLogConfig.Path must not be empty")
+	}
+
+	w := &WriteAheadLog{es: es, config: *config, stop: make(chan struct{}), rename: os.Rename}
+	if w.config.BatchSize <= 0 {
+		w.config.BatchSize = 500
+	}
+	if w.config.ReplayInterval <= 0 {
+		w.config.ReplayInterval = 5 * time.Second
+	}
+	if w.config.Logger == nil {
+		w.config.Logger = NewStdLogger(nil, LevelDebug)
+	}
+
+	entries, err := w.read()
+	if err != nil {
+		return nil, err
+	}
+	w.pending = len(entries)
+
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	w.deregister = onClose(es, w.drain)
+	return w, nil
+}
+
+func (w *WriteAheadLog) open() error {
+	f, err := os.OpenFile(w.config.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return err
+	}
+	w.file = f
+	return nil
+}
+
+// Write sends item, or appends it to the log when the cluster is unreachable or older writes
+// are still in the log. A buffered item returns no error.
+// The lock is not held while sending: concurrent writes have no order, and a write made after another
+// returned sees it in the log if it was buffered, since it is appended before Write returns.
+func (w *WriteAheadLog) Write(item *BulkItem) error {
+	w.mu.Lock()
+	pending := w.pending
+	w.mu.Unlock()
+
+	if pending == 0 {
+		_, results, err := w.es.Bulk([]*BulkItem{item}, w.config.Refresh)
+		var bulkErr *BulkError
+		if errors.As(err, &bulkErr) && len(results) == 1 {
+			err = results[0].esError()
+		}
+		if !unreachable(err) {
+			return err
+		}
+		w.config.Logger.Warnf("Cluster is unreachable, buffering writes to %s: %s", w.config.Path, err)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.append(item)
+}
+
+// append writes item to the log with w.mu held.
+func (w *WriteAheadLog) append(item *BulkItem) error {
+	entry := &walEntry{Action: item.Action, Index: item.Index, ID: item.ID, Routing: item.Routing, Pipeline: item.Pipeline, RetryOnConflict: item.RetryOnConflict, Version: item.Version}
+	if item.Body != nil {
+		body, err := marshalBody(jsonCodec{}, item.Body)
+		if err != nil {
+			return err
+		}
+		entry.Body = body
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if _, err := w.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	if w.config.Sync {
+		if err := w.file.Sync(); err != nil {
+			return err
+		}
+	}
+	w.pending++
+	return nil
+}
+
+// Pending returns the number of writes in the log.
+func (w *WriteAheadLog) Pending() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.pending
+}
+
+// Replay sends the writes of the log in order and returns the number sent.
+// It stops at the first batch failing because the cluster is unreachable, keeping the rest.
+func (w *WriteAheadLog) Replay() (int, error) {
+	w.replayMu.Lock()
+	defer w.replayMu.Unlock()
+
+	// The entries are sent without w.mu, so that Write is not blocked by the replay. The writes
+	// made meanwhile are appended since the log is not empty, and kept by the rewrite.
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return 0, errWriteAheadLogClosed
+	}
+	if w.pending == 0 {
+		w.mu.Unlock()
+		return 0, nil
+	}
+	entries, err := w.read()
+	w.mu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+
+	done := 0
+	var replayErr error
+	for done < len(entries) {
+		end := done + w.config.BatchSize
+		if end > len(entries) {
+			end = len(entries)
+		}
+		n, err := w.replay(entries[done:end])
+		done += n
+		if err != nil {
+			replayErr = err
+			break
+		}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		// The replayed entries stay in the log, to be replayed again after OpenWriteAheadLog.
+		return done, errWriteAheadLogClosed
+	}
+	all, err := w.read()
+	if err != nil {
+		return done, err
+	}
+	if err := w.rewrite(all[done:]); err != nil {
+		return done, err
+	}
+	if done > 0 {
+		w.config.Logger.Infof("Replayed %d writes from %s, %d left", done, w.config.Path, w.pending)
+	}
+	return done, replayErr
+}
+
+// replay sends batch and returns the number of entries done, either sent or dropped.
+func (w *WriteAheadLog) replay(batch []*walEntry) (int, error) {
+	items := make([]*BulkItem, len(batch))
+	for i, e := range batch {
+		items[i] = &BulkItem{Action: e.Action, Index: e.Index, ID: e.ID, Routing: e.Routing, Pipeline: e.Pipeline, RetryOnConflict: e.RetryOnConflict, Version: e.Version}
+		if len(e.Body) > 0 {
+			items[i].Body = e.Body
+		}
+	}
+
+	_, results, err := w.es.Bulk(items, w.config.Refresh)
+	var bulkErr *BulkError
+	if err != nil && !errors.As(err, &bulkErr) || len(results) != len(items) {
+		if err == nil {
+			err = fmt.Errorf("bulk returned %d results for %d items", len(results), len(items))
+		}
+		return 0, err
+	}
+
+	for i, result := range results {
+		err := result.esError()
+		if err == nil {
+			continue
+		}
+		if unreachable(err) {
+			return i, err
+		}
+		w.config.Logger.Errorf("Dropped replayed write to %s ID=%s: %s", items[i].Index, items[i].ID, err)
+		if w.config.OnDropped != nil {
+			w.config.OnDropped(items[i], err)
+		}
+	}
+	return len(items), nil
+}
+
+func (w *WriteAheadLog) read() ([]*walEntry, error) {
+	f, err := os.Open(w.config.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	entries := []*walEntry{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 64*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		entry := &walEntry{}
+		if err := json.Unmarshal(line, entry); err != nil {
+			// A line cut by a crash while appending.
+			w.config.Logger.Errorf("Skipped a broken line of %s: %s", w.config.Path, err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// rewrite replaces the log with entries with w.mu held.
+func (w *WriteAheadLog) rewrite(entries []*walEntry) error {
+	tmp := w.config.Path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	for _, entry := range entries {
+		if err := enc.Encode(entry); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	w.file.Close()
+	if err := w.rename(tmp, w.config.Path); err != nil {
+		// The log still holds every entry: keep appending to it.
+		if err := w.open(); err != nil {
+			w.config.Logger.Errorf("Error reopening %s: %s", w.config.Path, err)
+		}
+		return err
+	}
+	w.pending = len(entries)
+	return w.open()
+}
+
+// Run replays the log every ReplayInterval until ctx is done or Stop is called.
+func (w *WriteAheadLog) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.config.ReplayInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			if _, err := w.Replay(); err != nil {
+				w.config.Logger.Debugf("Replay of %s stopped: %s", w.config.Path, err)
+			}
+		}
+	}
+}
+
+// Stop makes Run return. The Close of the client stops it, then replays the log a last time.
+func (w *WriteAheadLog) Stop() {
+	w.stopOnce.Do(func() { close(w.stop) })
+}
+
+// drain stops Run and replays the log, for the Close of the client.
+// The writes it could not send stay in the log for the next OpenWriteAheadLog.
+func (w *WriteAheadLog) drain() {
+	w.Stop()
+	if _, err := w.Replay(); err != nil {
+		w.config.Logger.Warnf("Closing with %d writes left in %s: %s", w.Pending(), w.config.Path, err)
+	}
+}
+
+// Close stops Run and closes the file of the log. The pending writes stay in it for the next OpenWriteAheadLog.
+func (w *WriteAheadLog) Close() error {
+	w.Stop()
+	w.deregister()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed = true
+	return w.file.Close()
+}
+
+// unreachable reports whether err means that the write may succeed later.
+func unreachable(err error) bool {
+	var reqErr *RequestError
+	if errors.As(err, &reqErr) {
+		return true
+	}
+	var esErr *ESError
+	return errors.As(err, &esErr) && (esErr.StatusCode == 429 || esErr.StatusCode >= 500)
+}
@@ -0,0 +1,204 @@
+package elasticsearch
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteAheadLog(t *testing.T) {
+	var down int32 = 1
+	var bodies []string
+	server, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/_bulk" {
+			w.Write([]byte(`{}`))
+			return
+		}
+		if atomic.LoadInt32(&down) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"error": {"type": "cluster_block_exception", "reason": "blocked"}, "status": 503}`))
+			return
+		}
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		var items []string
+		for i := 0; i < strings.Count(string(b), "\n")/2; i++ {
+			items = append(items, `{"index": {"_index": "a", "status": 201, "result": "created"}}`)
+		}
+		w.Write([]byte(`{"errors": false, "items": [` + strings.Join(items, ",") + `]}`))
+	})
+	es, err := New(&Config{Address: []string{server.URL}, Logger: NopLogger(), Retry: &RetryPolicy{MaxAttempts: 1}})
+	assert.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "writes.log")
+	wal, err := OpenWriteAheadLog(es, &WriteAheadLogConfig{Path: path, BatchSize: 2, Logger: NopLogger()})
+	assert.NoError(t, err)
+
+	t.Run("Buffered", func(t *testing.T) {
+		for _, id := range []string{"1", "2", "3"} {
+			assert.NoError(t, wal.Write(&BulkItem{Index: "a", ID: id, Body: DocBody{Id: id}}))
+		}
+		assert.Equal(t, 3, wal.Pending())
+
+		n, err := wal.Replay()
+		assert.Error(t, err)
+		assert.Equal(t, 0, n)
+		assert.Equal(t, 3, wal.Pending())
+	})
+
+	t.Run("Reopened", func(t *testing.T) {
+		assert.NoError(t, wal.Close())
+		wal, err = OpenWriteAheadLog(es, &WriteAheadLogConfig{Path: path, BatchSize: 2, Logger: NopLogger()})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, wal.Pending())
+	})
+
+	t.Run("Replayed", func(t *testing.T) {
+		atomic.StoreInt32(&down, 0)
+		assert.NoError(t, wal.Write(&BulkItem{Index: "a", ID: "4", Body: DocBody{Id: "4"}}))
+		assert.Equal(t, 4, wal.Pending())
+		assert.Empty(t, bodies)
+
+		n, err := wal.Replay()
+		assert.NoError(t, err)
+		assert.Equal(t, 4, n)
+		assert.Equal(t, 0, wal.Pending())
+		assert.Len(t, bodies, 2)
+		assert.Contains(t, bodies[0], `"_id":"1"`)
+		assert.Contains(t, bodies[0], `"_id":"2"`)
+		assert.Contains(t, bodies[1], `"_id":"4"`)
+
+		assert.NoError(t, wal.Write(&BulkItem{Index: "a", ID: "5", Body: DocBody{Id: "5"}}))
+		assert.Equal(t, 0, wal.Pending())
+		assert.Len(t, bodies, 3)
+	})
+	wal.Close()
+
+	t.Run("Failed rewrite", func(t *testing.T) {
+		atomic.StoreInt32(&down, 1)
+		wal, err := OpenWriteAheadLog(es, &WriteAheadLogConfig{Path: filepath.Join(t.TempDir(), "writes.log"), Logger: NopLogger()})
+		assert.NoError(t, err)
+		defer wal.Close()
+		assert.NoError(t, wal.Write(&BulkItem{Index: "a", ID: "7", Body: DocBody{Id: "7"}}))
+
+		atomic.StoreInt32(&down, 0)
+		wal.rename = func(oldpath, newpath string) error { return errors.New("rename failed") }
+		_, err = wal.Replay()
+		assert.Error(t, err)
+
+		atomic.StoreInt32(&down, 1)
+		assert.NoError(t, wal.Write(&BulkItem{Index: "a", ID: "8", Body: DocBody{Id: "8"}}))
+		assert.Equal(t, 2, wal.Pending())
+	})
+
+	t.Run("Not locked while sending", func(t *testing.T) {
+		release := make(chan struct{})
+		server, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/_bulk" {
+				<-release
+			}
+			w.Write([]byte(`{"errors": false, "items": [{"index": {"_index": "a", "status": 201, "result": "created"}}]}`))
+		})
+		es, err := New(&Config{Address: []string{server.URL}, Logger: NopLogger()})
+		assert.NoError(t, err)
+		wal, err := OpenWriteAheadLog(es, &WriteAheadLogConfig{Path: filepath.Join(t.TempDir(), "writes.log"), Logger: NopLogger()})
+		assert.NoError(t, err)
+		defer wal.Close()
+
+		written := make(chan error)
+		go func() {
+			written <- wal.Write(&BulkItem{Index: "a", ID: "1", Body: DocBody{Id: "1"}})
+		}()
+
+		unlocked := make(chan struct{})
+		go func() {
+			wal.Pending()
+			wal.Replay()
+			close(unlocked)
+		}()
+		select {
+		case <-unlocked:
+		case <-time.After(time.Second):
+			t.Error("Pending and Replay wait for the Write in flight")
+		}
+		close(release)
+		assert.NoError(t, <-written)
+	})
+
+	t.Run("Write during Replay", func(t *testing.T) {
+		var slow int32
+		received, release := make(chan struct{}), make(chan struct{})
+		server, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/_bulk" {
+				w.Write([]byte(`{}`))
+				return
+			}
+			if atomic.LoadInt32(&slow) == 0 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				w.Write([]byte(`{"error": {"type": "cluster_block_exception", "reason": "blocked"}, "status": 503}`))
+				return
+			}
+			received <- struct{}{}
+			<-release
+			w.Write([]byte(`{"errors": false, "items": [{"index": {"_index": "a", "status": 201, "result": "created"}}]}`))
+		})
+		es, err := New(&Config{Address: []string{server.URL}, Logger: NopLogger(), Retry: &RetryPolicy{MaxAttempts: 1}})
+		assert.NoError(t, err)
+		wal, err := OpenWriteAheadLog(es, &WriteAheadLogConfig{Path: filepath.Join(t.TempDir(), "writes.log"), Logger: NopLogger()})
+		assert.NoError(t, err)
+		defer wal.Close()
+		assert.NoError(t, wal.Write(&BulkItem{Index: "a", ID: "1", Body: DocBody{Id: "1"}}))
+
+		atomic.StoreInt32(&slow, 1)
+		replayed := make(chan int)
+		go func() {
+			n, _ := wal.Replay()
+			replayed <- n
+		}()
+		<-received
+
+		written := make(chan error)
+		go func() {
+			written <- wal.Write(&BulkItem{Index: "a", ID: "2", Body: DocBody{Id: "2"}})
+		}()
+		select {
+		case err := <-written:
+			assert.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Error("Write waits for the Replay in flight")
+		}
+		close(release)
+		assert.Equal(t, 1, <-replayed)
+		assert.Equal(t, 1, wal.Pending())
+
+		go func() { <-received }()
+		n, err := wal.Replay()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+		assert.Equal(t, 0, wal.Pending())
+	})
+
+	t.Run("Replayed by Close", func(t *testing.T) {
+		atomic.StoreInt32(&down, 1)
+		es, err := New(&Config{Address: []string{server.URL}, Logger: NopLogger(), Retry: &RetryPolicy{MaxAttempts: 1}})
+		assert.NoError(t, err)
+		wal, err := OpenWriteAheadLog(es, &WriteAheadLogConfig{Path: filepath.Join(t.TempDir(), "writes.log"), Logger: NopLogger()})
+		assert.NoError(t, err)
+		defer wal.Close()
+
+		assert.NoError(t, wal.Write(&BulkItem{Index: "a", ID: "6", Body: DocBody{Id: "6"}}))
+		assert.Equal(t, 1, wal.Pending())
+
+		atomic.StoreInt32(&down, 0)
+		assert.NoError(t, es.Close())
+		assert.Equal(t, 0, wal.Pending())
+		assert.Contains(t, bodies[len(bodies)-1], `"_id":"6"`)
+	})
+}
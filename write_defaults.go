@@ -0,0 +1,86 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+)
+
+// WriteDefaults are the options of the writes that leave them empty, e.g.
+// RefreshWaitFor in tests and RefreshFalse in production.
+type WriteDefaults struct {
+	Refresh RefreshPolicy
+	// WaitForActiveShards is the number of shard copies that must be active before writing, or "all".
+	WaitForActiveShards string
+	// Pipeline is the ingest pipeline of indexed documents.
+	Pipeline string
+	// Routing returns the routing of a document without one, e.g. its tenant ID.
+	// An empty string routes by ID.
+	Routing func(doc *Document) string
+	// ID returns the ID of a document indexed without one by CreateDocument or Bulk, from its serialized body,
+	// e.g. ContentHashID or GeneratedID. An empty string lets Elasticsearch generate one.
+	ID func(index string, body json.RawMessage) (string, error)
+}
+
+// ContentHashID returns a WriteDefaults.ID deriving the ID of a document from the hex SHA-256 of fields,
+// or of its whole body when there are none, so that the same document sent twice, e.g. by an
+// at-least-once pipeline, is indexed once. A field may be a path such as "user.id"; missing fields are null.
+func ContentHashID(fields ...string) func(index string, body json.RawMessage) (string, error) {
+	return func(index string, body json.RawMessage) (string, error) {
+		var doc interface{}
+		dec := json.NewDecoder(bytes.NewReader(body))
+		dec.UseNumber()
+		if err := dec.Decode(&doc); err != nil {
+			return "", err
+		}
+
+		// Marshaling the decoded body sorts the keys of its objects, so that their order does not change the ID.
+		content := doc
+		if len(fields) > 0 {
+			values := make([]interface{}, len(fields))
+			for i, field := range fields {
+				values[i] = lookupField(doc, field)
+			}
+			content = values
+		}
+		b, err := json.Marshal(content)
+		if err != nil {
+			return "", err
+		}
+		sum := sha256.Sum256(b)
+		return hex.EncodeToString(sum[:]), nil
+	}
+}
+
+// lookupField returns the value at the path field of doc, or nil.
+func lookupField(doc interface{}, field string) interface{} {
+	v := doc
+	for _, name := range strings.Split(field, ".") {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = m[name]
+	}
+	return v
+}
+
+// withDefaults returns a copy of doc whose empty options are set to the write defaults.
+func (es *_elasticsearch) withDefaults(doc *Document) *Document {
+	d := *doc
+	if d.Refresh == "" {
+		d.Refresh = es.defaults.Refresh
+	}
+	if d.WaitForActiveShards == "" {
+		d.WaitForActiveShards = es.defaults.WaitForActiveShards
+	}
+	if d.Pipeline == "" {
+		d.Pipeline = es.defaults.Pipeline
+	}
+	if d.Routing == "" && es.defaults.Routing != nil {
+		d.Routing = es.defaults.Routing(doc)
+	}
+	return &d
+}
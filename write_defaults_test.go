@@ -0,0 +1,135 @@
+package elasticsearch
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteDefaults(t *testing.T) {
+	server, requests := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/_bulk") {
+			w.Write([]byte(`{"errors": false, "items": [{"index": {"_index": "x", "_id": "1", "status": 201}}]}`))
+			return
+		}
+		w.Write([]byte(`{"_id": "1", "_version": 1, "result": "created"}`))
+	})
+
+	es, err := New(&Config{
+		Address: []string{server.URL},
+		WriteDefaults: WriteDefaults{
+			Refresh:             RefreshWaitFor,
+			WaitForActiveShards: "all",
+			Pipeline:            "default",
+			Routing: func(doc *Document) string {
+				return doc.Body.(DocBody).S
+			},
+		},
+	})
+	assert.NoError(t, err)
+
+	t.Run("Defaults", func(t *testing.T) {
+		doc := &Document{Index: "x", ID: "1", Body: DocBody{Id: "1", S: "tenant1"}}
+		_, err := es.CreateDocument(doc)
+		assert.NoError(t, err)
+		assert.Empty(t, doc.Refresh)
+
+		reqs := requests()
+		q := reqs[len(reqs)-1].URL.Query()
+		assert.Equal(t, "wait_for", q.Get("refresh"))
+		assert.Equal(t, "all", q.Get("wait_for_active_shards"))
+		assert.Equal(t, "default", q.Get("pipeline"))
+		assert.Equal(t, "tenant1", q.Get("routing"))
+	})
+
+	t.Run("Overridden", func(t *testing.T) {
+		_, err := es.CreateDocument(&Document{
+			Index:    "x",
+			ID:       "1",
+			Body:     DocBody{Id: "1", S: "tenant1"},
+			Refresh:  RefreshFalse,
+			Routing:  "r",
+			Pipeline: "other",
+		})
+		assert.NoError(t, err)
+
+		reqs := requests()
+		q := reqs[len(reqs)-1].URL.Query()
+		assert.Equal(t, "false", q.Get("refresh"))
+		assert.Equal(t, "other", q.Get("pipeline"))
+		assert.Equal(t, "r", q.Get("routing"))
+	})
+
+	t.Run("Bulk", func(t *testing.T) {
+		_, _, err := es.Bulk([]*BulkItem{{Index: "x", ID: "1", Body: DocBody{Id: "1", S: "tenant1"}}}, "")
+		assert.NoError(t, err)
+
+		reqs := requests()
+		q := reqs[len(reqs)-1].URL.Query()
+		assert.Equal(t, "wait_for", q.Get("refresh"))
+		assert.Equal(t, "default", q.Get("pipeline"))
+	})
+}
+
+func TestContentHashID(t *testing.T) {
+	t.Run("Fields", func(t *testing.T) {
+		id := ContentHashID("source", "event.id")
+
+		a, err := id("x", []byte(`{"source": "s", "event": {"id": 1}, "received_at": "2024-01-01"}`))
+		assert.NoError(t, err)
+		b, err := id("x", []byte(`{"event": {"id": 1}, "source": "s", "received_at": "2024-01-02"}`))
+		assert.NoError(t, err)
+		c, err := id("x", []byte(`{"source": "s", "event": {"id": 2}}`))
+		assert.NoError(t, err)
+
+		assert.Len(t, a, 64)
+		assert.Equal(t, a, b)
+		assert.NotEqual(t, a, c)
+	})
+
+	t.Run("Body", func(t *testing.T) {
+		id := ContentHashID()
+
+		a, _ := id("x", []byte(`{"a": 1, "b": 2}`))
+		b, _ := id("x", []byte(`{"b": 2, "a": 1}`))
+		c, _ := id("x", []byte(`{"a": 1, "b": 3}`))
+		assert.Equal(t, a, b)
+		assert.NotEqual(t, a, c)
+
+		_, err := id("x", []byte(`{`))
+		assert.Error(t, err)
+	})
+
+	t.Run("Writes", func(t *testing.T) {
+		var body string
+		server, requests := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			b, _ := io.ReadAll(r.Body)
+			body = string(b)
+			w.Write([]byte(`{}`))
+		})
+		es, err := New(&Config{Address: []string{server.URL}, WriteDefaults: WriteDefaults{ID: ContentHashID("id")}})
+		assert.NoError(t, err)
+		id, _ := ContentHashID("id")("x", []byte(`{"id": "1"}`))
+
+		_, err = es.CreateDocument(&Document{Index: "x", Body: DocBody{Id: "1"}})
+		assert.NoError(t, err)
+		reqs := requests()
+		assert.Equal(t, "/x/_doc/"+id, reqs[len(reqs)-1].URL.Path)
+
+		_, err = es.CreateDocument(&Document{Index: "x", ID: "given", Body: DocBody{Id: "1"}})
+		assert.NoError(t, err)
+		reqs = requests()
+		assert.Equal(t, "/x/_doc/given", reqs[len(reqs)-1].URL.Path)
+
+		_, _, err = es.Bulk([]*BulkItem{
+			{Index: "x", Body: DocBody{Id: "1"}},
+			{Action: BulkUpdate, Index: "x", ID: "2", Body: DocBody{Id: "2"}},
+		}, "")
+		assert.NoError(t, err)
+		assert.Contains(t, body, `{"index":{"_id":"`+id+`","_index":"x"}}`)
+		assert.Contains(t, body, `{"update":{"_id":"2","_index":"x"}}`)
+	})
+}
@@ -0,0 +1,64 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// WriteEvent is a document write of CreateDocument, UpdateDocument, RemoveDocument or Bulk, given to the WriteHooks.
+type WriteEvent struct {
+	Action BulkAction
+	Index  string
+	// ID is empty before the write of a document without ID, and set to the generated ID after it.
+	ID      string
+	Routing string
+	// Body is the serialized document, the fields of a BulkUpdate, or nil for a BulkDelete.
+	Body json.RawMessage
+
+	// Result is "created", "updated", "deleted" or "noop" after a successful write.
+	Result string
+	// Err is the error of the write of this document, after it.
+	Err error
+}
+
+// WriteHook is called around the document writes, e.g. to publish change events for change data capture:
+//
+//	func (h *kafkaHook) AfterWrite(ctx context.Context, events []*WriteEvent) {
+//		for _, e := range events {
+//			if e.Err == nil {
+//				h.producer.Publish(ctx, e.Index, e)
+//			}
+//		}
+//	}
+//
+// It is called synchronously with the events of a single write, or of all the items of a bulk request in order.
+type WriteHook interface {
+	// BeforeWrite is called before the request. The Body of the events may be replaced, e.g. to redact fields,
+	// and is what is written. An error cancels the write, which returns it with StatusInternalError
+	// without sending the request, nor calling AfterWrite.
+	BeforeWrite(ctx context.Context, events []*WriteEvent) error
+	// AfterWrite is called after the request, even when it failed.
+	AfterWrite(ctx context.Context, events []*WriteEvent)
+}
+
+// beforeWrite calls the BeforeWrite of the hooks until one fails.
+func (es *_elasticsearch) beforeWrite(events []*WriteEvent) error {
+	for _, hook := range es.hooks {
+		if err := hook.BeforeWrite(es.ctx, events); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// afterWrite sets the error of the events without one to err, and calls the AfterWrite of the hooks.
+func (es *_elasticsearch) afterWrite(events []*WriteEvent, err error) {
+	for _, e := range events {
+		if e.Err == nil {
+			e.Err = err
+		}
+	}
+	for _, hook := range es.hooks {
+		hook.AfterWrite(es.ctx, events)
+	}
+}
@@ -0,0 +1,110 @@
+package elasticsearch
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingHook records the events of the writes, and fails BeforeWrite with err.
+type recordingHook struct {
+	before, after [][]*WriteEvent
+	err           error
+}
+
+func (h *recordingHook) BeforeWrite(ctx context.Context, events []*WriteEvent) error {
+	copied := make([]*WriteEvent, len(events))
+	for i, e := range events {
+		c := *e
+		copied[i] = &c
+	}
+	h.before = append(h.before, copied)
+	return h.err
+}
+
+func (h *recordingHook) AfterWrite(ctx context.Context, events []*WriteEvent) {
+	h.after = append(h.after, events)
+}
+
+func TestWriteHooks(t *testing.T) {
+	server, requests := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/p-a/_doc":
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"_id": "gen-1", "result": "created"}`))
+		case strings.HasSuffix(r.URL.Path, "/_update"):
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": {"type": "document_missing_exception", "reason": "missing"}}`))
+		case r.URL.Path == "/_bulk":
+			w.Write([]byte(`{"errors": true, "items": [
+				{"index": {"_index": "p-a", "_id": "1", "status": 201, "result": "created"}},
+				{"delete": {"_index": "p-a", "_id": "2", "status": 404, "result": "not_found", "error": {"type": "not_found", "reason": "missing"}}}
+			]}`))
+		default:
+			w.Write([]byte(`{}`))
+		}
+	})
+	hook := &recordingHook{}
+	es, err := New(&Config{Address: []string{server.URL}, IndexPrefix: "p-", Logger: NopLogger()}, WithWriteHook(hook))
+	assert.NoError(t, err)
+
+	t.Run("CreateDocument", func(t *testing.T) {
+		hook.before, hook.after = nil, nil
+
+		_, err := es.CreateDocument(&Document{Index: "a", Body: strings.NewReader(`{"id":"1"}`)})
+		assert.NoError(t, err)
+
+		assert.Equal(t, [][]*WriteEvent{{{Action: BulkIndex, Index: "a", Body: []byte(`{"id":"1"}`)}}}, hook.before)
+		assert.Equal(t, [][]*WriteEvent{{{Action: BulkIndex, Index: "a", ID: "gen-1", Body: []byte(`{"id":"1"}`), Result: "created"}}}, hook.after)
+	})
+
+	t.Run("UpdateDocument failed", func(t *testing.T) {
+		hook.before, hook.after = nil, nil
+
+		_, err := es.UpdateDocument(&Document{Index: "a", ID: "1", Body: map[string]string{"id": "2"}})
+		assert.ErrorIs(t, err, ErrNotFound)
+
+		assert.Len(t, hook.after, 1)
+		assert.Equal(t, BulkUpdate, hook.after[0][0].Action)
+		assert.JSONEq(t, `{"id":"2"}`, string(hook.after[0][0].Body))
+		assert.ErrorIs(t, hook.after[0][0].Err, ErrNotFound)
+	})
+
+	t.Run("Bulk", func(t *testing.T) {
+		hook.before, hook.after = nil, nil
+
+		_, _, err := es.Bulk([]*BulkItem{
+			{Index: "a", ID: "1", Body: map[string]string{"id": "1"}},
+			{Action: BulkDelete, Index: "a", ID: "2"},
+		}, RefreshFalse)
+		assert.Error(t, err)
+
+		assert.Len(t, hook.after, 1)
+		events := hook.after[0]
+		assert.Len(t, events, 2)
+		assert.Equal(t, "a", events[0].Index)
+		assert.Equal(t, "created", events[0].Result)
+		assert.NoError(t, events[0].Err)
+		assert.Equal(t, BulkDelete, events[1].Action)
+		assert.Nil(t, events[1].Body)
+		assert.ErrorIs(t, events[1].Err, ErrNotFound)
+	})
+
+	t.Run("BeforeWrite cancels the write", func(t *testing.T) {
+		hook.before, hook.after = nil, nil
+		hook.err = errors.New("refused")
+		defer func() { hook.err = nil }()
+		sent := len(requests())
+
+		status, err := es.RemoveDocument(&Document{Index: "a", ID: "1"})
+		assert.EqualError(t, err, "refused")
+		assert.Equal(t, StatusInternalError, status)
+		assert.Len(t, hook.before, 1)
+		assert.Empty(t, hook.after)
+		assert.Len(t, requests(), sent)
+	})
+}